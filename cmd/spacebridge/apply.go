@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/apply"
+	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/diff"
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/secrets"
+	"github.com/jnesspace/spacebridge/internal/transform"
+	"github.com/jnesspace/spacebridge/internal/ui"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// applyOptions holds the apply command flags.
+type applyOptions struct {
+	manifestPath string
+	configPath   string
+	spaceFilter  string
+	mappingPath  string
+	secretsFrom  []string
+	disabled     bool
+	dryRun       bool
+}
+
+// newApplyCmd creates the apply command.
+func newApplyCmd() *cobra.Command {
+	var opts applyOptions
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Create resources in the destination through the API, without OpenTofu",
+		Long: `Creates spaces, contexts, environment variables, mounted files, policies,
+stacks, attachments and stack dependencies directly in the destination
+account through the Spacelift GraphQL API, for environments where
+OpenTofu cannot be run.
+
+Resources are created in dependency order: spaces (parents first),
+contexts and their config, policies, stacks, then attachments.
+Administrative stacks get a SPACE_ADMIN role binding in their space
+instead of the deprecated administrative flag, as in generated code. Every
+source ID is recorded with its destination ID in the mapping file as soon
+as the resource is created. Existing resources are found through the
+mapping file or by name and left unchanged, so rerunning after a partial
+failure creates nothing twice.
+
+Not created:
+  - AWS/Azure integrations (they need cloud-side trust changes); stacks
+    are attached to destination integrations with the same name
+  - Stacks other than Terraform, OpenTofu and Terragrunt
+  - Secret values that no secret source provides (see: secrets fill)
+
+Example usage:
+  # Preview what would be created
+  spacebridge apply -m manifest.json --dry-run
+
+  # Create everything, stacks with autodeploy off, secrets from the environment
+  spacebridge apply -m manifest.json --disabled --secrets-from env
+
+  # Use space remapping, VCS rules and transforms
+  spacebridge apply -m manifest.json -c spacebridge.yaml --mapping id-mapping.json`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runApply(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().StringVarP(&opts.configPath, "config", "c", "", "Migration config YAML file (VCS overrides, space remapping, transforms)")
+	cmd.Flags().StringVarP(&opts.spaceFilter, "space", "s", "", "Only include resources from this space (and its children)")
+	cmd.Flags().StringVar(&opts.mappingPath, "mapping", "id-mapping.json", "ID mapping file (source ID → destination ID), read and updated")
+	cmd.Flags().StringArrayVar(&opts.secretsFrom, "secrets-from", nil, "Secret source type[:path] for write-only values; repeatable")
+	cmd.Flags().BoolVarP(&opts.disabled, "disabled", "d", false, "Create stacks with autodeploy disabled for safe state migration")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be created without making changes")
+
+	return cmd
+}
+
+// applyResource is a resource that apply created, found or skipped.
+type applyResource struct {
+	Status string `json:"status"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	ID     string `json:"id,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// applyResult is the result of apply.
+type applyResult struct {
+	DryRun      bool            `json:"dryRun"`
+	Resources   []applyResource `json:"resources"`
+	Created     int             `json:"created"`
+	Planned     int             `json:"planned"`
+	Existing    int             `json:"existing"`
+	Skipped     int             `json:"skipped"`
+	MappingPath string          `json:"mappingPath,omitempty"`
+	// Error is the error that stopped apply, after the resources above
+	Error   string `json:"error,omitempty"`
+	stopped error
+}
+
+// runApply creates the manifest's resources in the destination.
+func runApply(opts applyOptions) error {
+	result, err := applyResources(opts)
+	if err != nil {
+		return err
+	}
+	if err := render(result); err != nil {
+		return err
+	}
+	if result.stopped != nil {
+		return fmt.Errorf("apply stopped: %w", result.stopped)
+	}
+	return nil
+}
+
+// applyResources creates the manifest's resources in the destination,
+// printing each as it goes.
+func applyResources(opts applyOptions) (*applyResult, error) {
+	if err := cfg.ValidateDestination(); err != nil {
+		return nil, fmt.Errorf("destination configuration error: %w", err)
+	}
+	secretSources, err := parseSecretSources(opts.secretsFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := loadManifest(opts.manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.spaceFilter != "" {
+		fmt.Fprintf(progressOut, "Filtering to space: %s (and children)\n", opts.spaceFilter)
+		manifest = filterManifestBySpace(manifest, opts.spaceFilter)
+	}
+	sourceManifest := manifest
+
+	var migCfg *config.MigrationConfig
+	if opts.configPath != "" {
+		if migCfg, err = loadMigrationConfig(opts.configPath); err != nil {
+			return nil, err
+		}
+		secretSources = append(migCfg.Secrets.Sources, secretSources...)
+		if len(migCfg.Transforms) > 0 {
+			if manifest, _, err = transform.Apply(manifest, migCfg.Transforms); err != nil {
+				return nil, fmt.Errorf("failed to apply transforms: %w", err)
+			}
+		}
+	}
+
+	mapping, err := diff.LoadMapping(opts.mappingPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		mapping, err = make(diff.Mapping), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	// Secrets are looked up by source context name, before transforms
+	secretValues := make(map[string]string)
+	if len(secretSources) > 0 {
+		sources, err := secrets.NewAll(ctx, secretSources)
+		if err != nil {
+			return nil, err
+		}
+		resolved, err := secrets.Resolve(ctx, secrets.Collect(sourceManifest), sources, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+		}
+		for _, r := range resolved.Resolved {
+			secretValues[r.Variable] = r.Value
+		}
+	}
+
+	destClient, err := client.New(cfg.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
+	}
+	fmt.Fprintf(progressOut, "Discovering destination account: %s\n", cfg.Destination.URL)
+	dest, err := discovery.New(destClient).DiscoverAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover destination resources: %w", err)
+	}
+
+	fmt.Fprintln(progressOut, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(progressOut, "│                      APPLY VIA API                          │")
+	fmt.Fprintln(progressOut, "└─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintln(progressOut)
+
+	result := &applyResult{DryRun: opts.dryRun, Resources: []applyResource{}}
+	runErr := apply.Run(ctx, destClient, manifest, dest, apply.Options{
+		DryRun:          opts.dryRun,
+		SafeMode:        opts.disabled,
+		MigrationConfig: migCfg,
+		Secrets:         secretValues,
+		Mapping:         mapping,
+		MappingPath:     opts.mappingPath,
+		OnEvent: func(e apply.Event) {
+			result.Resources = append(result.Resources, applyResource{Status: e.Status, Kind: e.Kind, Name: e.Name, ID: e.ID, Detail: e.Detail})
+			switch e.Status {
+			case apply.StatusCreated:
+				result.Created++
+				fmt.Fprintf(progressOut, "  ✓ Created %s %s %s\n", e.Kind, e.Name, formatDestID(e.ID))
+			case apply.StatusPlanned:
+				result.Planned++
+				fmt.Fprintf(progressOut, "  + Would create %s %s\n", e.Kind, e.Name)
+			case apply.StatusExists:
+				result.Existing++
+				if verbose {
+					fmt.Fprintf(progressOut, "  ○ Exists %s %s %s\n", e.Kind, e.Name, formatDestID(e.ID))
+				}
+			case apply.StatusSkipped:
+				result.Skipped++
+			}
+		},
+	})
+	if runErr != nil {
+		fmt.Fprintf(progressOut, "  ✗ %v\n", runErr)
+		result.Error, result.stopped = runErr.Error(), runErr
+	}
+	if !opts.dryRun {
+		result.MappingPath = opts.mappingPath
+	}
+
+	return result, nil
+}
+
+func (r *applyResult) renderText(w io.Writer) {
+	var skipped []applyResource
+	for _, res := range r.Resources {
+		if res.Status == apply.StatusSkipped {
+			skipped = append(skipped, res)
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(w, "\n⚠ SKIPPED (%d)\n", len(skipped))
+		for _, res := range skipped {
+			fmt.Fprintf(w, "    • %s %s: %s\n", res.Kind, res.Name, res.Detail)
+		}
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	createdHeader, created := "CREATED", r.Created
+	if r.DryRun {
+		createdHeader, created = "TO CREATE", r.Planned
+	}
+	fmt.Fprint(w, ui.RenderTable([]string{createdHeader, "ALREADY EXISTED", "SKIPPED"}, [][]string{{
+		fmt.Sprint(created), fmt.Sprint(r.Existing), fmt.Sprint(r.Skipped),
+	}}))
+
+	if r.DryRun {
+		fmt.Fprintln(w, "\nDRY RUN - No changes made")
+		return
+	}
+	fmt.Fprintf(w, "\nID mapping written to: %s\n", r.MappingPath)
+	if r.stopped != nil {
+		fmt.Fprintln(w, "Fix the error and rerun: resources created so far will not be created again.")
+	}
+}
+
+// formatDestID formats a destination ID for display.
+func formatDestID(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "(" + id + ")"
+}
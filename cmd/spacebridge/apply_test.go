@@ -0,0 +1,130 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jnesspace/spacebridge/internal/apply"
+	"github.com/jnesspace/spacebridge/internal/fakespacelift"
+	"github.com/jnesspace/spacebridge/internal/generator"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// withoutDestinationApp removes the app stack from the destination, so that
+// apply creates it with its dependency.
+func withoutDestinationApp(fixture *fakespacelift.Fixture) {
+	var stacks []models.Stack
+	for _, stack := range fixture.Destination.Stacks {
+		if stack.ID != "app" {
+			stacks = append(stacks, stack)
+		}
+	}
+	fixture.Destination.Stacks = stacks
+}
+
+// created returns the kind and name of the resources apply created.
+func created(result *applyResult) []string {
+	var resources []string
+	for _, res := range result.Resources {
+		if res.Status == apply.StatusCreated {
+			resources = append(resources, res.Kind+" "+res.Name)
+		}
+	}
+	return resources
+}
+
+func TestApplyIsIdempotent(t *testing.T) {
+	_, destination := startEditedFixture(t, withoutDestinationApp)
+	opts := applyOptions{mappingPath: filepath.Join(t.TempDir(), "id-mapping.json"), disabled: true}
+
+	first, err := applyResources(opts)
+	if err != nil {
+		t.Fatalf("applyResources: %v", err)
+	}
+	if first.stopped != nil {
+		t.Fatalf("first apply stopped: %v", first.stopped)
+	}
+	want := map[string]bool{
+		"context aws-defaults":                      true,
+		"env var aws-defaults/AWS_REGION":           true,
+		"policy no-destroy":                         true,
+		"stack app":                                 true,
+		"context attachment network ← aws-defaults": true,
+		"policy attachment network ← no-destroy":    true,
+		"dependency app → network":                  true,
+	}
+	got := created(first)
+	for _, res := range got {
+		if !want[res] {
+			t.Errorf("first apply created %s", res)
+		}
+		delete(want, res)
+	}
+	for res := range want {
+		t.Errorf("first apply did not create %s", res)
+	}
+
+	app := accountStack(t, destination, "app")
+	if app.Autodeploy || app.Space != "production-02" || len(app.DependsOn) != 1 {
+		t.Errorf("created app stack: autodeploy %t, space %s, %d dependencies", app.Autodeploy, app.Space, len(app.DependsOn))
+	}
+
+	second, err := applyResources(opts)
+	if err != nil {
+		t.Fatalf("applyResources: %v", err)
+	}
+	if second.stopped != nil {
+		t.Fatalf("second apply stopped: %v", second.stopped)
+	}
+	if got := created(second); len(got) != 0 {
+		t.Errorf("second apply created %v", got)
+	}
+	if second.Existing != first.Existing+first.Created {
+		t.Errorf("second apply found %d existing resources, want %d", second.Existing, first.Existing+first.Created)
+	}
+	if got, want := len(destination.Account().Contexts), 1; got != want {
+		t.Errorf("destination has %d contexts, want %d", got, want)
+	}
+}
+
+func TestApplyBindsAdminRole(t *testing.T) {
+	_, destination := startEditedFixture(t, func(fixture *fakespacelift.Fixture) {
+		for i := range fixture.Source.Stacks {
+			if fixture.Source.Stacks[i].ID == "network" {
+				fixture.Source.Stacks[i].Administrative = true
+			}
+		}
+	})
+	opts := applyOptions{mappingPath: filepath.Join(t.TempDir(), "id-mapping.json")}
+
+	for run := 1; run <= 2; run++ {
+		result, err := applyResources(opts)
+		if err != nil {
+			t.Fatalf("applyResources: %v", err)
+		}
+		if result.stopped != nil {
+			t.Fatalf("apply %d stopped: %v", run, result.stopped)
+		}
+		statuses := make(map[string]string)
+		for _, res := range result.Resources {
+			statuses[res.Kind+" "+res.Name] = res.Status
+		}
+		want := apply.StatusCreated
+		if run == 2 {
+			want = apply.StatusExists
+		}
+		for _, res := range []string{"role " + generator.AdminRoleName, "role attachment network ← " + generator.AdminRoleName} {
+			if statuses[res] != want {
+				t.Errorf("apply %d: %s is %q, want %q", run, res, statuses[res], want)
+			}
+		}
+	}
+
+	roles := destination.Account().Roles
+	if len(roles) != 1 {
+		t.Fatalf("destination has %d roles, want 1", len(roles))
+	}
+	if roles[0].Name != generator.AdminRoleName || len(roles[0].Stacks) != 1 || roles[0].Stacks[0] != "network" {
+		t.Errorf("destination role %s is bound to %v, want %s bound to [network]", roles[0].Name, roles[0].Stacks, generator.AdminRoleName)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/dedup"
+)
+
+// dedupOptions holds the dedup command flags.
+type dedupOptions struct {
+	manifestPath string
+	apply        bool
+	outputFile   string
+}
+
+// newDedupCmd creates the dedup command.
+func newDedupCmd() *cobra.Command {
+	var opts dedupOptions
+	cmd := &cobra.Command{
+		Use:   "dedup",
+		Short: "Merge copy-pasted policies and contexts",
+		Long: `Finds policies with the same body, type and engine, and contexts with the
+same config and hooks, and proposes to merge each set of copies into one
+resource:
+
+  - the merged resource goes to the deepest space holding all the copies
+  - every stack attached to a copy must inherit from that space
+    (inherit_entities on each space up the way), or the set is skipped
+  - attachments to the copies are moved to the merged resource
+
+Resources with autoattach labels are left alone, since moving them would
+attach them to more stacks. Contexts with secrets are reported but not
+merged, since secret values cannot be read and compared.
+
+By default this only reports. With --apply the merged manifest is written to
+--output-file, ready for generate -m. generate --dedup merges on the fly.
+
+Example usage:
+  spacebridge dedup -m manifest.json
+  spacebridge dedup -m manifest.json --apply -o manifest.dedup.json
+  spacebridge generate -m manifest.json -o ./tofu/ --dedup`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDedup(opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().BoolVar(&opts.apply, "apply", false, "Write the merged manifest instead of only reporting")
+	cmd.Flags().StringVarP(&opts.outputFile, "output-file", "o", "manifest.dedup.json", "With --apply, file to write the merged manifest to")
+	return cmd
+}
+
+// dedupResult is the result of dedup.
+type dedupResult struct {
+	*dedup.Report
+	Applied    bool   `json:"applied"`
+	OutputFile string `json:"outputFile,omitempty"`
+}
+
+// runDedup reports, and with --apply writes, the merges of a manifest.
+func runDedup(opts dedupOptions) error {
+	manifest, err := loadManifest(opts.manifestPath)
+	if err != nil {
+		return err
+	}
+
+	result := dedupResult{Report: dedup.Find(manifest)}
+	if opts.apply && len(result.Merged()) > 0 {
+		merged, err := result.Apply(manifest)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(merged, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal manifest: %w", err)
+		}
+		if err := os.WriteFile(opts.outputFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write manifest file: %w", err)
+		}
+		result.Applied = true
+		result.OutputFile = opts.outputFile
+	}
+	return render(result)
+}
+
+func (r dedupResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                    DEDUPLICATION                            │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+
+	for _, kind := range []struct{ kind, title string }{
+		{dedup.KindPolicy, "POLICIES"},
+		{dedup.KindContext, "CONTEXTS"},
+	} {
+		var merges []dedup.Merge
+		for _, merge := range r.Merges {
+			if merge.Kind == kind.kind {
+				merges = append(merges, merge)
+			}
+		}
+		if len(merges) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d)\n", kind.title, len(merges))
+		for _, merge := range merges {
+			if merge.Skipped != "" {
+				fmt.Fprintf(w, "    ⚠ %s = %s\n", merge.Keep, strings.Join(merge.Duplicates, ", "))
+				fmt.Fprintf(w, "      Skipped: %s\n", merge.Skipped)
+				continue
+			}
+			fmt.Fprintf(w, "    ✓ %s ← %s\n", merge.Keep, strings.Join(merge.Duplicates, ", "))
+			move := "stays in space " + merge.Space
+			if merge.Space != merge.FromSpace {
+				move = fmt.Sprintf("moves from space %s to %s", merge.FromSpace, merge.Space)
+			}
+			fmt.Fprintf(w, "      %s, %d attachments rewritten\n", move, merge.Attachments)
+		}
+	}
+
+	merged := r.Merged()
+	duplicates := 0
+	for _, merge := range merged {
+		duplicates += len(merge.Duplicates)
+	}
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Merges: %d (%d duplicates removed) | Skipped: %d\n", len(merged), duplicates, len(r.Merges)-len(merged))
+
+	switch {
+	case len(r.Merges) == 0:
+		fmt.Fprintln(w, "\n✓ No duplicate policies or contexts found")
+	case r.Applied:
+		fmt.Fprintf(w, "\n✓ Merged manifest written to: %s\n", r.OutputFile)
+		fmt.Fprintf(w, "  Generate from it with: spacebridge generate -m %s\n", r.OutputFile)
+	case len(merged) > 0:
+		fmt.Fprintln(w, "\nDRY RUN - No changes made")
+		fmt.Fprintln(w, "Run with --apply to write the merged manifest, or generate with --dedup")
+	}
+}
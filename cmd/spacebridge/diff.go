@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/diff"
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/ui"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// diffOptions holds the flags shared by the diff commands.
+type diffOptions struct {
+	mappingPath string
+	format      string
+	exitCode    bool
+}
+
+// addFlags registers the shared diff flags on a command.
+func (o *diffOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.mappingPath, "mapping", "", "JSON file mapping old resource IDs to new IDs per kind")
+	cmd.Flags().StringVar(&o.format, "format", "text", "Output format: text or json")
+	cmd.Flags().BoolVar(&o.exitCode, "exit-code", false, "Exit non-zero if there are differences")
+}
+
+// newDiffCmd creates the live diff command.
+func newDiffCmd() *cobra.Command {
+	var (
+		source      bool
+		destination bool
+		manifest    string
+		opts        diffOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare live accounts and manifests resource by resource",
+		Long: `Compares two sets of resources and prints a per-field diff of stacks,
+contexts (including config keys, but never secret values), policies,
+spaces, integrations and their attachments.
+
+Exactly two sides must be chosen:
+  --manifest/-m  a manifest file (the old side)
+  --source       the live source account
+  --destination  the live destination account (the new side)
+
+Resources are matched by the --mapping file first, then by ID, then by
+name. References between resources are compared by name, so attachments
+are compared correctly across accounts.
+
+Example usage:
+  # What changed in the source account since the export?
+  spacebridge diff -m manifest.json --source
+
+  # What is still missing or different in the destination?
+  spacebridge diff --source --destination
+
+  # Machine-readable output
+  spacebridge diff --source --destination --format json > diff.json`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.format == "json" {
+				// Keep stdout for the diff
+				setProgressOut(cmd.ErrOrStderr())
+			}
+			return runDiff(source, destination, manifest, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&source, "source", false, "Discover the live source account")
+	cmd.Flags().BoolVar(&destination, "destination", false, "Discover the live destination account")
+	cmd.Flags().StringVarP(&manifest, "manifest", "m", "", "Manifest file to compare")
+	opts.addFlags(cmd)
+
+	return cmd
+}
+
+// runDiff compares the two selected sides.
+func runDiff(source, destination bool, manifestPath string, opts diffOptions) error {
+	type side struct {
+		label string
+		load  func() (*discovery.Manifest, error)
+	}
+
+	var sides []side
+	if manifestPath != "" {
+		sides = append(sides, side{manifestPath, func() (*discovery.Manifest, error) {
+			return readManifestFile(manifestPath)
+		}})
+	}
+	if source {
+		sides = append(sides, side{"source: " + cfg.Source.URL, func() (*discovery.Manifest, error) {
+			if err := cfg.ValidateSource(); err != nil {
+				return nil, fmt.Errorf("source configuration error: %w", err)
+			}
+			return discoverAccount("source", cfg.Source)
+		}})
+	}
+	if destination {
+		sides = append(sides, side{"destination: " + cfg.Destination.URL, func() (*discovery.Manifest, error) {
+			if err := cfg.ValidateDestination(); err != nil {
+				return nil, fmt.Errorf("destination configuration error: %w", err)
+			}
+			return discoverAccount("destination", cfg.Destination)
+		}})
+	}
+	if len(sides) != 2 {
+		return fmt.Errorf("choose exactly two of --manifest, --source and --destination")
+	}
+
+	oldM, err := sides[0].load()
+	if err != nil {
+		return err
+	}
+	newM, err := sides[1].load()
+	if err != nil {
+		return err
+	}
+
+	return compareManifests(sides[0].label, sides[1].label, oldM, newM, opts)
+}
+
+// newManifestDiffCmd creates the manifest diff command.
+func newManifestDiffCmd() *cobra.Command {
+	var opts diffOptions
+
+	cmd := &cobra.Command{
+		Use:   "diff <old.json> <new.json>",
+		Short: "Compare two manifest files resource by resource",
+		Long: `Compares two manifest files and prints a per-field diff of stacks,
+contexts (including config keys, but never secret values), policies,
+spaces, integrations and their attachments.
+
+Resources are matched by the --mapping file first, then by ID, then by
+name.
+
+Example usage:
+  spacebridge manifest diff before.json after.json
+  spacebridge manifest diff source.json dest.json --mapping id-mapping.json
+  spacebridge manifest diff a.json b.json --format json`,
+		Args:        cobra.ExactArgs(2),
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldM, err := readManifestFile(args[0])
+			if err != nil {
+				return err
+			}
+			newM, err := readManifestFile(args[1])
+			if err != nil {
+				return err
+			}
+			return compareManifests(args[0], args[1], oldM, newM, opts)
+		},
+	}
+	opts.addFlags(cmd)
+
+	return cmd
+}
+
+// readManifestFile reads a manifest file without printing progress, so JSON
+// output stays clean.
+func readManifestFile(path string) (*discovery.Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	manifest, _, err := discovery.ParseManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest file %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// discoverAccount discovers every resource in an account.
+func discoverAccount(label string, account config.AccountConfig) (*discovery.Manifest, error) {
+	fmt.Fprintf(progressOut, "Discovering %s account: %s\n", label, account.URL)
+
+	c, err := client.New(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s client: %w", label, err)
+	}
+
+	manifest, err := discovery.New(c).DiscoverAll(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover %s resources: %w", label, err)
+	}
+	return manifest, nil
+}
+
+// diffResult is the result of diff and manifest diff.
+type diffResult struct {
+	*diff.Result
+}
+
+// compareManifests diffs two manifests and prints the result.
+func compareManifests(oldLabel, newLabel string, oldM, newM *discovery.Manifest, opts diffOptions) error {
+	var mapping diff.Mapping
+	if opts.mappingPath != "" {
+		var err error
+		if mapping, err = diff.LoadMapping(opts.mappingPath); err != nil {
+			return err
+		}
+	}
+
+	result := &diffResult{diff.Compare(oldM, newM, mapping)}
+	result.Old, result.New = oldLabel, newLabel
+
+	switch opts.format {
+	case "json":
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff: %w", err)
+		}
+		fmt.Fprintln(resultOut, string(data))
+	case "text":
+		if err := render(result); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q (expected text or json)", opts.format)
+	}
+
+	if opts.exitCode && result.HasChanges() {
+		return fmt.Errorf("manifests differ")
+	}
+	return nil
+}
+
+// diffKindTitles are the section titles for each resource kind.
+var diffKindTitles = map[string]string{
+	diff.KindSpaces:            "SPACES",
+	diff.KindStacks:            "STACKS",
+	diff.KindContexts:          "CONTEXTS",
+	diff.KindPolicies:          "POLICIES",
+	diff.KindAWSIntegrations:   "AWS INTEGRATIONS",
+	diff.KindAzureIntegrations: "AZURE INTEGRATIONS",
+}
+
+// renderText writes the diff grouped by resource kind.
+func (r *diffResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                        MANIFEST DIFF                        │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(w, "\n--- %s\n+++ %s\n", r.Old, r.New)
+
+	for _, kind := range diff.Kinds {
+		var resources []diff.ResourceDiff
+		for _, res := range r.Resources {
+			if res.Kind == kind {
+				resources = append(resources, res)
+			}
+		}
+		if len(resources) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n%s (%d)\n", diffKindTitles[kind], len(resources))
+		for _, res := range resources {
+			switch res.Status {
+			case diff.StatusAdded:
+				fmt.Fprintf(w, "  + %s (%s)\n", res.Name, res.NewID)
+			case diff.StatusRemoved:
+				fmt.Fprintf(w, "  - %s (%s)\n", res.Name, res.OldID)
+			case diff.StatusChanged:
+				match := ""
+				if res.OldID != res.NewID {
+					match = fmt.Sprintf(" (%s → %s, matched by %s)", res.OldID, res.NewID, res.MatchedBy)
+				}
+				fmt.Fprintf(w, "  ~ %s%s\n", res.Name, match)
+				for _, c := range res.Changes {
+					printFieldChange(w, c)
+				}
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	var rows [][]string
+	for _, kind := range diff.Kinds {
+		c := r.Summary[kind]
+		rows = append(rows, []string{
+			diffKindTitles[kind],
+			fmt.Sprint(c.Added), fmt.Sprint(c.Removed), fmt.Sprint(c.Changed), fmt.Sprint(c.Unchanged),
+		})
+	}
+	fmt.Fprint(w, ui.RenderTable([]string{"KIND", "ADDED", "REMOVED", "CHANGED", "UNCHANGED"}, rows))
+
+	if !r.HasChanges() {
+		fmt.Fprintln(w, "\n✓ No differences")
+	}
+}
+
+// printFieldChange writes one field change. Multi-line values such as
+// policy bodies are shown as a line diff.
+func printFieldChange(w io.Writer, c diff.FieldChange) {
+	if strings.Contains(c.Old, "\n") || strings.Contains(c.New, "\n") {
+		fmt.Fprintf(w, "      %s:\n", c.Field)
+		for _, line := range lineDiff(c.Old, c.New) {
+			fmt.Fprintf(w, "        %s\n", line)
+		}
+		return
+	}
+	fmt.Fprintf(w, "      %s: %s → %s\n", c.Field, displayValue(c.Old), displayValue(c.New))
+}
+
+// lineDiff returns the lines removed from and added to a multi-line value,
+// in order, prefixed with - and +.
+func lineDiff(oldText, newText string) []string {
+	oldLines := strings.Split(oldText, "\n")
+	newLines := strings.Split(newText, "\n")
+
+	// Longest common subsequence keeps unchanged lines out of the output
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			i++
+			j++
+		case j < len(newLines) && (i == len(oldLines) || lcs[i][j+1] >= lcs[i+1][j]):
+			out = append(out, "+ "+newLines[j])
+			j++
+		default:
+			out = append(out, "- "+oldLines[i])
+			i++
+		}
+	}
+	return out
+}
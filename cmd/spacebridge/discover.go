@@ -3,10 +3,16 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
 	"github.com/jnesspace/spacebridge/internal/ui"
+	"github.com/jnesspace/spacebridge/internal/unused"
 )
 
 // newDiscoverCmd creates the discover command group.
@@ -22,6 +28,7 @@ func newDiscoverCmd() *cobra.Command {
 		newDiscoverContextsCmd(),
 		newDiscoverPoliciesCmd(),
 		newDiscoverAllCmd(),
+		newDiscoverUnusedCmd(),
 	)
 
 	return cmd
@@ -30,8 +37,9 @@ func newDiscoverCmd() *cobra.Command {
 // newDiscoverSpacesCmd creates the discover spaces command.
 func newDiscoverSpacesCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "spaces",
-		Short: "Discover all spaces with hierarchy",
+		Use:         "spaces",
+		Short:       "Discover all spaces with hierarchy",
+		Annotations: structuredOutput(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			svc, err := createDiscoveryService()
 			if err != nil {
@@ -44,9 +52,7 @@ func newDiscoverSpacesCmd() *cobra.Command {
 				return fmt.Errorf("failed to discover spaces: %w", err)
 			}
 
-			ui.PrintSpaces(spaces)
-			fmt.Printf("\nTotal: %d spaces\n", len(spaces))
-			return nil
+			return render(discoverSpacesResult{Spaces: spaces, Total: len(spaces)})
 		},
 	}
 }
@@ -54,8 +60,9 @@ func newDiscoverSpacesCmd() *cobra.Command {
 // newDiscoverStacksCmd creates the discover stacks command.
 func newDiscoverStacksCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "stacks",
-		Short: "Discover all stacks",
+		Use:         "stacks",
+		Short:       "Discover all stacks",
+		Annotations: structuredOutput(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			svc, err := createDiscoveryService()
 			if err != nil {
@@ -68,8 +75,7 @@ func newDiscoverStacksCmd() *cobra.Command {
 				return fmt.Errorf("failed to discover stacks: %w", err)
 			}
 
-			ui.PrintStacks(stacks)
-			return nil
+			return render(discoverStacksResult{Stacks: stacks, Total: len(stacks)})
 		},
 	}
 }
@@ -77,8 +83,9 @@ func newDiscoverStacksCmd() *cobra.Command {
 // newDiscoverContextsCmd creates the discover contexts command.
 func newDiscoverContextsCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "contexts",
-		Short: "Discover all contexts",
+		Use:         "contexts",
+		Short:       "Discover all contexts",
+		Annotations: structuredOutput(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			svc, err := createDiscoveryService()
 			if err != nil {
@@ -91,9 +98,7 @@ func newDiscoverContextsCmd() *cobra.Command {
 				return fmt.Errorf("failed to discover contexts: %w", err)
 			}
 
-			ui.PrintContexts(contexts)
-			ui.PrintSecretsWarning(contexts)
-			return nil
+			return render(discoverContextsResult{Contexts: contexts, Total: len(contexts)})
 		},
 	}
 }
@@ -101,8 +106,9 @@ func newDiscoverContextsCmd() *cobra.Command {
 // newDiscoverPoliciesCmd creates the discover policies command.
 func newDiscoverPoliciesCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "policies",
-		Short: "Discover all policies",
+		Use:         "policies",
+		Short:       "Discover all policies",
+		Annotations: structuredOutput(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			svc, err := createDiscoveryService()
 			if err != nil {
@@ -115,8 +121,7 @@ func newDiscoverPoliciesCmd() *cobra.Command {
 				return fmt.Errorf("failed to discover policies: %w", err)
 			}
 
-			ui.PrintPolicies(policies)
-			return nil
+			return render(discoverPoliciesResult{Policies: policies, Total: len(policies)})
 		},
 	}
 }
@@ -124,8 +129,9 @@ func newDiscoverPoliciesCmd() *cobra.Command {
 // newDiscoverAllCmd creates the discover all command.
 func newDiscoverAllCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "all",
-		Short: "Discover all resources",
+		Use:         "all",
+		Short:       "Discover all resources",
+		Annotations: structuredOutput(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			svc, err := createDiscoveryService()
 			if err != nil {
@@ -133,21 +139,197 @@ func newDiscoverAllCmd() *cobra.Command {
 			}
 
 			ctx := context.Background()
-			fmt.Println("Discovering all resources...")
+			fmt.Fprintln(progressOut, "Discovering all resources...")
 
 			manifest, err := svc.DiscoverAll(ctx)
 			if err != nil {
 				return fmt.Errorf("failed to discover resources: %w", err)
 			}
 
-			ui.PrintSpaces(manifest.Spaces)
-			ui.PrintStacks(manifest.Stacks)
-			ui.PrintContexts(manifest.Contexts)
-			ui.PrintPolicies(manifest.Policies)
-			ui.PrintSecretsWarning(manifest.Contexts)
-			ui.PrintSummary(manifest)
+			return render(discoverAllResult{
+				Manifest:       manifest,
+				Summary:        manifest.Summary(),
+				SecretsToEnter: manifest.SecretsCount(),
+			})
+		},
+	}
+}
+
+// defaultStaleDays is how long a disabled stack's state must be unchanged
+// for the stack to count as unused.
+const defaultStaleDays = 90
+
+// newDiscoverUnusedCmd creates the discover unused command.
+func newDiscoverUnusedCmd() *cobra.Command {
+	var manifestPath string
+	var days int
+	cmd := &cobra.Command{
+		Use:   "unused",
+		Short: "Report resources that nothing uses",
+		Long: `Reports the resources that are not worth migrating:
+
+  - contexts attached to no stack and without autoattach labels
+  - policies attached to no stack and without autoattach labels (login
+    policies apply to the whole account and are always used)
+  - AWS and Azure integrations attached to no stack
+  - disabled stacks whose state has not changed for --stale-days days,
+    unless a stack still in use depends on them
+  - spaces that hold none of the resources still in use
 
-			return nil
+Resources only attached to unused stacks are unused too. Modules are not
+discovered, so contexts and policies that only modules use are reported as
+unused; check them before pruning.
+
+Contexts with the same config and hooks are also listed, as candidates to
+merge.
+
+Leave the unused resources out of the generated code with:
+  spacebridge generate --prune-unused
+
+Example usage:
+  spacebridge discover unused
+  spacebridge discover unused -m manifest.json --stale-days 180`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cutoff, err := staleBefore(days)
+			if err != nil {
+				return err
+			}
+			manifest, err := loadManifest(manifestPath)
+			if err != nil {
+				return err
+			}
+			return render(discoverUnusedResult{Report: unused.Find(manifest, cutoff), StaleDays: days})
 		},
 	}
+	cmd.Flags().StringVarP(&manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().IntVar(&days, "stale-days", defaultStaleDays, "Report disabled stacks whose state has not changed for this many days")
+	return cmd
+}
+
+// staleBefore returns the time before which the state of a disabled stack
+// must have last changed for the stack to count as unused.
+func staleBefore(days int) (time.Time, error) {
+	if days < 0 {
+		return time.Time{}, fmt.Errorf("--stale-days must not be negative, got %d", days)
+	}
+	return time.Now().AddDate(0, 0, -days), nil
+}
+
+// discoverSpacesResult is the result of discover spaces.
+type discoverSpacesResult struct {
+	Spaces []models.Space `json:"spaces"`
+	Total  int            `json:"total"`
+}
+
+func (r discoverSpacesResult) renderText(w io.Writer) {
+	ui.PrintSpaces(w, r.Spaces)
+	fmt.Fprintf(w, "\nTotal: %d spaces\n", r.Total)
+}
+
+// discoverStacksResult is the result of discover stacks.
+type discoverStacksResult struct {
+	Stacks []models.Stack `json:"stacks"`
+	Total  int            `json:"total"`
+}
+
+func (r discoverStacksResult) renderText(w io.Writer) {
+	ui.PrintStacks(w, r.Stacks)
+}
+
+// discoverContextsResult is the result of discover contexts. Secret values
+// are write-only, so they are never included.
+type discoverContextsResult struct {
+	Contexts []models.Context `json:"contexts"`
+	Total    int              `json:"total"`
+}
+
+func (r discoverContextsResult) renderText(w io.Writer) {
+	ui.PrintContexts(w, r.Contexts)
+	ui.PrintSecretsWarning(w, r.Contexts)
+}
+
+// discoverPoliciesResult is the result of discover policies.
+type discoverPoliciesResult struct {
+	Policies []models.Policy `json:"policies"`
+	Total    int             `json:"total"`
+}
+
+func (r discoverPoliciesResult) renderText(w io.Writer) {
+	ui.PrintPolicies(w, r.Policies)
+}
+
+// discoverAllResult is the result of discover all.
+type discoverAllResult struct {
+	Manifest       *discovery.Manifest `json:"manifest"`
+	Summary        map[string]int      `json:"summary"`
+	SecretsToEnter int                 `json:"secretsToEnter"` // Write-only values to re-enter in the destination
+}
+
+func (r discoverAllResult) renderText(w io.Writer) {
+	ui.PrintSpaces(w, r.Manifest.Spaces)
+	ui.PrintStacks(w, r.Manifest.Stacks)
+	ui.PrintContexts(w, r.Manifest.Contexts)
+	ui.PrintPolicies(w, r.Manifest.Policies)
+	ui.PrintSecretsWarning(w, r.Manifest.Contexts)
+	ui.PrintSummary(w, r.Manifest)
+}
+
+// discoverUnusedResult is the result of discover unused.
+type discoverUnusedResult struct {
+	*unused.Report
+	StaleDays int `json:"staleDays"`
+}
+
+// unusedKinds are the section titles for each kind of unused resource, in
+// report order.
+var unusedKinds = []struct{ kind, title string }{
+	{unused.KindStack, "STACKS"},
+	{unused.KindContext, "CONTEXTS"},
+	{unused.KindPolicy, "POLICIES"},
+	{unused.KindAWSIntegration, "AWS INTEGRATIONS"},
+	{unused.KindAzureIntegration, "AZURE INTEGRATIONS"},
+	{unused.KindSpace, "SPACES"},
+}
+
+func (r discoverUnusedResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                    UNUSED RESOURCES                         │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+
+	for _, k := range unusedKinds {
+		var resources []unused.Resource
+		for _, res := range r.Resources {
+			if res.Kind == k.kind {
+				resources = append(resources, res)
+			}
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d)\n", k.title, len(resources))
+		for _, res := range resources {
+			fmt.Fprintf(w, "    ○ %s (%s): %s\n", res.Name, res.ID, res.Reason)
+		}
+	}
+
+	if len(r.DuplicateContexts) > 0 {
+		fmt.Fprintf(w, "\nDUPLICATE CONTEXTS (%d)\n", len(r.DuplicateContexts))
+		for _, group := range r.DuplicateContexts {
+			fmt.Fprintf(w, "    ⚠ %s\n", strings.Join(group.Contexts, ", "))
+			note := ""
+			if group.Secrets {
+				note = " (secret values not compared)"
+			}
+			fmt.Fprintf(w, "      same config: %s%s\n", strings.Join(group.Keys, ", "), note)
+		}
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Unused: %d | Duplicate context groups: %d | Stale after: %d days\n", len(r.Resources), len(r.DuplicateContexts), r.StaleDays)
+	if len(r.Resources) == 0 {
+		fmt.Fprintln(w, "\n✓ No unused resources found")
+		return
+	}
+	fmt.Fprintln(w, "\nLeave them out of the generated code with: spacebridge generate --prune-unused")
 }
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jnesspace/spacebridge/internal/fakespacelift"
+	"github.com/jnesspace/spacebridge/internal/generator"
+	"github.com/jnesspace/spacebridge/internal/journal"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// startFixture serves the source and destination accounts of the example
+// fixture, and points the configuration and journal at them for the test.
+func startFixture(t *testing.T) (source, destination *fakespacelift.Server) {
+	t.Helper()
+	return startEditedFixture(t, func(*fakespacelift.Fixture) {})
+}
+
+// startEditedFixture is startFixture with the fixture changed by edit first.
+func startEditedFixture(t *testing.T, edit func(*fakespacelift.Fixture)) (source, destination *fakespacelift.Server) {
+	t.Helper()
+	fixture, err := fakespacelift.LoadFixture("../../fixture.example.json")
+	if err != nil {
+		t.Fatalf("LoadFixture: %v", err)
+	}
+	edit(fixture)
+
+	source, err = fakespacelift.Start(fixture.Source)
+	if err != nil {
+		t.Fatalf("Start source: %v", err)
+	}
+	t.Cleanup(func() { source.Close() })
+	destination, err = fakespacelift.Start(fixture.Destination)
+	if err != nil {
+		t.Fatalf("Start destination: %v", err)
+	}
+	t.Cleanup(func() { destination.Close() })
+
+	savedCfg, savedJournal := cfg, journalPath
+	t.Cleanup(func() { cfg, journalPath = savedCfg, savedJournal })
+	cfg = &config.Config{
+		Source:      config.AccountConfig{URL: source.URL, KeyID: "fake", SecretKey: "fake"},
+		Destination: config.AccountConfig{URL: destination.URL, KeyID: "fake", SecretKey: "fake"},
+	}
+	journalPath = filepath.Join(t.TempDir(), journal.DefaultPath)
+	return source, destination
+}
+
+func TestMigrateEndToEnd(t *testing.T) {
+	source, destination := startFixture(t)
+	dir := t.TempDir()
+
+	// Discover
+	svc, err := createDiscoveryService()
+	if err != nil {
+		t.Fatalf("createDiscoveryService: %v", err)
+	}
+	manifest, err := svc.DiscoverAll(context.Background())
+	if err != nil {
+		t.Fatalf("DiscoverAll: %v", err)
+	}
+	if got, want := len(manifest.Stacks), len(source.Account().Stacks); got != want {
+		t.Fatalf("discovered %d stacks, want %d", got, want)
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("Marshal manifest: %v", err)
+	}
+	manifestPath := filepath.Join(dir, "manifest.json")
+	if err := os.WriteFile(manifestPath, data, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	// Generate
+	outputDir := filepath.Join(dir, "tofu")
+	if err := runGenerate(generateOptions{outputDir: outputDir, manifestPath: manifestPath, disabled: true, format: generator.FormatHCL}); err != nil {
+		t.Fatalf("runGenerate: %v", err)
+	}
+	main, err := os.ReadFile(filepath.Join(outputDir, "main.tf"))
+	if err != nil {
+		t.Fatalf("ReadFile main.tf: %v", err)
+	}
+	for _, stack := range manifest.Stacks {
+		if !strings.Contains(string(main), `"`+stack.Name+`"`) {
+			t.Errorf("main.tf has no stack %s", stack.Name)
+		}
+	}
+
+	// Migrate state, after enabling external access on the stacks without it
+	if err := runStateEnableAccess("", selectorOptions{}); err != nil {
+		t.Fatalf("runStateEnableAccess: %v", err)
+	}
+	if err := runStateMigrate(stateMigrateOptions{freezeSource: true}); err != nil {
+		t.Fatalf("runStateMigrate: %v", err)
+	}
+
+	sourceStates := source.Account().States
+	destStates := destination.Account().States
+	for id, state := range sourceStates {
+		if !bytes.Equal(destStates[id], state) {
+			t.Errorf("destination state of %s = %s, want %s", id, destStates[id], state)
+		}
+	}
+	for _, stack := range source.Account().Stacks {
+		if _, ok := sourceStates[stack.ID]; ok && !stack.IsDisabled {
+			t.Errorf("source stack %s not disabled by --freeze-source", stack.ID)
+		}
+	}
+
+	// The journal records every change, so that rollback can undo it
+	entries, err := journal.Load(journalPath)
+	if err != nil {
+		t.Fatalf("journal.Load: %v", err)
+	}
+	actions := make(map[string][]string)
+	for _, e := range entries {
+		actions[e.Action] = append(actions[e.Action], e.StackID())
+	}
+	want := map[string][]string{
+		journal.ExternalAccessEnabled: {"app"},
+		journal.SourceLocked:          {"network", "app"},
+		journal.StateImported:         {"network", "app"},
+		journal.SourceDisabled:        {"network", "app"},
+	}
+	for action, stacks := range want {
+		if got := strings.Join(actions[action], ","); got != strings.Join(stacks, ",") {
+			t.Errorf("journaled %s for %q, want %q", action, got, strings.Join(stacks, ","))
+		}
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	_, destination := startFixture(t)
+
+	if err := runStateMigrate(stateMigrateOptions{dryRun: true, freezeSource: true}); err != nil {
+		t.Fatalf("runStateMigrate: %v", err)
+	}
+	if states := destination.Account().States; len(states) != 0 {
+		t.Errorf("dry run imported %d states", len(states))
+	}
+	if _, err := os.Stat(journalPath); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote the journal: %v", err)
+	}
+}
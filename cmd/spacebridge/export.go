@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/spf13/cobra"
 
+	"github.com/jnesspace/spacebridge/internal/discovery"
 	"github.com/jnesspace/spacebridge/internal/ui"
 )
 
@@ -16,11 +18,12 @@ var outputFile string
 // newExportCmd creates the export command.
 func newExportCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "export",
-		Short: "Export all resources to a manifest file",
-		RunE:  runExport,
+		Use:         "export",
+		Short:       "Export all resources to a manifest file",
+		Annotations: structuredOutput(),
+		RunE:        runExport,
 	}
-	cmd.Flags().StringVarP(&outputFile, "output", "o", "manifest.json", "Output file path")
+	cmd.Flags().StringVarP(&outputFile, "output-file", "o", "manifest.json", "Output file path")
 	return cmd
 }
 
@@ -32,7 +35,7 @@ func runExport(cmd *cobra.Command, args []string) error {
 	}
 
 	ctx := context.Background()
-	fmt.Println("Discovering all resources for export...")
+	fmt.Fprintln(progressOut, "Discovering all resources for export...")
 
 	manifest, err := svc.DiscoverAll(ctx)
 	if err != nil {
@@ -50,8 +53,17 @@ func runExport(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to write manifest file: %w", err)
 	}
 
-	fmt.Printf("Manifest exported to: %s\n", outputFile)
-	ui.PrintSummary(manifest)
+	return render(exportResult{OutputFile: outputFile, Summary: manifest.Summary(), manifest: manifest})
+}
+
+// exportResult is the result of export.
+type exportResult struct {
+	OutputFile string         `json:"outputFile"`
+	Summary    map[string]int `json:"summary"`
+	manifest   *discovery.Manifest
+}
 
-	return nil
+func (r exportResult) renderText(w io.Writer) {
+	fmt.Fprintf(w, "Manifest exported to: %s\n", r.OutputFile)
+	ui.PrintSummary(w, r.manifest)
 }
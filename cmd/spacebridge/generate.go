@@ -2,28 +2,40 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 
 	"github.com/spf13/cobra"
 
+	"github.com/jnesspace/spacebridge/internal/dedup"
 	"github.com/jnesspace/spacebridge/internal/discovery"
 	"github.com/jnesspace/spacebridge/internal/generator"
 	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/internal/secrets"
+	"github.com/jnesspace/spacebridge/internal/transform"
+	"github.com/jnesspace/spacebridge/internal/unused"
 	"github.com/jnesspace/spacebridge/pkg/config"
 )
 
-var (
-	generateDir     string
-	manifestInput   string
-	disableStacks   bool
-	filterSpace     string
-	migrationConfig string
-)
+// generateOptions holds the generate command flags.
+type generateOptions struct {
+	outputDir        string
+	manifestPath     string
+	disabled         bool
+	spaceFilter      string
+	configPath       string
+	format           string
+	secretsFrom      []string
+	allowInlineCreds bool
+	selectors        selectorOptions
+	pruneUnused      bool
+	staleDays        int
+	dedup            bool
+}
 
 // newGenerateCmd creates the generate command.
 func newGenerateCmd() *cobra.Command {
+	var opts generateOptions
 	cmd := &cobra.Command{
 		Use:   "generate",
 		Short: "Generate Tofu code from discovered resources",
@@ -34,7 +46,29 @@ The generated code includes:
   - main.tf:      All Spacelift resources (spaces, stacks, contexts, policies)
   - variables.tf: Variable declarations for secrets
   - secrets.auto.tfvars.template: Template for secret values
-  - provider.tf:  Spacelift provider configuration
+  - provider.tf:  Spacelift provider configuration (no credentials)
+  - .gitignore:   Keeps secrets.auto.tfvars and provider.auto.tfvars out of git
+
+The provider reads credentials from the spacelift_api_key_* variables or the
+SPACELIFT_API_KEY_* environment variables. With --allow-inline-credentials the
+destination API key is written to the git-ignored provider.auto.tfvars.
+Without it, generation fails if an API secret would end up in any .tf file.
+
+Stacks can be picked with --label, --name-regex, --stack-ids-file and
+--vendor, and left out with the --exclude-* variants of these flags. The
+stacks they depend on, and the contexts, policies, integrations and spaces
+they use, come with them.
+
+With --prune-unused, the resources that spacebridge discover unused reports
+are left out: unattached contexts, policies and integrations, empty spaces,
+and disabled stacks whose state has not changed for --stale-days days.
+
+With --dedup, copies of the same policy or context are merged into one, as
+spacebridge dedup --apply would.
+
+With --format json the same resources are written as main.tf.json,
+variables.tf.json and provider.tf.json for tools that post-process
+the generated code.
 
 Example usage:
   # Generate from live discovery (stacks disabled for safe migration)
@@ -44,55 +78,155 @@ Example usage:
   spacebridge generate -m manifest.json -o ./tofu/
 
   # Generate with VCS override (e.g., use GitHub App instead of built-in)
-  spacebridge generate -o ./tofu/ -c spacebridge.yaml`,
-		RunE: runGenerate,
-	}
-	cmd.Flags().StringVarP(&generateDir, "output", "o", "./generated", "Output directory for Tofu files")
-	cmd.Flags().StringVarP(&manifestInput, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
-	cmd.Flags().BoolVarP(&disableStacks, "disabled", "d", false, "Create stacks as disabled for safe state migration")
-	cmd.Flags().StringVarP(&filterSpace, "space", "s", "", "Only include resources from this space (and its children)")
-	cmd.Flags().StringVarP(&migrationConfig, "config", "c", "", "Migration config YAML file for VCS overrides")
+  spacebridge generate -o ./tofu/ -c spacebridge.yaml
+
+  # Generate Tofu JSON syntax instead of HCL
+  spacebridge generate -o ./tofu/ --format json
+
+  # Generate the stacks picked with spacebridge select
+  spacebridge generate -o ./tofu/ --selection selection.yaml
+
+  # Generate the payments team's stacks, except Ansible ones
+  spacebridge generate -o ./tofu/ --label team:payments --exclude-vendor Ansible
+
+  # Leave unused resources behind (see: spacebridge discover unused)
+  spacebridge generate -o ./tofu/ --prune-unused --stale-days 180
+
+  # Fill secrets.auto.tfvars from secret stores (see: spacebridge secrets fill)
+  spacebridge generate -o ./tofu/ --secrets-from env --secrets-from sops:secrets.enc.yaml`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerate(opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.outputDir, "output-dir", "o", "./generated", "Output directory for Tofu files")
+	cmd.Flags().StringVarP(&opts.manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().BoolVarP(&opts.disabled, "disabled", "d", false, "Create stacks as disabled for safe state migration")
+	cmd.Flags().StringVarP(&opts.spaceFilter, "space", "s", "", "Only include resources from this space (and its children)")
+	cmd.Flags().StringVarP(&opts.configPath, "config", "c", "", "Migration config YAML file (VCS overrides, space remapping, transforms)")
+	cmd.Flags().StringVar(&opts.format, "format", generator.FormatHCL, "Output format: hcl (.tf) or json (.tf.json)")
+	cmd.Flags().BoolVar(&opts.allowInlineCreds, "allow-inline-credentials", false, "Write destination API credentials to provider.auto.tfvars and skip the credentials check")
+	cmd.Flags().StringArrayVar(&opts.secretsFrom, "secrets-from", nil, "Secret source type[:path] used to fill secrets.auto.tfvars; repeatable")
+	cmd.Flags().BoolVar(&opts.pruneUnused, "prune-unused", false, "Leave out unused resources (see: spacebridge discover unused)")
+	cmd.Flags().BoolVar(&opts.dedup, "dedup", false, "Merge identical policies and contexts (see: spacebridge dedup)")
+	cmd.Flags().IntVar(&opts.staleDays, "stale-days", defaultStaleDays, "With --prune-unused, leave out disabled stacks whose state has not changed for this many days")
+	opts.selectors.addFlags(cmd)
 	return cmd
 }
 
+// generatedResources counts the resources in generated Tofu code.
+type generatedResources struct {
+	Spaces            int `json:"spaces"`
+	MappedSpaces      int `json:"mappedSpaces"` // Referenced by ID, not generated
+	Contexts          int `json:"contexts"`
+	Policies          int `json:"policies"`
+	Stacks            int `json:"stacks"`
+	AWSIntegrations   int `json:"awsIntegrations"`
+	AzureIntegrations int `json:"azureIntegrations"`
+}
+
+// generateResult is the result of generate.
+type generateResult struct {
+	OutputDir           string             `json:"outputDir"`
+	Format              string             `json:"format"`
+	Files               []string           `json:"files"`
+	Resources           generatedResources `json:"resources"`
+	SafeMode            bool               `json:"safeMode"`
+	ManagedStateStacks  int                `json:"managedStateStacks"`  // Tofu stacks with Spacelift-managed state
+	AutodeployStacks    int                `json:"autodeployStacks"`    // Stacks that had autodeploy enabled
+	NeedsExternalAccess []string           `json:"needsExternalAccess"` // Managed-state stacks without external state access
+	SecretsToEnter      int                `json:"secretsToEnter"`      // Secret values still to be filled in
+	MissingSecrets      []string           `json:"missingSecrets"`      // context/key of secrets no source resolved
+	InlineCredentials   bool               `json:"inlineCredentials"`
+
+	missingSecrets []secrets.Secret
+	secretSources  []config.SecretSource
+}
+
 // runGenerate generates Tofu code from a manifest.
-func runGenerate(cmd *cobra.Command, args []string) error {
-	var manifest *discovery.Manifest
+func runGenerate(opts generateOptions) error {
+	result, err := generateCode(opts)
+	if err != nil {
+		return err
+	}
+	return render(result)
+}
 
-	if manifestInput != "" {
-		// Load from file
-		fmt.Printf("Loading manifest from: %s\n", manifestInput)
-		data, err := os.ReadFile(manifestInput)
-		if err != nil {
-			return fmt.Errorf("failed to read manifest file: %w", err)
-		}
+// generateCode writes Tofu code for the manifest and source selected by
+// the generate options.
+func generateCode(opts generateOptions) (*generateResult, error) {
+	if err := generator.ValidateFormat(opts.format); err != nil {
+		return nil, err
+	}
+	secretSources, err := parseSecretSources(opts.secretsFrom)
+	if err != nil {
+		return nil, err
+	}
+	stackSel, err := opts.selectors.load()
+	if err != nil {
+		return nil, err
+	}
 
-		manifest = &discovery.Manifest{}
-		if err := json.Unmarshal(data, manifest); err != nil {
-			return fmt.Errorf("failed to parse manifest file: %w", err)
-		}
-	} else {
-		// Discover fresh
-		svc, err := createDiscoveryService()
+	manifest, err := loadManifest(opts.manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prune unused resources before filtering, since whether a resource is
+	// used depends on the whole account
+	if opts.pruneUnused {
+		cutoff, err := staleBefore(opts.staleDays)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		report := unused.Find(manifest, cutoff)
+		manifest = report.Prune(manifest)
+		fmt.Fprintf(progressOut, "Pruned %d unused resources - run 'spacebridge discover unused' to see them\n", len(report.Resources))
+	}
 
-		ctx := context.Background()
-		fmt.Println("Discovering resources for code generation...")
-
-		manifest, err = svc.DiscoverAll(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to discover resources: %w", err)
+	// Merge duplicates before filtering too, since copies span spaces
+	if opts.dedup {
+		report := dedup.Find(manifest)
+		if manifest, err = report.Apply(manifest); err != nil {
+			return nil, err
 		}
+		fmt.Fprintf(progressOut, "Merged %d sets of duplicate policies and contexts - run 'spacebridge dedup' to see them\n", len(report.Merged()))
 	}
 
 	// Apply space filter if specified
-	if filterSpace != "" {
-		fmt.Printf("Filtering to space: %s (and children)\n", filterSpace)
-		manifest = filterManifestBySpace(manifest, filterSpace)
+	if opts.spaceFilter != "" {
+		fmt.Fprintf(progressOut, "Filtering to space: %s (and children)\n", opts.spaceFilter)
+		manifest = filterManifestBySpace(manifest, opts.spaceFilter)
 		if len(manifest.Stacks) == 0 && len(manifest.Contexts) == 0 && len(manifest.Policies) == 0 {
-			return fmt.Errorf("no resources found in space '%s'", filterSpace)
+			return nil, fmt.Errorf("no resources found in space '%s'", opts.spaceFilter)
+		}
+	}
+
+	// Apply stack selectors and selection if specified
+	if manifest, err = stackSel.manifest(manifest); err != nil {
+		return nil, err
+	}
+
+	sourceManifest := manifest
+
+	// Load migration config if provided
+	var migCfg *config.MigrationConfig
+	if opts.configPath != "" {
+		migCfg, err = loadMigrationConfig(opts.configPath)
+		if err != nil {
+			return nil, err
+		}
+		secretSources = append(migCfg.Secrets.Sources, secretSources...)
+
+		// Transforms rewrite the manifest before anything is generated.
+		// Secrets are still looked up by source context name.
+		if len(migCfg.Transforms) > 0 {
+			transformed, changes, err := transform.Apply(manifest, migCfg.Transforms)
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply transforms: %w", err)
+			}
+			manifest = transformed
+			fmt.Fprintf(progressOut, "Applied %d transforms (%d changes) - run 'spacebridge transform preview' to see them\n", len(migCfg.Transforms), len(changes))
 		}
 	}
 
@@ -107,124 +241,166 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Generate Tofu code
-	fmt.Printf("\nGenerating Tofu code to: %s\n", generateDir)
-	gen := generator.New(manifest, generateDir).WithSafeMode(disableStacks)
+	fmt.Fprintf(progressOut, "\nGenerating Tofu code to: %s\n", opts.outputDir)
+	gen := generator.New(manifest, opts.outputDir).
+		WithOutput(progressOut).
+		WithSafeMode(opts.disabled).
+		WithFormat(opts.format).
+		WithInlineCredentials(opts.allowInlineCreds).
+		WithSensitiveValues(cfg.Source.SecretKey, cfg.Destination.SecretKey)
 
 	// Use destination config if available for provider.tf
 	if cfg.HasDestination() {
 		gen.WithDestinationConfig(&cfg.Destination)
 	}
 
-	// Load migration config if provided
-	if migrationConfig != "" {
-		fmt.Printf("Loading migration config from: %s\n", migrationConfig)
-		migCfg, err := config.LoadMigrationConfig(migrationConfig)
-		if err != nil {
-			return fmt.Errorf("failed to load migration config: %w", err)
-		}
-		if err := migCfg.Validate(); err != nil {
-			return fmt.Errorf("invalid migration config: %w", err)
-		}
+	if migCfg != nil {
 		gen.WithMigrationConfig(migCfg)
-		if migCfg.Destination.VCS.HasVCSOverride() {
-			fmt.Println("VCS override configured - stacks will use custom VCS integration")
+		if len(migCfg.Destination.VCSRules) > 0 {
+			fmt.Fprintf(progressOut, "%d VCS rules configured - run 'spacebridge vcs plan' to see which rule matches each stack\n", len(migCfg.Destination.VCSRules))
+		} else if migCfg.Destination.VCS.HasVCSOverride() {
+			fmt.Fprintln(progressOut, "VCS override configured - stacks will use custom VCS integration")
 		}
 	}
 
 	if err := gen.Generate(); err != nil {
-		return fmt.Errorf("failed to generate Tofu code: %w", err)
+		return nil, fmt.Errorf("failed to generate Tofu code: %w", err)
+	}
+
+	result := &generateResult{
+		OutputDir:           opts.outputDir,
+		Format:              opts.format,
+		Files:               gen.Files(),
+		SafeMode:            opts.disabled,
+		NeedsExternalAccess: []string{},
+		MissingSecrets:      []string{},
+		SecretsToEnter:      secretCount,
+		InlineCredentials:   opts.allowInlineCreds && cfg.HasDestination(),
+		secretSources:       secretSources,
 	}
 
+	// Fill secrets.auto.tfvars from secret sources
+	if len(secretSources) > 0 && secretCount > 0 {
+		filled, path, err := fillSecrets(context.Background(), sourceManifest, secretSources, opts.outputDir, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fill secrets: %w", err)
+		}
+		fmt.Fprintf(progressOut, "  Created: %s (%d of %d secrets resolved)\n", path, len(filled.Resolved), secretCount)
+		result.Files = append(result.Files, path)
+		result.SecretsToEnter = len(filled.Missing)
+		result.missingSecrets = filled.Missing
+		for _, secret := range filled.Missing {
+			result.MissingSecrets = append(result.MissingSecrets, secret.ContextName+"/"+secret.Key)
+		}
+	}
+
+	// Count generated spaces (root and mapped spaces are not generated as resources)
+	for _, space := range manifest.Spaces {
+		if gen.IsSpaceGenerated(space.ID) {
+			result.Resources.Spaces++
+		} else if space.ID != "root" {
+			result.Resources.MappedSpaces++
+		}
+	}
+	result.Resources.Contexts = len(manifest.Contexts)
+	result.Resources.Policies = len(manifest.Policies)
+	result.Resources.Stacks = len(manifest.Stacks)
+	result.Resources.AWSIntegrations = len(manifest.AWSIntegrations)
+	result.Resources.AzureIntegrations = len(manifest.AzureIntegrations)
+
 	// Count stacks with managed state, autodeploy, and external state access
-	managedStateCount := 0
-	autodeployCount := 0
-	needsAccessCount := 0
-	var needsAccessStacks []string
 	for _, stack := range manifest.Stacks {
 		if stack.ManagesStateFile && stack.IsTerraform() {
-			managedStateCount++
+			result.ManagedStateStacks++
 			if !stack.ExternalStateAccessEnabled {
-				needsAccessCount++
-				needsAccessStacks = append(needsAccessStacks, stack.Name)
+				result.NeedsExternalAccess = append(result.NeedsExternalAccess, stack.Name)
 			}
 		}
 		if stack.Autodeploy {
-			autodeployCount++
+			result.AutodeployStacks++
 		}
 	}
 
-	// Print summary
-	fmt.Println("\n✓ Tofu code generated successfully!")
-	fmt.Println("\nGenerated resources:")
-	// Count non-root spaces (root is not generated as a resource)
-	nonRootSpaces := 0
-	for _, space := range manifest.Spaces {
-		if space.ID != "root" {
-			nonRootSpaces++
-		}
+	return result, nil
+}
+
+func (r *generateResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "\n✓ Tofu code generated successfully!")
+	fmt.Fprintln(w, "\nGenerated resources:")
+	fmt.Fprintf(w, "  - Spaces:             %d\n", r.Resources.Spaces)
+	if r.Resources.MappedSpaces > 0 {
+		fmt.Fprintf(w, "  - Mapped spaces:      %d (referenced by ID, not generated)\n", r.Resources.MappedSpaces)
 	}
-	fmt.Printf("  - Spaces:             %d\n", nonRootSpaces)
-	fmt.Printf("  - Contexts:           %d\n", len(manifest.Contexts))
-	fmt.Printf("  - Policies:           %d\n", len(manifest.Policies))
-	fmt.Printf("  - Stacks:             %d\n", len(manifest.Stacks))
-	fmt.Printf("  - AWS Integrations:   %d\n", len(manifest.AWSIntegrations))
-	fmt.Printf("  - Azure Integrations: %d\n", len(manifest.AzureIntegrations))
-
-	if disableStacks {
-		fmt.Println("\n🔒 Safe migration mode enabled:")
-		fmt.Printf("   - All stacks created with autodeploy = false\n")
-		if autodeployCount > 0 {
-			fmt.Printf("   - %d stacks need autodeploy re-enabled after migration\n", autodeployCount)
-			fmt.Printf("   - See autodeploy_re_enable.tf.disabled\n")
+	fmt.Fprintf(w, "  - Contexts:           %d\n", r.Resources.Contexts)
+	fmt.Fprintf(w, "  - Policies:           %d\n", r.Resources.Policies)
+	fmt.Fprintf(w, "  - Stacks:             %d\n", r.Resources.Stacks)
+	fmt.Fprintf(w, "  - AWS Integrations:   %d\n", r.Resources.AWSIntegrations)
+	fmt.Fprintf(w, "  - Azure Integrations: %d\n", r.Resources.AzureIntegrations)
+
+	if r.SafeMode {
+		fmt.Fprintln(w, "\n🔒 Safe migration mode enabled:")
+		fmt.Fprintf(w, "   - All stacks created with autodeploy = false\n")
+		if r.AutodeployStacks > 0 {
+			fmt.Fprintf(w, "   - %d stacks need autodeploy re-enabled after migration\n", r.AutodeployStacks)
+			fmt.Fprintf(w, "   - See autodeploy_re_enable.tf.disabled\n")
 		}
-		fmt.Printf("   - %d stacks with Spacelift-managed state can be migrated\n", managedStateCount)
+		fmt.Fprintf(w, "   - %d stacks with Spacelift-managed state can be migrated\n", r.ManagedStateStacks)
 
-		if needsAccessCount > 0 {
-			fmt.Printf("\n⚠️  %d stacks need external state access enabled before migration:\n", needsAccessCount)
-			for _, name := range needsAccessStacks {
-				fmt.Printf("   - %s\n", name)
+		if len(r.NeedsExternalAccess) > 0 {
+			fmt.Fprintf(w, "\n⚠️  %d stacks need external state access enabled before migration:\n", len(r.NeedsExternalAccess))
+			for _, name := range r.NeedsExternalAccess {
+				fmt.Fprintf(w, "   - %s\n", name)
 			}
-			fmt.Println("   Run: spacebridge state enable-access")
-		} else if managedStateCount > 0 {
-			fmt.Println("\n✓ All managed-state stacks already have external state access enabled")
+			fmt.Fprintln(w, "   Run: spacebridge state enable-access")
+		} else if r.ManagedStateStacks > 0 {
+			fmt.Fprintln(w, "\n✓ All managed-state stacks already have external state access enabled")
 		}
 	}
 
-	if secretCount > 0 {
-		fmt.Printf("\n⚠️  %d secret values require manual entry.\n", secretCount)
-		fmt.Println("   Edit secrets.auto.tfvars.template and rename to secrets.auto.tfvars")
+	if len(r.missingSecrets) > 0 {
+		printMissingSecrets(w, r.missingSecrets, r.secretSources)
+		fmt.Fprintln(w, "   Add them to a secret source and run: spacebridge secrets fill")
+	} else if r.SecretsToEnter > 0 {
+		fmt.Fprintf(w, "\n⚠️  %d secret values require manual entry.\n", r.SecretsToEnter)
+		fmt.Fprintln(w, "   Edit secrets.auto.tfvars.template and rename to secrets.auto.tfvars")
+		fmt.Fprintln(w, "   Or fill them from a secret store: spacebridge secrets fill")
 	}
 
-	fmt.Println("\nNext steps:")
-	fmt.Printf("  1. cd %s\n", generateDir)
-	fmt.Println("  2. Review and modify generated code as needed")
-	if secretCount > 0 {
-		fmt.Println("  3. Fill in secret values in secrets.auto.tfvars")
-		fmt.Println("  4. Tofu init && Tofu plan && Tofu apply")
+	if r.InlineCredentials {
+		fmt.Fprintf(w, "\n🔑 Destination credentials written to %s (git-ignored)\n", generator.ProviderVarsFile)
 	} else {
-		fmt.Println("  3. Tofu init && Tofu plan && Tofu apply")
-	}
-	if disableStacks && managedStateCount > 0 {
-		step := 5
-		if secretCount > 0 {
-			step = 5
-		} else {
-			step = 4
+		fmt.Fprintln(w, "\n🔑 provider.tf contains no credentials. Before running tofu, export:")
+		fmt.Fprintln(w, "   SPACELIFT_API_KEY_ID=$DESTINATION_SPACELIFT_KEY_ID")
+		fmt.Fprintln(w, "   SPACELIFT_API_KEY_SECRET=$DESTINATION_SPACELIFT_SECRET_KEY")
+		if !cfg.HasDestination() {
+			fmt.Fprintln(w, "   SPACELIFT_API_KEY_ENDPOINT=$DESTINATION_SPACELIFT_URL")
 		}
-		fmt.Printf("  %d. spacebridge state enable-access  # Enable external state access on source\n", step)
+	}
+
+	fmt.Fprintln(w, "\nNext steps:")
+	fmt.Fprintf(w, "  1. cd %s\n", r.OutputDir)
+	fmt.Fprintln(w, "  2. Review and modify generated code as needed")
+	step := 4
+	if r.SecretsToEnter > 0 {
+		fmt.Fprintln(w, "  3. Fill in secret values in secrets.auto.tfvars")
+		fmt.Fprintln(w, "  4. Tofu init && Tofu plan && Tofu apply")
+		step = 5
+	} else {
+		fmt.Fprintln(w, "  3. Tofu init && Tofu plan && Tofu apply")
+	}
+	if r.SafeMode && r.ManagedStateStacks > 0 {
+		fmt.Fprintf(w, "  %d. spacebridge state enable-access  # Enable external state access on source\n", step)
 		step++
-		fmt.Printf("  %d. spacebridge state plan           # Preview state migration\n", step)
+		fmt.Fprintf(w, "  %d. spacebridge state plan           # Preview state migration\n", step)
 		step++
-		fmt.Printf("  %d. spacebridge state migrate        # Migrate state to new stacks\n", step)
+		fmt.Fprintf(w, "  %d. spacebridge state migrate        # Migrate state to new stacks\n", step)
 		step++
-		if autodeployCount > 0 {
-			fmt.Printf("  %d. Rename autodeploy_re_enable.tf.disabled → .tf\n", step)
+		if r.AutodeployStacks > 0 {
+			fmt.Fprintf(w, "  %d. Rename autodeploy_re_enable.tf.disabled → .tf\n", step)
 			step++
-			fmt.Printf("  %d. tofu apply                       # Re-enable autodeploy\n", step)
+			fmt.Fprintf(w, "  %d. tofu apply                       # Re-enable autodeploy\n", step)
 		}
 	}
-
-	return nil
 }
 
 // filterManifestBySpace filters a manifest to only include resources in the given space,
@@ -384,6 +560,7 @@ func filterManifestBySpace(manifest *discovery.Manifest, spaceID string) *discov
 	}
 
 	return &discovery.Manifest{
+		SchemaVersion:     manifest.SchemaVersion,
 		SourceURL:         manifest.SourceURL,
 		Spaces:            filteredSpaces,
 		Stacks:            filteredStacks,
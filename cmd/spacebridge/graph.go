@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/graph"
+)
+
+// graphOptions holds the graph command flags.
+type graphOptions struct {
+	manifestPath string
+	format       string
+	outputFile   string
+	spaceFilter  string
+	selectors    selectorOptions
+}
+
+// newGraphCmd creates the graph command.
+func newGraphCmd() *cobra.Command {
+	var opts graphOptions
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Export the graph of spaces, stacks, contexts and policies",
+		Long: `Builds a graph of how the resources of a manifest relate to each other and
+writes it as Graphviz DOT, Mermaid or JSON:
+
+  space → parent space
+  stack → attached (or autoattached) context
+  stack → attached policy
+  stack → stack it depends on
+  stack → attached AWS or Azure integration
+
+With --space or stack selectors, the graph holds the selected resources as
+generate would migrate them, plus the resources outside the selection that
+they are connected to. Those outside nodes, and the edges that cross into
+them, are highlighted: they are dependencies or attachments that the
+selection leaves behind.
+
+The graph is written to stdout, or to --output-file, and progress messages
+go to stderr. With --output json or yaml, stdout holds the nodes and edges
+instead.
+
+Example usage:
+  # Render the whole account with Graphviz
+  spacebridge graph -m manifest.json | dot -Tsvg > graph.svg
+
+  # Mermaid chart of the payments stacks and what they reach outside
+  spacebridge graph -m manifest.json --format mermaid --label team:payments
+
+  # JSON of one space
+  spacebridge graph -m manifest.json --format json -s production`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// The graph is the text output, so messages always go to stderr
+			setProgressOut(cmd.ErrOrStderr())
+			return runGraph(opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().StringVar(&opts.format, "format", graph.FormatDOT, "Output format: dot, mermaid or json")
+	cmd.Flags().StringVarP(&opts.outputFile, "output-file", "o", "", "Write the graph to this file instead of stdout")
+	cmd.Flags().StringVarP(&opts.spaceFilter, "space", "s", "", "Only include resources from this space (and its children)")
+	opts.selectors.addFlags(cmd)
+	return cmd
+}
+
+// graphResult is the result of graph.
+type graphResult struct {
+	Format     string       `json:"format"`
+	OutputFile string       `json:"outputFile,omitempty"`
+	Graph      *graph.Graph `json:"graph"`
+}
+
+// runGraph writes the resource graph.
+func runGraph(opts graphOptions) error {
+	result, err := buildGraph(opts)
+	if err != nil {
+		return err
+	}
+	return render(result)
+}
+
+// buildGraph builds the resource graph of the selected resources, and
+// writes it to --output-file if set.
+func buildGraph(opts graphOptions) (*graphResult, error) {
+	if err := graph.ValidateFormat(opts.format); err != nil {
+		return nil, err
+	}
+
+	stackSel, err := opts.selectors.load()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := loadManifest(opts.manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	g := graph.Build(manifest)
+	filtered := manifest
+	if opts.spaceFilter != "" {
+		spaceID, spaceName, err := matchSpace(manifest.Spaces, opts.spaceFilter)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(progressOut, "Filtering to space: %s (ID: %s)\n", spaceName, spaceID)
+		filtered = filterManifestBySpace(filtered, spaceID)
+	}
+	if filtered, err = stackSel.manifest(filtered); err != nil {
+		return nil, err
+	}
+	if filtered != manifest {
+		g = g.Focus(graphNodes(filtered))
+	}
+
+	if opts.outputFile != "" {
+		f, err := os.Create(opts.outputFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create graph file: %w", err)
+		}
+		defer f.Close()
+		if err := g.Write(f, opts.format); err != nil {
+			return nil, fmt.Errorf("failed to write graph: %w", err)
+		}
+	}
+
+	fmt.Fprintf(progressOut, "Graph: %d nodes, %d edges\n", len(g.Nodes), len(g.Edges))
+	if outside := g.Outside(); len(outside) > 0 {
+		fmt.Fprintf(progressOut, "⚠ %d resources outside the selection are connected to it:\n", len(outside))
+		for _, node := range outside {
+			fmt.Fprintf(progressOut, "    • %s %s\n", node.Kind, node.Name)
+		}
+	}
+	if opts.outputFile != "" {
+		fmt.Fprintf(progressOut, "Graph written to: %s\n", opts.outputFile)
+	}
+	return &graphResult{Format: opts.format, OutputFile: opts.outputFile, Graph: g}, nil
+}
+
+// renderText writes the graph itself, unless it went to --output-file.
+func (r *graphResult) renderText(w io.Writer) {
+	if r.OutputFile == "" {
+		_ = r.Graph.Write(w, r.Format)
+	}
+}
+
+// graphNodes returns the IDs of the graph nodes of every resource in a
+// manifest.
+func graphNodes(m *discovery.Manifest) map[string]bool {
+	nodes := make(map[string]bool)
+	for _, space := range m.Spaces {
+		nodes[graph.NodeID(graph.KindSpace, space.ID)] = true
+	}
+	for _, stack := range m.Stacks {
+		nodes[graph.NodeID(graph.KindStack, stack.ID)] = true
+	}
+	for _, c := range m.Contexts {
+		nodes[graph.NodeID(graph.KindContext, c.ID)] = true
+	}
+	for _, p := range m.Policies {
+		nodes[graph.NodeID(graph.KindPolicy, p.ID)] = true
+	}
+	for _, i := range m.AWSIntegrations {
+		nodes[graph.NodeID(graph.KindAWSIntegration, i.ID)] = true
+	}
+	for _, i := range m.AzureIntegrations {
+		nodes[graph.NodeID(graph.KindAzureIntegration, i.ID)] = true
+	}
+	return nodes
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/jnesspace/spacebridge/internal/client"
 	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/journal"
+	"github.com/jnesspace/spacebridge/internal/selection"
+	"github.com/jnesspace/spacebridge/pkg/config"
 )
 
 // createDiscoveryService creates a new discovery service with the source client.
@@ -13,9 +18,9 @@ func createDiscoveryService() (*discovery.Service, error) {
 		return nil, fmt.Errorf("source configuration error: %w", err)
 	}
 
-	fmt.Printf("Connecting to: %s\n", cfg.Source.URL)
+	fmt.Fprintf(progressOut, "Connecting to: %s\n", cfg.Source.URL)
 	if verbose {
-		fmt.Printf("[CONFIG] API Key ID: %s\n", cfg.Source.KeyID)
+		fmt.Fprintf(progressOut, "[CONFIG] API Key ID: %s\n", cfg.Source.KeyID)
 	}
 
 	c, err := client.New(cfg.Source)
@@ -26,6 +31,65 @@ func createDiscoveryService() (*discovery.Service, error) {
 	return discovery.New(c), nil
 }
 
+// loadManifest reads a manifest file, or discovers a fresh one from the
+// source account when path is empty.
+func loadManifest(path string) (*discovery.Manifest, error) {
+	if path != "" {
+		fmt.Fprintf(progressOut, "Loading manifest from: %s\n", path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest file: %w", err)
+		}
+
+		manifest, version, err := discovery.ParseManifest(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest file: %w", err)
+		}
+		if version < discovery.SchemaVersion {
+			fmt.Fprintf(progressOut, "⚠ Manifest schema version %d is older than %d; upgraded in memory (run: spacebridge manifest upgrade %s)\n", version, discovery.SchemaVersion, path)
+		}
+		if problems := manifest.CheckReferences(); len(problems) > 0 {
+			fmt.Fprintf(progressOut, "⚠ Manifest has %d referential integrity problems (run: spacebridge manifest validate %s)\n", len(problems), path)
+		}
+		return manifest, nil
+	}
+
+	svc, err := createDiscoveryService()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(progressOut, "Discovering resources...")
+	manifest, err := svc.DiscoverAll(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover resources: %w", err)
+	}
+	return manifest, nil
+}
+
+// loadSelection reads a selection file written by spacebridge select. An
+// empty path selects everything and returns nil.
+func loadSelection(path string) (*selection.Selection, error) {
+	if path == "" {
+		return nil, nil
+	}
+	fmt.Fprintf(progressOut, "Loading selection from: %s\n", path)
+	return selection.Load(path)
+}
+
+// loadMigrationConfig reads and validates a migration config file.
+func loadMigrationConfig(path string) (*config.MigrationConfig, error) {
+	fmt.Fprintf(progressOut, "Loading migration config from: %s\n", path)
+	migCfg, err := config.LoadMigrationConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migration config: %w", err)
+	}
+	if err := migCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid migration config: %w", err)
+	}
+	return migCfg, nil
+}
+
 // friendlyVendorType converts the GraphQL typename to a friendly name.
 func friendlyVendorType(vendorType string) string {
 	switch vendorType {
@@ -45,3 +109,41 @@ func friendlyVendorType(vendorType string) string {
 		return vendorType
 	}
 }
+
+// recordJournal appends a stack change to the migration journal. A journal
+// that cannot be written is reported but does not stop the command.
+func recordJournal(e journal.Entry) {
+	e.SourceHost = journal.AccountHost(cfg.Source.URL)
+	e.DestHost = journal.AccountHost(cfg.Destination.URL)
+	if err := journal.Open(journalPath).Record(e); err != nil {
+		fmt.Fprintf(progressOut, "      ⚠ %v\n", err)
+	}
+}
+
+// pendingJournal returns the journaled changes of one action that are still
+// in effect, by the ID of the stack they changed.
+func pendingJournal(action string) (map[string]journal.Entry, error) {
+	byStack := make(map[string]journal.Entry)
+	if journalPath == "" {
+		return byStack, nil
+	}
+	entries, err := journal.Load(journalPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range journal.Pending(entries) {
+		if e.Action == action && e.InAccount(journalHost(e)) {
+			byStack[e.StackID()] = e
+		}
+	}
+	return byStack, nil
+}
+
+// journalHost returns the host of the configured account that a journaled
+// change belongs to.
+func journalHost(e journal.Entry) string {
+	if e.Destination() {
+		return journal.AccountHost(cfg.Destination.URL)
+	}
+	return journal.AccountHost(cfg.Source.URL)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/lint"
+)
+
+// lintOptions holds the lint command flags.
+type lintOptions struct {
+	manifestPath string
+	spaceFilter  string
+	selectors    selectorOptions
+}
+
+// newLintCmd creates the lint command.
+func newLintCmd() *cobra.Command {
+	var opts lintOptions
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Report everything that needs attention before a migration",
+		Long: `Checks the resources to migrate and reports each problem with a severity
+and a hint on how to fix it.
+
+Checks:
+  administrative         stacks on the deprecated administrative flag
+  manual-secrets         secret context values that need manual entry
+  name-collision         resources whose IDs sanitize to the same Tofu name
+  outside-selection      attachments and dependencies on resources that are
+                         not selected (with --space or selectors) or missing
+  dependency-cycle       stack dependency cycles
+  unsupported-vendor     stacks whose vendor generate does not support
+  external-state-access  managed state without external state access
+  aws-trust-policy       AWS integrations whose trust policy must change
+  blueprint              blueprints, which are not migrated
+
+Blueprints are only checked against the live source account, since manifests
+do not record them.
+
+Exits non-zero if any errors are found.
+
+Example usage:
+  spacebridge lint
+  spacebridge lint -m manifest.json -s production
+  spacebridge lint --label team:payments --output json`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLint(opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().StringVarP(&opts.spaceFilter, "space", "s", "", "Only include resources from this space (and its children)")
+	opts.selectors.addFlags(cmd)
+	return cmd
+}
+
+// lintResult is the result of lint.
+type lintResult struct {
+	Findings []lint.Finding `json:"findings"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Info     int            `json:"info"`
+}
+
+// runLint checks the selected resources.
+func runLint(opts lintOptions) error {
+	result, err := lintMigration(opts)
+	if err != nil {
+		return err
+	}
+	if err := render(result); err != nil {
+		return err
+	}
+	if result.Errors > 0 {
+		return fmt.Errorf("lint found %d errors", result.Errors)
+	}
+	return nil
+}
+
+// lintMigration loads and filters the manifest and runs the checks.
+func lintMigration(opts lintOptions) (*lintResult, error) {
+	stackSel, err := opts.selectors.load()
+	if err != nil {
+		return nil, err
+	}
+	account, err := loadManifest(opts.manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := account
+	if opts.spaceFilter != "" {
+		spaceID, spaceName, err := matchSpace(account.Spaces, opts.spaceFilter)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(progressOut, "Filtering to space: %s (ID: %s)\n", spaceName, spaceID)
+		manifest = filterManifestBySpace(manifest, spaceID)
+	}
+	if manifest, err = stackSel.manifest(manifest); err != nil {
+		return nil, err
+	}
+
+	in := lint.Input{
+		Manifest:       manifest,
+		Account:        account,
+		DestinationURL: cfg.Destination.URL,
+	}
+	if opts.manifestPath == "" {
+		// The manifest was discovered live, so blueprints can be listed too
+		svc, err := createDiscoveryService()
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintln(progressOut, "Discovering blueprints...")
+		if in.Blueprints, err = svc.DiscoverBlueprints(context.Background()); err != nil {
+			fmt.Fprintf(progressOut, "⚠ Could not list blueprints: %v\n", err)
+		} else {
+			in.BlueprintsChecked = true
+		}
+	}
+
+	result := &lintResult{Findings: lint.Run(in)}
+	for _, f := range result.Findings {
+		switch f.Severity {
+		case lint.SeverityError:
+			result.Errors++
+		case lint.SeverityWarning:
+			result.Warnings++
+		default:
+			result.Info++
+		}
+	}
+	return result, nil
+}
+
+func (r *lintResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                    MIGRATION LINT                           │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+
+	r.printFindings(w, lint.SeverityError, "✗ ERRORS", r.Errors)
+	r.printFindings(w, lint.SeverityWarning, "⚠ WARNINGS", r.Warnings)
+	r.printFindings(w, lint.SeverityInfo, "○ INFO", r.Info)
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Errors: %d | Warnings: %d | Info: %d\n", r.Errors, r.Warnings, r.Info)
+	switch {
+	case r.Errors > 0:
+		fmt.Fprintln(w, "\n✗ Fix the errors above before migrating")
+	case r.Warnings > 0:
+		fmt.Fprintln(w, "\n⚠ Ready to migrate once the warnings above are handled")
+	default:
+		fmt.Fprintln(w, "\n✓ Ready to migrate! Run: spacebridge generate")
+	}
+}
+
+// printFindings writes the findings of one severity.
+func (r *lintResult) printFindings(w io.Writer, severity, title string, count int) {
+	if count == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s (%d)\n", title, count)
+	for _, f := range r.Findings {
+		if f.Severity != severity {
+			continue
+		}
+		line := f.Message
+		if f.Resource != "" {
+			line = f.Resource + ": " + line
+		}
+		fmt.Fprintf(w, "    • [%s] %s\n", f.Check, line)
+		fmt.Fprintf(w, "      Fix: %s\n", f.Fix)
+	}
+}
@@ -3,18 +3,30 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 
+	"github.com/jnesspace/spacebridge/internal/cassette"
 	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/fakespacelift"
+	"github.com/jnesspace/spacebridge/internal/journal"
 	"github.com/jnesspace/spacebridge/pkg/config"
 )
 
 var (
-	verbose bool
-	cfg     *config.Config
+	verbose     bool
+	journalPath string
+	fakeFixture string
+	recordDir   string
+	replayDir   string
+	cfg         *config.Config
+
+	// fakeServers are the fake accounts started by --fake.
+	fakeServers []*fakespacelift.Server
 )
 
 func main() {
@@ -36,23 +48,141 @@ func main() {
 cloning Spacelift resources between accounts.
 
 It provides safe, validated migrations with full dry-run support.`,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			client.Verbose = verbose
+			if err := setupOutput(cmd); err != nil {
+				return err
+			}
+			if fakeFixture != "" {
+				if err := startFakeAccounts(fakeFixture); err != nil {
+					return err
+				}
+			}
+			return setupCassette()
 		},
 	}
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().StringVar(&outputMode, "output", outputText, "Output format: text, json or yaml (json and yaml write progress to stderr)")
+	rootCmd.PersistentFlags().StringVar(&journalPath, "journal", journal.DefaultPath, "Journal file recording stack changes for rollback (empty to disable)")
+	rootCmd.PersistentFlags().StringVar(&fakeFixture, "fake", "", "Run against in-process fake source and destination accounts loaded from this fixture file")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Record every GraphQL request and response to this directory, with secrets redacted")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Answer GraphQL requests from a directory written by --record instead of the network")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	rootCmd.MarkFlagsMutuallyExclusive("fake", "replay")
 
 	// Add command groups
 	rootCmd.AddCommand(
 		newDiscoverCmd(),
 		newExportCmd(),
+		newSelectCmd(),
 		newGenerateCmd(),
 		newStateCmd(),
 		newStacksCmd(),
+		newVCSCmd(),
+		newTransformCmd(),
+		newSecretsCmd(),
+		newManifestCmd(),
+		newDiffCmd(),
+		newSyncCmd(),
+		newApplyCmd(),
+		newMigrateCmd(),
+		newPlanCmd(),
+		newGraphCmd(),
+		newLintCmd(),
+		newDedupCmd(),
+		newRollbackCmd(),
 	)
 
-	if err := rootCmd.Execute(); err != nil {
+	err = rootCmd.Execute()
+	for _, server := range fakeServers {
+		server.Close()
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// setupCassette routes GraphQL requests through a cassette recorder or
+// replayer when --record or --replay is given.
+func setupCassette() error {
+	accounts := []struct {
+		role    string
+		account *config.AccountConfig
+	}{
+		{"source", &cfg.Source},
+		{"destination", &cfg.Destination},
+	}
+
+	switch {
+	case recordDir != "":
+		recorder, err := cassette.NewRecorder(recordDir, http.DefaultTransport)
+		if err != nil {
+			return err
+		}
+		for _, a := range accounts {
+			recorder.WithAccount(urlHost(a.account.URL), a.role)
+		}
+		client.Transport = recorder
+	case replayDir != "":
+		replayer, err := cassette.NewReplayer(replayDir)
+		if err != nil {
+			return err
+		}
+
+		// Replaying needs no credentials; fill in placeholders for checks.
+		// Requests to either account are answered from what was recorded
+		// for it, whatever its URL.
+		for _, a := range accounts {
+			if a.account.URL == "" {
+				a.account.URL = "https://" + a.role + ".replay.invalid"
+			}
+			if a.account.KeyID == "" {
+				a.account.KeyID = "replay"
+			}
+			if a.account.SecretKey == "" {
+				a.account.SecretKey = "replay"
+			}
+			replayer.WithAccount(urlHost(a.account.URL), a.role)
+		}
+		client.Transport = replayer
+	}
+	return nil
+}
+
+// urlHost returns the host of an account URL, or "" if it has none.
+func urlHost(accountURL string) string {
+	u, err := url.Parse(accountURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// startFakeAccounts serves the source and destination accounts of a
+// fixture in process and points the configuration at them.
+func startFakeAccounts(path string) error {
+	fixture, err := fakespacelift.LoadFixture(path)
+	if err != nil {
+		return err
+	}
+
+	source, err := fakespacelift.Start(fixture.Source)
+	if err != nil {
+		return fmt.Errorf("failed to start fake source account: %w", err)
+	}
+	fakeServers = append(fakeServers, source)
+
+	destination, err := fakespacelift.Start(fixture.Destination)
+	if err != nil {
+		return fmt.Errorf("failed to start fake destination account: %w", err)
+	}
+	fakeServers = append(fakeServers, destination)
+
+	cfg.Source = config.AccountConfig{URL: source.URL, KeyID: "fake", SecretKey: "fake"}
+	cfg.Destination = config.AccountConfig{URL: destination.URL, KeyID: "fake", SecretKey: "fake"}
+	if verbose {
+		fmt.Fprintf(progressOut, "[FAKE] Source: %s, destination: %s (fixture: %s)\n", source.URL, destination.URL, path)
 	}
+	return nil
 }
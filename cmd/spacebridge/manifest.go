@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+)
+
+// newManifestCmd creates the manifest command group.
+func newManifestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Validate, upgrade, compare and describe manifest files",
+	}
+	cmd.AddCommand(
+		newManifestValidateCmd(),
+		newManifestUpgradeCmd(),
+		newManifestSchemaCmd(),
+		newManifestDiffCmd(),
+	)
+	return cmd
+}
+
+// newManifestValidateCmd creates the manifest validate command.
+func newManifestValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <manifest.json>",
+		Short: "Check a manifest's schema version, structure and references",
+		Long: `Validates a manifest file written by 'spacebridge export'.
+
+Checks:
+  - schemaVersion is supported (older versions can be upgraded)
+  - every field is known to this version of the schema
+  - every stack attachment points at an existing context, policy,
+    AWS/Azure integration or stack
+  - every space parent exists and the hierarchy has no cycles
+  - resource IDs are unique
+
+Exits non-zero if any errors are found.`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runManifestValidate(args[0])
+		},
+	}
+}
+
+// manifestValidateResult is the result of manifest validate.
+type manifestValidateResult struct {
+	Path     string              `json:"path"`
+	Errors   []discovery.Problem `json:"errors"`
+	Warnings []discovery.Problem `json:"warnings"`
+}
+
+// runManifestValidate prints validation problems for a manifest file.
+func runManifestValidate(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest file: %w", err)
+	}
+
+	problems, err := discovery.ValidateManifest(data)
+	if err != nil {
+		return err
+	}
+
+	result := &manifestValidateResult{Path: path, Errors: []discovery.Problem{}, Warnings: []discovery.Problem{}}
+	for _, p := range problems {
+		if p.Severity == discovery.SeverityError {
+			result.Errors = append(result.Errors, p)
+		} else {
+			result.Warnings = append(result.Warnings, p)
+		}
+	}
+
+	if err := render(result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("manifest has %d errors", len(result.Errors))
+	}
+	return nil
+}
+
+func (r *manifestValidateResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                    MANIFEST VALIDATION                      │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(w, "\nFile: %s\n", r.Path)
+
+	if len(r.Errors) > 0 {
+		fmt.Fprintf(w, "\n✗ ERRORS (%d)\n", len(r.Errors))
+		for _, p := range r.Errors {
+			fmt.Fprintf(w, "    • %s\n", formatProblem(p))
+		}
+	}
+	if len(r.Warnings) > 0 {
+		fmt.Fprintf(w, "\n⚠ WARNINGS (%d)\n", len(r.Warnings))
+		for _, p := range r.Warnings {
+			fmt.Fprintf(w, "    • %s\n", formatProblem(p))
+		}
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	switch {
+	case len(r.Errors) > 0:
+	case len(r.Warnings) > 0:
+		fmt.Fprintf(w, "✓ Manifest is valid (%d warnings)\n", len(r.Warnings))
+	default:
+		fmt.Fprintln(w, "✓ Manifest is valid")
+	}
+}
+
+// formatProblem formats a validation problem as "path: message".
+func formatProblem(p discovery.Problem) string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// newManifestUpgradeCmd creates the manifest upgrade command.
+func newManifestUpgradeCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "upgrade <manifest.json>",
+		Short: "Migrate a manifest to the current schema version",
+		Long: `Migrates a manifest written by an older version of SpaceBridge to the
+current schema version. By default the file is upgraded in place and the
+original is kept as <manifest.json>.bak.
+
+Example usage:
+  spacebridge manifest upgrade manifest.json
+  spacebridge manifest upgrade old.json -o manifest.json`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runManifestUpgrade(args[0], output)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output-file", "o", "", "Output file (default: upgrade in place)")
+	return cmd
+}
+
+// manifestUpgradeResult is the result of manifest upgrade.
+type manifestUpgradeResult struct {
+	Path        string   `json:"path"`
+	FromVersion int      `json:"fromVersion"`
+	ToVersion   int      `json:"toVersion"`
+	Steps       []string `json:"steps"`
+	Backup      string   `json:"backup,omitempty"`
+	OutputFile  string   `json:"outputFile,omitempty"` // Empty if already current
+}
+
+// runManifestUpgrade upgrades a manifest file to the current schema version.
+func runManifestUpgrade(path, output string) error {
+	result, err := upgradeManifest(path, output)
+	if err != nil {
+		return err
+	}
+	return render(result)
+}
+
+// upgradeManifest writes a manifest file upgraded to the current schema
+// version.
+func upgradeManifest(path, output string) (*manifestUpgradeResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+
+	version, err := discovery.ManifestVersion(data)
+	if err != nil {
+		return nil, err
+	}
+
+	upgraded, steps, err := discovery.UpgradeManifest(data)
+	if err != nil {
+		return nil, err
+	}
+	result := &manifestUpgradeResult{Path: path, FromVersion: version, ToVersion: discovery.SchemaVersion, Steps: []string{}}
+	if len(steps) == 0 && output == "" {
+		return result, nil
+	}
+
+	// Re-encode through the typed manifest for canonical formatting
+	manifest, _, err := discovery.ParseManifest(upgraded)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	if output == "" {
+		output = path
+		result.Backup = path + ".bak"
+		if err := os.WriteFile(result.Backup, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write backup: %w", err)
+		}
+	}
+	if err := os.WriteFile(output, formatted, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write manifest file: %w", err)
+	}
+
+	result.Steps = append(result.Steps, steps...)
+	result.OutputFile = output
+	return result, nil
+}
+
+func (r *manifestUpgradeResult) renderText(w io.Writer) {
+	if r.OutputFile == "" {
+		fmt.Fprintf(w, "✓ %s is already at schema version %d\n", r.Path, r.FromVersion)
+		return
+	}
+	if r.Backup != "" {
+		fmt.Fprintf(w, "Backup written to: %s\n", r.Backup)
+	}
+	for _, step := range r.Steps {
+		fmt.Fprintf(w, "  ✓ %s\n", step)
+	}
+	fmt.Fprintf(w, "Manifest upgraded from schema version %d to %d: %s\n", r.FromVersion, r.ToVersion, r.OutputFile)
+}
+
+// manifestSchemaResult is the result of manifest schema.
+type manifestSchemaResult struct {
+	OutputFile string          `json:"outputFile,omitempty"`
+	Schema     json.RawMessage `json:"schema,omitempty"` // Unless written to OutputFile
+}
+
+// newManifestSchemaCmd creates the manifest schema command.
+func newManifestSchemaCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the manifest JSON Schema",
+		Long: `Prints the JSON Schema for the current manifest version, for use with
+editors and external validators.`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output == "" {
+				return render(&manifestSchemaResult{Schema: discovery.JSONSchema})
+			}
+			if err := os.WriteFile(output, discovery.JSONSchema, 0644); err != nil {
+				return fmt.Errorf("failed to write schema: %w", err)
+			}
+			return render(&manifestSchemaResult{OutputFile: output})
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output-file", "o", "", "Output file (default: stdout)")
+	return cmd
+}
+
+func (r *manifestSchemaResult) renderText(w io.Writer) {
+	if r.OutputFile != "" {
+		fmt.Fprintf(w, "Schema written to: %s\n", r.OutputFile)
+		return
+	}
+	_, _ = w.Write(r.Schema)
+}
@@ -0,0 +1,655 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/generator"
+	"github.com/jnesspace/spacebridge/internal/orchestrate"
+	"github.com/jnesspace/spacebridge/internal/ui"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// tofuPlanFile is the saved plan written by the tofu-apply phase.
+const tofuPlanFile = "spacebridge.tfplan"
+
+// errMigrationPaused is returned by a phase when confirmation is declined.
+var errMigrationPaused = errors.New("migration paused")
+
+// newMigrateCmd creates the migrate command group.
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Run a full migration from a plan file",
+	}
+	cmd.AddCommand(
+		newMigrateRunCmd(),
+		newMigrateStatusCmd(),
+	)
+	return cmd
+}
+
+// migrateRunOptions holds the migrate run command flags.
+type migrateRunOptions struct {
+	planPath string
+	yes      bool
+	dryRun   bool
+}
+
+// newMigrateRunCmd creates the migrate run command.
+func newMigrateRunCmd() *cobra.Command {
+	var opts migrateRunOptions
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run every migration phase, resuming from the last checkpoint",
+		Long: `Runs the migration workflow for each space in the plan file:
+
+  1. generate       Generate Tofu code (spacebridge generate)
+  2. enable-access  Enable external state access (spacebridge state enable-access)
+  3. state-plan     Check migration readiness (spacebridge state plan)
+  4. tofu-apply     Apply the generated code in the destination
+  5. state-migrate  Migrate state (spacebridge state migrate)
+  6. stacks-enable  Enable destination stacks (spacebridge stacks enable)
+
+Spaces are migrated one after another. A checkpoint is written after every
+phase, so rerunning the command resumes each space from its last completed
+phase. A failed phase is retried on the next run.
+
+Before tofu-apply, state-migrate and stacks-enable the command shows what
+will change and asks for confirmation. Declining pauses the migration; run
+the command again to continue. --yes answers every prompt with yes.
+
+With tofu.run set in the plan, the tofu-apply phase runs tofu init, plan
+and apply in the output directory, passing the destination credentials in
+the SPACELIFT_API_KEY_* environment variables. Otherwise it waits for you
+to apply the code yourself.
+
+Plan file:
+  manifest: manifest.json         # optional, discovers fresh if not set
+  config: spacebridge.yaml        # optional migration config
+  output: ./generated             # one subdirectory per space if several
+  format: hcl
+  disabled: true
+  secrets_from: [env]
+  spaces: [networking, production]
+  skip: [enable-access]
+  tofu:
+    run: true
+    binary: tofu
+  checkpoint: migration.checkpoint.json
+
+Example usage:
+  # Show which phases would run
+  spacebridge migrate run --plan migration.yaml --dry-run
+
+  # Run (or resume) the migration
+  spacebridge migrate run --plan migration.yaml`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateRun(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.planPath, "plan", "", "Migration plan YAML file (required)")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "Answer yes to every confirmation prompt")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show which phases would run without running them")
+	_ = cmd.MarkFlagRequired("plan")
+
+	return cmd
+}
+
+// newMigrateStatusCmd creates the migrate status command.
+func newMigrateStatusCmd() *cobra.Command {
+	var planPath string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the current migration phase of each space",
+		Long: `Reads the plan's checkpoint file and shows, for each space, the phase it
+is at, its status and how many phases have completed.
+
+Example usage:
+  spacebridge migrate status --plan migration.yaml`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateStatus(planPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&planPath, "plan", "", "Migration plan YAML file (required)")
+	_ = cmd.MarkFlagRequired("plan")
+
+	return cmd
+}
+
+// loadMigrationPlan reads and validates a migration plan and its checkpoint.
+func loadMigrationPlan(path string) (*orchestrate.Plan, *orchestrate.Checkpoint, error) {
+	plan, err := orchestrate.LoadPlan(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load migration plan: %w", err)
+	}
+	if err := plan.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid migration plan: %w", err)
+	}
+	if plan.Format != "" {
+		if err := generator.ValidateFormat(plan.Format); err != nil {
+			return nil, nil, fmt.Errorf("invalid migration plan: %w", err)
+		}
+	}
+	if _, err := parseSecretSources(plan.SecretsFrom); err != nil {
+		return nil, nil, fmt.Errorf("invalid migration plan: %w", err)
+	}
+
+	checkpoint, err := orchestrate.LoadCheckpoint(plan.Checkpoint, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, checkpoint, nil
+}
+
+// Statuses of a phase in migrate run.
+const (
+	phasePending   = "pending"
+	phaseSkipped   = "skipped"
+	phaseCompleted = "completed"
+	phasePaused    = "paused"
+)
+
+// Statuses of migrate run.
+const (
+	runPlanned  = "planned"
+	runPaused   = "paused"
+	runComplete = "complete"
+)
+
+// migrationPhase is a phase of a space's migration.
+type migrationPhase struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Confirm marks phases that ask for confirmation before they change anything
+	Confirm bool `json:"confirm,omitempty"`
+}
+
+// migrationSpace is a space of the migration plan and its phases.
+type migrationSpace struct {
+	Name      string           `json:"name"`
+	OutputDir string           `json:"outputDir"`
+	Phases    []migrationPhase `json:"phases"`
+}
+
+// migrateRunResult is the result of migrate run.
+type migrateRunResult struct {
+	Plan   string           `json:"plan"`
+	DryRun bool             `json:"dryRun"`
+	Status string           `json:"status"`
+	Spaces []migrationSpace `json:"spaces"`
+}
+
+// runMigrateRun runs the migration phases for every space in the plan.
+func runMigrateRun(opts migrateRunOptions) error {
+	result, err := migrate(opts)
+	if err != nil {
+		return err
+	}
+	return render(result)
+}
+
+// migrate runs the plan's phases, or lists them with --dry-run, printing
+// each phase as it goes.
+func migrate(opts migrateRunOptions) (*migrateRunResult, error) {
+	plan, checkpoint, err := loadMigrationPlan(opts.planPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(progressOut, "┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(progressOut, "│                        MIGRATION                            │")
+	fmt.Fprintln(progressOut, "└─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(progressOut, "\nPlan:       %s\n", opts.planPath)
+	fmt.Fprintf(progressOut, "Checkpoint: %s\n", checkpoint.Path())
+
+	result := &migrateRunResult{Plan: opts.planPath, DryRun: opts.dryRun, Spaces: []migrationSpace{}}
+	if opts.dryRun {
+		result.Status = runPlanned
+		for _, unit := range plan.Units() {
+			result.Spaces = append(result.Spaces, migrationSchedule(plan, checkpoint, unit))
+		}
+		return result, nil
+	}
+
+	if err := cfg.ValidateSource(); err != nil {
+		return nil, fmt.Errorf("source configuration error: %w", err)
+	}
+	if err := cfg.ValidateDestination(); err != nil {
+		return nil, fmt.Errorf("destination configuration error: %w", err)
+	}
+
+	var migCfg *config.MigrationConfig
+	if plan.Config != "" {
+		if migCfg, err = loadMigrationConfig(plan.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &migrationRunner{
+		plan:       plan,
+		planPath:   opts.planPath,
+		checkpoint: checkpoint,
+		migCfg:     migCfg,
+		yes:        opts.yes,
+		stdin:      bufio.NewReader(os.Stdin),
+	}
+	for _, unit := range plan.Units() {
+		space := migrationSpace{Name: unit.Name, OutputDir: unit.OutputDir, Phases: []migrationPhase{}}
+		completed, err := r.runUnit(unit, &space)
+		result.Spaces = append(result.Spaces, space)
+		if err != nil {
+			fmt.Fprintf(progressOut, "\nRerun to retry the failed phase: spacebridge migrate run --plan %s\n", opts.planPath)
+			return nil, err
+		}
+		if !completed {
+			result.Status = runPaused
+			return result, nil
+		}
+	}
+
+	result.Status = runComplete
+	return result, nil
+}
+
+func (r *migrateRunResult) renderText(w io.Writer) {
+	switch r.Status {
+	case runPlanned:
+		for _, space := range r.Spaces {
+			fmt.Fprintf(w, "\nSpace: %s (output: %s)\n", space.Name, space.OutputDir)
+			for _, phase := range space.Phases {
+				switch {
+				case phase.Status == phaseSkipped:
+					fmt.Fprintf(w, "  ○ %s (skipped by plan)\n", phase.Name)
+				case phase.Status == phaseCompleted:
+					fmt.Fprintf(w, "  ✓ %s (completed)\n", phase.Name)
+				case phase.Confirm:
+					fmt.Fprintf(w, "  • %s (asks for confirmation)\n", phase.Name)
+				default:
+					fmt.Fprintf(w, "  • %s\n", phase.Name)
+				}
+			}
+		}
+		fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+		fmt.Fprintln(w, "DRY RUN - No changes made")
+		fmt.Fprintln(w, "Remove --dry-run flag to run the migration")
+
+	case runPaused:
+		space := r.Spaces[len(r.Spaces)-1]
+		for _, phase := range space.Phases {
+			if phase.Status == phasePaused {
+				fmt.Fprintf(w, "\n⚠ Migration paused at %s for space %s\n", phase.Name, space.Name)
+			}
+		}
+		fmt.Fprintf(w, "  Resume with: spacebridge migrate run --plan %s\n", r.Plan)
+
+	case runComplete:
+		fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+		fmt.Fprintln(w, "✓ Migration complete for every space in the plan!")
+		fmt.Fprintln(w, "\nNext steps:")
+		fmt.Fprintln(w, "  1. Trigger runs on enabled stacks to verify state matches infrastructure")
+		fmt.Fprintln(w, "  2. Re-enable autodeploy where needed (see autodeploy_re_enable.tf.disabled)")
+	}
+}
+
+// migrationSchedule lists a space's phases and whether they still run.
+func migrationSchedule(plan *orchestrate.Plan, checkpoint *orchestrate.Checkpoint, unit orchestrate.Unit) migrationSpace {
+	progress := checkpoint.Progress(unit.Name)
+	space := migrationSpace{Name: unit.Name, OutputDir: unit.OutputDir, Phases: []migrationPhase{}}
+	for _, phase := range orchestrate.Phases {
+		planned := migrationPhase{Name: phase, Status: phasePending, Confirm: orchestrate.Destructive(phase)}
+		switch {
+		case plan.Skipped(phase):
+			planned.Status = phaseSkipped
+		case progress.Done(phase):
+			planned.Status = phaseCompleted
+		}
+		space.Phases = append(space.Phases, planned)
+	}
+	return space
+}
+
+// migrationRunner runs plan phases and records them in the checkpoint.
+type migrationRunner struct {
+	plan       *orchestrate.Plan
+	planPath   string
+	checkpoint *orchestrate.Checkpoint
+	migCfg     *config.MigrationConfig
+	yes        bool
+	stdin      *bufio.Reader
+}
+
+// runUnit runs a space's remaining phases, recording each in space. It
+// returns false when the migration was paused at a confirmation prompt.
+func (r *migrationRunner) runUnit(unit orchestrate.Unit, space *migrationSpace) (bool, error) {
+	progress := r.checkpoint.Progress(unit.Name)
+
+	fmt.Fprintln(progressOut, "\n═════════════════════════════════════════════════════════════")
+	fmt.Fprintf(progressOut, "Space: %s\n", unit.Name)
+	if progress.Next(r.plan) == "" {
+		fmt.Fprintln(progressOut, "✓ All phases already completed")
+		*space = migrationSchedule(r.plan, r.checkpoint, unit)
+		return true, nil
+	}
+
+	for i, phase := range orchestrate.Phases {
+		if r.plan.Skipped(phase) {
+			fmt.Fprintf(progressOut, "\n○ Phase %d/%d: %s (skipped by plan)\n", i+1, len(orchestrate.Phases), phase)
+			space.Phases = append(space.Phases, migrationPhase{Name: phase, Status: phaseSkipped})
+			continue
+		}
+		if progress.Done(phase) {
+			fmt.Fprintf(progressOut, "\n✓ Phase %d/%d: %s (already completed)\n", i+1, len(orchestrate.Phases), phase)
+			space.Phases = append(space.Phases, migrationPhase{Name: phase, Status: phaseCompleted})
+			continue
+		}
+
+		fmt.Fprintln(progressOut, "\n─────────────────────────────────────────────────────────────")
+		fmt.Fprintf(progressOut, "Phase %d/%d: %s\n", i+1, len(orchestrate.Phases), phase)
+		fmt.Fprintln(progressOut, "─────────────────────────────────────────────────────────────")
+		if err := r.checkpoint.Start(unit.Name, phase); err != nil {
+			return false, err
+		}
+
+		err := r.runPhase(unit, phase)
+		if errors.Is(err, errMigrationPaused) {
+			if err := r.checkpoint.Pause(unit.Name, phase); err != nil {
+				return false, err
+			}
+			space.Phases = append(space.Phases, migrationPhase{Name: phase, Status: phasePaused})
+			return false, nil
+		}
+		if err != nil {
+			if saveErr := r.checkpoint.Fail(unit.Name, phase, err); saveErr != nil {
+				return false, saveErr
+			}
+			return false, fmt.Errorf("space %s: phase %s failed: %w", unit.Name, phase, err)
+		}
+
+		if err := r.checkpoint.Complete(unit.Name, phase, r.plan); err != nil {
+			return false, err
+		}
+		space.Phases = append(space.Phases, migrationPhase{Name: phase, Status: phaseCompleted})
+		fmt.Fprintf(progressOut, "\n✓ Checkpoint saved: %s completed for space %s\n", phase, unit.Name)
+	}
+
+	return true, nil
+}
+
+// runPhase runs a single phase for a space. The phase commands' results
+// are written as progress, so that migrate run's result is the only one on
+// stdout.
+func (r *migrationRunner) runPhase(unit orchestrate.Unit, phase string) error {
+	switch phase {
+	case orchestrate.PhaseGenerate:
+		return r.generate(unit)
+	case orchestrate.PhaseEnableAccess:
+		result, err := enableStateAccess(unit.Space, selectorOptions{})
+		if err != nil {
+			return err
+		}
+		result.renderText(progressOut)
+		return failureError(result.Failed, len(result.Stacks), "stacks failed to update")
+	case orchestrate.PhaseStatePlan:
+		result, err := planStateMigration(unit.Space, selectorOptions{})
+		if err != nil {
+			return err
+		}
+		result.renderText(progressOut)
+		return nil
+	case orchestrate.PhaseTofuApply:
+		return r.tofuApply(unit)
+	case orchestrate.PhaseStateMigrate:
+		if _, err := migrateState(stateMigrateOptions{dryRun: true, spaceFilter: unit.Space, configPath: r.plan.Config}); err != nil {
+			return err
+		}
+		if !r.confirm("Migrate the state of the stacks above into the destination?") {
+			return errMigrationPaused
+		}
+		result, err := migrateState(stateMigrateOptions{spaceFilter: unit.Space, configPath: r.plan.Config})
+		if err != nil {
+			return err
+		}
+		result.renderText(progressOut)
+		return failureError(result.Failed, len(result.Stacks), "stacks failed to migrate")
+	case orchestrate.PhaseStacksEnable:
+		destSpace, err := r.destinationSpace(unit)
+		if err != nil {
+			return err
+		}
+		if _, err := enableStacks(stacksEnableOptions{dryRun: true, spaceFilter: destSpace}); err != nil {
+			return err
+		}
+		if !r.confirm("Enable the destination stacks above?") {
+			return errMigrationPaused
+		}
+		result, err := enableStacks(stacksEnableOptions{spaceFilter: destSpace})
+		if err != nil {
+			return err
+		}
+		result.renderText(progressOut)
+		return failureError(result.Failed, len(result.Stacks), "stacks failed to enable")
+	}
+	return fmt.Errorf("unknown phase %q", phase)
+}
+
+// generate generates the space's code with the plan's settings.
+func (r *migrationRunner) generate(unit orchestrate.Unit) error {
+	opts := generateOptions{
+		outputDir:    unit.OutputDir,
+		manifestPath: r.plan.Manifest,
+		disabled:     r.plan.Disabled,
+		spaceFilter:  unit.Space,
+		configPath:   r.plan.Config,
+		format:       r.plan.Format,
+		secretsFrom:  r.plan.SecretsFrom,
+	}
+	if opts.format == "" {
+		opts.format = generator.FormatHCL
+	}
+	result, err := generateCode(opts)
+	if err != nil {
+		return err
+	}
+	result.renderText(progressOut)
+	return nil
+}
+
+// tofuApply applies the generated code, either by running tofu or by
+// waiting for the user to apply it.
+func (r *migrationRunner) tofuApply(unit orchestrate.Unit) error {
+	if !r.plan.Tofu.Run {
+		fmt.Fprintln(progressOut, "Apply the generated code in the destination account:")
+		fmt.Fprintf(progressOut, "  cd %s && tofu init && tofu apply\n", unit.OutputDir)
+		if !r.confirm("Has the code been applied?") {
+			return errMigrationPaused
+		}
+		return nil
+	}
+
+	if err := r.runTofu(unit.OutputDir, "init", "-input=false"); err != nil {
+		return err
+	}
+	if err := r.runTofu(unit.OutputDir, "plan", "-input=false", "-out="+tofuPlanFile); err != nil {
+		return err
+	}
+	if !r.confirm("Apply this plan to the destination account?") {
+		return errMigrationPaused
+	}
+	return r.runTofu(unit.OutputDir, "apply", "-input=false", tofuPlanFile)
+}
+
+// runTofu runs tofu in dir with the destination credentials in the
+// environment, unless they are already set there.
+func (r *migrationRunner) runTofu(dir string, args ...string) error {
+	fmt.Fprintf(progressOut, "\n$ %s %s\n", r.plan.Tofu.Binary, strings.Join(args, " "))
+
+	cmd := exec.Command(r.plan.Tofu.Binary, args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = progressOut
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	for name, value := range map[string]string{
+		"SPACELIFT_API_KEY_ENDPOINT": cfg.Destination.URL,
+		"SPACELIFT_API_KEY_ID":       cfg.Destination.KeyID,
+		"SPACELIFT_API_KEY_SECRET":   cfg.Destination.SecretKey,
+	} {
+		if os.Getenv(name) == "" && value != "" {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w", r.plan.Tofu.Binary, args[0], err)
+	}
+	return nil
+}
+
+// destinationSpace translates a source space filter into the destination
+// space it was migrated to: the mapped space ID, or the generated space's
+// name (with the configured prefix).
+func (r *migrationRunner) destinationSpace(unit orchestrate.Unit) (string, error) {
+	if unit.Space == "" {
+		return "", nil
+	}
+
+	svc, err := createDiscoveryService()
+	if err != nil {
+		return "", err
+	}
+	spaceID, spaceName, err := resolveSpaceFilter(context.Background(), svc, unit.Space)
+	if err != nil {
+		return "", err
+	}
+	if spaceID == "root" {
+		return spaceID, nil
+	}
+	if r.migCfg != nil {
+		if destID, ok := r.migCfg.Spaces.MappedSpace(spaceID); ok {
+			return destID, nil
+		}
+		spaceName = r.migCfg.Spaces.NamePrefix + spaceName
+	}
+	return spaceName, nil
+}
+
+// confirm asks a yes/no question. Anything but yes (including end of
+// input) declines.
+func (r *migrationRunner) confirm(question string) bool {
+	fmt.Fprintf(progressOut, "\n%s [y/N] ", question)
+	if r.yes {
+		fmt.Fprintln(progressOut, "y (--yes)")
+		return true
+	}
+	answer, _ := r.stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// spaceProgress is the checkpointed progress of a space.
+type spaceProgress struct {
+	Space     string     `json:"space"`
+	Phase     string     `json:"phase"`
+	Status    string     `json:"status"`
+	Completed int        `json:"completed"` // Phases completed, out of Phases
+	Phases    int        `json:"phases"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// migrateStatusResult is the result of migrate status.
+type migrateStatusResult struct {
+	Plan       string          `json:"plan"`
+	Checkpoint string          `json:"checkpoint"`
+	Spaces     []spaceProgress `json:"spaces"`
+}
+
+// runMigrateStatus shows the checkpointed progress of every space.
+func runMigrateStatus(planPath string) error {
+	plan, checkpoint, err := loadMigrationPlan(planPath)
+	if err != nil {
+		return err
+	}
+
+	phaseCount := 0
+	for _, phase := range orchestrate.Phases {
+		if !plan.Skipped(phase) {
+			phaseCount++
+		}
+	}
+
+	result := &migrateStatusResult{Plan: planPath, Checkpoint: checkpoint.Path(), Spaces: []spaceProgress{}}
+	for _, unit := range plan.Units() {
+		progress := checkpoint.Progress(unit.Name)
+
+		phase := progress.Current
+		if phase == "" {
+			phase = progress.Next(plan)
+		}
+
+		completed := 0
+		for _, done := range progress.Completed {
+			if !plan.Skipped(done) {
+				completed++
+			}
+		}
+
+		status := spaceProgress{Space: unit.Name, Phase: phase, Status: progress.Status, Completed: completed, Phases: phaseCount}
+		if !progress.UpdatedAt.IsZero() {
+			updated := progress.UpdatedAt
+			status.UpdatedAt = &updated
+		}
+		if progress.Status == orchestrate.StatusFailed {
+			status.Error = progress.Error
+		}
+		result.Spaces = append(result.Spaces, status)
+	}
+	return render(result)
+}
+
+func (r *migrateStatusResult) renderText(w io.Writer) {
+	fmt.Fprintf(w, "Plan:       %s\n", r.Plan)
+	fmt.Fprintf(w, "Checkpoint: %s\n\n", r.Checkpoint)
+
+	var rows [][]string
+	var failed []string
+	for _, s := range r.Spaces {
+		phase := s.Phase
+		if phase == "" {
+			phase = "-"
+		}
+		updated := "-"
+		if s.UpdatedAt != nil {
+			updated = s.UpdatedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		rows = append(rows, []string{s.Space, phase, s.Status, fmt.Sprintf("%d/%d", s.Completed, s.Phases), updated})
+		if s.Status == orchestrate.StatusFailed {
+			failed = append(failed, fmt.Sprintf("%s (%s): %s", s.Space, phase, s.Error))
+		}
+	}
+
+	fmt.Fprint(w, ui.RenderTable([]string{"SPACE", "PHASE", "STATUS", "COMPLETED", "UPDATED"}, rows))
+
+	if len(failed) > 0 {
+		fmt.Fprintf(w, "\n✗ FAILED (%d)\n", len(failed))
+		for _, f := range failed {
+			fmt.Fprintf(w, "    • %s\n", f)
+		}
+	}
+}
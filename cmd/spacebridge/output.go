@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jnesspace/spacebridge/internal/client"
+)
+
+// Output formats for --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// Exit codes.
+const (
+	exitFailure        = 1 // The command failed, or every item it worked on failed
+	exitPartialFailure = 2 // Some items succeeded and some failed
+)
+
+// structuredOutputAnnotation marks commands that return a typed result and
+// so support --output json and yaml.
+const structuredOutputAnnotation = "spacebridge/structured-output"
+
+var (
+	outputMode = outputText
+
+	// resultOut receives rendered results.
+	resultOut io.Writer = os.Stdout
+
+	// progressOut receives the messages commands print as they work: the
+	// command's stdout in text mode, and its stderr in json and yaml modes
+	// so that stdout holds only the result.
+	progressOut io.Writer = os.Stdout
+)
+
+// result is the typed outcome of a command.
+type result interface {
+	// renderText writes the result as decorated text.
+	renderText(w io.Writer)
+}
+
+// structuredOutput returns the annotations of a command with a typed result.
+func structuredOutput() map[string]string {
+	return map[string]string{structuredOutputAnnotation: "true"}
+}
+
+// setupOutput validates --output for a command and points resultOut and
+// progressOut at its output streams.
+func setupOutput(cmd *cobra.Command) error {
+	resultOut = cmd.OutOrStdout()
+	setProgressOut(cmd.OutOrStdout())
+	switch outputMode {
+	case outputText:
+		return nil
+	case outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("unsupported output format %q (expected %q, %q or %q)", outputMode, outputText, outputJSON, outputYAML)
+	}
+	if cmd.Annotations[structuredOutputAnnotation] == "" {
+		return fmt.Errorf("%s does not support --output %s", cmd.CommandPath(), outputMode)
+	}
+
+	setProgressOut(cmd.ErrOrStderr())
+	return nil
+}
+
+// setProgressOut points progressOut, and the client's verbose output, at w.
+func setProgressOut(w io.Writer) {
+	progressOut = w
+	client.Log = w
+}
+
+// render writes a result in the selected output format.
+func render(r result) error {
+	switch outputMode {
+	case outputJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal result: %w", err)
+		}
+		fmt.Fprintln(resultOut, string(data))
+	case outputYAML:
+		data, err := marshalYAML(r)
+		if err != nil {
+			return fmt.Errorf("failed to marshal result: %w", err)
+		}
+		fmt.Fprint(resultOut, string(data))
+	default:
+		r.renderText(resultOut)
+	}
+	return nil
+}
+
+// marshalYAML marshals a value to YAML using its JSON field names, so both
+// machine formats have the same keys.
+func marshalYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle resets the flow and quoting styles that parsing JSON leaves on
+// every node. Strings that would read as another type are still quoted.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// partialFailureError is returned when some, but not all, items of a bulk
+// command failed.
+type partialFailureError struct {
+	err error
+}
+
+func (e *partialFailureError) Error() string { return e.err.Error() }
+func (e *partialFailureError) Unwrap() error { return e.err }
+
+// failureError returns the error for a bulk command where failed of total
+// items failed: nil when none did, a partial failure when some did.
+func failureError(failed, total int, what string) error {
+	if failed == 0 {
+		return nil
+	}
+	err := fmt.Errorf("%d of %d %s", failed, total, what)
+	if failed < total {
+		return &partialFailureError{err: err}
+	}
+	return err
+}
+
+// exitCode returns the process exit code for a command error.
+func exitCode(err error) int {
+	var partial *partialFailureError
+	if errors.As(err, &partial) {
+		return exitPartialFailure
+	}
+	return exitFailure
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/diff"
+	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/internal/waves"
+)
+
+// newPlanCmd creates the plan command group.
+func newPlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Plan the order of a migration",
+	}
+	cmd.AddCommand(
+		newPlanWavesCmd(),
+	)
+	return cmd
+}
+
+// newPlanWavesCmd creates the plan waves command.
+func newPlanWavesCmd() *cobra.Command {
+	var manifestPath string
+	var spaceFilter string
+	var maxWaveSize int
+
+	cmd := &cobra.Command{
+		Use:   "waves",
+		Short: "Show the cutover schedule in dependency waves",
+		Long: `Orders stacks into waves from their stack dependencies, so that upstream
+stacks are migrated and enabled before the stacks that depend on them.
+
+Wave 1 holds stacks without dependencies. Every later wave holds stacks
+whose dependencies are all in earlier waves. --max-wave-size splits large
+waves into consecutive smaller ones. Dependency cycles are reported as
+errors, since they have no valid order.
+
+The schedule is always computed from every stack of the source manifest,
+so a wave number means the same stacks whatever --space or selection each
+command uses. --space only limits the stacks shown. Migrate and enable one
+wave at a time with the same --max-wave-size (and --manifest, if any):
+  spacebridge state migrate --wave 1
+  spacebridge stacks enable --wave 1
+
+Example usage:
+  spacebridge plan waves
+  spacebridge plan waves -m manifest.json -s production --max-wave-size 10`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlanWaves(manifestPath, spaceFilter, maxWaveSize)
+		},
+	}
+
+	cmd.Flags().StringVarP(&manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().StringVarP(&spaceFilter, "space", "s", "", "Only show stacks from this space")
+	cmd.Flags().IntVar(&maxWaveSize, "max-wave-size", 0, "Split waves into waves of at most this many stacks")
+
+	return cmd
+}
+
+// waveStack is a stack of a cutover wave.
+type waveStack struct {
+	ID    string   `json:"id"`
+	Name  string   `json:"name"`
+	After []string `json:"after,omitempty"`
+	// External lists dependencies missing from the manifest, which are ignored
+	External []string `json:"external,omitempty"`
+}
+
+// planWave is a cutover wave.
+type planWave struct {
+	Number int         `json:"number"`
+	Stacks []waveStack `json:"stacks"`
+}
+
+// planWavesResult is the result of plan waves.
+type planWavesResult struct {
+	// Waves holds the waves with shown stacks; numbers count every wave
+	Waves      []planWave `json:"waves"`
+	Stacks     int        `json:"stacks"`
+	TotalWaves int        `json:"totalWaves"`
+	flags      string
+}
+
+// runPlanWaves prints the wave schedule.
+func runPlanWaves(manifestPath, spaceFilter string, maxWaveSize int) error {
+	result, err := planWaves(manifestPath, spaceFilter, maxWaveSize)
+	if err != nil {
+		return err
+	}
+	return render(result)
+}
+
+// planWaves schedules every stack of the manifest and returns the waves of
+// the stacks shown.
+func planWaves(manifestPath, spaceFilter string, maxWaveSize int) (*planWavesResult, error) {
+	if maxWaveSize < 0 {
+		return nil, fmt.Errorf("--max-wave-size must not be negative")
+	}
+
+	manifest, err := loadManifest(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schedule every stack, then show the ones in the space
+	graph := waves.NewGraph(manifest.Stacks)
+	schedule, err := graph.Waves(maxWaveSize)
+	var cycle *waves.CycleError
+	if errors.As(err, &cycle) {
+		fmt.Fprintf(progressOut, "\n✗ Dependency cycle: %s\n", strings.Join(cycle.Cycle, " → "))
+		fmt.Fprintln(progressOut, "  Remove one of these dependencies before migrating in waves")
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	shown := func(models.Stack) bool { return true }
+	if spaceFilter != "" {
+		spaceID, spaceName, err := matchSpace(manifest.Spaces, spaceFilter)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(progressOut, "Filtering to space: %s (ID: %s)\n", spaceName, spaceID)
+		shown = func(stack models.Stack) bool { return stack.Space == spaceID }
+	}
+
+	result := &planWavesResult{Waves: []planWave{}, TotalWaves: len(schedule), flags: waveFlags(manifestPath, maxWaveSize)}
+	for _, wave := range schedule {
+		planned := planWave{Number: wave.Number}
+		for _, stack := range wave.Stacks {
+			if !shown(stack) {
+				continue
+			}
+			planned.Stacks = append(planned.Stacks, waveStack{
+				ID:       stack.ID,
+				Name:     stack.Name,
+				After:    graph.DependsOn(stack.ID),
+				External: graph.External(stack.ID),
+			})
+		}
+		if len(planned.Stacks) == 0 {
+			continue
+		}
+		result.Waves = append(result.Waves, planned)
+		result.Stacks += len(planned.Stacks)
+	}
+	return result, nil
+}
+
+func (r *planWavesResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                    CUTOVER WAVES                            │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+
+	external := 0
+	for _, wave := range r.Waves {
+		fmt.Fprintf(w, "\nWave %d (%d stacks)\n", wave.Number, len(wave.Stacks))
+		for _, stack := range wave.Stacks {
+			line := "    • " + stack.Name
+			if len(stack.After) > 0 {
+				line += " (after: " + strings.Join(stack.After, ", ") + ")"
+			}
+			if len(stack.External) > 0 {
+				line += " ⚠"
+				external++
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	if external > 0 {
+		fmt.Fprintf(w, "\n⚠ %d stacks depend on stacks missing from the manifest; those dependencies are ignored\n", external)
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Total: %d stacks in %d waves\n", r.Stacks, r.TotalWaves)
+	if r.TotalWaves > 0 {
+		fmt.Fprintln(w, "\nMigrate wave by wave, starting with:")
+		fmt.Fprintf(w, "  spacebridge state migrate --wave 1%s\n", r.flags)
+		fmt.Fprintf(w, "  spacebridge stacks enable --wave 1%s\n", r.flags)
+	}
+}
+
+// waveFlags formats the options that must be repeated to select the same wave.
+func waveFlags(manifestPath string, maxWaveSize int) string {
+	var flags string
+	if manifestPath != "" {
+		flags += " --manifest " + manifestPath
+	}
+	if maxWaveSize > 0 {
+		flags += fmt.Sprintf(" --max-wave-size %d", maxWaveSize)
+	}
+	return flags
+}
+
+// waveOptions selects one wave of the dependency schedule. The schedule is
+// computed from every stack of the source manifest, so that a wave selects
+// the same stacks in every command whatever its other filters.
+type waveOptions struct {
+	wave         int
+	maxWaveSize  int
+	manifestPath string
+	mappingPath  string
+}
+
+// addFlags registers the wave flags on a command.
+func (o *waveOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&o.wave, "wave", 0, "Only include stacks in this dependency wave (see: spacebridge plan waves)")
+	cmd.Flags().IntVar(&o.maxWaveSize, "max-wave-size", 0, "Maximum stacks per wave, as passed to plan waves")
+	cmd.Flags().StringVar(&o.manifestPath, "manifest", "", "Source manifest the waves are computed from (optional, discovers the source account if not provided)")
+}
+
+// addMappingFlag registers the flag of commands that select destination
+// stacks by wave.
+func (o *waveOptions) addMappingFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.mappingPath, "mapping", "", "ID mapping file (source ID → destination ID) to find the destination stacks of a wave (optional, matched by name if not provided)")
+}
+
+// validate checks the wave flags.
+func (o waveOptions) validate() error {
+	if o.wave < 0 {
+		return fmt.Errorf("--wave must be 1 or higher")
+	}
+	if o.maxWaveSize < 0 {
+		return fmt.Errorf("--max-wave-size must not be negative")
+	}
+	if o.wave == 0 {
+		switch {
+		case o.maxWaveSize > 0:
+			return fmt.Errorf("--max-wave-size requires --wave")
+		case o.manifestPath != "":
+			return fmt.Errorf("--manifest requires --wave")
+		case o.mappingPath != "":
+			return fmt.Errorf("--mapping requires --wave")
+		}
+	}
+	return nil
+}
+
+// selected returns the source stacks in the selected wave.
+func (o waveOptions) selected() ([]models.Stack, error) {
+	var stacks []models.Stack
+	if o.manifestPath != "" {
+		manifest, err := loadManifest(o.manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		stacks = manifest.Stacks
+	} else {
+		svc, err := createDiscoveryService()
+		if err != nil {
+			return nil, err
+		}
+		if stacks, err = svc.DiscoverStacks(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to discover source stacks: %w", err)
+		}
+	}
+
+	selected, total, err := waves.Select(stacks, o.wave, o.maxWaveSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule waves: %w", err)
+	}
+	fmt.Fprintf(progressOut, "Wave:        %d of %d (%d stacks)\n", o.wave, total, len(selected))
+	return selected, nil
+}
+
+// filterSource returns the source stacks in the selected wave, or every
+// stack when no wave is selected.
+func (o waveOptions) filterSource(stacks []models.Stack) ([]models.Stack, error) {
+	if o.wave == 0 {
+		return stacks, nil
+	}
+	selected, err := o.selected()
+	if err != nil {
+		return nil, err
+	}
+
+	inWave := make(map[string]bool)
+	for _, stack := range selected {
+		inWave[stack.ID] = true
+	}
+	var filtered []models.Stack
+	for _, stack := range stacks {
+		if inWave[stack.ID] {
+			filtered = append(filtered, stack)
+		}
+	}
+	return filtered, nil
+}
+
+// filterDestination returns the destination stacks of the source stacks in
+// the selected wave, or every stack when no wave is selected. Source stacks
+// are found in the destination by the ID mapping file, or else by name.
+func (o waveOptions) filterDestination(stacks []models.Stack) ([]models.Stack, error) {
+	if o.wave == 0 {
+		return stacks, nil
+	}
+	var mapping diff.Mapping
+	if o.mappingPath != "" {
+		var err error
+		if mapping, err = diff.LoadMapping(o.mappingPath); err != nil {
+			return nil, err
+		}
+	}
+	selected, err := o.selected()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, stack := range selected {
+		if id, ok := mapping[diff.KindStacks][stack.ID]; ok {
+			ids[id] = true
+		} else {
+			names[stack.Name] = true
+		}
+	}
+	var filtered []models.Stack
+	for _, stack := range stacks {
+		if ids[stack.ID] || names[stack.Name] {
+			filtered = append(filtered, stack)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/diff"
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/journal"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// rollbackOptions holds the rollback command flags.
+type rollbackOptions struct {
+	mappingPath  string
+	restoreState bool
+	dryRun       bool
+	stacks       []string
+}
+
+// rollbackStep is one action that undoes a migration change.
+type rollbackStep struct {
+	entry       journal.Entry
+	description string
+	skipped     string // Reason the step cannot run
+	bestEffort  bool   // A failure is a warning (mapping mode guesses what changed)
+	reverts     bool   // Success marks the entry as reverted in the journal
+	run         func(ctx context.Context) error
+}
+
+// newRollbackCmd creates the rollback command.
+func newRollbackCmd() *cobra.Command {
+	var opts rollbackOptions
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Undo a failed or aborted migration",
+		Long: `Undoes the stack changes SpaceBridge made during a migration, newest
+first, as recorded in the journal (--journal):
+
+  - Destination stacks that were enabled or had state imported are disabled
+  - Source stacks frozen by 'state migrate --freeze-source' are unlocked,
+    re-enabled and lose their migrated-to label
+  - External state access is turned off again on source stacks where
+    'state enable-access' turned it on
+  - With --restore-state, destination state saved by
+    'state migrate --backup-dir' is imported back
+
+Every action is printed and recorded in the journal, so rolling back twice
+does nothing the second time. Use --dry-run to see the actions first.
+
+Without a journal, --mapping rolls back the stacks in an ID mapping file
+instead: mapped destination stacks are disabled, and mapped source stacks
+are unlocked, re-enabled and lose their migrated-to label. External state
+access and state are left alone, since the mapping does not say what
+SpaceBridge changed.
+
+Example usage:
+  spacebridge rollback --dry-run
+  spacebridge rollback --stack payments-api --restore-state
+  spacebridge rollback --mapping id-mapping.json`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRollback(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.mappingPath, "mapping", "", "Roll back the stacks in this ID mapping file instead of the journal")
+	cmd.Flags().BoolVar(&opts.restoreState, "restore-state", false, "Import destination state backups taken before the migration")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be rolled back without making changes")
+	cmd.Flags().StringArrayVar(&opts.stacks, "stack", nil, "Only roll back this stack (source or destination name or ID); repeatable")
+
+	return cmd
+}
+
+// Statuses of a rollback action.
+const (
+	rollbackPlanned = "planned"
+	rollbackSkipped = "skipped"
+	rollbackDone    = "done"
+	rollbackWarning = "warning"
+	rollbackFailed  = "failed"
+)
+
+// rollbackAction is a rollback step and its outcome.
+type rollbackAction struct {
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	// Reason says why a skipped action cannot run, or why an action failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// rollbackResult is the result of rollback.
+type rollbackResult struct {
+	DryRun    bool             `json:"dryRun"`
+	Actions   []rollbackAction `json:"actions"`
+	Runnable  int              `json:"runnable"`
+	Succeeded int              `json:"succeeded"`
+	Warnings  int              `json:"warnings"`
+	Failed    int              `json:"failed"`
+}
+
+// runRollback undoes journaled (or mapped) migration changes.
+func runRollback(opts rollbackOptions) error {
+	result, err := rollback(opts)
+	if err != nil {
+		return err
+	}
+	if err := render(result); err != nil {
+		return err
+	}
+	return failureError(result.Failed, result.Runnable, "rollback actions failed")
+}
+
+// rollback runs the rollback steps newest first, printing each as it goes.
+func rollback(opts rollbackOptions) (*rollbackResult, error) {
+	if err := cfg.ValidateSource(); err != nil {
+		return nil, fmt.Errorf("source configuration error: %w", err)
+	}
+	if err := cfg.ValidateDestination(); err != nil {
+		return nil, fmt.Errorf("destination configuration error: %w", err)
+	}
+	if opts.mappingPath == "" && journalPath == "" {
+		return nil, fmt.Errorf("rollback needs a journal (--journal) or an ID mapping file (--mapping)")
+	}
+
+	ctx := context.Background()
+
+	sourceClient, err := client.New(cfg.Source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create source client: %w", err)
+	}
+	destClient, err := client.New(cfg.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
+	}
+
+	fmt.Fprintf(progressOut, "Source:      %s\n", cfg.Source.URL)
+	fmt.Fprintf(progressOut, "Destination: %s\n", cfg.Destination.URL)
+
+	fmt.Fprintln(progressOut, "\nDiscovering stacks...")
+	sourceStacks, err := discovery.New(sourceClient).DiscoverStacks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover source stacks: %w", err)
+	}
+	destStacks, err := discovery.New(destClient).DiscoverStacks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover destination stacks: %w", err)
+	}
+	sourceByID := stacksByID(sourceStacks)
+	destByID := stacksByID(destStacks)
+
+	var entries []journal.Entry
+	bestEffort := false
+	if opts.mappingPath != "" {
+		fmt.Fprintf(progressOut, "Mapping:     %s\n", opts.mappingPath)
+		mapping, err := diff.LoadMapping(opts.mappingPath)
+		if err != nil {
+			return nil, err
+		}
+		entries = mappingEntries(mapping, sourceByID, destByID)
+		bestEffort = true
+	} else {
+		fmt.Fprintf(progressOut, "Journal:     %s\n", journalPath)
+		all, err := journal.Load(journalPath)
+		if err != nil {
+			return nil, err
+		}
+		entries = journal.Pending(all)
+	}
+
+	if len(opts.stacks) > 0 {
+		entries = filterEntriesByStack(entries, opts.stacks)
+	}
+
+	// Stack IDs repeat across accounts, so changes journaled against other
+	// accounts are never rolled back here
+	var steps []rollbackStep
+	var current []journal.Entry
+	for _, e := range entries {
+		if opts.mappingPath != "" || e.InAccount(journalHost(e)) {
+			current = append(current, e)
+			continue
+		}
+		steps = append(steps, rollbackStep{
+			entry:       e,
+			description: fmt.Sprintf("Undo %s on stack %s", e.Action, e.StackName()),
+			skipped:     fmt.Sprintf("journaled against %s, not the configured account %s", e.Host(), journalHost(e)),
+		})
+	}
+	steps = append(steps, planRollback(current, sourceClient, destClient, sourceByID, destByID, opts.restoreState)...)
+	for i := range steps {
+		steps[i].bestEffort = steps[i].bestEffort || bestEffort
+	}
+
+	fmt.Fprintln(progressOut, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(progressOut, "│                         ROLLBACK                            │")
+	fmt.Fprintln(progressOut, "└─────────────────────────────────────────────────────────────┘")
+
+	result := &rollbackResult{DryRun: opts.dryRun, Actions: []rollbackAction{}}
+	var runnable []rollbackStep
+	var skipped []rollbackAction
+	for _, step := range steps {
+		if step.skipped != "" {
+			skipped = append(skipped, rollbackAction{Description: step.description, Status: rollbackSkipped, Reason: step.skipped})
+		} else {
+			runnable = append(runnable, step)
+		}
+	}
+	result.Runnable = len(runnable)
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(progressOut, "\n○ SKIPPED (%d)\n", len(skipped))
+		for _, action := range skipped {
+			fmt.Fprintf(progressOut, "    • %s: %s\n", action.Description, action.Reason)
+		}
+	}
+
+	if len(runnable) == 0 {
+		result.Actions = append(result.Actions, skipped...)
+		return result, nil
+	}
+
+	fmt.Fprintf(progressOut, "\n✓ ACTIONS (%d)\n", len(runnable))
+	if opts.dryRun {
+		for _, step := range runnable {
+			fmt.Fprintf(progressOut, "    • %s\n", step.description)
+			result.Actions = append(result.Actions, rollbackAction{Description: step.description, Status: rollbackPlanned})
+		}
+		result.Actions = append(result.Actions, skipped...)
+		return result, nil
+	}
+
+	for _, step := range runnable {
+		action := rollbackAction{Description: step.description, Status: rollbackDone}
+		fmt.Fprintf(progressOut, "  • %s ... ", step.description)
+		if err := step.run(ctx); err != nil {
+			if step.bestEffort {
+				fmt.Fprintf(progressOut, "⚠ %v\n", err)
+				action.Status = rollbackWarning
+				result.Warnings++
+			} else {
+				fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+				action.Status = rollbackFailed
+				result.Failed++
+			}
+			action.Reason = err.Error()
+			result.Actions = append(result.Actions, action)
+			continue
+		}
+		fmt.Fprintln(progressOut, "✓")
+		if step.reverts {
+			reverted := step.entry
+			reverted.Reverted = true
+			recordJournal(reverted)
+		}
+		result.Actions = append(result.Actions, action)
+		result.Succeeded++
+	}
+	result.Actions = append(result.Actions, skipped...)
+
+	return result, nil
+}
+
+func (r *rollbackResult) renderText(w io.Writer) {
+	if r.Runnable == 0 {
+		fmt.Fprintln(w, "\n✓ Nothing to roll back")
+		return
+	}
+
+	if r.DryRun {
+		fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+		fmt.Fprintln(w, "DRY RUN - No changes made")
+		fmt.Fprintln(w, "Remove --dry-run flag to roll back")
+		return
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Rollback complete: %d succeeded, %d warnings, %d failed\n", r.Succeeded, r.Warnings, r.Failed)
+}
+
+// planRollback turns pending changes (newest first) into rollback steps.
+func planRollback(entries []journal.Entry, sourceClient, destClient *client.Client, sourceByID, destByID map[string]models.Stack, restoreState bool) []rollbackStep {
+	var steps []rollbackStep
+	destDisabled := make(map[string]bool) // Destination stacks already scheduled to be disabled
+
+	disableDest := func(e journal.Entry, reverts bool) {
+		stack, ok := destByID[e.DestID]
+		alreadyDisabled := ok && stack.IsDisabled || destDisabled[e.DestID]
+		if alreadyDisabled && !reverts {
+			return
+		}
+		destDisabled[e.DestID] = true
+
+		step := rollbackStep{entry: e, description: "Disable destination stack " + e.DestName, reverts: reverts}
+		switch {
+		case !ok:
+			step.skipped = "stack no longer exists"
+		case alreadyDisabled:
+			// Record the revert without an API call
+			step.description += " (already disabled)"
+			step.run = func(ctx context.Context) error { return nil }
+		default:
+			step.run = func(ctx context.Context) error { return destClient.DisableStack(ctx, stack) }
+		}
+		steps = append(steps, step)
+	}
+
+	for _, e := range entries {
+		switch e.Action {
+		case journal.DestEnabled:
+			disableDest(e, true)
+
+		case journal.StateImported:
+			// The import itself stays pending until its state is restored
+			disableDest(e, false)
+			if !restoreState {
+				continue
+			}
+			step := rollbackStep{entry: e, description: "Restore destination state of " + e.DestName, reverts: true}
+			stack, ok := destByID[e.DestID]
+			switch {
+			case !ok:
+				step.skipped = "stack no longer exists"
+			case e.Backup == "":
+				step.skipped = "no backup was taken (stack had no state, or --backup-dir was not used)"
+			default:
+				backup := e.Backup
+				step.description += " from " + backup
+				step.run = func(ctx context.Context) error { return restoreDestinationState(ctx, destClient, stack, backup) }
+			}
+			steps = append(steps, step)
+
+		case journal.SourceLabeled:
+			step := rollbackStep{entry: e, description: fmt.Sprintf("Remove label %s from source stack %s", e.Label, e.SourceName), reverts: true}
+			if stack, ok := sourceByID[e.SourceID]; !ok {
+				step.skipped = "stack no longer exists"
+			} else {
+				removed := e.Label
+				step.run = func(ctx context.Context) error {
+					var labels []string
+					for _, label := range stack.Labels {
+						if label != removed {
+							labels = append(labels, label)
+						}
+					}
+					return sourceClient.SetStackLabels(ctx, stack, labels)
+				}
+			}
+			steps = append(steps, step)
+
+		case journal.SourceDisabled:
+			step := rollbackStep{entry: e, description: "Re-enable source stack " + e.SourceName, reverts: true}
+			if stack, ok := sourceByID[e.SourceID]; !ok {
+				step.skipped = "stack no longer exists"
+			} else {
+				step.run = func(ctx context.Context) error { return sourceClient.EnableStack(ctx, stack) }
+			}
+			steps = append(steps, step)
+
+		case journal.SourceLocked:
+			step := rollbackStep{entry: e, description: "Unlock source stack " + e.SourceName, reverts: true}
+			if _, ok := sourceByID[e.SourceID]; !ok {
+				step.skipped = "stack no longer exists"
+			} else {
+				id := e.SourceID
+				step.run = func(ctx context.Context) error { return sourceClient.UnlockStack(ctx, id) }
+			}
+			steps = append(steps, step)
+
+		case journal.ExternalAccessEnabled:
+			step := rollbackStep{entry: e, description: "Disable external state access on source stack " + e.SourceName, reverts: true}
+			stack, ok := sourceByID[e.SourceID]
+			switch {
+			case !ok:
+				step.skipped = "stack no longer exists"
+			case !stack.ExternalStateAccessEnabled:
+				// Record the revert without an API call
+				step.description += " (already disabled)"
+				step.run = func(ctx context.Context) error { return nil }
+			default:
+				step.run = func(ctx context.Context) error { return sourceClient.DisableExternalStateAccess(ctx, stack) }
+			}
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
+
+// mappingEntries builds the changes to roll back from an ID mapping file,
+// based on the current state of each mapped stack.
+func mappingEntries(mapping diff.Mapping, sourceByID, destByID map[string]models.Stack) []journal.Entry {
+	stackIDs := mapping[diff.KindStacks]
+	sourceIDs := make([]string, 0, len(stackIDs))
+	for id := range stackIDs {
+		sourceIDs = append(sourceIDs, id)
+	}
+	sort.Strings(sourceIDs)
+
+	var entries []journal.Entry
+	for _, sourceID := range sourceIDs {
+		destID := stackIDs[sourceID]
+		if dest, ok := destByID[destID]; ok && !dest.IsDisabled {
+			entries = append(entries, journal.Entry{Action: journal.DestEnabled, DestID: dest.ID, DestName: dest.Name})
+		}
+
+		source, ok := sourceByID[sourceID]
+		if !ok {
+			continue
+		}
+		if label, labeled := migratedTo(source); labeled {
+			entries = append(entries, journal.Entry{Action: journal.SourceLabeled, SourceID: source.ID, SourceName: source.Name, Label: label})
+		}
+		if source.IsDisabled {
+			entries = append(entries, journal.Entry{Action: journal.SourceDisabled, SourceID: source.ID, SourceName: source.Name})
+		}
+		entries = append(entries, journal.Entry{Action: journal.SourceLocked, SourceID: source.ID, SourceName: source.Name})
+	}
+	return entries
+}
+
+// filterEntriesByStack keeps entries that involve one of the named stacks.
+func filterEntriesByStack(entries []journal.Entry, stacks []string) []journal.Entry {
+	wanted := make(map[string]bool)
+	for _, s := range stacks {
+		wanted[s] = true
+	}
+	var filtered []journal.Entry
+	for _, e := range entries {
+		if wanted[e.SourceID] || wanted[e.SourceName] || wanted[e.DestID] || wanted[e.DestName] {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// stacksByID indexes stacks by ID.
+func stacksByID(stacks []models.Stack) map[string]models.Stack {
+	byID := make(map[string]models.Stack, len(stacks))
+	for _, stack := range stacks {
+		byID[stack.ID] = stack
+	}
+	return byID
+}
+
+// restoreDestinationState imports a state backup into a destination stack.
+func restoreDestinationState(ctx context.Context, c *client.Client, stack models.Stack, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	upload, err := c.GetStateUploadURL(ctx, stack.ID)
+	if err != nil {
+		return err
+	}
+	if err := client.UploadStateToURL(ctx, upload.URL, f, info.Size()); err != nil {
+		return err
+	}
+
+	if err := c.LockStack(ctx, stack.ID); err != nil {
+		return err
+	}
+	importErr := c.ImportManagedState(ctx, stack.ID, upload.ObjectID)
+	unlockErr := c.UnlockStack(ctx, stack.ID)
+	if importErr != nil {
+		return importErr
+	}
+	return unlockErr
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/secrets"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// newSecretsCmd creates the secrets command group.
+func newSecretsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Resolve write-only context values",
+	}
+	cmd.AddCommand(
+		newSecretsFillCmd(),
+	)
+	return cmd
+}
+
+// newSecretsFillCmd creates the secrets fill command.
+func newSecretsFillCmd() *cobra.Command {
+	var manifestPath string
+	var configPath string
+	var outputDir string
+	var spaceFilter string
+	var sourceSpecs []string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "fill",
+		Short: "Fill secrets.auto.tfvars from secret stores",
+		Long: `Looks up every write-only context value (which cannot be exported from
+Spacelift) in the configured secret sources and writes the results to
+secrets.auto.tfvars with 0600 permissions. Secrets that no source has are
+listed in a report and written as empty strings.
+
+Sources are tried in order and the first one with a value wins. Values
+already present in secrets.auto.tfvars are kept when no source has them.
+
+Secrets are looked up by context name and key (falling back to context ID):
+  env, dotenv   SPACEBRIDGE_SECRET_<CONTEXT>__<KEY> (upper-cased, _ separated)
+  sops          a file of <context>: { <key>: <value> }
+  pass/passage  <path>/<context>/<key>
+  vault         KV v2 secret <mount>/<path>/<context>, field <key>
+
+Sources come from the secrets section of the migration config and from
+--source flags of the form type[:path].
+
+Example usage:
+  spacebridge secrets fill -m manifest.json -o ./tofu/ --source env --source dotenv:.env.secrets
+  spacebridge secrets fill -m manifest.json -o ./tofu/ --source sops:secrets.enc.yaml
+  spacebridge secrets fill -m manifest.json -o ./tofu/ -c spacebridge.yaml`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSecretsFill(manifestPath, configPath, outputDir, spaceFilter, sourceSpecs, dryRun)
+		},
+	}
+	cmd.Flags().StringVarP(&manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().StringVarP(&configPath, "config", "c", "", "Migration config YAML file with a secrets section")
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", "./generated", "Directory containing the generated Tofu code")
+	cmd.Flags().StringVarP(&spaceFilter, "space", "s", "", "Only include contexts from this space (and its children)")
+	cmd.Flags().StringArrayVar(&sourceSpecs, "source", nil, "Secret source type[:path] (env, dotenv, sops, pass, passage, vault); repeatable")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report which secrets resolve without writing the file")
+	return cmd
+}
+
+// filledSecret is a write-only context value that secrets fill looked up.
+type filledSecret struct {
+	Context string `json:"context"`
+	Key     string `json:"key"`
+	Source  string `json:"source,omitempty"` // Source that had the value
+}
+
+// secretsFillResult is the result of secrets fill. It never holds values.
+type secretsFillResult struct {
+	DryRun   bool           `json:"dryRun"`
+	Resolved []filledSecret `json:"resolved"`
+	Missing  []filledSecret `json:"missing"`
+	Path     string         `json:"path,omitempty"` // Written file, unless dry run
+
+	missing []secrets.Secret
+	sources []config.SecretSource
+}
+
+// runSecretsFill resolves secrets and writes secrets.auto.tfvars.
+func runSecretsFill(manifestPath, configPath, outputDir, spaceFilter string, sourceSpecs []string, dryRun bool) error {
+	var sources []config.SecretSource
+	if configPath != "" {
+		migCfg, err := loadMigrationConfig(configPath)
+		if err != nil {
+			return err
+		}
+		sources = migCfg.Secrets.Sources
+	}
+	flagSources, err := parseSecretSources(sourceSpecs)
+	if err != nil {
+		return err
+	}
+	sources = append(sources, flagSources...)
+	if len(sources) == 0 {
+		return fmt.Errorf("no secret sources configured (use --source or a secrets section in the migration config)")
+	}
+
+	manifest, err := loadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if spaceFilter != "" {
+		manifest = filterManifestBySpace(manifest, spaceFilter)
+	}
+
+	filled, path, err := fillSecrets(context.Background(), manifest, sources, outputDir, dryRun)
+	if err != nil {
+		return err
+	}
+
+	result := &secretsFillResult{
+		DryRun:   dryRun,
+		Resolved: []filledSecret{},
+		Missing:  []filledSecret{},
+		missing:  filled.Missing,
+		sources:  sources,
+	}
+	for _, r := range filled.Resolved {
+		result.Resolved = append(result.Resolved, filledSecret{Context: r.ContextName, Key: r.Key, Source: r.Source})
+	}
+	for _, s := range filled.Missing {
+		result.Missing = append(result.Missing, filledSecret{Context: s.ContextName, Key: s.Key})
+	}
+	if !dryRun {
+		result.Path = path
+	}
+	return render(result)
+}
+
+func (r *secretsFillResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                        SECRETS FILL                         │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+
+	if len(r.Resolved)+len(r.Missing) == 0 {
+		fmt.Fprintln(w, "\n○ No write-only context values in the manifest")
+		return
+	}
+
+	if len(r.Resolved) > 0 {
+		fmt.Fprintf(w, "\n✓ RESOLVED (%d secrets)\n", len(r.Resolved))
+		for _, s := range r.Resolved {
+			fmt.Fprintf(w, "    • %s/%s (%s)\n", s.Context, s.Key, s.Source)
+		}
+	}
+	printMissingSecrets(w, r.missing, r.sources)
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Resolved: %d, Missing: %d\n", len(r.Resolved), len(r.Missing))
+
+	if r.DryRun {
+		fmt.Fprintln(w, "\nDRY RUN - No changes made")
+		return
+	}
+	fmt.Fprintf(w, "Wrote: %s (mode 0600)\n", r.Path)
+	if len(r.Missing) > 0 {
+		fmt.Fprintln(w, "Fill in the missing values by hand, or add them to a source and re-run")
+	}
+}
+
+// fillSecrets resolves the manifest's secrets from the given sources and,
+// unless dryRun is set, writes them to the output directory.
+func fillSecrets(ctx context.Context, manifest *discovery.Manifest, sourceCfgs []config.SecretSource, outputDir string, dryRun bool) (*secrets.Result, string, error) {
+	sources, err := secrets.NewAll(ctx, sourceCfgs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	path := filepath.Join(outputDir, secrets.FileName)
+	existing, err := secrets.ReadExisting(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result, err := secrets.Resolve(ctx, secrets.Collect(manifest), sources, existing)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if dryRun {
+		return result, path, nil
+	}
+	if path, err = secrets.Write(outputDir, result); err != nil {
+		return nil, "", err
+	}
+	return result, path, nil
+}
+
+// printMissingSecrets lists unresolved secrets with the env name they would
+// be read from.
+func printMissingSecrets(w io.Writer, missing []secrets.Secret, sources []config.SecretSource) {
+	if len(missing) == 0 {
+		return
+	}
+
+	prefix := config.DefaultSecretPrefix
+	for _, src := range sources {
+		if src.Type == config.SecretSourceEnv || src.Type == config.SecretSourceDotenv {
+			prefix = src.EnvPrefix()
+			break
+		}
+	}
+
+	fmt.Fprintf(w, "\n⚠ MISSING (%d secrets)\n", len(missing))
+	for _, s := range missing {
+		fmt.Fprintf(w, "    • %s/%s → %s\n", s.ContextName, s.Key, secrets.EnvName(prefix, s))
+	}
+}
+
+// parseSecretSources parses --source flag values.
+func parseSecretSources(specs []string) ([]config.SecretSource, error) {
+	var sources []config.SecretSource
+	for _, spec := range specs {
+		src, err := config.ParseSecretSource(spec)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
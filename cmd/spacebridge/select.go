@@ -0,0 +1,727 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/internal/selection"
+	"github.com/jnesspace/spacebridge/internal/selector"
+	"github.com/jnesspace/spacebridge/internal/ui"
+)
+
+// selectKeys is the help text of the select screen.
+const selectKeys = `  ↑ ↓ k j, PgUp PgDn, Home End   Move
+  → l Enter                       Open a space
+  ← h                             Close a space, or go to its parent
+  Space x                         Tick or untick a resource; on a space, every
+                                  listed stack below it
+  /                               Filter stacks: a name pattern, or name=<regex>,
+                                  label=<label> and vendor=<vendor>; Esc clears
+  v                               View the selection, including pulled-in resources
+  s                               Save the selection
+  q                               Quit (asks again when there are unsaved changes)`
+
+// newSelectCmd creates the select command.
+func newSelectCmd() *cobra.Command {
+	var manifestPath, selectionPath string
+	cmd := &cobra.Command{
+		Use:   "select",
+		Short: "Interactively pick the stacks, contexts and policies to migrate",
+		Long: `Opens a full-screen browser of the source space tree to tick the stacks,
+contexts and policies to migrate.
+
+Stacks that a ticked stack depends on, and the contexts and policies attached
+to the selected stacks, are pulled in automatically. The selection is saved
+to a file that generate, state migrate and stacks enable accept with
+--selection. An existing selection file is loaded so it can be edited.
+
+Keys:
+` + selectKeys + `
+
+Example usage:
+  spacebridge select -m manifest.json
+  spacebridge generate -m manifest.json --selection selection.yaml -o ./tofu/`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSelect(manifestPath, selectionPath)
+		},
+	}
+	cmd.Flags().StringVarP(&manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().StringVarP(&selectionPath, "file", "f", selection.DefaultPath, "Selection file to load and save")
+	return cmd
+}
+
+// Kinds of rows on the select screen.
+const (
+	selectSpace   = "space"
+	selectStack   = "stack"
+	selectContext = "context"
+	selectPolicy  = "policy"
+)
+
+// selectRow is a row of the resource tree.
+type selectRow struct {
+	kind  string
+	id    string
+	depth int
+}
+
+// selectSession is an interactive selection of resources to migrate.
+type selectSession struct {
+	manifest *discovery.Manifest
+	path     string
+
+	picks    selection.Picks
+	resolved *selection.Selection
+	dirty    bool
+	saved    bool
+
+	children map[string][]models.Space // Child spaces by parent ID, "" for the top
+	expanded map[string]bool
+
+	filter     selector.Selector // Narrows the listed stacks
+	filterText string
+
+	rows     []selectRow
+	cursor   int
+	offset   int    // First row shown
+	status   string // Message shown below the tree
+	quitting bool   // Quit was pressed once with unsaved changes
+	editing  bool   // The filter is being typed
+	input    string
+	viewing  bool // The selection is shown instead of the tree
+	viewTop  int  // First line of the selection shown
+}
+
+// runSelect runs the interactive select screen.
+func runSelect(manifestPath, selectionPath string) error {
+	if !ui.IsTerminal() {
+		return fmt.Errorf("select needs an interactive terminal; use the stack selectors or write a selection file instead")
+	}
+	manifest, err := loadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	s := newSelectSession(manifest, selectionPath)
+	if existing, err := selection.Load(selectionPath); err == nil {
+		s.picks = existing.Picks()
+		s.status = "Loaded selection from: " + selectionPath
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	s.resolve()
+	s.buildRows()
+
+	terminal, err := ui.OpenTerminal()
+	if err != nil {
+		return err
+	}
+	err = s.loop(terminal)
+	if closeErr := terminal.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(progressOut, "Selected: %s\n", s.counts())
+	switch {
+	case s.dirty:
+		fmt.Fprintln(progressOut, "⚠ Unsaved selection discarded")
+	case s.saved:
+		fmt.Fprintf(progressOut, "✓ Saved selection to: %s\n", s.path)
+		fmt.Fprintf(progressOut, "  Use it with: spacebridge generate --selection %s\n", s.path)
+	}
+	return nil
+}
+
+// newSelectSession starts a session with every top-level space open.
+func newSelectSession(manifest *discovery.Manifest, path string) *selectSession {
+	s := &selectSession{
+		manifest: manifest,
+		path:     path,
+		picks:    selection.NewPicks(),
+		children: make(map[string][]models.Space),
+		expanded: make(map[string]bool),
+	}
+
+	ids := make(map[string]bool)
+	for _, space := range manifest.Spaces {
+		ids[space.ID] = true
+	}
+	for _, space := range manifest.Spaces {
+		parent := ""
+		if space.ParentSpace != nil && ids[*space.ParentSpace] {
+			parent = *space.ParentSpace
+		}
+		s.children[parent] = append(s.children[parent], space)
+	}
+	for _, spaces := range s.children {
+		sort.Slice(spaces, func(i, j int) bool { return spaces[i].Name < spaces[j].Name })
+	}
+	for _, space := range s.children[""] {
+		s.expanded[space.ID] = true
+	}
+	return s
+}
+
+// loop draws the screen and handles keys until the session ends.
+func (s *selectSession) loop(terminal *ui.Terminal) error {
+	for {
+		_, height := terminal.Size()
+		lines, highlight := s.render(height)
+		terminal.Draw(lines, highlight)
+
+		key, err := terminal.ReadKey()
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		if s.handle(key, height) {
+			return nil
+		}
+	}
+}
+
+// treeHeight is the number of tree rows that fit on a screen of height
+// lines, next to the header and footer.
+func treeHeight(height int) int {
+	if height < 8 {
+		return 1
+	}
+	return height - 7
+}
+
+// handle applies a key press and reports whether the session ends.
+func (s *selectSession) handle(key string, height int) bool {
+	if s.editing {
+		s.editFilter(key)
+		return false
+	}
+	if s.viewing {
+		s.scrollView(key, treeHeight(height))
+		return false
+	}
+	if key != "q" && key != ui.KeyCtrlC {
+		s.quitting = false
+	}
+
+	page := treeHeight(height)
+	switch key {
+	case ui.KeyUp, "k":
+		s.move(-1, page)
+	case ui.KeyDown, "j":
+		s.move(1, page)
+	case ui.KeyPageUp:
+		s.move(-page, page)
+	case ui.KeyPageDown:
+		s.move(page, page)
+	case ui.KeyHome, "g":
+		s.move(-len(s.rows), page)
+	case ui.KeyEnd, "G":
+		s.move(len(s.rows), page)
+	case ui.KeyRight, "l", ui.KeyEnter:
+		if row, ok := s.current(); ok && row.kind == selectSpace {
+			s.expanded[row.id] = true
+			s.buildRows()
+		}
+	case ui.KeyLeft, "h":
+		s.collapse(page)
+	case " ", "x":
+		s.toggle()
+	case "/":
+		s.editing = true
+		s.input = s.filterText
+	case ui.KeyEscape:
+		if s.filterText != "" {
+			s.filter, s.filterText = selector.Selector{}, ""
+			s.buildRows()
+			s.status = "Filter cleared"
+		}
+	case "v":
+		s.viewing, s.viewTop = true, 0
+	case "s":
+		s.save()
+	case "q", ui.KeyCtrlC:
+		if s.dirty && !s.quitting {
+			s.quitting = true
+			s.status = "⚠ The selection has unsaved changes. Press s to save, or q again to discard them."
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// current returns the row under the cursor.
+func (s *selectSession) current() (selectRow, bool) {
+	if s.cursor < 0 || s.cursor >= len(s.rows) {
+		return selectRow{}, false
+	}
+	return s.rows[s.cursor], true
+}
+
+// move moves the cursor and scrolls it into view.
+func (s *selectSession) move(delta, page int) {
+	s.cursor += delta
+	if s.cursor >= len(s.rows) {
+		s.cursor = len(s.rows) - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+page {
+		s.offset = s.cursor - page + 1
+	}
+}
+
+// collapse closes the space under the cursor, or moves to the space holding
+// the row under the cursor.
+func (s *selectSession) collapse(page int) {
+	row, ok := s.current()
+	if !ok {
+		return
+	}
+	if row.kind == selectSpace && s.expanded[row.id] {
+		delete(s.expanded, row.id)
+		s.buildRows()
+		return
+	}
+	for i := s.cursor - 1; i >= 0; i-- {
+		if s.rows[i].kind == selectSpace && s.rows[i].depth < row.depth {
+			s.move(i-s.cursor, page)
+			return
+		}
+	}
+}
+
+// buildRows lists the open spaces with their child spaces, their stacks that
+// pass the filter, their contexts and their policies.
+func (s *selectSession) buildRows() {
+	var selected selectRow
+	if row, ok := s.current(); ok {
+		selected = row
+	}
+
+	s.rows = nil
+	var walk func(parent string, depth int)
+	walk = func(parent string, depth int) {
+		for _, space := range s.children[parent] {
+			s.rows = append(s.rows, selectRow{kind: selectSpace, id: space.ID, depth: depth})
+			if !s.expanded[space.ID] {
+				continue
+			}
+			walk(space.ID, depth+1)
+			for _, stack := range s.manifest.Stacks {
+				if stack.Space == space.ID && s.filter.Match(stack) {
+					s.rows = append(s.rows, selectRow{kind: selectStack, id: stack.ID, depth: depth + 1})
+				}
+			}
+			for _, c := range s.manifest.Contexts {
+				if c.Space == space.ID {
+					s.rows = append(s.rows, selectRow{kind: selectContext, id: c.ID, depth: depth + 1})
+				}
+			}
+			for _, p := range s.manifest.Policies {
+				if p.Space == space.ID {
+					s.rows = append(s.rows, selectRow{kind: selectPolicy, id: p.ID, depth: depth + 1})
+				}
+			}
+		}
+	}
+	walk("", 0)
+
+	// Keep the cursor on the same row when it is still listed
+	s.cursor = 0
+	for i, row := range s.rows {
+		if row.kind == selected.kind && row.id == selected.id {
+			s.cursor = i
+		}
+	}
+	if s.offset > s.cursor {
+		s.offset = s.cursor
+	}
+}
+
+// subtreeStacks returns the IDs of the stacks in a space and the spaces
+// below it that pass the filter.
+func (s *selectSession) subtreeStacks(spaceID string) []string {
+	var ids []string
+	for _, stack := range s.manifest.Stacks {
+		if !s.filter.Match(stack) {
+			continue
+		}
+		for _, ancestor := range s.manifest.SpaceAncestry(stack.Space) {
+			if ancestor == spaceID {
+				ids = append(ids, stack.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// mark shows whether a resource is picked, pulled in or not selected, and
+// for a space whether all, some or none of its listed stacks are.
+func (s *selectSession) mark(row selectRow) string {
+	var picked bool
+	var items []selection.Item
+	switch row.kind {
+	case selectSpace:
+		stacks := s.subtreeStacks(row.id)
+		n := 0
+		for _, id := range stacks {
+			if containsItem(s.resolved.Stacks, id) {
+				n++
+			}
+		}
+		switch {
+		case n == 0:
+			return "[ ]"
+		case n == len(stacks):
+			return "[x]"
+		default:
+			return "[-]"
+		}
+	case selectStack:
+		picked, items = s.picks.Stacks[row.id], s.resolved.Stacks
+	case selectContext:
+		picked, items = s.picks.Contexts[row.id], s.resolved.Contexts
+	case selectPolicy:
+		picked, items = s.picks.Policies[row.id], s.resolved.Policies
+	}
+	if picked {
+		return "[x]"
+	}
+	if containsItem(items, row.id) {
+		return "[+]"
+	}
+	return "[ ]"
+}
+
+// toggle ticks or unticks the row under the cursor. On a space it ticks
+// every listed stack below it, or unticks them all if they all are ticked.
+func (s *selectSession) toggle() {
+	row, ok := s.current()
+	if !ok {
+		return
+	}
+
+	before := s.resolved
+	switch row.kind {
+	case selectSpace:
+		stacks := s.subtreeStacks(row.id)
+		if len(stacks) == 0 {
+			s.status = "No stacks listed in this space"
+			return
+		}
+		all := true
+		for _, id := range stacks {
+			all = all && s.picks.Stacks[id]
+		}
+		for _, id := range stacks {
+			setPick(s.picks.Stacks, id, !all)
+		}
+	case selectStack:
+		setPick(s.picks.Stacks, row.id, !s.picks.Stacks[row.id])
+	case selectContext:
+		setPick(s.picks.Contexts, row.id, !s.picks.Contexts[row.id])
+	case selectPolicy:
+		setPick(s.picks.Policies, row.id, !s.picks.Policies[row.id])
+	}
+	s.resolve()
+	s.dirty = true
+
+	// Report what the change pulled in
+	var pulled []string
+	for _, kind := range []struct {
+		name          string
+		before, after []selection.Item
+	}{
+		{selectStack, before.Stacks, s.resolved.Stacks},
+		{selectContext, before.Contexts, s.resolved.Contexts},
+		{selectPolicy, before.Policies, s.resolved.Policies},
+	} {
+		for _, item := range kind.after {
+			if item.RequiredBy != "" && !containsItem(kind.before, item.ID) {
+				pulled = append(pulled, fmt.Sprintf("%s %s (required by %s)", kind.name, item.Name, item.RequiredBy))
+			}
+		}
+	}
+	s.status = ""
+	if len(pulled) > 0 {
+		s.status = "+ Pulled in " + strings.Join(pulled, ", ")
+	}
+}
+
+// scrollView handles a key while the selection is shown: arrows scroll it
+// and any other key goes back to the tree.
+func (s *selectSession) scrollView(key string, page int) {
+	last := len(s.selectionLines()) - page
+	switch key {
+	case ui.KeyUp, "k":
+		s.viewTop--
+	case ui.KeyDown, "j":
+		s.viewTop++
+	case ui.KeyPageUp:
+		s.viewTop -= page
+	case ui.KeyPageDown:
+		s.viewTop += page
+	default:
+		s.viewing = false
+		return
+	}
+	if s.viewTop > last {
+		s.viewTop = last
+	}
+	if s.viewTop < 0 {
+		s.viewTop = 0
+	}
+}
+
+// editFilter handles a key while the filter is being typed.
+func (s *selectSession) editFilter(key string) {
+	switch key {
+	case ui.KeyEnter:
+		if err := s.setFilter(s.input); err != nil {
+			s.status = "✗ " + err.Error()
+			return
+		}
+		s.editing = false
+		s.buildRows()
+		matches := 0
+		for _, stack := range s.manifest.Stacks {
+			if s.filter.Match(stack) {
+				matches++
+			}
+		}
+		s.status = fmt.Sprintf("%d stacks match", matches)
+		if s.filterText == "" {
+			s.status = "Filter cleared"
+		}
+	case ui.KeyEscape, ui.KeyCtrlC:
+		s.editing = false
+		s.status = ""
+	case ui.KeyBackspace:
+		if runes := []rune(s.input); len(runes) > 0 {
+			s.input = string(runes[:len(runes)-1])
+		}
+	default:
+		if len([]rune(key)) == 1 {
+			s.input += key
+		}
+	}
+}
+
+// setFilter sets the stack filter from a name pattern or key=value terms,
+// and opens every space holding a matching stack.
+func (s *selectSession) setFilter(text string) error {
+	var f selector.Selector
+	for _, term := range strings.Fields(text) {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok {
+			key, value = "name", term
+		}
+		if value == "" {
+			return fmt.Errorf("invalid filter %q (expected name=<regex>, label=<label> or vendor=<vendor>)", term)
+		}
+		switch key {
+		case "name":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return fmt.Errorf("invalid name pattern: %w", err)
+			}
+			f.NameRegex = re
+		case "label":
+			f.Labels = []string{value}
+		case "vendor":
+			f.Vendors = []string{value}
+		default:
+			return fmt.Errorf("unknown filter %q (expected name, label or vendor)", key)
+		}
+	}
+	s.filter, s.filterText = f, strings.TrimSpace(text)
+
+	if s.filterText != "" {
+		for _, stack := range s.manifest.Stacks {
+			if f.Match(stack) {
+				for _, space := range s.manifest.SpaceAncestry(stack.Space) {
+					s.expanded[space] = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// setPick adds or removes a picked ID.
+func setPick(picks map[string]bool, id string, selected bool) {
+	if selected {
+		picks[id] = true
+	} else {
+		delete(picks, id)
+	}
+}
+
+// containsItem reports whether items include an ID.
+func containsItem(items []selection.Item, id string) bool {
+	for _, item := range items {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve recomputes the selection from the picks.
+func (s *selectSession) resolve() {
+	s.resolved = selection.Resolve(s.manifest, s.picks)
+}
+
+// counts describes the size of the selection.
+func (s *selectSession) counts() string {
+	return fmt.Sprintf("%d stacks, %d contexts, %d policies",
+		len(s.resolved.Stacks), len(s.resolved.Contexts), len(s.resolved.Policies))
+}
+
+// save writes the selection file.
+func (s *selectSession) save() {
+	if err := s.resolved.Save(s.path); err != nil {
+		s.status = "✗ " + err.Error()
+		return
+	}
+	s.dirty, s.saved, s.quitting = false, true, false
+	s.status = "✓ Saved selection to: " + s.path
+}
+
+// render returns the lines of a screen of the given height and the index
+// of the highlighted line, or -1.
+func (s *selectSession) render(height int) ([]string, int) {
+	lines := []string{
+		fmt.Sprintf(" SPACEBRIDGE SELECT  %d spaces, %d stacks, %d contexts, %d policies",
+			len(s.manifest.Spaces), len(s.manifest.Stacks), len(s.manifest.Contexts), len(s.manifest.Policies)),
+		fmt.Sprintf(" Selected: %s", s.counts()),
+	}
+	switch {
+	case s.editing:
+		lines = append(lines, " Filter: "+s.input+"▏")
+	case s.filterText != "":
+		lines = append(lines, " Filter: "+s.filterText+"  (Esc clears)")
+	default:
+		lines = append(lines, "")
+	}
+	lines = append(lines, " "+strings.Repeat("─", 61))
+
+	page := treeHeight(height)
+	highlight := -1
+	if s.viewing {
+		view := s.selectionLines()
+		for i := s.viewTop; i < s.viewTop+page; i++ {
+			line := ""
+			if i < len(view) {
+				line = view[i]
+			}
+			lines = append(lines, line)
+		}
+	} else {
+		for i := s.offset; i < s.offset+page; i++ {
+			if i >= len(s.rows) {
+				lines = append(lines, "")
+				continue
+			}
+			if i == s.cursor {
+				highlight = len(lines)
+			}
+			lines = append(lines, s.rowLine(s.rows[i]))
+		}
+	}
+
+	lines = append(lines, " "+strings.Repeat("─", 61), " "+s.status)
+	switch {
+	case s.viewing:
+		lines = append(lines, " ↑↓ scroll  any other key goes back")
+	case s.editing:
+		lines = append(lines, " Enter apply  Esc cancel")
+	default:
+		lines = append(lines, " ↑↓ move  → open  ← close  space tick  / filter  v view  s save  q quit   [x] ticked  [+] pulled in")
+	}
+	return lines, highlight
+}
+
+// rowLine formats a row of the resource tree.
+func (s *selectSession) rowLine(row selectRow) string {
+	indent := strings.Repeat("  ", row.depth)
+	mark := s.mark(row)
+
+	switch row.kind {
+	case selectSpace:
+		arrow := "▸"
+		if s.expanded[row.id] {
+			arrow = "▾"
+		}
+		name := row.id
+		for _, space := range s.manifest.Spaces {
+			if space.ID == row.id {
+				name = space.Name
+			}
+		}
+		return fmt.Sprintf(" %s%s %s %s  (%d stacks)", indent, arrow, mark, name, len(s.subtreeStacks(row.id)))
+	case selectStack:
+		for _, stack := range s.manifest.Stacks {
+			if stack.ID == row.id {
+				line := fmt.Sprintf(" %s  %s %s  %s", indent, mark, stack.Name, selector.VendorName(stack.VendorType))
+				if len(stack.Labels) > 0 {
+					line += "  " + strings.Join(stack.Labels, ", ")
+				}
+				return line
+			}
+		}
+	case selectContext:
+		for _, c := range s.manifest.Contexts {
+			if c.ID == row.id {
+				return fmt.Sprintf(" %s  %s context %s", indent, mark, c.Name)
+			}
+		}
+	case selectPolicy:
+		for _, p := range s.manifest.Policies {
+			if p.ID == row.id {
+				return fmt.Sprintf(" %s  %s policy %s  %s", indent, mark, p.Name, p.Type)
+			}
+		}
+	}
+	return " " + indent + "  " + mark + " " + row.id
+}
+
+// selectionLines lists the selection with the resources pulled in.
+func (s *selectSession) selectionLines() []string {
+	var lines []string
+	for _, kind := range []struct {
+		title string
+		items []selection.Item
+	}{
+		{"STACKS", s.resolved.Stacks},
+		{"CONTEXTS", s.resolved.Contexts},
+		{"POLICIES", s.resolved.Policies},
+	} {
+		lines = append(lines, fmt.Sprintf(" %s (%d)", kind.title, len(kind.items)))
+		for _, item := range kind.items {
+			if item.RequiredBy == "" {
+				lines = append(lines, fmt.Sprintf("     ✓ %s", item.Name))
+			} else {
+				lines = append(lines, fmt.Sprintf("     + %s (required by %s)", item.Name, item.RequiredBy))
+			}
+		}
+		lines = append(lines, "")
+	}
+	return lines
+}
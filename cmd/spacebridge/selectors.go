@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/internal/selection"
+	"github.com/jnesspace/spacebridge/internal/selector"
+)
+
+// selectorOptions selects stacks by label, name, ID, vendor or selection
+// file, on top of --space.
+type selectorOptions struct {
+	labels              []string
+	nameRegex           string
+	stackIDsFile        string
+	vendors             []string
+	excludeLabels       []string
+	excludeNameRegex    string
+	excludeStackIDsFile string
+	excludeVendors      []string
+	selectionPath       string
+}
+
+// addFlags registers the selector flags on a command.
+func (o *selectorOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&o.labels, "label", nil, "Only include stacks with this label; repeatable, any label matches")
+	cmd.Flags().StringVar(&o.nameRegex, "name-regex", "", "Only include stacks whose name matches this regular expression")
+	cmd.Flags().StringVar(&o.stackIDsFile, "stack-ids-file", "", "Only include the stacks listed in this file, one ID per line")
+	cmd.Flags().StringArrayVar(&o.vendors, "vendor", nil, "Only include stacks of this vendor (e.g. Terraform, Ansible); repeatable")
+	cmd.Flags().StringArrayVar(&o.excludeLabels, "exclude-label", nil, "Leave out stacks with this label; repeatable")
+	cmd.Flags().StringVar(&o.excludeNameRegex, "exclude-name-regex", "", "Leave out stacks whose name matches this regular expression")
+	cmd.Flags().StringVar(&o.excludeStackIDsFile, "exclude-stack-ids-file", "", "Leave out the stacks listed in this file, one ID per line")
+	cmd.Flags().StringArrayVar(&o.excludeVendors, "exclude-vendor", nil, "Leave out stacks of this vendor; repeatable")
+	cmd.Flags().StringVar(&o.selectionPath, "selection", "", "Only include stacks from this selection file (see: spacebridge select)")
+}
+
+// stackSelector is the parsed form of selectorOptions.
+type stackSelector struct {
+	selector  *selector.Selector
+	selection *selection.Selection // nil without --selection
+}
+
+// load parses the selector flags and reads the files they name.
+func (o selectorOptions) load() (*stackSelector, error) {
+	s := &selector.Selector{
+		Labels:         o.labels,
+		Vendors:        o.vendors,
+		ExcludeLabels:  o.excludeLabels,
+		ExcludeVendors: o.excludeVendors,
+	}
+
+	var err error
+	if o.nameRegex != "" {
+		if s.NameRegex, err = regexp.Compile(o.nameRegex); err != nil {
+			return nil, fmt.Errorf("invalid --name-regex: %w", err)
+		}
+	}
+	if o.excludeNameRegex != "" {
+		if s.ExcludeNameRegex, err = regexp.Compile(o.excludeNameRegex); err != nil {
+			return nil, fmt.Errorf("invalid --exclude-name-regex: %w", err)
+		}
+	}
+	if o.stackIDsFile != "" {
+		if s.StackIDs, err = selector.ReadStackIDs(o.stackIDsFile); err != nil {
+			return nil, err
+		}
+	}
+	if o.excludeStackIDsFile != "" {
+		if s.ExcludeStackIDs, err = selector.ReadStackIDs(o.excludeStackIDsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	sel, err := loadSelection(o.selectionPath)
+	if err != nil {
+		return nil, err
+	}
+	if !s.IsZero() {
+		fmt.Fprintf(progressOut, "Selecting stacks: %s\n", s)
+	}
+	return &stackSelector{selector: s, selection: sel}, nil
+}
+
+// sourceStacks returns the selected source stacks.
+func (s *stackSelector) sourceStacks(stacks []models.Stack) []models.Stack {
+	stacks = s.selector.Filter(stacks)
+	if s.selection == nil {
+		return stacks
+	}
+	var filtered []models.Stack
+	for _, stack := range stacks {
+		if s.selection.HasStack(stack.ID) {
+			filtered = append(filtered, stack)
+		}
+	}
+	return filtered
+}
+
+// destinationStacks returns the selected destination stacks. Stacks of a
+// selection file are matched by name, because the file lists source stacks.
+func (s *stackSelector) destinationStacks(stacks []models.Stack) []models.Stack {
+	stacks = s.selector.Filter(stacks)
+	if s.selection == nil {
+		return stacks
+	}
+	var filtered []models.Stack
+	for _, stack := range stacks {
+		if s.selection.HasStackName(stack.Name) {
+			filtered = append(filtered, stack)
+		}
+	}
+	return filtered
+}
+
+// manifest narrows a manifest down to the selected stacks, the stacks they
+// depend on, and the resources attached to them. Dependencies are pulled in
+// even if the selectors leave them out, since the generated stack
+// dependencies need them.
+func (s *stackSelector) manifest(m *discovery.Manifest) (*discovery.Manifest, error) {
+	if s.selector.IsZero() && s.selection == nil {
+		return m, nil
+	}
+
+	if !s.selector.IsZero() {
+		for _, item := range s.selector.Resolve(m).Stacks {
+			if item.RequiredBy != "" {
+				fmt.Fprintf(progressOut, "+ %s (required by %s)\n", item.Name, item.RequiredBy)
+			}
+		}
+		m = s.selector.Apply(m)
+	}
+	if s.selection != nil {
+		if missing := s.selection.Missing(m); len(missing) > 0 {
+			fmt.Fprintf(progressOut, "⚠ %d selected resources are not in the manifest: %s\n", len(missing), strings.Join(missing, ", "))
+		}
+		m = s.selection.Apply(m)
+	}
+	if len(m.Stacks) == 0 && len(m.Contexts) == 0 && len(m.Policies) == 0 {
+		return nil, fmt.Errorf("no selected resources found in the manifest")
+	}
+	fmt.Fprintf(progressOut, "Selection: %d stacks, %d contexts, %d policies\n", len(m.Stacks), len(m.Contexts), len(m.Policies))
+	return m, nil
+}
@@ -2,31 +2,49 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/jnesspace/spacebridge/internal/client"
 	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/journal"
 	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/internal/ui"
+	"github.com/jnesspace/spacebridge/internal/verify"
 )
 
 // newStacksCmd creates the stacks command group.
 func newStacksCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "stacks",
-		Short: "Manage stacks in destination account",
+		Short: "Enable and verify destination stacks, unfreeze source stacks",
 	}
 	cmd.AddCommand(
 		newStacksEnableCmd(),
+		newStacksUnfreezeCmd(),
+		newStacksVerifyCmd(),
 	)
 	return cmd
 }
 
+// stacksEnableOptions holds the flags of stacks enable.
+type stacksEnableOptions struct {
+	dryRun      bool
+	spaceFilter string
+	waves       waveOptions
+	selectors   selectorOptions
+}
+
 // newStacksEnableCmd creates the stacks enable command.
 func newStacksEnableCmd() *cobra.Command {
-	var dryRun bool
-	var spaceFilter string
+	var opts stacksEnableOptions
 	cmd := &cobra.Command{
 		Use:   "enable",
 		Short: "Enable all disabled stacks in destination",
@@ -43,21 +61,76 @@ This command will:
 
 Note: This command operates on the DESTINATION account.
 
-Use --dry-run to see what would be enabled without making changes.`,
+Use --dry-run to see what would be enabled without making changes.
+Use --wave to enable one wave of the dependency schedule at a time, so
+upstream stacks are enabled first (see: spacebridge plan waves). Waves are
+computed from the source stacks' dependencies and matched to destination
+stacks by --mapping, or else by name.
+Use --selection to enable only the stacks of a file written by
+spacebridge select, matched by name. --label, --name-regex, --stack-ids-file,
+--vendor and their --exclude-* variants match the destination stacks.`,
+		Annotations: structuredOutput(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runStacksEnable(dryRun, spaceFilter)
+			return runStacksEnable(opts)
 		},
 	}
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be enabled without making changes")
-	cmd.Flags().StringVarP(&spaceFilter, "space", "s", "", "Only include stacks from this space")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be enabled without making changes")
+	cmd.Flags().StringVarP(&opts.spaceFilter, "space", "s", "", "Only include stacks from this destination space (ID or name)")
+	opts.selectors.addFlags(cmd)
+	opts.waves.addFlags(cmd)
+	opts.waves.addMappingFlag(cmd)
 	return cmd
 }
 
+// Statuses of a stack in stacks enable.
+const (
+	enablePlanned = "planned"
+	enableEnabled = "enabled"
+	enableFailed  = "failed"
+)
+
+// stackEnable is a disabled destination stack that stacks enable enables.
+type stackEnable struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// stacksEnableResult is the result of stacks enable.
+type stacksEnableResult struct {
+	DryRun  bool          `json:"dryRun"`
+	Stacks  []stackEnable `json:"stacks"`
+	Enabled int           `json:"enabled"`
+	Failed  int           `json:"failed"`
+}
+
 // runStacksEnable enables all disabled stacks in the destination.
-func runStacksEnable(dryRun bool, spaceFilter string) error {
+func runStacksEnable(opts stacksEnableOptions) error {
+	result, err := enableStacks(opts)
+	if err != nil {
+		return err
+	}
+	if err := render(result); err != nil {
+		return err
+	}
+	return failureError(result.Failed, len(result.Stacks), "stacks failed to enable")
+}
+
+// enableStacks enables the disabled destination stacks, printing each as
+// it goes.
+func enableStacks(opts stacksEnableOptions) (*stacksEnableResult, error) {
+	if err := opts.waves.validate(); err != nil {
+		return nil, err
+	}
+	stackSel, err := opts.selectors.load()
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate destination config
 	if err := cfg.ValidateDestination(); err != nil {
-		return fmt.Errorf("destination configuration error: %w\n\nPlease set DESTINATION_SPACELIFT_URL, DESTINATION_SPACELIFT_KEY_ID, and DESTINATION_SPACELIFT_SECRET_KEY", err)
+		return nil, fmt.Errorf("destination configuration error: %w\n\nPlease set DESTINATION_SPACELIFT_URL, DESTINATION_SPACELIFT_KEY_ID, and DESTINATION_SPACELIFT_SECRET_KEY", err)
 	}
 
 	ctx := context.Background()
@@ -65,33 +138,49 @@ func runStacksEnable(dryRun bool, spaceFilter string) error {
 	// Create destination client
 	destClient, err := client.New(cfg.Destination)
 	if err != nil {
-		return fmt.Errorf("failed to create destination client: %w", err)
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
 	}
 
-	fmt.Printf("Destination: %s\n", cfg.Destination.URL)
-	if spaceFilter != "" {
-		fmt.Printf("Space:       %s\n", spaceFilter)
+	fmt.Fprintf(progressOut, "Destination: %s\n", cfg.Destination.URL)
+
+	// Resolve space filter if specified (using destination account spaces)
+	destSvc := discovery.New(destClient)
+	var resolvedSpaceID string
+	if opts.spaceFilter != "" {
+		spaceID, spaceName, err := resolveSpaceFilter(ctx, destSvc, opts.spaceFilter)
+		if err != nil {
+			return nil, err
+		}
+		resolvedSpaceID = spaceID
+		fmt.Fprintf(progressOut, "Space:       %s (ID: %s)\n", spaceName, spaceID)
 	}
-	fmt.Println("\nDiscovering disabled stacks...")
+	fmt.Fprintln(progressOut, "\nDiscovering disabled stacks...")
 
 	// Discover stacks from destination
-	destSvc := discovery.New(destClient)
 	stacks, err := destSvc.DiscoverStacks(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to discover stacks: %w", err)
+		return nil, fmt.Errorf("failed to discover stacks: %w", err)
 	}
 
 	// Filter by space if specified
-	if spaceFilter != "" {
+	if resolvedSpaceID != "" {
 		var filtered []models.Stack
 		for _, stack := range stacks {
-			if stack.Space == spaceFilter {
+			if stack.Space == resolvedSpaceID {
 				filtered = append(filtered, stack)
 			}
 		}
 		stacks = filtered
 	}
 
+	// Restrict to the selected stacks if specified
+	stacks = stackSel.destinationStacks(stacks)
+
+	// Restrict to one dependency wave if specified
+	if stacks, err = opts.waves.filterDestination(stacks); err != nil {
+		return nil, err
+	}
+
 	// Find disabled stacks
 	var disabled []models.Stack
 	for _, stack := range stacks {
@@ -100,53 +189,639 @@ func runStacksEnable(dryRun bool, spaceFilter string) error {
 		}
 	}
 
+	result := &stacksEnableResult{DryRun: opts.dryRun, Stacks: []stackEnable{}}
 	if len(disabled) == 0 {
-		fmt.Println("\n✓ No disabled stacks found!")
-		return nil
+		return result, nil
 	}
 
-	fmt.Printf("\nFound %d disabled stacks:\n", len(disabled))
+	fmt.Fprintf(progressOut, "\nFound %d disabled stacks:\n", len(disabled))
 	for _, stack := range disabled {
-		fmt.Printf("    • %s\n", stack.Name)
+		fmt.Fprintf(progressOut, "    • %s\n", stack.Name)
+		result.Stacks = append(result.Stacks, stackEnable{ID: stack.ID, Name: stack.Name, Status: enablePlanned})
 	}
 
-	if dryRun {
-		fmt.Println("\n─────────────────────────────────────────────────────────────")
-		fmt.Println("DRY RUN - No changes made")
-		fmt.Println("Remove --dry-run flag to enable stacks")
-		return nil
+	if opts.dryRun {
+		return result, nil
 	}
 
 	// Enable stacks
-	fmt.Println("\n─────────────────────────────────────────────────────────────")
-	fmt.Println("Enabling stacks...")
+	fmt.Fprintln(progressOut, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintln(progressOut, "Enabling stacks...")
 
-	successCount := 0
-	failCount := 0
-
-	for _, stack := range disabled {
-		fmt.Printf("  • %s ... ", stack.Name)
+	for i, stack := range disabled {
+		entry := &result.Stacks[i]
+		fmt.Fprintf(progressOut, "  • %s ... ", stack.Name)
 		if err := destClient.EnableStack(ctx, stack); err != nil {
-			fmt.Printf("✗ Failed: %v\n", err)
-			failCount++
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+			entry.Status, entry.Error = enableFailed, err.Error()
+			result.Failed++
 		} else {
-			fmt.Printf("✓ Enabled\n")
-			successCount++
+			fmt.Fprintf(progressOut, "✓ Enabled\n")
+			recordJournal(journal.Entry{Action: journal.DestEnabled, DestID: stack.ID, DestName: stack.Name})
+			entry.Status = enableEnabled
+			result.Enabled++
 		}
 	}
 
+	return result, nil
+}
+
+func (r *stacksEnableResult) renderText(w io.Writer) {
+	if len(r.Stacks) == 0 {
+		fmt.Fprintln(w, "\n✓ No disabled stacks found!")
+		return
+	}
+
+	if r.DryRun {
+		fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+		fmt.Fprintln(w, "DRY RUN - No changes made")
+		fmt.Fprintln(w, "Remove --dry-run flag to enable stacks")
+		return
+	}
+
 	// Print summary
-	fmt.Println("\n─────────────────────────────────────────────────────────────")
-	fmt.Printf("Results: %d enabled, %d failed\n", successCount, failCount)
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Results: %d enabled, %d failed\n", r.Enabled, r.Failed)
+	if r.Failed > 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\n✓ All stacks enabled!")
+	fmt.Fprintln(w, "\nNext steps:")
+	fmt.Fprintln(w, "  1. Verify state matches infrastructure: spacebridge stacks verify")
+	fmt.Fprintln(w, "  2. Review the report for any drift or failed runs")
+}
+
+// migratedToLabelPrefix starts the label that state migrate --freeze-source
+// puts on migrated source stacks: migrated-to:<destination host>/<stack ID>.
+const migratedToLabelPrefix = "migrated-to:"
+
+// accountHost returns the host of a Spacelift account URL.
+func accountHost(accountURL string) string {
+	u, err := url.Parse(accountURL)
+	if err != nil || u.Host == "" {
+		return accountURL
+	}
+	return u.Host
+}
+
+// migratedTo returns a stack's migrated-to label, if it has one.
+func migratedTo(stack models.Stack) (string, bool) {
+	for _, label := range stack.Labels {
+		if strings.HasPrefix(label, migratedToLabelPrefix) {
+			return label, true
+		}
+	}
+	return "", false
+}
+
+// withoutMigratedTo returns labels without any migrated-to label.
+func withoutMigratedTo(labels []string) []string {
+	var kept []string
+	for _, label := range labels {
+		if !strings.HasPrefix(label, migratedToLabelPrefix) {
+			kept = append(kept, label)
+		}
+	}
+	return kept
+}
+
+// freezeSourceStack locks a source stack, and disables it if asked to.
+// It reports whether it disabled the stack.
+func freezeSourceStack(ctx context.Context, c *client.Client, stack models.Stack, disable bool) (bool, error) {
+	fmt.Fprint(progressOut, "    Locking source stack... ")
+	if err := c.LockStack(ctx, stack.ID); err != nil {
+		fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+		return false, err
+	}
+	fmt.Fprintln(progressOut, "✓")
+	recordJournal(journal.Entry{Action: journal.SourceLocked, SourceID: stack.ID, SourceName: stack.Name})
+
+	if !disable || stack.IsDisabled {
+		return false, nil
+	}
+
+	fmt.Fprint(progressOut, "    Disabling source stack... ")
+	if err := c.DisableStack(ctx, stack); err != nil {
+		fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+		// Don't leave a half-frozen stack behind
+		if c.UnlockStack(ctx, stack.ID) == nil {
+			recordJournal(journal.Entry{Action: journal.SourceLocked, SourceID: stack.ID, SourceName: stack.Name, Reverted: true})
+		}
+		return false, err
+	}
+	fmt.Fprintln(progressOut, "✓")
+	recordJournal(journal.Entry{Action: journal.SourceDisabled, SourceID: stack.ID, SourceName: stack.Name})
+	return true, nil
+}
+
+// thawSourceStack undoes freezeSourceStack after a failed migration.
+func thawSourceStack(ctx context.Context, c *client.Client, stack models.Stack, reenable bool) {
+	fmt.Fprint(progressOut, "    Unfreezing source stack... ")
+	var errs []string
+	if reenable {
+		if err := c.EnableStack(ctx, stack); err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			recordJournal(journal.Entry{Action: journal.SourceDisabled, SourceID: stack.ID, SourceName: stack.Name, Reverted: true})
+		}
+	}
+	if err := c.UnlockStack(ctx, stack.ID); err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		recordJournal(journal.Entry{Action: journal.SourceLocked, SourceID: stack.ID, SourceName: stack.Name, Reverted: true})
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(progressOut, "✗ Failed: %s\n", strings.Join(errs, "; "))
+		fmt.Fprintf(progressOut, "      Run: spacebridge stacks unfreeze --stack %s\n", stack.Name)
+		return
+	}
+	fmt.Fprintln(progressOut, "✓")
+}
+
+// markSourceMigrated leaves a migrated source stack disabled and labeled
+// with its destination. Failures are reported but do not fail the
+// migration, since the state was imported.
+func markSourceMigrated(ctx context.Context, c *client.Client, stack models.Stack, disabled bool, label string) {
+	if !disabled {
+		fmt.Fprint(progressOut, "    Disabling source stack... ")
+		if err := c.DisableStack(ctx, stack); err != nil {
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+		} else {
+			fmt.Fprintln(progressOut, "✓")
+			recordJournal(journal.Entry{Action: journal.SourceDisabled, SourceID: stack.ID, SourceName: stack.Name})
+		}
+	}
 
-	if failCount > 0 {
-		return fmt.Errorf("%d stacks failed to enable", failCount)
+	fmt.Fprintf(progressOut, "    Labeling source stack %s... ", label)
+	if err := c.SetStackLabels(ctx, stack, append(withoutMigratedTo(stack.Labels), label)); err != nil {
+		fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+	} else {
+		fmt.Fprintln(progressOut, "✓")
+		recordJournal(journal.Entry{Action: journal.SourceLabeled, SourceID: stack.ID, SourceName: stack.Name, Label: label})
 	}
+}
+
+// stacksUnfreezeOptions holds the stacks unfreeze command flags.
+type stacksUnfreezeOptions struct {
+	dryRun      bool
+	spaceFilter string
+	stacks      []string
+}
 
-	fmt.Println("\n✓ All stacks enabled!")
-	fmt.Println("\nNext steps:")
-	fmt.Println("  1. Trigger runs on enabled stacks to verify state matches infrastructure")
-	fmt.Println("  2. Monitor runs for any drift or issues")
+// newStacksUnfreezeCmd creates the stacks unfreeze command.
+func newStacksUnfreezeCmd() *cobra.Command {
+	var opts stacksUnfreezeOptions
+	cmd := &cobra.Command{
+		Use:   "unfreeze",
+		Short: "Unlock and re-enable source stacks frozen by state migrate",
+		Long: `Rolls back 'spacebridge state migrate --freeze-source' in the SOURCE account.
+
+This command will:
+  1. Find source stacks with a migrated-to:<destination> label, plus any
+     stacks named with --stack (e.g. stacks left frozen by an interrupted run)
+  2. Unlock each stack
+  3. Re-enable it if it is disabled and remove the migrated-to label
+  4. Report success/failure for each stack
+
+Use --dry-run to see what would be unfrozen without making changes.`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStacksUnfreeze(opts)
+		},
+	}
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be unfrozen without making changes")
+	cmd.Flags().StringVarP(&opts.spaceFilter, "space", "s", "", "Only include stacks from this source space")
+	cmd.Flags().StringArrayVar(&opts.stacks, "stack", nil, "Also unfreeze this source stack (name or ID); repeatable")
+	return cmd
+}
+
+// Statuses of a stack in stacks unfreeze.
+const (
+	unfreezePlanned  = "planned"
+	unfreezeUnfrozen = "unfrozen"
+	unfreezeFailed   = "failed"
+)
+
+// stackUnfreeze is a frozen source stack that stacks unfreeze unfreezes.
+type stackUnfreeze struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Label  string `json:"label,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Warning reports a failed unlock of a stack that was otherwise unfrozen
+	Warning string `json:"warning,omitempty"`
+}
+
+// stacksUnfreezeResult is the result of stacks unfreeze.
+type stacksUnfreezeResult struct {
+	DryRun   bool            `json:"dryRun"`
+	Stacks   []stackUnfreeze `json:"stacks"`
+	Unfrozen int             `json:"unfrozen"`
+	Failed   int             `json:"failed"`
+}
+
+// runStacksUnfreeze unlocks and re-enables frozen source stacks.
+func runStacksUnfreeze(opts stacksUnfreezeOptions) error {
+	result, err := unfreezeStacks(opts)
+	if err != nil {
+		return err
+	}
+	if err := render(result); err != nil {
+		return err
+	}
+	return failureError(result.Failed, len(result.Stacks), "stacks failed to unfreeze")
+}
+
+// unfreezeStacks unfreezes the frozen source stacks, printing each as it
+// goes.
+func unfreezeStacks(opts stacksUnfreezeOptions) (*stacksUnfreezeResult, error) {
+	svc, err := createDiscoveryService()
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	ctx := context.Background()
+	fmt.Fprintln(progressOut, "Finding frozen source stacks...")
+
+	stacks, err := svc.DiscoverStacks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover stacks: %w", err)
+	}
+
+	// Filter by space if specified
+	if opts.spaceFilter != "" {
+		spaceID, spaceName, err := resolveSpaceFilter(ctx, svc, opts.spaceFilter)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(progressOut, "Filtering to space: %s (ID: %s)\n", spaceName, spaceID)
+		var filtered []models.Stack
+		for _, stack := range stacks {
+			if stack.Space == spaceID {
+				filtered = append(filtered, stack)
+			}
+		}
+		stacks = filtered
+	}
+
+	named := make(map[string]bool)
+	for _, name := range opts.stacks {
+		named[name] = false
+	}
+
+	var frozen []models.Stack
+	for _, stack := range stacks {
+		_, labeled := migratedTo(stack)
+		_, byName := named[stack.Name]
+		_, byID := named[stack.ID]
+		if byName {
+			named[stack.Name] = true
+		}
+		if byID {
+			named[stack.ID] = true
+		}
+		if labeled || byName || byID {
+			frozen = append(frozen, stack)
+		}
+	}
+	for name, found := range named {
+		if !found {
+			return nil, fmt.Errorf("stack not found: %s", name)
+		}
+	}
+
+	result := &stacksUnfreezeResult{DryRun: opts.dryRun, Stacks: []stackUnfreeze{}}
+	if len(frozen) == 0 {
+		return result, nil
+	}
+
+	fmt.Fprintf(progressOut, "\nFound %d frozen stacks:\n", len(frozen))
+	for _, stack := range frozen {
+		label, _ := migratedTo(stack)
+		if label != "" {
+			fmt.Fprintf(progressOut, "    • %s (%s)\n", stack.Name, label)
+		} else {
+			fmt.Fprintf(progressOut, "    • %s\n", stack.Name)
+		}
+		result.Stacks = append(result.Stacks, stackUnfreeze{ID: stack.ID, Name: stack.Name, Label: label, Status: unfreezePlanned})
+	}
+
+	if opts.dryRun {
+		return result, nil
+	}
+
+	c, err := client.New(cfg.Source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	fmt.Fprintln(progressOut, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintln(progressOut, "Unfreezing stacks...")
+
+	for i, stack := range frozen {
+		entry := &result.Stacks[i]
+		fmt.Fprintf(progressOut, "  • %s ... ", stack.Name)
+
+		// The stack may already be unlocked, so a failed unlock is only a warning
+		unlockErr := c.UnlockStack(ctx, stack.ID)
+		if unlockErr == nil {
+			recordJournal(journal.Entry{Action: journal.SourceLocked, SourceID: stack.ID, SourceName: stack.Name, Reverted: true})
+		}
+
+		var err error
+		if stack.IsDisabled {
+			if err = c.EnableStack(ctx, stack); err == nil {
+				recordJournal(journal.Entry{Action: journal.SourceDisabled, SourceID: stack.ID, SourceName: stack.Name, Reverted: true})
+			}
+		}
+		if entry.Label != "" && err == nil {
+			if err = c.SetStackLabels(ctx, stack, withoutMigratedTo(stack.Labels)); err == nil {
+				recordJournal(journal.Entry{Action: journal.SourceLabeled, SourceID: stack.ID, SourceName: stack.Name, Label: entry.Label, Reverted: true})
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+			entry.Status, entry.Error = unfreezeFailed, err.Error()
+			result.Failed++
+			continue
+		}
+
+		fmt.Fprintln(progressOut, "✓ Unfrozen")
+		if unlockErr != nil {
+			fmt.Fprintf(progressOut, "      ⚠ Not unlocked: %v\n", unlockErr)
+			entry.Warning = "not unlocked: " + unlockErr.Error()
+		}
+		entry.Status = unfreezeUnfrozen
+		result.Unfrozen++
+	}
+
+	return result, nil
+}
+
+func (r *stacksUnfreezeResult) renderText(w io.Writer) {
+	if len(r.Stacks) == 0 {
+		fmt.Fprintln(w, "\n✓ No frozen source stacks found!")
+		return
+	}
+
+	if r.DryRun {
+		fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+		fmt.Fprintln(w, "DRY RUN - No changes made")
+		fmt.Fprintln(w, "Remove --dry-run flag to unfreeze stacks")
+		return
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Results: %d unfrozen, %d failed\n", r.Unfrozen, r.Failed)
+}
+
+// stacksVerifyOptions holds the stacks verify command flags.
+type stacksVerifyOptions struct {
+	dryRun      bool
+	all         bool
+	spaceFilter string
+	waves       waveOptions
+	concurrency int
+	timeout     time.Duration
+	reportPath  string
+}
+
+// newStacksVerifyCmd creates the stacks verify command.
+func newStacksVerifyCmd() *cobra.Command {
+	var opts stacksVerifyOptions
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Run proposed runs on migrated stacks and report drift",
+		Long: `Verifies migrated stacks in the DESTINATION account by triggering a
+proposed run (plan only) on each and reading its plan summary. A stack whose
+state was migrated correctly plans no changes.
+
+This command will:
+  1. Select the destination stacks that state migrate imported state into,
+     as recorded in the journal (--journal), or every enabled stack with --all
+  2. Trigger a proposed run on each, at most --concurrency at a time
+  3. Poll each run, backing off from 5s to 1m, until it finishes or --timeout
+  4. Write the stacks that drifted (+add ~change -destroy) or whose run failed
+     to the report file
+
+Exits with an error if any stack drifted or failed to verify.
+
+Use --dry-run to see which stacks would be verified without triggering runs.`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStacksVerify(opts)
+		},
+	}
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be verified without triggering runs")
+	cmd.Flags().BoolVar(&opts.all, "all", false, "Verify every enabled destination stack, not only migrated ones")
+	cmd.Flags().StringVarP(&opts.spaceFilter, "space", "s", "", "Only include stacks from this destination space (ID or name)")
+	opts.waves.addFlags(cmd)
+	opts.waves.addMappingFlag(cmd)
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", verify.DefaultConcurrency, "Maximum runs in progress at the same time")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", verify.DefaultTimeout, "Maximum time to wait for each run")
+	cmd.Flags().StringVar(&opts.reportPath, "report", "verify-report.json", "Report file (empty to skip)")
+	return cmd
+}
+
+// verifyPlanned is the status of a stack that stacks verify --dry-run would
+// verify.
+const verifyPlanned = "planned"
+
+// stacksVerifyResult is the result of stacks verify.
+type stacksVerifyResult struct {
+	DryRun bool `json:"dryRun"`
+	// Disabled counts the selected stacks skipped because they cannot run
+	Disabled   int             `json:"disabled"`
+	Stacks     []verify.Result `json:"stacks"`
+	NoChanges  int             `json:"noChanges"`
+	Drifted    int             `json:"drifted"`
+	Failed     int             `json:"failed"`
+	ReportPath string          `json:"reportPath,omitempty"`
+}
+
+// runStacksVerify triggers and awaits proposed runs on destination stacks.
+func runStacksVerify(opts stacksVerifyOptions) error {
+	result, err := verifyStacks(opts)
+	if err != nil {
+		return err
+	}
+	if err := render(result); err != nil {
+		return err
+	}
+	return failureError(result.Drifted+result.Failed, len(result.Stacks), "stacks drifted or failed to verify")
+}
+
+// verifyStacks verifies the selected destination stacks, printing each as
+// its run finishes.
+func verifyStacks(opts stacksVerifyOptions) (*stacksVerifyResult, error) {
+	if err := opts.waves.validate(); err != nil {
+		return nil, err
+	}
+	if opts.concurrency < 1 {
+		return nil, fmt.Errorf("--concurrency must be 1 or higher")
+	}
+
+	// Validate destination config
+	if err := cfg.ValidateDestination(); err != nil {
+		return nil, fmt.Errorf("destination configuration error: %w\n\nPlease set DESTINATION_SPACELIFT_URL, DESTINATION_SPACELIFT_KEY_ID, and DESTINATION_SPACELIFT_SECRET_KEY", err)
+	}
+
+	imported, err := pendingJournal(journal.StateImported)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.all && len(imported) == 0 {
+		return nil, fmt.Errorf("no migrated stacks in the journal (%s); use --all to verify every enabled stack", journalPath)
+	}
+
+	ctx := context.Background()
+
+	destClient, err := client.New(cfg.Destination)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
+	}
+
+	fmt.Fprintf(progressOut, "Destination: %s\n", cfg.Destination.URL)
+
+	destSvc := discovery.New(destClient)
+	var resolvedSpaceID string
+	if opts.spaceFilter != "" {
+		spaceID, spaceName, err := resolveSpaceFilter(ctx, destSvc, opts.spaceFilter)
+		if err != nil {
+			return nil, err
+		}
+		resolvedSpaceID = spaceID
+		fmt.Fprintf(progressOut, "Space:       %s (ID: %s)\n", spaceName, spaceID)
+	}
+	fmt.Fprintln(progressOut, "\nDiscovering stacks to verify...")
+
+	stacks, err := destSvc.DiscoverStacks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover stacks: %w", err)
+	}
+
+	// Filter by space if specified
+	if resolvedSpaceID != "" {
+		var filtered []models.Stack
+		for _, stack := range stacks {
+			if stack.Space == resolvedSpaceID {
+				filtered = append(filtered, stack)
+			}
+		}
+		stacks = filtered
+	}
+
+	// Restrict to one dependency wave if specified
+	if stacks, err = opts.waves.filterDestination(stacks); err != nil {
+		return nil, err
+	}
+
+	// Migrated stacks only, unless --all; disabled stacks cannot run
+	result := &stacksVerifyResult{DryRun: opts.dryRun, Stacks: []verify.Result{}}
+	var selected []models.Stack
+	for _, stack := range stacks {
+		if _, ok := imported[stack.ID]; !ok && !opts.all {
+			continue
+		}
+		if stack.IsDisabled {
+			result.Disabled++
+			continue
+		}
+		selected = append(selected, stack)
+	}
+
+	if result.Disabled > 0 {
+		fmt.Fprintf(progressOut, "\n⚠ Skipping %d disabled stacks (run: spacebridge stacks enable)\n", result.Disabled)
+	}
+	if len(selected) == 0 {
+		return result, nil
+	}
+
+	fmt.Fprintf(progressOut, "\nFound %d stacks to verify:\n", len(selected))
+	for _, stack := range selected {
+		fmt.Fprintf(progressOut, "    • %s\n", stack.Name)
+	}
+
+	if opts.dryRun {
+		for _, stack := range selected {
+			result.Stacks = append(result.Stacks, verify.Result{StackID: stack.ID, StackName: stack.Name, Status: verifyPlanned})
+		}
+		return result, nil
+	}
+
+	fmt.Fprintln(progressOut, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(progressOut, "Triggering proposed runs (%d at a time)...\n", opts.concurrency)
+
+	result.Stacks = verify.Run(ctx, destClient, selected, verify.Options{
+		Concurrency: opts.concurrency,
+		Timeout:     opts.timeout,
+		OnResult: func(r verify.Result) {
+			switch r.Status {
+			case verify.StatusNoChanges:
+				fmt.Fprintf(progressOut, "  ✓ %s: no changes\n", r.StackName)
+			case verify.StatusDrifted:
+				fmt.Fprintf(progressOut, "  ⚠ %s: %s\n", r.StackName, r.Summary())
+			default:
+				fmt.Fprintf(progressOut, "  ✗ %s: %s\n", r.StackName, r.Error)
+			}
+		},
+	})
+
+	report := verify.NewReport(cfg.Destination.URL, result.Stacks)
+	result.NoChanges, result.Drifted, result.Failed = report.NoChanges, report.Drifted, report.Failed
+
+	if opts.reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal report: %w", err)
+		}
+		if err := os.WriteFile(opts.reportPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write report: %w", err)
+		}
+		result.ReportPath = opts.reportPath
+	}
+
+	return result, nil
+}
+
+func (r *stacksVerifyResult) renderText(w io.Writer) {
+	if len(r.Stacks) == 0 {
+		fmt.Fprintln(w, "\n✓ No stacks to verify!")
+		return
+	}
+
+	if r.DryRun {
+		fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+		fmt.Fprintln(w, "DRY RUN - No runs triggered")
+		fmt.Fprintln(w, "Remove --dry-run flag to verify stacks")
+		return
+	}
+
+	var rows [][]string
+	for _, s := range r.Stacks {
+		switch s.Status {
+		case verify.StatusNoChanges:
+		case verify.StatusDrifted:
+			rows = append(rows, []string{s.StackName, s.Status, s.RunID, s.Summary()})
+		default:
+			rows = append(rows, []string{s.StackName, s.Status, s.RunID, s.Error})
+		}
+	}
+	if len(rows) > 0 {
+		fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+		fmt.Fprintln(w, "│                    VERIFICATION REPORT                      │")
+		fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+		fmt.Fprint(w, ui.RenderTable([]string{"STACK", "STATUS", "RUN", "DETAIL"}, rows))
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Results: %d no changes, %d drifted, %d failed\n", r.NoChanges, r.Drifted, r.Failed)
+	if r.ReportPath != "" {
+		fmt.Fprintf(w, "Report:  %s\n", r.ReportPath)
+	}
+	if r.Drifted+r.Failed == 0 {
+		fmt.Fprintln(w, "\n✓ All stacks verified - no changes planned!")
+	}
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/fakespacelift"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// accountStack returns a stack of a fake account by ID.
+func accountStack(t *testing.T, s *fakespacelift.Server, id string) models.Stack {
+	t.Helper()
+	for _, stack := range s.Account().Stacks {
+		if stack.ID == id {
+			return stack
+		}
+	}
+	t.Fatalf("stack %s not found", id)
+	return models.Stack{}
+}
+
+func TestFreezeKeepsStackSettings(t *testing.T) {
+	source, _ := startFixture(t)
+	ctx := context.Background()
+	c, err := client.New(cfg.Source)
+	if err != nil {
+		t.Fatalf("client.New: %v", err)
+	}
+
+	before := accountStack(t, source, "network")
+	disabled, err := freezeSourceStack(ctx, c, before, true)
+	if err != nil {
+		t.Fatalf("freezeSourceStack: %v", err)
+	}
+	label := migratedToLabelPrefix + "destination/network"
+	markSourceMigrated(ctx, c, before, disabled, label)
+
+	frozen := accountStack(t, source, "network")
+	if !frozen.IsDisabled {
+		t.Error("frozen stack is not disabled")
+	}
+	if got, want := strings.Join(frozen.Labels, ","), strings.Join(append(before.Labels, label), ","); got != want {
+		t.Errorf("frozen stack labels = %s, want %s", got, want)
+	}
+	checkSettings(t, "frozen", before, frozen)
+
+	if err := runStacksUnfreeze(stacksUnfreezeOptions{}); err != nil {
+		t.Fatalf("runStacksUnfreeze: %v", err)
+	}
+	unfrozen := accountStack(t, source, "network")
+	if unfrozen.IsDisabled {
+		t.Error("unfrozen stack is still disabled")
+	}
+	if got, want := strings.Join(unfrozen.Labels, ","), strings.Join(before.Labels, ","); got != want {
+		t.Errorf("unfrozen stack labels = %s, want %s", got, want)
+	}
+	checkSettings(t, "unfrozen", before, unfrozen)
+}
+
+// checkSettings fails the test if an update changed a stack setting that
+// it was not asked to change.
+func checkSettings(t *testing.T, what string, before, after models.Stack) {
+	t.Helper()
+	projectRoot := func(s models.Stack) string {
+		if s.ProjectRoot == nil {
+			return "<none>"
+		}
+		return *s.ProjectRoot
+	}
+	if projectRoot(after) != projectRoot(before) {
+		t.Errorf("%s stack project root = %s, want %s", what, projectRoot(after), projectRoot(before))
+	}
+	if after.Space != before.Space || after.Branch != before.Branch || after.Repository != before.Repository {
+		t.Errorf("%s stack moved: space %s, branch %s, repository %s", what, after.Space, after.Branch, after.Repository)
+	}
+	if after.ExternalStateAccessEnabled != before.ExternalStateAccessEnabled {
+		t.Errorf("%s stack external state access = %t, want %t", what, after.ExternalStateAccessEnabled, before.ExternalStateAccessEnabled)
+	}
+}
@@ -3,15 +3,21 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/jnesspace/spacebridge/internal/client"
 	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/journal"
 	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/internal/transform"
+	"github.com/jnesspace/spacebridge/pkg/config"
 )
 
-
 // newStateCmd creates the state command group.
 func newStateCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -21,6 +27,7 @@ func newStateCmd() *cobra.Command {
 	cmd.AddCommand(
 		newStatePlanCmd(),
 		newStateEnableAccessCmd(),
+		newStateDisableAccessCmd(),
 		newStateMigrateCmd(),
 	)
 	return cmd
@@ -29,6 +36,7 @@ func newStateCmd() *cobra.Command {
 // newStatePlanCmd creates the state plan command.
 func newStatePlanCmd() *cobra.Command {
 	var spaceFilter string
+	var selectors selectorOptions
 	cmd := &cobra.Command{
 		Use:   "plan",
 		Short: "Preview state migration for all stacks",
@@ -38,11 +46,13 @@ Stacks are categorized as:
   ✓ Ready:    Managed state + external access enabled (can migrate)
   ⚠ Blocked:  Managed state but external access disabled (needs enabling)
   ○ Skipped:  Self-managed state (migrate via external backend)`,
+		Annotations: structuredOutput(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runStatePlan(spaceFilter)
+			return runStatePlan(spaceFilter, selectors)
 		},
 	}
 	cmd.Flags().StringVarP(&spaceFilter, "space", "s", "", "Only include stacks from this space")
+	selectors.addFlags(cmd)
 	return cmd
 }
 
@@ -54,7 +64,12 @@ func resolveSpaceFilter(ctx context.Context, svc *discovery.Service, filter stri
 	if err != nil {
 		return "", "", fmt.Errorf("failed to discover spaces: %w", err)
 	}
+	return matchSpace(spaces, filter)
+}
 
+// matchSpace resolves a space filter against a list of spaces, in the same
+// formats as resolveSpaceFilter.
+func matchSpace(spaces []models.Space, filter string) (spaceID string, displayName string, err error) {
 	// Build a map for quick lookup
 	spaceByID := make(map[string]models.Space)
 	for _, space := range spaces {
@@ -87,28 +102,75 @@ func resolveSpaceFilter(ctx context.Context, svc *discovery.Service, filter stri
 	return "", "", fmt.Errorf("space not found: %s", filter)
 }
 
+// stateStack is a stack listed in the result of a state command.
+type stateStack struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Note string `json:"note,omitempty"`
+}
+
+// String returns the stack name with its note.
+func (s stateStack) String() string {
+	if s.Note == "" {
+		return s.Name
+	}
+	return s.Name + " (" + s.Note + ")"
+}
+
+// printStateStacks writes a bullet list of stacks.
+func printStateStacks(w io.Writer, stacks []stateStack) {
+	for _, stack := range stacks {
+		fmt.Fprintf(w, "    • %s\n", stack)
+	}
+}
+
+// statePlanResult is the result of state plan.
+type statePlanResult struct {
+	Total         int          `json:"total"`
+	Ready         []stateStack `json:"ready"`         // Managed state with external access enabled
+	Blocked       []stateStack `json:"blocked"`       // Managed state with external access disabled
+	Skipped       []stateStack `json:"skipped"`       // Self-managed state
+	NotApplicable []stateStack `json:"notApplicable"` // Non-Tofu stacks
+	StillOpen     []stateStack `json:"stillOpen"`     // Migrated stacks whose state is still readable
+}
+
 // runStatePlan shows the state migration plan.
-func runStatePlan(spaceFilter string) error {
-	svc, err := createDiscoveryService()
+func runStatePlan(spaceFilter string, selectors selectorOptions) error {
+	result, err := planStateMigration(spaceFilter, selectors)
 	if err != nil {
 		return err
 	}
+	return render(result)
+}
+
+// planStateMigration sorts the source stacks by whether their state can be
+// migrated.
+func planStateMigration(spaceFilter string, selectors selectorOptions) (*statePlanResult, error) {
+	stackSel, err := selectors.load()
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := createDiscoveryService()
+	if err != nil {
+		return nil, err
+	}
 
 	ctx := context.Background()
-	fmt.Println("Analyzing stacks for state migration...")
+	fmt.Fprintln(progressOut, "Analyzing stacks for state migration...")
 
 	stacks, err := svc.DiscoverStacks(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to discover stacks: %w", err)
+		return nil, fmt.Errorf("failed to discover stacks: %w", err)
 	}
 
 	// Filter by space if specified
 	if spaceFilter != "" {
 		spaceID, spaceName, err := resolveSpaceFilter(ctx, svc, spaceFilter)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		fmt.Printf("Filtering to space: %s (ID: %s)\n", spaceName, spaceID)
+		fmt.Fprintf(progressOut, "Filtering to space: %s (ID: %s)\n", spaceName, spaceID)
 		var filtered []models.Stack
 		for _, stack := range stacks {
 			if stack.Space == spaceID {
@@ -117,90 +179,121 @@ func runStatePlan(spaceFilter string) error {
 		}
 		stacks = filtered
 	}
+	stacks = stackSel.sourceStacks(stacks)
 
-	var ready, blocked, skipped, nonTofu []string
-	readyStacks := make(map[string]bool)
+	// Stacks SpaceBridge enabled access on, and stacks already migrated
+	enabledBySpaceBridge, err := pendingJournal(journal.ExternalAccessEnabled)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := pendingJournal(journal.StateImported)
+	if err != nil {
+		return nil, err
+	}
+	migratedSources := make(map[string]bool)
+	for _, e := range imported {
+		migratedSources[e.SourceID] = true
+	}
+
+	result := &statePlanResult{
+		Total:         len(stacks),
+		Ready:         []stateStack{},
+		Blocked:       []stateStack{},
+		Skipped:       []stateStack{},
+		NotApplicable: []stateStack{},
+		StillOpen:     []stateStack{},
+	}
 
 	for _, stack := range stacks {
+		entry := stateStack{ID: stack.ID, Name: stack.Name}
 		if !stack.ManagesStateFile {
-			skipped = append(skipped, stack.Name)
+			result.Skipped = append(result.Skipped, entry)
 		} else if !stack.IsTerraform() {
 			// Non-Terraform stacks (Ansible, Kubernetes, etc.) don't have TF state
-			nonTofu = append(nonTofu, fmt.Sprintf("%s (%s)", stack.Name, friendlyVendorType(stack.VendorType)))
+			entry.Note = friendlyVendorType(stack.VendorType)
+			result.NotApplicable = append(result.NotApplicable, entry)
 		} else if stack.ExternalStateAccessEnabled {
-			ready = append(ready, stack.Name)
-			readyStacks[stack.ID] = true
+			result.Ready = append(result.Ready, entry)
+			if _, labeled := migratedTo(stack); labeled || migratedSources[stack.ID] {
+				if _, ok := enabledBySpaceBridge[stack.ID]; ok {
+					entry.Note = "enabled by SpaceBridge"
+				}
+				result.StillOpen = append(result.StillOpen, entry)
+			}
 		} else {
-			blocked = append(blocked, stack.Name)
+			result.Blocked = append(result.Blocked, entry)
 		}
 	}
 
-	fmt.Println("\n┌─────────────────────────────────────────────────────────────┐")
-	fmt.Println("│                    STATE MIGRATION PLAN                     │")
-	fmt.Println("└─────────────────────────────────────────────────────────────┘")
+	return result, nil
+}
+
+func (r *statePlanResult) renderText(w io.Writer) {
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                    STATE MIGRATION PLAN                     │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
 
 	// Ready stacks
-	fmt.Printf("\n✓ READY TO MIGRATE (%d stacks)\n", len(ready))
-	if len(ready) > 0 {
-		fmt.Println("  These stacks have managed state with external access enabled:")
-		for _, name := range ready {
-			fmt.Printf("    • %s\n", name)
-		}
+	fmt.Fprintf(w, "\n✓ READY TO MIGRATE (%d stacks)\n", len(r.Ready))
+	if len(r.Ready) > 0 {
+		fmt.Fprintln(w, "  These stacks have managed state with external access enabled:")
+		printStateStacks(w, r.Ready)
 	} else {
-		fmt.Println("  No stacks ready for migration")
+		fmt.Fprintln(w, "  No stacks ready for migration")
 	}
 
 	// Blocked stacks
-	fmt.Printf("\n⚠ BLOCKED - External State Access Disabled (%d stacks)\n", len(blocked))
-	if len(blocked) > 0 {
-		fmt.Println("  Enable external state access on these stacks first:")
-		for _, name := range blocked {
-			fmt.Printf("    • %s\n", name)
-		}
-		fmt.Println("\n  To enable, go to Stack Settings > Backend > Enable 'External State Access'")
-		fmt.Println("  Or use the Spacelift API/Tofu to enable it")
+	fmt.Fprintf(w, "\n⚠ BLOCKED - External State Access Disabled (%d stacks)\n", len(r.Blocked))
+	if len(r.Blocked) > 0 {
+		fmt.Fprintln(w, "  Enable external state access on these stacks first:")
+		printStateStacks(w, r.Blocked)
+		fmt.Fprintln(w, "\n  To enable, go to Stack Settings > Backend > Enable 'External State Access'")
+		fmt.Fprintln(w, "  Or use the Spacelift API/Tofu to enable it")
 	} else {
-		fmt.Println("  All managed-state stacks have external access enabled")
+		fmt.Fprintln(w, "  All managed-state stacks have external access enabled")
 	}
 
 	// Skipped stacks
-	fmt.Printf("\n○ SKIPPED - Self-Managed State (%d stacks)\n", len(skipped))
-	if len(skipped) > 0 {
-		fmt.Println("  These stacks use external backends (S3, GCS, etc.):")
-		for _, name := range skipped {
-			fmt.Printf("    • %s\n", name)
-		}
-		fmt.Println("\n  Migrate state via your external backend directly")
+	fmt.Fprintf(w, "\n○ SKIPPED - Self-Managed State (%d stacks)\n", len(r.Skipped))
+	if len(r.Skipped) > 0 {
+		fmt.Fprintln(w, "  These stacks use external backends (S3, GCS, etc.):")
+		printStateStacks(w, r.Skipped)
+		fmt.Fprintln(w, "\n  Migrate state via your external backend directly")
 	} else {
-		fmt.Println("  All stacks use Spacelift-managed state")
+		fmt.Fprintln(w, "  All stacks use Spacelift-managed state")
 	}
 
 	// Non-Tofu stacks
-	if len(nonTofu) > 0 {
-		fmt.Printf("\n○ N/A - Non-Tofu Stacks (%d stacks)\n", len(nonTofu))
-		fmt.Println("  These stacks don't use Tofu state:")
-		for _, name := range nonTofu {
-			fmt.Printf("    • %s\n", name)
-		}
+	if len(r.NotApplicable) > 0 {
+		fmt.Fprintf(w, "\n○ N/A - Non-Tofu Stacks (%d stacks)\n", len(r.NotApplicable))
+		fmt.Fprintln(w, "  These stacks don't use Tofu state:")
+		printStateStacks(w, r.NotApplicable)
 	}
 
-	// Summary
-	fmt.Println("\n─────────────────────────────────────────────────────────────")
-	fmt.Printf("Total: %d stacks | Ready: %d | Blocked: %d | Skipped: %d | N/A: %d\n",
-		len(stacks), len(ready), len(blocked), len(skipped), len(nonTofu))
-
-	if len(blocked) > 0 {
-		fmt.Println("\n⚠️  Run: spacebridge state enable-access")
-	} else if len(ready) > 0 {
-		fmt.Println("\n✓ Ready to migrate! Run: spacebridge state migrate")
+	// Migrated stacks whose old state is still readable
+	if len(r.StillOpen) > 0 {
+		fmt.Fprintf(w, "\n⚠ MIGRATED - External State Access Still Enabled (%d stacks)\n", len(r.StillOpen))
+		fmt.Fprintln(w, "  The state of these migrated stacks is still readable in this account:")
+		printStateStacks(w, r.StillOpen)
+		fmt.Fprintln(w, "\n  Run: spacebridge state disable-access  # Reverts access SpaceBridge enabled")
 	}
 
-	return nil
+	// Summary
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Total: %d stacks | Ready: %d | Blocked: %d | Skipped: %d | N/A: %d\n",
+		r.Total, len(r.Ready), len(r.Blocked), len(r.Skipped), len(r.NotApplicable))
+
+	if len(r.Blocked) > 0 {
+		fmt.Fprintln(w, "\n⚠️  Run: spacebridge state enable-access")
+	} else if len(r.Ready) > 0 {
+		fmt.Fprintln(w, "\n✓ Ready to migrate! Run: spacebridge state migrate")
+	}
 }
 
 // newStateEnableAccessCmd creates the state enable-access command.
 func newStateEnableAccessCmd() *cobra.Command {
 	var spaceFilter string
+	var selectors selectorOptions
 	cmd := &cobra.Command{
 		Use:   "enable-access",
 		Short: "Enable external state access on all managed-state stacks",
@@ -210,37 +303,83 @@ This is required before state can be downloaded for migration.
 This command will:
   1. Find all stacks with managed state but external access disabled
   2. Enable external state access via the Spacelift API
-  3. Report success/failure for each stack`,
+  3. Report success/failure for each stack
+
+Each stack is recorded in the journal (--journal), so that
+'spacebridge state disable-access' can turn access off again after cutover.`,
+		Annotations: structuredOutput(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runStateEnableAccess(spaceFilter)
+			return runStateEnableAccess(spaceFilter, selectors)
 		},
 	}
 	cmd.Flags().StringVarP(&spaceFilter, "space", "s", "", "Only include stacks from this space")
+	selectors.addFlags(cmd)
 	return cmd
 }
 
+// Statuses of a stack in state enable-access and disable-access.
+const (
+	accessPlanned  = "planned"
+	accessEnabled  = "enabled"
+	accessDisabled = "disabled"
+	accessFailed   = "failed"
+)
+
+// stackAccess is a stack whose external state access is turned on or off.
+type stackAccess struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// stateEnableAccessResult is the result of state enable-access.
+type stateEnableAccessResult struct {
+	Stacks  []stackAccess `json:"stacks"`
+	Enabled int           `json:"enabled"`
+	Failed  int           `json:"failed"`
+}
+
 // runStateEnableAccess enables external state access on blocked stacks.
-func runStateEnableAccess(spaceFilter string) error {
-	svc, err := createDiscoveryService()
+func runStateEnableAccess(spaceFilter string, selectors selectorOptions) error {
+	result, err := enableStateAccess(spaceFilter, selectors)
 	if err != nil {
 		return err
 	}
+	if err := render(result); err != nil {
+		return err
+	}
+	return failureError(result.Failed, len(result.Stacks), "stacks failed to update")
+}
+
+// enableStateAccess enables external state access on the blocked source
+// stacks, printing each as it goes.
+func enableStateAccess(spaceFilter string, selectors selectorOptions) (*stateEnableAccessResult, error) {
+	stackSel, err := selectors.load()
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := createDiscoveryService()
+	if err != nil {
+		return nil, err
+	}
 
 	ctx := context.Background()
-	fmt.Println("Finding stacks that need external state access enabled...")
+	fmt.Fprintln(progressOut, "Finding stacks that need external state access enabled...")
 
 	stacks, err := svc.DiscoverStacks(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to discover stacks: %w", err)
+		return nil, fmt.Errorf("failed to discover stacks: %w", err)
 	}
 
 	// Filter by space if specified
 	if spaceFilter != "" {
 		spaceID, spaceName, err := resolveSpaceFilter(ctx, svc, spaceFilter)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		fmt.Printf("Filtering to space: %s (ID: %s)\n", spaceName, spaceID)
+		fmt.Fprintf(progressOut, "Filtering to space: %s (ID: %s)\n", spaceName, spaceID)
 		var filtered []models.Stack
 		for _, stack := range stacks {
 			if stack.Space == spaceID {
@@ -249,6 +388,7 @@ func runStateEnableAccess(spaceFilter string) error {
 		}
 		stacks = filtered
 	}
+	stacks = stackSel.sourceStacks(stacks)
 
 	// Find blocked Terraform stacks (need full stack info for update)
 	var blocked []models.Stack
@@ -259,51 +399,225 @@ func runStateEnableAccess(spaceFilter string) error {
 		}
 	}
 
+	result := &stateEnableAccessResult{Stacks: []stackAccess{}}
 	if len(blocked) == 0 {
-		fmt.Println("\n✓ All managed-state stacks already have external access enabled!")
-		return nil
+		return result, nil
 	}
 
-	fmt.Printf("\nEnabling external state access on %d stacks...\n\n", len(blocked))
+	fmt.Fprintf(progressOut, "\nEnabling external state access on %d stacks...\n\n", len(blocked))
 
 	// Get the client directly for mutations
 	c, err := client.New(cfg.Source)
 	if err != nil {
-		return fmt.Errorf("failed to create client: %w", err)
+		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
 
-	successCount := 0
-	failCount := 0
-
 	for _, stack := range blocked {
-		fmt.Printf("  • %s ... ", stack.Name)
+		entry := stackAccess{ID: stack.ID, Name: stack.Name, Status: accessEnabled}
+		fmt.Fprintf(progressOut, "  • %s ... ", stack.Name)
 		if err := c.EnableExternalStateAccess(ctx, stack); err != nil {
-			fmt.Printf("✗ Failed: %v\n", err)
-			failCount++
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+			entry.Status, entry.Error = accessFailed, err.Error()
+			result.Failed++
 		} else {
-			fmt.Printf("✓ Enabled\n")
-			successCount++
+			fmt.Fprintf(progressOut, "✓ Enabled\n")
+			recordJournal(journal.Entry{Action: journal.ExternalAccessEnabled, SourceID: stack.ID, SourceName: stack.Name})
+			result.Enabled++
 		}
+		result.Stacks = append(result.Stacks, entry)
 	}
 
-	fmt.Println("\n─────────────────────────────────────────────────────────────")
-	fmt.Printf("Results: %d enabled, %d failed\n", successCount, failCount)
+	return result, nil
+}
 
-	if failCount > 0 {
-		return fmt.Errorf("%d stacks failed to update", failCount)
+func (r *stateEnableAccessResult) renderText(w io.Writer) {
+	if len(r.Stacks) == 0 {
+		fmt.Fprintln(w, "\n✓ All managed-state stacks already have external access enabled!")
+		return
 	}
 
-	fmt.Println("\n✓ All stacks ready for state migration!")
-	fmt.Println("  Run: spacebridge state plan   # Verify all stacks are ready")
-	fmt.Println("  Run: spacebridge state migrate # Migrate state")
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Results: %d enabled, %d failed\n", r.Enabled, r.Failed)
+	if r.Failed > 0 {
+		return
+	}
 
-	return nil
+	fmt.Fprintln(w, "\n✓ All stacks ready for state migration!")
+	fmt.Fprintln(w, "  Run: spacebridge state plan   # Verify all stacks are ready")
+	fmt.Fprintln(w, "  Run: spacebridge state migrate # Migrate state")
 }
 
-// newStateMigrateCmd creates the state migrate command.
-func newStateMigrateCmd() *cobra.Command {
+// newStateDisableAccessCmd creates the state disable-access command.
+func newStateDisableAccessCmd() *cobra.Command {
 	var dryRun bool
 	var spaceFilter string
+	var selectors selectorOptions
+	cmd := &cobra.Command{
+		Use:   "disable-access",
+		Short: "Disable external state access that SpaceBridge enabled",
+		Long: `Turns external state access off again on the source stacks where
+'spacebridge state enable-access' turned it on, as recorded in the journal
+(--journal). Stacks that already had external state access before the
+migration are left alone.
+
+Run this after cutover, so the old account's state is no longer readable.
+
+Use --dry-run to see what would be disabled without making changes.`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStateDisableAccess(dryRun, spaceFilter, selectors)
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be disabled without making changes")
+	cmd.Flags().StringVarP(&spaceFilter, "space", "s", "", "Only include stacks from this space")
+	selectors.addFlags(cmd)
+	return cmd
+}
+
+// stateDisableAccessResult is the result of state disable-access.
+type stateDisableAccessResult struct {
+	DryRun   bool          `json:"dryRun"`
+	Stacks   []stackAccess `json:"stacks"`
+	Disabled int           `json:"disabled"`
+	Failed   int           `json:"failed"`
+}
+
+// runStateDisableAccess reverts external state access enabled by SpaceBridge.
+func runStateDisableAccess(dryRun bool, spaceFilter string, selectors selectorOptions) error {
+	result, err := disableStateAccess(dryRun, spaceFilter, selectors)
+	if err != nil {
+		return err
+	}
+	if err := render(result); err != nil {
+		return err
+	}
+	return failureError(result.Failed, len(result.Stacks), "stacks failed to update")
+}
+
+// disableStateAccess disables the external state access that the journal
+// records SpaceBridge enabled, printing each stack as it goes.
+func disableStateAccess(dryRun bool, spaceFilter string, selectors selectorOptions) (*stateDisableAccessResult, error) {
+	stackSel, err := selectors.load()
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := pendingJournal(journal.ExternalAccessEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := createDiscoveryService()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	fmt.Fprintf(progressOut, "Finding stacks that SpaceBridge enabled external state access on (journal: %s)...\n", journalPath)
+
+	stacks, err := svc.DiscoverStacks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover stacks: %w", err)
+	}
+
+	// Filter by space if specified
+	if spaceFilter != "" {
+		spaceID, spaceName, err := resolveSpaceFilter(ctx, svc, spaceFilter)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(progressOut, "Filtering to space: %s (ID: %s)\n", spaceName, spaceID)
+		var filtered []models.Stack
+		for _, stack := range stacks {
+			if stack.Space == spaceID {
+				filtered = append(filtered, stack)
+			}
+		}
+		stacks = filtered
+	}
+	stacks = stackSel.sourceStacks(stacks)
+
+	// Only stacks that SpaceBridge changed and that still have access
+	var open []models.Stack
+	for _, stack := range stacks {
+		if _, ok := enabled[stack.ID]; ok && stack.ExternalStateAccessEnabled {
+			open = append(open, stack)
+		}
+	}
+
+	result := &stateDisableAccessResult{DryRun: dryRun, Stacks: []stackAccess{}}
+	if len(open) == 0 {
+		return result, nil
+	}
+
+	fmt.Fprintf(progressOut, "\nFound %d stacks:\n", len(open))
+	for _, stack := range open {
+		fmt.Fprintf(progressOut, "    • %s\n", stack.Name)
+		result.Stacks = append(result.Stacks, stackAccess{ID: stack.ID, Name: stack.Name, Status: accessPlanned})
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	c, err := client.New(cfg.Source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	fmt.Fprintf(progressOut, "\nDisabling external state access on %d stacks...\n\n", len(open))
+
+	for i, stack := range open {
+		entry := &result.Stacks[i]
+		fmt.Fprintf(progressOut, "  • %s ... ", stack.Name)
+		if err := c.DisableExternalStateAccess(ctx, stack); err != nil {
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+			entry.Status, entry.Error = accessFailed, err.Error()
+			result.Failed++
+		} else {
+			fmt.Fprintf(progressOut, "✓ Disabled\n")
+			reverted := enabled[stack.ID]
+			reverted.Reverted = true
+			recordJournal(reverted)
+			entry.Status = accessDisabled
+			result.Disabled++
+		}
+	}
+
+	return result, nil
+}
+
+func (r *stateDisableAccessResult) renderText(w io.Writer) {
+	if len(r.Stacks) == 0 {
+		fmt.Fprintln(w, "\n✓ No stacks with external state access enabled by SpaceBridge!")
+		return
+	}
+
+	if r.DryRun {
+		fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+		fmt.Fprintln(w, "DRY RUN - No changes made")
+		fmt.Fprintln(w, "Remove --dry-run flag to disable external state access")
+		return
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Results: %d disabled, %d failed\n", r.Disabled, r.Failed)
+}
+
+// stateMigrateOptions holds the state migrate command flags.
+type stateMigrateOptions struct {
+	dryRun        bool
+	spaceFilter   string
+	configPath    string
+	waves         waveOptions
+	freezeSource  bool
+	disableSource bool
+	backupDir     string
+	selectors     selectorOptions
+}
+
+// newStateMigrateCmd creates the state migrate command.
+func newStateMigrateCmd() *cobra.Command {
+	var opts stateMigrateOptions
 	cmd := &cobra.Command{
 		Use:   "migrate",
 		Short: "Migrate Tofu state from source to destination",
@@ -320,24 +634,120 @@ Prerequisites:
   - Source stacks must have external state access enabled (run: spacebridge state enable-access)
   - Both SOURCE_* and DESTINATION_* environment variables must be configured
 
-Use --dry-run to see what would be migrated without making changes.`,
+Use --dry-run to see what would be migrated without making changes.
+Pass the migration config with -c when transforms rename stacks, so that
+source stacks are matched to their renamed destination stacks.
+
+Use --wave to migrate one wave of the dependency schedule at a time, so
+upstream stacks move first (see: spacebridge plan waves).
+
+Use --freeze-source to stop source stacks from running while their state is
+copied. Each source stack is locked (and with --disable-source, disabled)
+before its state is downloaded. Once the destination import succeeds, the
+source stack is left locked and disabled with a migrated-to:<destination>
+label. If the migration of a stack fails, its source stack is unlocked and
+re-enabled. Roll back with: spacebridge stacks unfreeze
+
+Use --backup-dir to save each destination stack's current state before it is
+replaced, so 'spacebridge rollback --restore-state' can put it back.
+
+Use --selection to migrate only the stacks of a file written by
+spacebridge select, or pick stacks with --label, --name-regex,
+--stack-ids-file and --vendor and leave some out with --exclude-*.
+
+Every change is recorded in the journal (--journal) for spacebridge rollback.`,
+		Annotations: structuredOutput(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runStateMigrate(dryRun, spaceFilter)
+			return runStateMigrate(opts)
 		},
 	}
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be migrated without making changes")
-	cmd.Flags().StringVarP(&spaceFilter, "space", "s", "", "Only include stacks from this space")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be migrated without making changes")
+	cmd.Flags().StringVarP(&opts.spaceFilter, "space", "s", "", "Only include stacks from this space")
+	cmd.Flags().StringVarP(&opts.configPath, "config", "c", "", "Migration config YAML file (applies rename transforms when matching stacks)")
+	cmd.Flags().BoolVar(&opts.freezeSource, "freeze-source", false, "Lock source stacks before download and disable them after a successful import")
+	cmd.Flags().BoolVar(&opts.disableSource, "disable-source", false, "With --freeze-source, also disable source stacks before download")
+	cmd.Flags().StringVar(&opts.backupDir, "backup-dir", "", "Save destination state to this directory before importing")
+	opts.selectors.addFlags(cmd)
+	opts.waves.addFlags(cmd)
 	return cmd
 }
 
+// Statuses of a stack in a state migration.
+const (
+	migrationPlanned  = "planned"
+	migrationMigrated = "migrated"
+	migrationFailed   = "failed"
+)
+
+// stackMigration is a stack whose state is migrated.
+type stackMigration struct {
+	SourceID        string `json:"sourceId"`
+	SourceName      string `json:"sourceName"`
+	DestinationID   string `json:"destinationId"`
+	DestinationName string `json:"destinationName"`
+	Status          string `json:"status"`
+	Error           string `json:"error,omitempty"`
+	Bytes           int64  `json:"bytes,omitempty"`  // Size of the migrated state
+	Backup          string `json:"backup,omitempty"` // Backup of the replaced destination state
+
+	source models.Stack
+	dest   models.Stack
+}
+
+// stateMigrateResult is the result of state migrate.
+type stateMigrateResult struct {
+	DryRun           bool             `json:"dryRun"`
+	Stacks           []stackMigration `json:"stacks"`
+	Skipped          []stateStack     `json:"skipped"`
+	NotInDestination []stateStack     `json:"notInDestination"`
+	NoExternalAccess []stateStack     `json:"noExternalAccess"`
+	Succeeded        int              `json:"succeeded"`
+	Failed           int              `json:"failed"`
+
+	freezeSource  bool
+	disableSource bool
+}
+
 // runStateMigrate performs the state migration.
-func runStateMigrate(dryRun bool, spaceFilter string) error {
+func runStateMigrate(opts stateMigrateOptions) error {
+	result, err := migrateState(opts)
+	if err != nil {
+		return err
+	}
+	if err := render(result); err != nil {
+		return err
+	}
+	return failureError(result.Failed, len(result.Stacks), "stacks failed to migrate")
+}
+
+// migrateState migrates the state of every eligible source stack, printing
+// the plan and each step as it goes.
+func migrateState(opts stateMigrateOptions) (*stateMigrateResult, error) {
+	if err := opts.waves.validate(); err != nil {
+		return nil, err
+	}
+	if opts.disableSource && !opts.freezeSource {
+		return nil, fmt.Errorf("--disable-source requires --freeze-source")
+	}
+
 	// Validate both source and destination configs
 	if err := cfg.ValidateSource(); err != nil {
-		return fmt.Errorf("source configuration error: %w", err)
+		return nil, fmt.Errorf("source configuration error: %w", err)
 	}
 	if err := cfg.ValidateDestination(); err != nil {
-		return fmt.Errorf("destination configuration error: %w\n\nPlease set DESTINATION_SPACELIFT_URL, DESTINATION_SPACELIFT_KEY_ID, and DESTINATION_SPACELIFT_SECRET_KEY", err)
+		return nil, fmt.Errorf("destination configuration error: %w\n\nPlease set DESTINATION_SPACELIFT_URL, DESTINATION_SPACELIFT_KEY_ID, and DESTINATION_SPACELIFT_SECRET_KEY", err)
+	}
+
+	var migCfg *config.MigrationConfig
+	if opts.configPath != "" {
+		var err error
+		if migCfg, err = loadMigrationConfig(opts.configPath); err != nil {
+			return nil, err
+		}
+	}
+	stackSel, err := opts.selectors.load()
+	if err != nil {
+		return nil, err
 	}
 
 	ctx := context.Background()
@@ -345,36 +755,36 @@ func runStateMigrate(dryRun bool, spaceFilter string) error {
 	// Create clients
 	sourceClient, err := client.New(cfg.Source)
 	if err != nil {
-		return fmt.Errorf("failed to create source client: %w", err)
+		return nil, fmt.Errorf("failed to create source client: %w", err)
 	}
 
 	destClient, err := client.New(cfg.Destination)
 	if err != nil {
-		return fmt.Errorf("failed to create destination client: %w", err)
+		return nil, fmt.Errorf("failed to create destination client: %w", err)
 	}
 
-	fmt.Printf("Source:      %s\n", cfg.Source.URL)
-	fmt.Printf("Destination: %s\n", cfg.Destination.URL)
+	fmt.Fprintf(progressOut, "Source:      %s\n", cfg.Source.URL)
+	fmt.Fprintf(progressOut, "Destination: %s\n", cfg.Destination.URL)
 
 	// Discover stacks from both accounts
-	fmt.Println("\nDiscovering stacks...")
+	fmt.Fprintln(progressOut, "\nDiscovering stacks...")
 	sourceSvc := discovery.New(sourceClient)
 	destSvc := discovery.New(destClient)
 
 	// Resolve space filter if specified (using source account spaces)
 	var resolvedSpaceID string
-	if spaceFilter != "" {
-		spaceID, spaceName, err := resolveSpaceFilter(ctx, sourceSvc, spaceFilter)
+	if opts.spaceFilter != "" {
+		spaceID, spaceName, err := resolveSpaceFilter(ctx, sourceSvc, opts.spaceFilter)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		resolvedSpaceID = spaceID
-		fmt.Printf("Space:       %s (ID: %s)\n", spaceName, spaceID)
+		fmt.Fprintf(progressOut, "Space:       %s (ID: %s)\n", spaceName, spaceID)
 	}
 
 	sourceStacks, err := sourceSvc.DiscoverStacks(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to discover source stacks: %w", err)
+		return nil, fmt.Errorf("failed to discover source stacks: %w", err)
 	}
 
 	// Filter source stacks by space if specified
@@ -388,9 +798,29 @@ func runStateMigrate(dryRun bool, spaceFilter string) error {
 		sourceStacks = filtered
 	}
 
+	// Restrict to the selected stacks if specified
+	sourceStacks = stackSel.sourceStacks(sourceStacks)
+
+	// Restrict to one dependency wave if specified
+	if sourceStacks, err = opts.waves.filterSource(sourceStacks); err != nil {
+		return nil, err
+	}
+
 	destStacks, err := destSvc.DiscoverStacks(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to discover destination stacks: %w", err)
+		return nil, fmt.Errorf("failed to discover destination stacks: %w", err)
+	}
+
+	// Destination stack names follow any rename transforms
+	destNames := make(map[string]string)
+	if migCfg != nil && len(migCfg.Transforms) > 0 {
+		sourceSpaces, err := sourceSvc.DiscoverSpaces(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover source spaces: %w", err)
+		}
+		if destNames, err = transform.StackNames(sourceSpaces, sourceStacks, migCfg.Transforms); err != nil {
+			return nil, fmt.Errorf("failed to apply transforms: %w", err)
+		}
 	}
 
 	// Build map of destination stacks by name
@@ -400,191 +830,297 @@ func runStateMigrate(dryRun bool, spaceFilter string) error {
 	}
 
 	// Find stacks eligible for migration
-	type migrationCandidate struct {
-		Source models.Stack
-		Dest   models.Stack
+	result := &stateMigrateResult{
+		DryRun:           opts.dryRun,
+		Stacks:           []stackMigration{},
+		Skipped:          []stateStack{},
+		NotInDestination: []stateStack{},
+		NoExternalAccess: []stateStack{},
+		freezeSource:     opts.freezeSource,
+		disableSource:    opts.disableSource,
 	}
-	var candidates []migrationCandidate
-	var skipped []string
-	var notInDest []string
-	var noAccess []string
 
 	for _, stack := range sourceStacks {
+		entry := stateStack{ID: stack.ID, Name: stack.Name}
+
 		// Only Tofu stacks with managed state
 		if !stack.ManagesStateFile {
-			skipped = append(skipped, stack.Name+" (self-managed state)")
+			entry.Note = "self-managed state"
+			result.Skipped = append(result.Skipped, entry)
 			continue
 		}
 		if !stack.IsTerraform() {
-			skipped = append(skipped, stack.Name+" ("+friendlyVendorType(stack.VendorType)+")")
+			entry.Note = friendlyVendorType(stack.VendorType)
+			result.Skipped = append(result.Skipped, entry)
 			continue
 		}
 		if !stack.ExternalStateAccessEnabled {
-			noAccess = append(noAccess, stack.Name)
+			result.NoExternalAccess = append(result.NoExternalAccess, entry)
 			continue
 		}
 
 		// Find matching destination stack
-		destStack, exists := destStackMap[stack.Name]
+		destName := stack.Name
+		if name, ok := destNames[stack.ID]; ok {
+			destName = name
+		}
+		destStack, exists := destStackMap[destName]
 		if !exists {
-			notInDest = append(notInDest, stack.Name)
+			result.NotInDestination = append(result.NotInDestination, entry)
 			continue
 		}
 
-		candidates = append(candidates, migrationCandidate{
-			Source: stack,
-			Dest:   destStack,
+		result.Stacks = append(result.Stacks, stackMigration{
+			SourceID:        stack.ID,
+			SourceName:      stack.Name,
+			DestinationID:   destStack.ID,
+			DestinationName: destStack.Name,
+			Status:          migrationPlanned,
+			source:          stack,
+			dest:            destStack,
 		})
 	}
 
-	// Print migration plan
-	fmt.Println("\n┌─────────────────────────────────────────────────────────────┐")
-	fmt.Println("│                    STATE MIGRATION                          │")
-	fmt.Println("└─────────────────────────────────────────────────────────────┘")
-
-	if len(candidates) == 0 {
-		fmt.Println("\n⚠ No stacks eligible for migration.")
-		if len(noAccess) > 0 {
-			fmt.Printf("\n  %d stacks need external state access enabled:\n", len(noAccess))
-			for _, name := range noAccess {
-				fmt.Printf("    • %s\n", name)
-			}
-			fmt.Println("\n  Run: spacebridge state enable-access")
-		}
-		if len(notInDest) > 0 {
-			fmt.Printf("\n  %d stacks not found in destination:\n", len(notInDest))
-			for _, name := range notInDest {
-				fmt.Printf("    • %s\n", name)
-			}
-			fmt.Println("\n  Apply Tofu to create destination stacks first")
-		}
-		return nil
-	}
-
-	fmt.Printf("\n✓ WILL MIGRATE (%d stacks)\n", len(candidates))
-	for _, c := range candidates {
-		fmt.Printf("    • %s\n", c.Source.Name)
+	result.printPlan()
+	if len(result.Stacks) == 0 || opts.dryRun {
+		return result, nil
 	}
 
-	if len(skipped) > 0 {
-		fmt.Printf("\n○ SKIPPED (%d stacks)\n", len(skipped))
-		for _, name := range skipped {
-			fmt.Printf("    • %s\n", name)
-		}
-	}
-
-	if len(notInDest) > 0 {
-		fmt.Printf("\n⚠ NOT IN DESTINATION (%d stacks)\n", len(notInDest))
-		for _, name := range notInDest {
-			fmt.Printf("    • %s\n", name)
+	// Perform migration
+	fmt.Fprintln(progressOut, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintln(progressOut, "Starting state migration...")
+
+	destHost := accountHost(cfg.Destination.URL)
+
+	for i := range result.Stacks {
+		m := &result.Stacks[i]
+		fmt.Fprintf(progressOut, "\n  Migrating: %s\n", m.SourceName)
+
+		// Freeze the source stack so its state cannot change after download
+		var sourceDisabled bool
+		if opts.freezeSource {
+			if sourceDisabled, err = freezeSourceStack(ctx, sourceClient, m.source, opts.disableSource); err != nil {
+				m.Status, m.Error = migrationFailed, fmt.Sprintf("failed to freeze source stack: %v", err)
+				result.Failed++
+				continue
+			}
 		}
-	}
 
-	if len(noAccess) > 0 {
-		fmt.Printf("\n⚠ NO EXTERNAL ACCESS (%d stacks)\n", len(noAccess))
-		for _, name := range noAccess {
-			fmt.Printf("    • %s\n", name)
+		// Undo the freeze when the state could not be migrated
+		failed := func(err error) {
+			m.Status, m.Error = migrationFailed, err.Error()
+			result.Failed++
+			if opts.freezeSource {
+				thawSourceStack(ctx, sourceClient, m.source, sourceDisabled)
+			}
 		}
-	}
-
-	if dryRun {
-		fmt.Println("\n─────────────────────────────────────────────────────────────")
-		fmt.Println("DRY RUN - No changes made")
-		fmt.Println("Remove --dry-run flag to perform migration")
-		return nil
-	}
-
-	// Perform migration
-	fmt.Println("\n─────────────────────────────────────────────────────────────")
-	fmt.Println("Starting state migration...")
-
-	successCount := 0
-	failCount := 0
-
-	for _, c := range candidates {
-		fmt.Printf("\n  Migrating: %s\n", c.Source.Name)
 
 		// Get download URL from source
-		fmt.Print("    Getting download URL... ")
-		downloadURL, err := sourceClient.GetStateDownloadURL(ctx, c.Source.ID)
+		fmt.Fprint(progressOut, "    Getting download URL... ")
+		downloadURL, err := sourceClient.GetStateDownloadURL(ctx, m.SourceID)
 		if err != nil {
-			fmt.Printf("✗ Failed: %v\n", err)
-			failCount++
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+			failed(err)
 			continue
 		}
-		fmt.Println("✓")
+		fmt.Fprintln(progressOut, "✓")
 
 		// Get upload URL from destination
-		fmt.Print("    Getting upload URL... ")
-		uploadResult, err := destClient.GetStateUploadURL(ctx, c.Dest.ID)
+		fmt.Fprint(progressOut, "    Getting upload URL... ")
+		uploadResult, err := destClient.GetStateUploadURL(ctx, m.DestinationID)
 		if err != nil {
-			fmt.Printf("✗ Failed: %v\n", err)
-			failCount++
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+			failed(err)
 			continue
 		}
-		fmt.Println("✓")
+		fmt.Fprintln(progressOut, "✓")
 
 		// Stream state from source to destination
-		fmt.Print("    Streaming state... ")
+		fmt.Fprint(progressOut, "    Streaming state... ")
 		stateReader, contentLength, err := client.StreamStateFromURL(ctx, downloadURL)
 		if err != nil {
-			fmt.Printf("✗ Failed to download: %v\n", err)
-			failCount++
+			fmt.Fprintf(progressOut, "✗ Failed to download: %v\n", err)
+			failed(err)
 			continue
 		}
 
 		err = client.UploadStateToURL(ctx, uploadResult.URL, stateReader, contentLength)
 		stateReader.Close()
 		if err != nil {
-			fmt.Printf("✗ Failed to upload: %v\n", err)
-			failCount++
+			fmt.Fprintf(progressOut, "✗ Failed to upload: %v\n", err)
+			failed(err)
 			continue
 		}
-		fmt.Printf("✓ (%d bytes)\n", contentLength)
+		fmt.Fprintf(progressOut, "✓ (%d bytes)\n", contentLength)
+		m.Bytes = contentLength
+
+		// Back up the destination's current state so rollback can restore it
+		if opts.backupDir != "" {
+			fmt.Fprint(progressOut, "    Backing up destination state... ")
+			if backupPath, err := backupDestinationState(ctx, destClient, m.dest, opts.backupDir); err != nil {
+				fmt.Fprintf(progressOut, "○ None (%v)\n", err)
+			} else {
+				fmt.Fprintf(progressOut, "✓ %s\n", backupPath)
+				m.Backup = backupPath
+			}
+		}
 
 		// Lock stack, import state, then unlock
-		fmt.Print("    Locking stack... ")
-		if err := destClient.LockStack(ctx, c.Dest.ID); err != nil {
-			fmt.Printf("✗ Failed: %v\n", err)
-			failCount++
+		fmt.Fprint(progressOut, "    Locking stack... ")
+		if err := destClient.LockStack(ctx, m.DestinationID); err != nil {
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
+			failed(err)
 			continue
 		}
-		fmt.Println("✓")
+		fmt.Fprintln(progressOut, "✓")
 
-		fmt.Print("    Importing state... ")
-		if err := destClient.ImportManagedState(ctx, c.Dest.ID, uploadResult.ObjectID); err != nil {
-			fmt.Printf("✗ Failed: %v\n", err)
+		fmt.Fprint(progressOut, "    Importing state... ")
+		if err := destClient.ImportManagedState(ctx, m.DestinationID, uploadResult.ObjectID); err != nil {
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
 			// Try to unlock even if import failed
-			destClient.UnlockStack(ctx, c.Dest.ID)
-			failCount++
+			destClient.UnlockStack(ctx, m.DestinationID)
+			failed(err)
 			continue
 		}
-		fmt.Println("✓")
+		fmt.Fprintln(progressOut, "✓")
+		recordJournal(journal.Entry{
+			Action:     journal.StateImported,
+			SourceID:   m.SourceID,
+			SourceName: m.SourceName,
+			DestID:     m.DestinationID,
+			DestName:   m.DestinationName,
+			Backup:     m.Backup,
+		})
 
-		fmt.Print("    Unlocking stack... ")
-		if err := destClient.UnlockStack(ctx, c.Dest.ID); err != nil {
-			fmt.Printf("✗ Failed: %v\n", err)
+		fmt.Fprint(progressOut, "    Unlocking stack... ")
+		if err := destClient.UnlockStack(ctx, m.DestinationID); err != nil {
+			fmt.Fprintf(progressOut, "✗ Failed: %v\n", err)
 			// Don't count as failure since state was imported
 		} else {
-			fmt.Println("✓")
+			fmt.Fprintln(progressOut, "✓")
+		}
+
+		// Leave the source stack disabled and labeled with where it went
+		if opts.freezeSource {
+			markSourceMigrated(ctx, sourceClient, m.source, sourceDisabled || m.source.IsDisabled, migratedToLabelPrefix+destHost+"/"+m.DestinationID)
+		}
+
+		m.Status = migrationMigrated
+		result.Succeeded++
+	}
+
+	return result, nil
+}
+
+// printPlan prints the stacks a state migration covers, before it starts.
+func (r *stateMigrateResult) printPlan() {
+	fmt.Fprintln(progressOut, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(progressOut, "│                    STATE MIGRATION                          │")
+	fmt.Fprintln(progressOut, "└─────────────────────────────────────────────────────────────┘")
+
+	if len(r.Stacks) == 0 {
+		fmt.Fprintln(progressOut, "\n⚠ No stacks eligible for migration.")
+		if len(r.NoExternalAccess) > 0 {
+			fmt.Fprintf(progressOut, "\n  %d stacks need external state access enabled:\n", len(r.NoExternalAccess))
+			printStateStacks(progressOut, r.NoExternalAccess)
+			fmt.Fprintln(progressOut, "\n  Run: spacebridge state enable-access")
+		}
+		if len(r.NotInDestination) > 0 {
+			fmt.Fprintf(progressOut, "\n  %d stacks not found in destination:\n", len(r.NotInDestination))
+			printStateStacks(progressOut, r.NotInDestination)
+			fmt.Fprintln(progressOut, "\n  Apply Tofu to create destination stacks first")
+		}
+		return
+	}
+
+	fmt.Fprintf(progressOut, "\n✓ WILL MIGRATE (%d stacks)\n", len(r.Stacks))
+	for _, m := range r.Stacks {
+		fmt.Fprintf(progressOut, "    • %s\n", m.SourceName)
+	}
+
+	if len(r.Skipped) > 0 {
+		fmt.Fprintf(progressOut, "\n○ SKIPPED (%d stacks)\n", len(r.Skipped))
+		printStateStacks(progressOut, r.Skipped)
+	}
+
+	if len(r.NotInDestination) > 0 {
+		fmt.Fprintf(progressOut, "\n⚠ NOT IN DESTINATION (%d stacks)\n", len(r.NotInDestination))
+		printStateStacks(progressOut, r.NotInDestination)
+	}
+
+	if len(r.NoExternalAccess) > 0 {
+		fmt.Fprintf(progressOut, "\n⚠ NO EXTERNAL ACCESS (%d stacks)\n", len(r.NoExternalAccess))
+		printStateStacks(progressOut, r.NoExternalAccess)
+	}
+
+	if r.freezeSource {
+		if r.disableSource {
+			fmt.Fprintln(progressOut, "\n🔒 Source stacks will be locked and disabled before their state is downloaded")
+		} else {
+			fmt.Fprintln(progressOut, "\n🔒 Source stacks will be locked before their state is downloaded")
 		}
+		fmt.Fprintf(progressOut, "   and left disabled with a %s<destination> label once imported\n", migratedToLabelPrefix)
+	}
+}
 
-		successCount++
+// renderText prints how the migration ended. The plan and each step have
+// already been printed.
+func (r *stateMigrateResult) renderText(w io.Writer) {
+	if len(r.Stacks) == 0 {
+		return
 	}
 
-	// Print summary
-	fmt.Println("\n─────────────────────────────────────────────────────────────")
-	fmt.Printf("Migration complete: %d succeeded, %d failed\n", successCount, failCount)
+	if r.DryRun {
+		fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+		fmt.Fprintln(w, "DRY RUN - No changes made")
+		fmt.Fprintln(w, "Remove --dry-run flag to perform migration")
+		return
+	}
 
-	if failCount > 0 {
-		return fmt.Errorf("%d stacks failed to migrate", failCount)
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "Migration complete: %d succeeded, %d failed\n", r.Succeeded, r.Failed)
+	if r.Failed > 0 {
+		return
 	}
 
-	fmt.Println("\n✓ All states migrated successfully!")
-	fmt.Println("\nNext steps:")
-	fmt.Println("  1. Verify state in destination stacks (Spacelift UI > Stack > State)")
-	fmt.Println("  2. Enable stacks: spacebridge stacks enable")
-	fmt.Println("  3. Trigger runs to verify infrastructure matches")
+	fmt.Fprintln(w, "\n✓ All states migrated successfully!")
+	fmt.Fprintln(w, "\nNext steps:")
+	fmt.Fprintln(w, "  1. Verify state in destination stacks (Spacelift UI > Stack > State)")
+	fmt.Fprintln(w, "  2. Enable stacks: spacebridge stacks enable")
+	fmt.Fprintln(w, "  3. Trigger runs to verify infrastructure matches")
+	if r.freezeSource {
+		fmt.Fprintln(w, "\nSource stacks are left locked and disabled. To roll back: spacebridge stacks unfreeze")
+	}
+}
 
-	return nil
+// backupDestinationState saves a destination stack's current state to dir
+// before it is replaced. Stacks without state have nothing to download.
+func backupDestinationState(ctx context.Context, c *client.Client, stack models.Stack, dir string) (string, error) {
+	downloadURL, err := c.GetStateDownloadURL(ctx, stack.ID)
+	if err != nil {
+		return "", err
+	}
+	stateReader, _, err := client.StreamStateFromURL(ctx, downloadURL)
+	if err != nil {
+		return "", err
+	}
+	defer stateReader.Close()
+
+	// State can contain secrets, so backups are only readable by the owner
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.tfstate", stack.ID, time.Now().UTC().Format("20060102T150405Z")))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup file: %w", err)
+	}
+	if _, err := io.Copy(f, stateReader); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write backup file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write backup file: %w", err)
+	}
+	return path, nil
 }
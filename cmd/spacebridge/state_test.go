@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisableAccessKeepsFrozenStack(t *testing.T) {
+	source, _ := startFixture(t)
+
+	if err := runStateEnableAccess("", selectorOptions{}); err != nil {
+		t.Fatalf("runStateEnableAccess: %v", err)
+	}
+	if err := runStateMigrate(stateMigrateOptions{freezeSource: true, disableSource: true}); err != nil {
+		t.Fatalf("runStateMigrate: %v", err)
+	}
+	frozen := accountStack(t, source, "app")
+
+	if err := runStateDisableAccess(false, "", selectorOptions{}); err != nil {
+		t.Fatalf("runStateDisableAccess: %v", err)
+	}
+	after := accountStack(t, source, "app")
+	if after.ExternalStateAccessEnabled {
+		t.Error("external state access is still enabled")
+	}
+	if !after.IsDisabled {
+		t.Error("disable-access re-enabled the frozen stack")
+	}
+	if got, want := strings.Join(after.Labels, ","), strings.Join(frozen.Labels, ","); got != want {
+		t.Errorf("labels = %s, want %s", got, want)
+	}
+	if !strings.Contains(strings.Join(after.Labels, ","), migratedToLabelPrefix) {
+		t.Errorf("labels %v lost the migrated-to label", after.Labels)
+	}
+
+	// Access that was enabled before SpaceBridge ran is left alone
+	if network := accountStack(t, source, "network"); !network.ExternalStateAccessEnabled {
+		t.Error("disable-access turned off access it did not enable")
+	}
+}
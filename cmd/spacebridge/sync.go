@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/diff"
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/generator"
+	"github.com/jnesspace/spacebridge/internal/reconcile"
+	"github.com/jnesspace/spacebridge/internal/transform"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// Sync modes.
+const (
+	syncModeAPI  = "api"  // Apply changes with GraphQL mutations
+	syncModeTofu = "tofu" // Regenerate the Tofu code
+)
+
+// syncOptions holds the sync command flags.
+type syncOptions struct {
+	mode        string
+	dryRun      bool
+	interval    time.Duration
+	include     []string
+	exclude     []string
+	spaceFilter string
+	configPath  string
+	mappingPath string
+	outputDir   string
+	format      string
+	disabled    bool
+}
+
+// newSyncCmd creates the sync command.
+func newSyncCmd() *cobra.Command {
+	var opts syncOptions
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Continuously apply source account changes to the destination",
+		Long: `Discovers both accounts, computes the difference with the same logic as
+'spacebridge diff --source --destination', and brings the destination in
+line with the source. Sync is one-way: resources that only exist in the
+destination are never deleted.
+
+Modes:
+  api   Apply changes with GraphQL mutations: create and update contexts
+        and policies, set and delete non-secret context config, and attach
+        or detach contexts and policies. Everything else (stacks, spaces,
+        integrations, secret values) is reported but left alone.
+  tofu  Regenerate the Tofu code in --output-dir when the accounts differ.
+        Review and apply it with tofu as usual. Filters only decide which
+        differences trigger regeneration; the code always covers every
+        resource, so tofu never plans to destroy filtered-out resources.
+
+Filters take a kind (spaces, stacks, contexts, policies, awsIntegrations,
+azureIntegrations) or kind/name-glob, e.g. --include contexts
+--include policies/team-* --exclude contexts/legacy-*.
+
+Example usage:
+  # Preview what would be synced
+  spacebridge sync --dry-run
+
+  # Sync contexts and policies every 10 minutes
+  spacebridge sync --include contexts --include policies --interval 10m
+
+  # Keep the generated Tofu code up to date instead
+  spacebridge sync --mode tofu -o ./tofu/ -c spacebridge.yaml --interval 1h`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSync(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.mode, "mode", syncModeAPI, "Sync mode: api (GraphQL mutations) or tofu (regenerate code)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would be changed without making changes")
+	cmd.Flags().DurationVar(&opts.interval, "interval", 0, "Repeat the sync at this interval (default: run once)")
+	cmd.Flags().StringArrayVar(&opts.include, "include", nil, "Only sync resources matching kind or kind/name-glob; repeatable")
+	cmd.Flags().StringArrayVar(&opts.exclude, "exclude", nil, "Skip resources matching kind or kind/name-glob; repeatable")
+	cmd.Flags().StringVarP(&opts.spaceFilter, "space", "s", "", "Only sync resources from this source space (and its children)")
+	cmd.Flags().StringVarP(&opts.configPath, "config", "c", "", "Migration config YAML file (transforms, VCS overrides, space remapping)")
+	cmd.Flags().StringVar(&opts.mappingPath, "mapping", "", "JSON file mapping source IDs to destination IDs per kind")
+	cmd.Flags().StringVarP(&opts.outputDir, "output-dir", "o", "./generated", "Output directory for Tofu files (tofu mode)")
+	cmd.Flags().StringVar(&opts.format, "format", generator.FormatHCL, "Tofu output format: hcl or json (tofu mode)")
+	cmd.Flags().BoolVarP(&opts.disabled, "disabled", "d", false, "Generate stacks as disabled, as with 'generate --disabled' (tofu mode)")
+
+	return cmd
+}
+
+// runSync runs one sync pass, or repeats it until interrupted.
+func runSync(opts syncOptions) error {
+	if opts.mode != syncModeAPI && opts.mode != syncModeTofu {
+		return fmt.Errorf("unknown mode %q (expected %s or %s)", opts.mode, syncModeAPI, syncModeTofu)
+	}
+	if opts.mode == syncModeTofu {
+		if err := generator.ValidateFormat(opts.format); err != nil {
+			return err
+		}
+	}
+	filter := reconcile.Filter{Include: opts.include, Exclude: opts.exclude}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.ValidateSource(); err != nil {
+		return fmt.Errorf("source configuration error: %w", err)
+	}
+	if err := cfg.ValidateDestination(); err != nil {
+		return fmt.Errorf("destination configuration error: %w", err)
+	}
+
+	var migCfg *config.MigrationConfig
+	if opts.configPath != "" {
+		var err error
+		if migCfg, err = loadMigrationConfig(opts.configPath); err != nil {
+			return err
+		}
+	}
+	var mapping diff.Mapping
+	if opts.mappingPath != "" {
+		var err error
+		if mapping, err = diff.LoadMapping(opts.mappingPath); err != nil {
+			return err
+		}
+	}
+
+	destClient, err := client.New(cfg.Destination)
+	if err != nil {
+		return fmt.Errorf("failed to create destination client: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		err := runSyncOnce(ctx, destClient, migCfg, mapping, filter, opts)
+		if opts.interval == 0 {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(progressOut, "\n✗ Sync failed: %v\n", err)
+		}
+
+		fmt.Fprintf(progressOut, "\nNext sync at %s (Ctrl+C to stop)\n", time.Now().Add(opts.interval).Format("15:04:05"))
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(progressOut, "Sync stopped")
+			return nil
+		case <-time.After(opts.interval):
+		}
+	}
+}
+
+// Statuses of a change in sync.
+const (
+	syncPlanned     = "planned"
+	syncApplied     = "applied"
+	syncFailed      = "failed"
+	syncNotSynced   = "not-synced"
+	syncRegenerated = "regenerated"
+)
+
+// syncChange is a difference that sync applies, or reports.
+type syncChange struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	// Reason says why a change is not synced, or why it failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// syncResult is the result of one sync pass.
+type syncResult struct {
+	Mode      string       `json:"mode"`
+	DryRun    bool         `json:"dryRun"`
+	StartedAt time.Time    `json:"startedAt"`
+	Changes   []syncChange `json:"changes"`
+	// DestinationOnly counts the resources left alone because they only
+	// exist in the destination
+	DestinationOnly int    `json:"destinationOnly"`
+	Applied         int    `json:"applied"`
+	Failed          int    `json:"failed"`
+	NotSynced       int    `json:"notSynced"`
+	OutputDir       string `json:"outputDir,omitempty"`
+}
+
+// runSyncOnce runs and renders one sync pass.
+func runSyncOnce(ctx context.Context, destClient *client.Client, migCfg *config.MigrationConfig, mapping diff.Mapping, filter reconcile.Filter, opts syncOptions) error {
+	result, err := syncOnce(ctx, destClient, migCfg, mapping, filter, opts)
+	if err != nil {
+		return err
+	}
+	if err := render(result); err != nil {
+		return err
+	}
+	return failureError(result.Failed, result.Applied+result.Failed, "sync actions failed")
+}
+
+// syncOnce discovers both accounts and reconciles the differences.
+func syncOnce(ctx context.Context, destClient *client.Client, migCfg *config.MigrationConfig, mapping diff.Mapping, filter reconcile.Filter, opts syncOptions) (*syncResult, error) {
+	result := &syncResult{Mode: opts.mode, DryRun: opts.dryRun, StartedAt: time.Now(), Changes: []syncChange{}}
+
+	fmt.Fprintln(progressOut, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(progressOut, "│                           SYNC                              │")
+	fmt.Fprintln(progressOut, "└─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(progressOut, "\nStarted: %s\n", result.StartedAt.Format(time.RFC3339))
+
+	source, err := discoverAccount("source", cfg.Source)
+	if err != nil {
+		return nil, err
+	}
+	if opts.spaceFilter != "" {
+		source = filterManifestBySpace(source, opts.spaceFilter)
+	}
+	if migCfg != nil && len(migCfg.Transforms) > 0 {
+		if source, _, err = transform.Apply(source, migCfg.Transforms); err != nil {
+			return nil, fmt.Errorf("failed to apply transforms: %w", err)
+		}
+	}
+
+	dest, err := discoverAccount("destination", cfg.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	// The destination is the old side: the source is what it should become
+	compared := diff.Compare(dest, source, invertMapping(mapping))
+
+	var pending []diff.ResourceDiff
+	for _, r := range compared.Resources {
+		if !filter.Match(r.Kind, r.Name) {
+			continue
+		}
+		if r.Status == diff.StatusRemoved {
+			result.DestinationOnly++
+			continue
+		}
+		pending = append(pending, r)
+	}
+
+	if result.DestinationOnly > 0 {
+		fmt.Fprintf(progressOut, "\n○ %d resources only exist in the destination (left untouched)\n", result.DestinationOnly)
+	}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	if opts.mode == syncModeTofu {
+		return result, syncTofu(result, source, migCfg, pending, opts)
+	}
+	syncAPI(ctx, result, destClient, source, dest, migCfg, pending, opts.dryRun)
+	return result, nil
+}
+
+// syncAPI applies the differences with GraphQL mutations.
+func syncAPI(ctx context.Context, result *syncResult, destClient *client.Client, source, dest *discovery.Manifest, migCfg *config.MigrationConfig, pending []diff.ResourceDiff, dryRun bool) {
+	actions := reconcile.Plan(source, dest, migCfg, pending)
+
+	var apply, skipped []reconcile.Action
+	for _, a := range actions {
+		if a.Skipped != "" {
+			skipped = append(skipped, a)
+		} else {
+			apply = append(apply, a)
+		}
+	}
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(progressOut, "\n⚠ NOT SYNCED (%d)\n", len(skipped))
+		for _, a := range skipped {
+			fmt.Fprintf(progressOut, "    • %s %s: %s - %s\n", diffKindTitles[a.Kind], a.Name, a.Description, a.Skipped)
+		}
+	}
+
+	fmt.Fprintf(progressOut, "\n✓ CHANGES (%d)\n", len(apply))
+	for _, a := range apply {
+		change := syncChange{Kind: a.Kind, Name: a.Name, Description: a.Description, Status: syncPlanned}
+		if dryRun {
+			fmt.Fprintf(progressOut, "    • %s %s: %s\n", diffKindTitles[a.Kind], a.Name, a.Description)
+		} else if err := a.Apply(ctx, destClient); err != nil {
+			fmt.Fprintf(progressOut, "    ✗ %s %s: %s - %v\n", diffKindTitles[a.Kind], a.Name, a.Description, err)
+			change.Status, change.Reason = syncFailed, err.Error()
+			result.Failed++
+		} else {
+			fmt.Fprintf(progressOut, "    ✓ %s %s: %s\n", diffKindTitles[a.Kind], a.Name, a.Description)
+			change.Status = syncApplied
+			result.Applied++
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	for _, a := range skipped {
+		result.Changes = append(result.Changes, syncChange{Kind: a.Kind, Name: a.Name, Description: a.Description, Status: syncNotSynced, Reason: a.Skipped})
+		result.NotSynced++
+	}
+}
+
+// syncTofu regenerates the Tofu code when the accounts differ.
+func syncTofu(result *syncResult, source *discovery.Manifest, migCfg *config.MigrationConfig, pending []diff.ResourceDiff, opts syncOptions) error {
+	fmt.Fprintf(progressOut, "\n✓ OUT OF SYNC (%d resources)\n", len(pending))
+	for _, r := range pending {
+		fmt.Fprintf(progressOut, "    • %s %s (%s)\n", diffKindTitles[r.Kind], r.Name, r.Status)
+		result.Changes = append(result.Changes, syncChange{Kind: r.Kind, Name: r.Name, Description: r.Status, Status: syncPlanned})
+	}
+	result.OutputDir = opts.outputDir
+
+	if opts.dryRun {
+		return nil
+	}
+
+	fmt.Fprintf(progressOut, "\nRegenerating Tofu code in: %s\n", opts.outputDir)
+	gen := generator.New(source, opts.outputDir).
+		WithOutput(progressOut).
+		WithSafeMode(opts.disabled).
+		WithFormat(opts.format).
+		WithSensitiveValues(cfg.Source.SecretKey, cfg.Destination.SecretKey).
+		WithDestinationConfig(&cfg.Destination)
+	if migCfg != nil {
+		gen.WithMigrationConfig(migCfg)
+	}
+	if err := gen.Generate(); err != nil {
+		return fmt.Errorf("failed to generate Tofu code: %w", err)
+	}
+	for i := range result.Changes {
+		result.Changes[i].Status = syncRegenerated
+	}
+	return nil
+}
+
+func (r *syncResult) renderText(w io.Writer) {
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(w, "\n✓ Destination is in sync with the source")
+		return
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	switch {
+	case r.Mode == syncModeTofu && r.DryRun:
+		fmt.Fprintf(w, "DRY RUN - No changes made (would regenerate %s)\n", r.OutputDir)
+	case r.Mode == syncModeTofu:
+		fmt.Fprintf(w, "Review and apply: cd %s && tofu plan && tofu apply\n", r.OutputDir)
+	case r.DryRun:
+		fmt.Fprintln(w, "DRY RUN - No changes made")
+	default:
+		fmt.Fprintf(w, "Applied: %d, Failed: %d, Not synced: %d\n", r.Applied, r.Failed, r.NotSynced)
+	}
+}
+
+// invertMapping swaps each kind's old and new IDs. Sync compares the
+// destination with the source, while mapping files map source IDs to
+// destination IDs.
+func invertMapping(mapping diff.Mapping) diff.Mapping {
+	if mapping == nil {
+		return nil
+	}
+	inverted := make(diff.Mapping)
+	for kind, ids := range mapping {
+		inverted[kind] = make(map[string]string)
+		for sourceID, destID := range ids {
+			inverted[kind][destID] = sourceID
+		}
+	}
+	return inverted
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/transform"
+)
+
+// newTransformCmd creates the transform command group.
+func newTransformCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transform",
+		Short: "Inspect manifest transforms",
+	}
+	cmd.AddCommand(
+		newTransformPreviewCmd(),
+	)
+	return cmd
+}
+
+// newTransformPreviewCmd creates the transform preview command.
+func newTransformPreviewCmd() *cobra.Command {
+	var manifestPath string
+	var configPath string
+	var spaceFilter string
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Show the changes transforms would make (dry run)",
+		Long: `Applies the transforms in the migration config to the manifest and
+shows a diff of every changed resource. Nothing is generated or changed.
+
+Transforms run in order, so later rules see the output of earlier ones.
+generate applies the same transforms before writing Tofu code.
+
+Example usage:
+  spacebridge transform preview -c spacebridge.yaml -m manifest.json`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTransformPreview(manifestPath, configPath, spaceFilter)
+		},
+	}
+	cmd.Flags().StringVarP(&manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().StringVarP(&configPath, "config", "c", "spacebridge.yaml", "Migration config YAML file")
+	cmd.Flags().StringVarP(&spaceFilter, "space", "s", "", "Only include resources from this space (and its children)")
+	return cmd
+}
+
+// transformRuleCount is the number of changes a transform rule makes.
+type transformRuleCount struct {
+	Rule    string `json:"rule"`
+	Changes int    `json:"changes"`
+}
+
+// transformPreviewResult is the result of transform preview.
+type transformPreviewResult struct {
+	Changes   []transform.Change   `json:"changes"`
+	Resources int                  `json:"resources"` // Resources with changes
+	Rules     []transformRuleCount `json:"rules"`
+
+	configPath string
+}
+
+// runTransformPreview prints the changes made by the configured transforms.
+func runTransformPreview(manifestPath, configPath, spaceFilter string) error {
+	migCfg, err := loadMigrationConfig(configPath)
+	if err != nil {
+		return err
+	}
+	result := &transformPreviewResult{Changes: []transform.Change{}, Rules: []transformRuleCount{}, configPath: configPath}
+	if len(migCfg.Transforms) == 0 {
+		return render(result)
+	}
+
+	manifest, err := loadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if spaceFilter != "" {
+		manifest = filterManifestBySpace(manifest, spaceFilter)
+	}
+
+	_, changes, err := transform.Apply(manifest, migCfg.Transforms)
+	if err != nil {
+		return fmt.Errorf("failed to apply transforms: %w", err)
+	}
+
+	type resourceKey struct{ kind, id string }
+	resources := make(map[resourceKey]bool)
+	ruleCounts := make([]int, len(migCfg.Transforms))
+	for _, c := range changes {
+		resources[resourceKey{c.Kind, c.ID}] = true
+		ruleCounts[c.RuleIndex]++
+	}
+	result.Changes = append(result.Changes, changes...)
+	result.Resources = len(resources)
+	for i := range migCfg.Transforms {
+		result.Rules = append(result.Rules, transformRuleCount{Rule: migCfg.Transforms[i].DisplayName(i), Changes: ruleCounts[i]})
+	}
+	return render(result)
+}
+
+func (r *transformPreviewResult) renderText(w io.Writer) {
+	if len(r.Rules) == 0 {
+		fmt.Fprintln(w, "\nNo transforms configured in", r.configPath)
+		return
+	}
+
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                     TRANSFORM PREVIEW                       │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+
+	if len(r.Changes) == 0 {
+		fmt.Fprintln(w, "\n○ No resources matched any transform")
+		fmt.Fprintln(w, "\nDRY RUN - No changes made")
+		return
+	}
+
+	// Group changes by resource, keeping first-seen order
+	type resourceKey struct{ kind, id string }
+	var order []resourceKey
+	byResource := make(map[resourceKey][]transform.Change)
+	for _, c := range r.Changes {
+		key := resourceKey{c.Kind, c.ID}
+		if _, ok := byResource[key]; !ok {
+			order = append(order, key)
+		}
+		byResource[key] = append(byResource[key], c)
+	}
+
+	for _, key := range order {
+		resChanges := byResource[key]
+		fmt.Fprintf(w, "\n~ %s %s\n", key.kind, resChanges[0].Name)
+		for _, c := range resChanges {
+			if c.Field == "labels" {
+				added, removed := labelDiff(c.OldLabels, c.NewLabels)
+				for _, label := range removed {
+					fmt.Fprintf(w, "    - label %-24s (%s)\n", label, c.Rule)
+				}
+				for _, label := range added {
+					fmt.Fprintf(w, "    + label %-24s (%s)\n", label, c.Rule)
+				}
+				continue
+			}
+			fmt.Fprintf(w, "    %s: %s → %s (%s)\n", c.Field, displayValue(c.Old), displayValue(c.New), c.Rule)
+		}
+	}
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(w, "%d changes to %d resources\n\n", len(r.Changes), r.Resources)
+	for _, rule := range r.Rules {
+		fmt.Fprintf(w, "  %-30s %d changes\n", rule.Rule, rule.Changes)
+	}
+
+	fmt.Fprintln(w, "\nDRY RUN - No changes made")
+}
+
+// labelDiff compares the labels before and after a transform change.
+func labelDiff(oldLabels, newLabels []string) (added, removed []string) {
+	set := func(labels []string) map[string]bool {
+		in := make(map[string]bool)
+		for _, label := range labels {
+			in[label] = true
+		}
+		return in
+	}
+	oldSet, newSet := set(oldLabels), set(newLabels)
+	for _, label := range newLabels {
+		if !oldSet[label] {
+			added = append(added, label)
+		}
+	}
+	for _, label := range oldLabels {
+		if !newSet[label] {
+			removed = append(removed, label)
+		}
+	}
+	return added, removed
+}
+
+// displayValue quotes a field value for the preview, showing empty values as (none).
+func displayValue(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return fmt.Sprintf("%q", value)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jnesspace/spacebridge/internal/generator"
+	"github.com/jnesspace/spacebridge/internal/ui"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// newVCSCmd creates the vcs command group.
+func newVCSCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vcs",
+		Short: "Inspect VCS mapping rules",
+	}
+	cmd.AddCommand(
+		newVCSPlanCmd(),
+	)
+	return cmd
+}
+
+// newVCSPlanCmd creates the vcs plan command.
+func newVCSPlanCmd() *cobra.Command {
+	var manifestPath string
+	var configPath string
+	var spaceFilter string
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show which VCS rule matches each stack (dry run)",
+		Long: `Evaluates the VCS rules in the migration config against every stack
+and shows the destination VCS integration, namespace and repository that
+generate would write. Nothing is generated or changed.
+
+Rules are evaluated in order and the first match wins. Stacks that match no
+rule fall back to destination.vcs, or keep the default VCS provider.
+
+Example usage:
+  spacebridge vcs plan -c spacebridge.yaml -m manifest.json`,
+		Annotations: structuredOutput(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVCSPlan(manifestPath, configPath, spaceFilter)
+		},
+	}
+	cmd.Flags().StringVarP(&manifestPath, "manifest", "m", "", "Input manifest file (optional, discovers fresh if not provided)")
+	cmd.Flags().StringVarP(&configPath, "config", "c", "spacebridge.yaml", "Migration config YAML file")
+	cmd.Flags().StringVarP(&spaceFilter, "space", "s", "", "Only include stacks from this space (and its children)")
+	return cmd
+}
+
+// vcsStackPlan is the VCS rule assignment of a stack.
+type vcsStackPlan struct {
+	Stack            string `json:"stack"`
+	Source           string `json:"source"`         // provider/namespace
+	Rule             string `json:"rule,omitempty"` // Empty if no rule matches
+	DestinationVCS   string `json:"destinationVcs"`
+	SourceRepository string `json:"sourceRepository"`
+	Repository       string `json:"repository"`
+}
+
+// vcsRuleCount is the number of stacks a VCS rule matches.
+type vcsRuleCount struct {
+	Rule   string `json:"rule"`
+	Stacks int    `json:"stacks"`
+}
+
+// vcsPlanResult is the result of vcs plan.
+type vcsPlanResult struct {
+	Stacks    []vcsStackPlan `json:"stacks"`
+	Rules     []vcsRuleCount `json:"rules"`
+	Unmatched int            `json:"unmatched"`
+}
+
+// runVCSPlan prints the VCS rule assignment for every stack.
+func runVCSPlan(manifestPath, configPath, spaceFilter string) error {
+	migCfg, err := loadMigrationConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := loadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if spaceFilter != "" {
+		manifest = filterManifestBySpace(manifest, spaceFilter)
+	}
+
+	gen := generator.New(manifest, "").WithMigrationConfig(migCfg)
+
+	result := &vcsPlanResult{Stacks: []vcsStackPlan{}, Rules: []vcsRuleCount{}}
+	ruleCounts := make(map[string]int)
+	for _, stack := range manifest.Stacks {
+		res := gen.ResolveVCS(stack)
+		if res.RuleName == "" {
+			result.Unmatched++
+		}
+		ruleCounts[res.RuleName]++
+
+		result.Stacks = append(result.Stacks, vcsStackPlan{
+			Stack:            stack.Name,
+			Source:           fmt.Sprintf("%s/%s", stack.Provider, stack.Namespace),
+			Rule:             res.RuleName,
+			DestinationVCS:   describeVCS(res),
+			SourceRepository: stack.Repository,
+			Repository:       res.Repository,
+		})
+	}
+
+	for i := range migCfg.Destination.VCSRules {
+		name := migCfg.Destination.VCSRules[i].DisplayName(i)
+		result.Rules = append(result.Rules, vcsRuleCount{Rule: name, Stacks: ruleCounts[name]})
+	}
+	if migCfg.Destination.VCS.HasVCSOverride() {
+		result.Rules = append(result.Rules, vcsRuleCount{Rule: "destination.vcs", Stacks: ruleCounts["destination.vcs"]})
+	}
+	return render(result)
+}
+
+func (r *vcsPlanResult) renderText(w io.Writer) {
+	headers := []string{"Stack", "Source", "Rule", "Destination VCS", "Repository"}
+	var rows [][]string
+	for _, s := range r.Stacks {
+		rule := s.Rule
+		if rule == "" {
+			rule = "-"
+		}
+		repository := s.Repository
+		if repository != s.SourceRepository {
+			repository = s.SourceRepository + " → " + repository
+		}
+		rows = append(rows, []string{s.Stack, s.Source, rule, s.DestinationVCS, repository})
+	}
+
+	fmt.Fprintln(w, "\n┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintln(w, "│                      VCS MAPPING PLAN                       │")
+	fmt.Fprintln(w, "└─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintln(w)
+	fmt.Fprint(w, ui.RenderTable(headers, rows))
+
+	fmt.Fprintln(w, "\n─────────────────────────────────────────────────────────────")
+	for _, rule := range r.Rules {
+		fmt.Fprintf(w, "  %-30s %d stacks\n", rule.Rule, rule.Stacks)
+	}
+	if r.Unmatched > 0 {
+		fmt.Fprintf(w, "\n⚠ %d stacks match no rule and keep the default VCS provider\n", r.Unmatched)
+	}
+
+	fmt.Fprintln(w, "\nDRY RUN - No changes made")
+}
+
+// describeVCS formats the destination VCS block of a resolution for display.
+func describeVCS(res config.VCSResolution) string {
+	vcs := res.VCS
+	if vcs == nil {
+		return "default"
+	}
+	switch {
+	case vcs.GithubEnterprise != nil:
+		return fmt.Sprintf("github_enterprise %s (%s)", vcs.GithubEnterprise.ID, vcs.GithubEnterprise.Namespace)
+	case vcs.Gitlab != nil:
+		return fmt.Sprintf("gitlab %s (%s)", vcs.Gitlab.ID, vcs.Gitlab.Namespace)
+	case vcs.BitbucketDatacenter != nil:
+		return fmt.Sprintf("bitbucket_datacenter %s (%s)", vcs.BitbucketDatacenter.ID, vcs.BitbucketDatacenter.Namespace)
+	case vcs.BitbucketCloud != nil:
+		return fmt.Sprintf("bitbucket_cloud %s (%s)", vcs.BitbucketCloud.ID, vcs.BitbucketCloud.Namespace)
+	case vcs.AzureDevops != nil:
+		return fmt.Sprintf("azure_devops %s (%s)", vcs.AzureDevops.ID, vcs.AzureDevops.Project)
+	default:
+		return "default"
+	}
+}
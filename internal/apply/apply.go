@@ -0,0 +1,721 @@
+// Package apply creates manifest resources in the destination account
+// directly through the GraphQL API, without OpenTofu.
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/diff"
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/generator"
+	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// Event statuses.
+const (
+	StatusCreated = "created" // Created in the destination
+	StatusExists  = "exists"  // Already in the destination, left unchanged
+	StatusPlanned = "planned" // Would be created (dry run)
+	StatusSkipped = "skipped" // Cannot be created through the API
+)
+
+// Event reports what happened to one resource or attachment.
+type Event struct {
+	Status string
+	Kind   string // e.g. "space", "context", "env var", "policy attachment"
+	Name   string
+	ID     string // Destination ID, if known
+	Detail string // Reason for skipped resources
+}
+
+// Options controls an apply run.
+type Options struct {
+	DryRun          bool
+	SafeMode        bool                    // Create stacks with autodeploy disabled
+	MigrationConfig *config.MigrationConfig // Space remapping and VCS rules
+	Secrets         map[string]string       // Secret values by generator.SecretVariableName
+	Mapping         diff.Mapping            // Source ID -> destination ID, updated as resources are created
+	MappingPath     string                  // Mapping file, saved after every creation
+	OnEvent         func(Event)
+}
+
+// pendingID stands in for the ID of a resource that a dry run would create.
+const pendingID = "(new)"
+
+// Run creates every resource in the source manifest that does not exist in
+// the destination yet, in dependency order: spaces (parents first),
+// contexts and their config, policies, stacks, then attachments,
+// dependencies and the admin role bindings of administrative stacks.
+// Existing resources are found through the mapping file or by name, so a
+// rerun after a partial failure creates nothing twice. Run stops at the
+// first failed mutation.
+func Run(ctx context.Context, c *client.Client, source, dest *discovery.Manifest, opts Options) error {
+	if opts.Mapping == nil {
+		opts.Mapping = make(diff.Mapping)
+	}
+	a := &applier{client: c, source: source, dest: dest, opts: opts, planned: make(diff.Mapping)}
+
+	steps := []func(context.Context) error{
+		a.applySpaces,
+		a.applyContexts,
+		a.applyPolicies,
+		a.resolveIntegrations,
+		a.applyStacks,
+		a.applyAttachments,
+		a.applyAdminRoles,
+	}
+	for _, step := range steps {
+		if err := step(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applier holds the state of one apply run.
+type applier struct {
+	client       *client.Client
+	source, dest *discovery.Manifest
+	opts         Options
+
+	// planned holds IDs of resources a dry run would create
+	planned diff.Mapping
+}
+
+func (a *applier) emit(e Event) {
+	if a.opts.OnEvent != nil {
+		a.opts.OnEvent(e)
+	}
+}
+
+// destID returns the destination ID of a source resource that exists or
+// has been (or would be) created in this run.
+func (a *applier) destID(kind, sourceID string) (string, bool) {
+	if id, ok := a.opts.Mapping[kind][sourceID]; ok {
+		return id, true
+	}
+	if id, ok := a.planned[kind][sourceID]; ok {
+		return id, true
+	}
+	return "", false
+}
+
+// found records an existing destination resource.
+func (a *applier) found(kind, label, sourceID, name, destID string) error {
+	a.opts.Mapping.Set(kind, sourceID, destID)
+	a.emit(Event{Status: StatusExists, Kind: label, Name: name, ID: destID})
+	return a.saveMapping()
+}
+
+// create runs a create mutation, or records a planned creation in a dry run.
+func (a *applier) create(ctx context.Context, kind, label, sourceID, name string, mutate func(context.Context) (string, error)) error {
+	if a.opts.DryRun {
+		a.planned.Set(kind, sourceID, pendingID)
+		a.emit(Event{Status: StatusPlanned, Kind: label, Name: name})
+		return nil
+	}
+
+	id, err := mutate(ctx)
+	if err != nil {
+		return err
+	}
+	a.opts.Mapping.Set(kind, sourceID, id)
+	a.emit(Event{Status: StatusCreated, Kind: label, Name: name, ID: id})
+	return a.saveMapping()
+}
+
+// do runs a mutation that creates nothing with an ID of its own, such as
+// an attachment.
+func (a *applier) do(ctx context.Context, label, name string, mutate func(context.Context) error) error {
+	if a.opts.DryRun {
+		a.emit(Event{Status: StatusPlanned, Kind: label, Name: name})
+		return nil
+	}
+	if err := mutate(ctx); err != nil {
+		return err
+	}
+	a.emit(Event{Status: StatusCreated, Kind: label, Name: name})
+	return nil
+}
+
+func (a *applier) saveMapping() error {
+	if a.opts.DryRun || a.opts.MappingPath == "" {
+		return nil
+	}
+	return a.opts.Mapping.Save(a.opts.MappingPath)
+}
+
+// mappedExists returns the destination ID from the mapping file if that
+// resource still exists in the destination.
+func (a *applier) mappedExists(kind, sourceID string, exists func(id string) bool) (string, bool) {
+	id, ok := a.opts.Mapping[kind][sourceID]
+	if ok && exists(id) {
+		return id, true
+	}
+	return "", false
+}
+
+func (a *applier) applySpaces(ctx context.Context) error {
+	var spaces *config.SpacesConfig
+	if a.opts.MigrationConfig != nil {
+		spaces = &a.opts.MigrationConfig.Spaces
+	}
+
+	destSpaces := make(map[string]models.Space)
+	for _, s := range a.dest.Spaces {
+		destSpaces[s.ID] = s
+	}
+	a.opts.Mapping.Set(diff.KindSpaces, "root", "root")
+
+	for _, space := range generator.SortSpacesByDependency(a.source.Spaces) {
+		if space.ID == "root" {
+			continue
+		}
+		if spaces != nil {
+			if destID, ok := spaces.MappedSpace(space.ID); ok {
+				if err := a.found(diff.KindSpaces, "space", space.ID, space.Name, destID); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		// Resolve the destination parent
+		parent := "root"
+		if space.ParentSpace != nil && *space.ParentSpace != "" {
+			parent = *space.ParentSpace
+		}
+		destParent, ok := "", false
+		if spaces != nil {
+			destParent, ok = spaces.ParentOverride(space.ID, parent)
+		}
+		if !ok {
+			if destParent, ok = a.destID(diff.KindSpaces, parent); !ok {
+				a.emit(Event{Status: StatusSkipped, Kind: "space", Name: space.Name, Detail: fmt.Sprintf("parent space %q is not in the manifest", parent)})
+				continue
+			}
+		}
+
+		desired := space
+		desired.ParentSpace = &destParent
+		if spaces != nil {
+			desired.Name = spaces.NamePrefix + space.Name
+		}
+
+		if id, ok := a.mappedExists(diff.KindSpaces, space.ID, func(id string) bool { _, ok := destSpaces[id]; return ok }); ok {
+			if err := a.found(diff.KindSpaces, "space", space.ID, desired.Name, id); err != nil {
+				return err
+			}
+			continue
+		}
+		existing := ""
+		for _, s := range a.dest.Spaces {
+			if s.Name == desired.Name && s.ParentSpace != nil && *s.ParentSpace == destParent {
+				existing = s.ID
+			}
+		}
+		if existing != "" {
+			if err := a.found(diff.KindSpaces, "space", space.ID, desired.Name, existing); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := a.create(ctx, diff.KindSpaces, "space", space.ID, desired.Name, func(ctx context.Context) (string, error) {
+			return a.client.CreateSpace(ctx, desired)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// space resolves a source space ID to a destination space ID.
+func (a *applier) space(sourceID string) (string, bool) {
+	if sourceID == "" {
+		return "root", true
+	}
+	return a.destID(diff.KindSpaces, sourceID)
+}
+
+func (a *applier) applyContexts(ctx context.Context) error {
+	destContexts := make(map[string]models.Context)
+	for _, c := range a.dest.Contexts {
+		destContexts[c.ID] = c
+	}
+
+	for _, sc := range a.source.Contexts {
+		space, ok := a.space(sc.Space)
+		if !ok {
+			a.emit(Event{Status: StatusSkipped, Kind: "context", Name: sc.Name, Detail: fmt.Sprintf("space %q was not created", sc.Space)})
+			continue
+		}
+		desired := sc
+		desired.Space = space
+
+		// Existing contexts keep their config; only missing keys are added
+		var existing *models.Context
+		if id, ok := a.mappedExists(diff.KindContexts, sc.ID, func(id string) bool { _, ok := destContexts[id]; return ok }); ok {
+			c := destContexts[id]
+			existing = &c
+		} else {
+			for _, c := range a.dest.Contexts {
+				if c.Name == desired.Name && c.Space == space {
+					c := c
+					existing = &c
+				}
+			}
+		}
+
+		if existing != nil {
+			if err := a.found(diff.KindContexts, "context", sc.ID, sc.Name, existing.ID); err != nil {
+				return err
+			}
+		} else if err := a.create(ctx, diff.KindContexts, "context", sc.ID, sc.Name, func(ctx context.Context) (string, error) {
+			return a.client.CreateContext(ctx, desired)
+		}); err != nil {
+			return err
+		}
+
+		if err := a.applyConfig(ctx, sc, existing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyConfig sets the config elements a destination context is missing.
+// Secret values come from Options.Secrets.
+func (a *applier) applyConfig(ctx context.Context, sc models.Context, existing *models.Context) error {
+	contextID, _ := a.destID(diff.KindContexts, sc.ID)
+
+	for _, element := range sc.Config {
+		label := "env var"
+		if element.Type == "FILE_MOUNT" {
+			label = "mounted file"
+		}
+		name := sc.Name + "/" + element.ID
+
+		if existing != nil {
+			if _, ok := findConfig(*existing, element.ID); ok {
+				a.emit(Event{Status: StatusExists, Kind: label, Name: name})
+				continue
+			}
+		}
+
+		if element.WriteOnly {
+			value, ok := a.opts.Secrets[generator.SecretVariableName(sc.ID, element.ID)]
+			if !ok {
+				a.emit(Event{Status: StatusSkipped, Kind: label, Name: name, Detail: "secret value not found in any secret source"})
+				continue
+			}
+			element.Value = value
+		}
+
+		element := element
+		if err := a.do(ctx, label, name, func(ctx context.Context) error {
+			return a.client.SetContextConfig(ctx, contextID, element)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func findConfig(sc models.Context, key string) (models.ConfigElement, bool) {
+	for _, e := range sc.Config {
+		if e.ID == key {
+			return e, true
+		}
+	}
+	return models.ConfigElement{}, false
+}
+
+func (a *applier) applyPolicies(ctx context.Context) error {
+	destPolicies := make(map[string]bool)
+	for _, p := range a.dest.Policies {
+		destPolicies[p.ID] = true
+	}
+
+	for _, policy := range a.source.Policies {
+		space, ok := a.space(policy.Space)
+		if !ok {
+			a.emit(Event{Status: StatusSkipped, Kind: "policy", Name: policy.Name, Detail: fmt.Sprintf("space %q was not created", policy.Space)})
+			continue
+		}
+		desired := policy
+		desired.Space = space
+
+		if id, ok := a.mappedExists(diff.KindPolicies, policy.ID, func(id string) bool { return destPolicies[id] }); ok {
+			if err := a.found(diff.KindPolicies, "policy", policy.ID, policy.Name, id); err != nil {
+				return err
+			}
+			continue
+		}
+		existing := ""
+		for _, p := range a.dest.Policies {
+			if p.Name == policy.Name && p.Space == space {
+				existing = p.ID
+			}
+		}
+		if existing != "" {
+			if err := a.found(diff.KindPolicies, "policy", policy.ID, policy.Name, existing); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := a.create(ctx, diff.KindPolicies, "policy", policy.ID, policy.Name, func(ctx context.Context) (string, error) {
+			return a.client.CreatePolicy(ctx, desired)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveIntegrations maps source integrations onto existing destination
+// integrations by name. Integrations need trust changes in the cloud
+// account, so they are never created here.
+func (a *applier) resolveIntegrations(ctx context.Context) error {
+	for _, i := range a.source.AWSIntegrations {
+		existing := ""
+		for _, d := range a.dest.AWSIntegrations {
+			if d.ID == a.opts.Mapping[diff.KindAWSIntegrations][i.ID] || d.Name == i.Name {
+				existing = d.ID
+			}
+		}
+		if existing == "" {
+			a.emit(Event{Status: StatusSkipped, Kind: "AWS integration", Name: i.Name, Detail: "create it in the destination (it needs an IAM trust policy update)"})
+			continue
+		}
+		if err := a.found(diff.KindAWSIntegrations, "AWS integration", i.ID, i.Name, existing); err != nil {
+			return err
+		}
+	}
+	for _, i := range a.source.AzureIntegrations {
+		existing := ""
+		for _, d := range a.dest.AzureIntegrations {
+			if d.ID == a.opts.Mapping[diff.KindAzureIntegrations][i.ID] || d.Name == i.Name {
+				existing = d.ID
+			}
+		}
+		if existing == "" {
+			a.emit(Event{Status: StatusSkipped, Kind: "Azure integration", Name: i.Name, Detail: "create it in the destination (it needs an Azure AD app registration update)"})
+			continue
+		}
+		if err := a.found(diff.KindAzureIntegrations, "Azure integration", i.ID, i.Name, existing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *applier) applyStacks(ctx context.Context) error {
+	destStacks := make(map[string]bool)
+	for _, s := range a.dest.Stacks {
+		destStacks[s.ID] = true
+	}
+
+	for _, stack := range a.source.Stacks {
+		if id, ok := a.mappedExists(diff.KindStacks, stack.ID, func(id string) bool { return destStacks[id] }); ok {
+			if err := a.found(diff.KindStacks, "stack", stack.ID, stack.Name, id); err != nil {
+				return err
+			}
+			continue
+		}
+		existing := ""
+		for _, s := range a.dest.Stacks {
+			if s.Name == stack.Name {
+				existing = s.ID
+			}
+		}
+		if existing != "" {
+			if err := a.found(diff.KindStacks, "stack", stack.ID, stack.Name, existing); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !stack.IsTerraform() {
+			a.emit(Event{Status: StatusSkipped, Kind: "stack", Name: stack.Name, Detail: "only Terraform, OpenTofu and Terragrunt stacks can be created; use 'spacebridge generate'"})
+			continue
+		}
+		space, ok := a.space(stack.Space)
+		if !ok {
+			a.emit(Event{Status: StatusSkipped, Kind: "stack", Name: stack.Name, Detail: fmt.Sprintf("space %q was not created", stack.Space)})
+			continue
+		}
+
+		desired := stack
+		desired.Space = space
+		if a.opts.SafeMode {
+			desired.Autodeploy = false
+		}
+		vcs := a.stackVCS(stack)
+
+		if err := a.create(ctx, diff.KindStacks, "stack", stack.ID, stack.Name, func(ctx context.Context) (string, error) {
+			return a.client.CreateStack(ctx, desired, vcs)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stackVCS resolves a stack's destination VCS settings with the same rules
+// as the generated Tofu code.
+func (a *applier) stackVCS(stack models.Stack) client.StackVCS {
+	if a.opts.MigrationConfig == nil {
+		return client.StackVCS{Provider: stack.Provider, Namespace: stack.Namespace, Repository: stack.Repository}
+	}
+
+	res := a.opts.MigrationConfig.ResolveVCS(config.VCSSubject{
+		Provider:   stack.Provider,
+		Namespace:  stack.Namespace,
+		Repository: stack.Repository,
+		Spaces:     a.source.SpaceAncestry(stack.Space),
+		Labels:     stack.Labels,
+	})
+	vcs := client.StackVCS{Provider: stack.Provider, Namespace: res.Namespace, Repository: res.Repository}
+
+	switch {
+	case res.VCS == nil:
+	case res.VCS.GithubEnterprise != nil:
+		vcs.Provider, vcs.IntegrationID, vcs.Namespace = "GITHUB_ENTERPRISE", res.VCS.GithubEnterprise.ID, res.VCS.GithubEnterprise.Namespace
+	case res.VCS.Gitlab != nil:
+		vcs.Provider, vcs.IntegrationID, vcs.Namespace = "GITLAB", res.VCS.Gitlab.ID, res.VCS.Gitlab.Namespace
+	case res.VCS.BitbucketDatacenter != nil:
+		vcs.Provider, vcs.IntegrationID, vcs.Namespace = "BITBUCKET_DATACENTER", res.VCS.BitbucketDatacenter.ID, res.VCS.BitbucketDatacenter.Namespace
+	case res.VCS.BitbucketCloud != nil:
+		vcs.Provider, vcs.IntegrationID, vcs.Namespace = "BITBUCKET_CLOUD", res.VCS.BitbucketCloud.ID, res.VCS.BitbucketCloud.Namespace
+	case res.VCS.AzureDevops != nil:
+		vcs.Provider, vcs.IntegrationID, vcs.Namespace = "AZURE_DEVOPS", res.VCS.AzureDevops.ID, res.VCS.AzureDevops.Project
+	}
+	return vcs
+}
+
+// applyAttachments attaches contexts, policies and integrations to stacks
+// and creates stack dependencies, skipping those that already exist.
+func (a *applier) applyAttachments(ctx context.Context) error {
+	destStacks := make(map[string]models.Stack)
+	for _, s := range a.dest.Stacks {
+		destStacks[s.ID] = s
+	}
+
+	for _, stack := range a.source.Stacks {
+		stackID, ok := a.destID(diff.KindStacks, stack.ID)
+		if !ok {
+			continue // Skipped above
+		}
+		existing := destStacks[stackID]
+
+		for _, att := range stack.AttachedContexts {
+			contextID, ok := a.destID(diff.KindContexts, att.ContextID)
+			name := stack.Name + " ← " + att.ContextID
+			if !ok {
+				a.emit(Event{Status: StatusSkipped, Kind: "context attachment", Name: name, Detail: "context was not created"})
+				continue
+			}
+			if hasContext(existing, contextID) {
+				a.emit(Event{Status: StatusExists, Kind: "context attachment", Name: name})
+				continue
+			}
+			priority := att.Priority
+			if err := a.do(ctx, "context attachment", name, func(ctx context.Context) error {
+				_, err := a.client.AttachContext(ctx, contextID, stackID, priority)
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, att := range stack.AttachedPolicies {
+			policyID, ok := a.destID(diff.KindPolicies, att.PolicyID)
+			name := stack.Name + " ← " + att.PolicyID
+			if !ok {
+				a.emit(Event{Status: StatusSkipped, Kind: "policy attachment", Name: name, Detail: "policy was not created"})
+				continue
+			}
+			if hasPolicy(existing, policyID) {
+				a.emit(Event{Status: StatusExists, Kind: "policy attachment", Name: name})
+				continue
+			}
+			if err := a.do(ctx, "policy attachment", name, func(ctx context.Context) error {
+				_, err := a.client.AttachPolicy(ctx, policyID, stackID)
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, att := range stack.AttachedAWSIntegrations {
+			integrationID, ok := a.destID(diff.KindAWSIntegrations, att.IntegrationID)
+			name := stack.Name + " ← " + att.IntegrationID
+			if !ok {
+				a.emit(Event{Status: StatusSkipped, Kind: "AWS attachment", Name: name, Detail: "integration does not exist in the destination"})
+				continue
+			}
+			if hasAWSIntegration(existing, integrationID) {
+				a.emit(Event{Status: StatusExists, Kind: "AWS attachment", Name: name})
+				continue
+			}
+			att := att
+			if err := a.do(ctx, "AWS attachment", name, func(ctx context.Context) error {
+				return a.client.AttachAWSIntegration(ctx, integrationID, stackID, att.Read, att.Write)
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, att := range stack.AttachedAzureIntegrations {
+			integrationID, ok := a.destID(diff.KindAzureIntegrations, att.IntegrationID)
+			name := stack.Name + " ← " + att.IntegrationID
+			if !ok {
+				a.emit(Event{Status: StatusSkipped, Kind: "Azure attachment", Name: name, Detail: "integration does not exist in the destination"})
+				continue
+			}
+			if hasAzureIntegration(existing, integrationID) {
+				a.emit(Event{Status: StatusExists, Kind: "Azure attachment", Name: name})
+				continue
+			}
+			att := att
+			if err := a.do(ctx, "Azure attachment", name, func(ctx context.Context) error {
+				return a.client.AttachAzureIntegration(ctx, integrationID, stackID, att)
+			}); err != nil {
+				return err
+			}
+		}
+
+		for _, dep := range stack.DependsOn {
+			dependsOnID, ok := a.destID(diff.KindStacks, dep.DependsOnStackID)
+			name := stack.Name + " → " + dep.DependsOnStackID
+			if !ok {
+				a.emit(Event{Status: StatusSkipped, Kind: "dependency", Name: name, Detail: "stack was not created"})
+				continue
+			}
+			if hasDependency(existing, dependsOnID) {
+				a.emit(Event{Status: StatusExists, Kind: "dependency", Name: name})
+				continue
+			}
+			if err := a.do(ctx, "dependency", name, func(ctx context.Context) error {
+				return a.client.AddStackDependency(ctx, stackID, dependsOnID)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyAdminRoles binds generator.AdminRoleName to administrative stacks in
+// their space, creating the role if needed, as the generated
+// spacelift_role_attachment does. The administrative flag itself is
+// deprecated and not set on created stacks.
+func (a *applier) applyAdminRoles(ctx context.Context) error {
+	var admin []models.Stack
+	for _, stack := range a.source.Stacks {
+		if _, ok := a.destID(diff.KindStacks, stack.ID); ok && stack.Administrative {
+			admin = append(admin, stack)
+		}
+	}
+	if len(admin) == 0 {
+		return nil
+	}
+
+	roles, err := a.client.ListRoles(ctx)
+	if err != nil {
+		return err
+	}
+	roleID := ""
+	bound := make(map[string]bool)
+	for _, role := range roles {
+		if role.Name == generator.AdminRoleName {
+			roleID = role.ID
+			for _, stackID := range role.Stacks {
+				bound[stackID] = true
+			}
+		}
+	}
+
+	switch {
+	case roleID != "":
+		a.emit(Event{Status: StatusExists, Kind: "role", Name: generator.AdminRoleName, ID: roleID})
+	case a.opts.DryRun:
+		roleID = pendingID
+		a.emit(Event{Status: StatusPlanned, Kind: "role", Name: generator.AdminRoleName})
+	default:
+		if roleID, err = a.client.CreateRole(ctx, generator.AdminRoleName, generator.AdminRoleActions); err != nil {
+			return err
+		}
+		a.emit(Event{Status: StatusCreated, Kind: "role", Name: generator.AdminRoleName, ID: roleID})
+	}
+
+	for _, stack := range admin {
+		stackID, _ := a.destID(diff.KindStacks, stack.ID)
+		name := stack.Name + " ← " + generator.AdminRoleName
+		if bound[stackID] {
+			a.emit(Event{Status: StatusExists, Kind: "role attachment", Name: name})
+			continue
+		}
+		space, ok := a.space(stack.Space)
+		if !ok {
+			a.emit(Event{Status: StatusSkipped, Kind: "role attachment", Name: name, Detail: fmt.Sprintf("space %q was not created", stack.Space)})
+			continue
+		}
+		if err := a.do(ctx, "role attachment", name, func(ctx context.Context) error {
+			return a.client.AttachRoleToStack(ctx, roleID, stackID, space)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasContext(s models.Stack, contextID string) bool {
+	for _, att := range s.AttachedContexts {
+		if att.ContextID == contextID {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPolicy(s models.Stack, policyID string) bool {
+	for _, att := range s.AttachedPolicies {
+		if att.PolicyID == policyID {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAWSIntegration(s models.Stack, integrationID string) bool {
+	for _, att := range s.AttachedAWSIntegrations {
+		if att.IntegrationID == integrationID {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAzureIntegration(s models.Stack, integrationID string) bool {
+	for _, att := range s.AttachedAzureIntegrations {
+		if att.IntegrationID == integrationID {
+			return true
+		}
+	}
+	return false
+}
+
+func hasDependency(s models.Stack, stackID string) bool {
+	for _, dep := range s.DependsOn {
+		if dep.DependsOnStackID == stackID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,327 @@
+// Package cassette records GraphQL exchanges with Spacelift to files and
+// replays them in place of the network, so that problems seen on a real
+// account can be reproduced without access to it.
+//
+// A cassette is a directory with one JSON file per exchange, numbered in
+// the order they happened. API keys, tokens, secret config values and the
+// signatures of presigned URLs are redacted before anything is written.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secret values in a cassette.
+const Redacted = "REDACTED"
+
+// Interaction is one recorded GraphQL exchange.
+type Interaction struct {
+	Host      string                 `json:"host"`              // Account the request went to
+	Account   string                 `json:"account,omitempty"` // source or destination
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	Status    int                    `json:"status"`
+	Response  json.RawMessage        `json:"response"`
+}
+
+// graphqlRequest is the body of a GraphQL request.
+type graphqlRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// Recorder is an http.RoundTripper that forwards requests to a base
+// transport and writes every GraphQL exchange to a cassette directory.
+type Recorder struct {
+	dir      string
+	base     http.RoundTripper
+	accounts map[string]string // Role of each account host
+
+	mu sync.Mutex
+	n  int
+}
+
+// NewRecorder returns a recorder writing to dir, which is created if needed.
+func NewRecorder(dir string, base http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	return &Recorder{dir: dir, base: base, accounts: make(map[string]string)}, nil
+}
+
+// WithAccount records requests to host as going to account, source or
+// destination, so that a replay can tell the accounts apart.
+func (r *Recorder) WithAccount(host, account string) *Recorder {
+	r.accounts[host] = account
+	return r
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("failed to read request: %w", err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var gql graphqlRequest
+	if json.Unmarshal(body, &gql) != nil || gql.Query == "" {
+		return resp, nil // Not GraphQL
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Host:      req.URL.Host,
+		Account:   r.accounts[req.URL.Host],
+		Query:     gql.Query,
+		Variables: redactObject(gql.Variables),
+		Status:    resp.StatusCode,
+		Response:  redactJSON(respBody),
+	}
+	if err := r.write(interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// write saves an interaction as the next file of the cassette.
+func (r *Recorder) write(interaction Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal interaction: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.n++
+	name := fmt.Sprintf("%04d-%s.json", r.n, operationName(interaction.Query))
+	if err := os.WriteFile(filepath.Join(r.dir, name), data, 0600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Replayer is an http.RoundTripper that answers GraphQL requests from a
+// cassette instead of the network.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	hosts        map[string]string // Recorded host to answer requests to each host from
+}
+
+// NewReplayer loads the cassette in dir.
+func NewReplayer(dir string) (*Replayer, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cassette: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no recorded interactions in %s", dir)
+	}
+	sort.Strings(paths)
+
+	r := &Replayer{hosts: make(map[string]string)}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+		}
+		r.interactions = append(r.interactions, interaction)
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// WithAccount answers requests to host with the interactions recorded for
+// account, source or destination, whatever host they were recorded from.
+// This lets a replay use placeholder URLs for the accounts.
+func (r *Replayer) WithAccount(host, account string) *Replayer {
+	for _, interaction := range r.interactions {
+		if interaction.Account == account {
+			r.hosts[host] = interaction.Host
+			break
+		}
+	}
+	return r
+}
+
+// RoundTrip implements http.RoundTripper. A request gets the first unused
+// recorded response with the same host, query and variables, compared after
+// redaction. Once all of those are used, the last one is repeated, so
+// polling loops replay however often they poll.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var gql graphqlRequest
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request: %w", err)
+		}
+		if err := json.Unmarshal(body, &gql); err != nil {
+			return nil, fmt.Errorf("replay: not a GraphQL request: %w", err)
+		}
+	}
+	host := req.URL.Host
+	if recorded, ok := r.hosts[host]; ok {
+		host = recorded
+	}
+	key := matchKey(host, gql.Query, redactObject(gql.Variables))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, interaction := range r.interactions {
+		if matchKey(interaction.Host, interaction.Query, interaction.Variables) != key {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			r.used[i] = true
+			return response(req, interaction), nil
+		}
+	}
+	if last >= 0 {
+		return response(req, r.interactions[last]), nil
+	}
+	return nil, fmt.Errorf("replay: no recorded response for %s on %s", operationName(gql.Query), host)
+}
+
+// response builds the HTTP response of a recorded interaction.
+func response(req *http.Request, interaction Interaction) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(interaction.Response)),
+		ContentLength: int64(len(interaction.Response)),
+		Request:       req,
+	}
+}
+
+// matchKey identifies a request for replay: its host, its query with
+// whitespace collapsed, and its variables.
+func matchKey(host, query string, variables map[string]interface{}) string {
+	vars := []byte("{}")
+	if len(variables) > 0 {
+		vars, _ = json.Marshal(variables) // Map keys are sorted
+	}
+	return host + "\x00" + strings.Join(strings.Fields(query), " ") + "\x00" + string(vars)
+}
+
+var (
+	namedOperation = regexp.MustCompile(`^\s*(?:query|mutation)\s+([A-Za-z_][A-Za-z0-9_]*)`)
+	firstField     = regexp.MustCompile(`\{\s*([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// operationName returns the name of a GraphQL operation, or its first
+// field for anonymous operations.
+func operationName(query string) string {
+	if m := namedOperation.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	if m := firstField.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	return "request"
+}
+
+// secretKeys are object keys whose values are always redacted.
+var secretKeys = map[string]bool{
+	"jwt":       true,
+	"keyId":     true,
+	"keySecret": true,
+	"secret":    true,
+	"secretKey": true,
+	"password":  true,
+	"token":     true,
+}
+
+// redactJSON redacts a JSON document. Documents that are not valid JSON
+// are replaced entirely.
+func redactJSON(data []byte) json.RawMessage {
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		out, _ := json.Marshal(Redacted)
+		return out
+	}
+	out, _ := json.Marshal(redact(v))
+	return out
+}
+
+// redactObject redacts GraphQL variables.
+func redactObject(object map[string]interface{}) map[string]interface{} {
+	if object == nil {
+		return nil
+	}
+	return redact(object).(map[string]interface{})
+}
+
+// redact returns a copy of a decoded JSON value with secrets replaced:
+// values under secretKeys, values of write-only config elements, and the
+// query strings (signatures) of URLs.
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		writeOnly, _ := v["writeOnly"].(bool)
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			switch {
+			case secretKeys[key] && value != nil:
+				out[key] = Redacted
+			case key == "value" && writeOnly:
+				out[key] = Redacted
+			default:
+				out[key] = redact(value)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redact(item)
+		}
+		return out
+	case string:
+		if (strings.HasPrefix(v, "https://") || strings.HasPrefix(v, "http://")) && strings.Contains(v, "?") {
+			return v[:strings.Index(v, "?")] + "?" + Redacted
+		}
+		return v
+	}
+	return v
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"time"
 
 	graphql "github.com/hasura/go-graphql-client"
@@ -17,6 +18,13 @@ import (
 // Verbose controls whether verbose output is enabled.
 var Verbose bool
 
+// Log receives the verbose output.
+var Log io.Writer = os.Stdout
+
+// Transport carries all GraphQL requests, including authentication. Replace
+// it to record or replay them (see package cassette).
+var Transport http.RoundTripper = http.DefaultTransport
+
 // Client wraps the GraphQL client with Spacelift-specific functionality.
 type Client struct {
 	graphql *graphql.Client
@@ -43,13 +51,13 @@ func (t *spaceliftTransport) RoundTrip(req *http.Request) (*http.Response, error
 	// Refresh token if expired or not set
 	if t.token == "" || time.Now().After(t.tokenExp) {
 		if Verbose {
-			fmt.Printf("[AUTH] Authenticating with Spacelift at %s...\n", t.baseURL)
+			fmt.Fprintf(Log, "[AUTH] Authenticating with Spacelift at %s...\n", t.baseURL)
 		}
 		if err := t.refreshToken(); err != nil {
 			return nil, fmt.Errorf("failed to authenticate: %w", err)
 		}
 		if Verbose {
-			fmt.Printf("[AUTH] Successfully authenticated! Token expires in ~55 minutes\n")
+			fmt.Fprintf(Log, "[AUTH] Successfully authenticated! Token expires in ~55 minutes\n")
 		}
 	}
 
@@ -150,7 +158,7 @@ func New(cfg config.AccountConfig) (*Client, error) {
 		baseURL:   cfg.URL,
 		keyID:     cfg.KeyID,
 		secretKey: cfg.SecretKey,
-		base:      http.DefaultTransport,
+		base:      Transport,
 	}
 
 	httpClient := &http.Client{
@@ -184,50 +192,25 @@ func (c *Client) URL() string {
 
 // EnableExternalStateAccess enables external state access on a stack.
 func (c *Client) EnableExternalStateAccess(ctx context.Context, stack models.Stack) error {
-	// The mutation needs all required fields from StackInput
-	mutation := `mutation EnableExternalState(
-		$id: ID!,
-		$administrative: Boolean!,
-		$branch: String!,
-		$name: String!,
-		$repository: String!
-	) {
-		stackUpdate(id: $id, input: {
-			administrative: $administrative
-			branch: $branch
-			name: $name
-			repository: $repository
-			vendorConfig: {
-				terraform: {
-					externalStateAccessEnabled: true
-				}
-			}
-		}) {
-			id
-		}
-	}`
-
-	var result struct {
-		StackUpdate struct {
-			ID string `json:"id"`
-		} `json:"stackUpdate"`
-	}
-
-	variables := map[string]interface{}{
-		"id":             stack.ID,
-		"administrative": stack.Administrative,
-		"branch":         stack.Branch,
-		"name":           stack.Name,
-		"repository":     stack.Repository,
-	}
-
-	if err := c.rawMutate(ctx, mutation, variables, &result); err != nil {
+	if err := c.setExternalStateAccess(ctx, stack, true); err != nil {
 		return fmt.Errorf("failed to enable external state access: %w", err)
 	}
+	return nil
+}
 
+// DisableExternalStateAccess disables external state access on a stack.
+func (c *Client) DisableExternalStateAccess(ctx context.Context, stack models.Stack) error {
+	if err := c.setExternalStateAccess(ctx, stack, false); err != nil {
+		return fmt.Errorf("failed to disable external state access: %w", err)
+	}
 	return nil
 }
 
+// setExternalStateAccess turns external state access on a stack on or off.
+func (c *Client) setExternalStateAccess(ctx context.Context, stack models.Stack, enabled bool) error {
+	return c.updateStack(ctx, stack.ID, func(s *models.Stack) { s.ExternalStateAccessEnabled = enabled })
+}
+
 // GetStateDownloadURL gets a pre-signed URL to download stack state.
 func (c *Client) GetStateDownloadURL(ctx context.Context, stackID string) (string, error) {
 	mutation := `mutation GetStateDownloadURL($stackId: ID!) {
@@ -388,43 +371,261 @@ func UploadStateToURL(ctx context.Context, uploadURL string, data io.Reader, con
 
 // EnableStack enables a disabled stack.
 func (c *Client) EnableStack(ctx context.Context, stack models.Stack) error {
-	mutation := `mutation EnableStack(
-		$id: ID!,
-		$administrative: Boolean!,
-		$branch: String!,
-		$name: String!,
-		$repository: String!
-	) {
-		stackUpdate(id: $id, input: {
-			administrative: $administrative
-			branch: $branch
-			name: $name
-			repository: $repository
-			isDisabled: false
-		}) {
+	if err := c.updateStack(ctx, stack.ID, func(s *models.Stack) { s.IsDisabled = false }); err != nil {
+		return fmt.Errorf("failed to enable stack: %w", err)
+	}
+	return nil
+}
+
+// DisableStack disables a stack so that no runs can be triggered on it.
+func (c *Client) DisableStack(ctx context.Context, stack models.Stack) error {
+	if err := c.updateStack(ctx, stack.ID, func(s *models.Stack) { s.IsDisabled = true }); err != nil {
+		return fmt.Errorf("failed to disable stack: %w", err)
+	}
+	return nil
+}
+
+// SetStackLabels replaces the labels of a stack.
+func (c *Client) SetStackLabels(ctx context.Context, stack models.Stack, labels []string) error {
+	if err := c.updateStack(ctx, stack.ID, func(s *models.Stack) { s.Labels = labels }); err != nil {
+		return fmt.Errorf("failed to set stack labels: %w", err)
+	}
+	return nil
+}
+
+// updateStack changes a stack with stackUpdate. stackUpdate replaces the
+// whole stack input and resets any field left out, so the stack is fetched
+// first and sent back with only the changes made by change.
+func (c *Client) updateStack(ctx context.Context, stackID string, change func(*models.Stack)) error {
+	query := `query StackForUpdate($id: ID!) {
+		stack(id: $id) {
 			id
+			name
+			description
+			space
+			branch
+			repository
+			namespace
+			projectRoot
+			provider
+			runnerImage
+			administrative
+			autodeploy
+			autoretry
+			localPreviewEnabled
+			protectFromDeletion
+			isDisabled
+			labels
+			additionalProjectGlobs
+			hooks {
+				afterApply
+				beforeApply
+				afterInit
+				beforeInit
+				afterPlan
+				beforePlan
+				afterPerform
+				beforePerform
+				afterDestroy
+				beforeDestroy
+				afterRun
+			}
+			workerPool {
+				id
+			}
+			vcsIntegration {
+				id
+			}
+			vendorConfig {
+				__typename
+				... on StackConfigVendorTerraform {
+					version
+					workflowTool
+					externalStateAccessEnabled
+				}
+				... on StackConfigVendorTerragrunt {
+					terraformVersion
+					terragruntVersion
+					tool
+				}
+				... on StackConfigVendorAnsible {
+					playbook
+				}
+				... on StackConfigVendorKubernetes {
+					namespace
+					kubectlVersion
+				}
+				... on StackConfigVendorPulumi {
+					loginURL
+					stackName
+				}
+				... on StackConfigVendorCloudFormation {
+					entryTemplateFile
+					region
+					stackName
+					templateBucket
+				}
+			}
 		}
 	}`
 
 	var result struct {
-		StackUpdate struct {
+		Stack *struct {
+			models.Stack
+			WorkerPool *struct {
+				ID string `json:"id"`
+			} `json:"workerPool"`
+			VCSIntegration *struct {
+				ID string `json:"id"`
+			} `json:"vcsIntegration"`
+			VendorConfig map[string]interface{} `json:"vendorConfig"`
+		} `json:"stack"`
+	}
+
+	// rawMutate posts any GraphQL document, queries included
+	if err := c.rawMutate(ctx, query, map[string]interface{}{"id": stackID}, &result); err != nil {
+		return err
+	}
+	if result.Stack == nil {
+		return fmt.Errorf("stack %s not found", stackID)
+	}
+
+	stack := result.Stack.Stack
+	vendorType, _ := result.Stack.VendorConfig["__typename"].(string)
+	vendorConfig := make(map[string]interface{})
+	for key, value := range result.Stack.VendorConfig {
+		if key != "__typename" && value != nil {
+			vendorConfig[key] = value
+		}
+	}
+	if enabled, ok := vendorConfig["externalStateAccessEnabled"].(bool); ok {
+		stack.ExternalStateAccessEnabled = enabled
+	}
+
+	change(&stack)
+
+	input := stackInput(stack)
+	input["administrative"] = stack.Administrative
+	input["isDisabled"] = stack.IsDisabled
+	if stack.Provider != "" {
+		input["provider"] = stack.Provider
+	}
+	if result.Stack.WorkerPool != nil {
+		input["workerPool"] = result.Stack.WorkerPool.ID
+	}
+	if result.Stack.VCSIntegration != nil {
+		input["vcsIntegrationId"] = result.Stack.VCSIntegration.ID
+	}
+	if key, ok := vendorInputKeys[vendorType]; ok {
+		if key == "terraform" {
+			vendorConfig["externalStateAccessEnabled"] = stack.ExternalStateAccessEnabled
+		}
+		input["vendorConfig"] = map[string]interface{}{key: vendorConfig}
+	}
+
+	mutation := `mutation UpdateStack($id: ID!, $input: StackInput!) {
+		stackUpdate(id: $id, input: $input) {
+			id
+		}
+	}`
+
+	variables := map[string]interface{}{
+		"id":    stackID,
+		"input": input,
+	}
+
+	return c.rawMutate(ctx, mutation, variables, nil)
+}
+
+// vendorInputKeys maps the vendor config types of the schema to their key
+// in the vendorConfig input.
+var vendorInputKeys = map[string]string{
+	"StackConfigVendorTerraform":      "terraform",
+	"StackConfigVendorTerragrunt":     "terragrunt",
+	"StackConfigVendorAnsible":        "ansible",
+	"StackConfigVendorKubernetes":     "kubernetes",
+	"StackConfigVendorPulumi":         "pulumi",
+	"StackConfigVendorCloudFormation": "cloudFormation",
+}
+
+// Run is the state of a stack run.
+type Run struct {
+	ID       string   `json:"id"`
+	State    string   `json:"state"`    // e.g. QUEUED, PLANNING, FINISHED, FAILED
+	Finished bool     `json:"finished"` // The run reached a terminal state
+	Delta    RunDelta `json:"delta"`
+}
+
+// RunDelta summarizes the changes planned by a run.
+type RunDelta struct {
+	Added   int `json:"added"`
+	Changed int `json:"changed"`
+	Deleted int `json:"deleted"`
+}
+
+// TriggerProposedRun triggers a proposed run (plan only) on a stack and
+// returns the run ID.
+func (c *Client) TriggerProposedRun(ctx context.Context, stackID string) (string, error) {
+	mutation := `mutation TriggerProposedRun($stack: ID!) {
+		runTrigger(stack: $stack, runType: PROPOSED) {
+			id
+		}
+	}`
+
+	var result struct {
+		RunTrigger struct {
 			ID string `json:"id"`
-		} `json:"stackUpdate"`
+		} `json:"runTrigger"`
 	}
 
 	variables := map[string]interface{}{
-		"id":             stack.ID,
-		"administrative": stack.Administrative,
-		"branch":         stack.Branch,
-		"name":           stack.Name,
-		"repository":     stack.Repository,
+		"stack": stackID,
 	}
 
 	if err := c.rawMutate(ctx, mutation, variables, &result); err != nil {
-		return fmt.Errorf("failed to enable stack: %w", err)
+		return "", fmt.Errorf("failed to trigger run: %w", err)
 	}
 
-	return nil
+	return result.RunTrigger.ID, nil
+}
+
+// GetRun returns the state of a run.
+func (c *Client) GetRun(ctx context.Context, stackID, runID string) (*Run, error) {
+	query := `query GetRun($stack: ID!, $run: ID!) {
+		stack(id: $stack) {
+			run(id: $run) {
+				id
+				state
+				finished
+				delta {
+					added
+					changed
+					deleted
+				}
+			}
+		}
+	}`
+
+	var result struct {
+		Stack *struct {
+			Run *Run `json:"run"`
+		} `json:"stack"`
+	}
+
+	variables := map[string]interface{}{
+		"stack": stackID,
+		"run":   runID,
+	}
+
+	// rawMutate posts any GraphQL document, queries included
+	if err := c.rawMutate(ctx, query, variables, &result); err != nil {
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+	if result.Stack == nil || result.Stack.Run == nil {
+		return nil, fmt.Errorf("run %s not found on stack %s", runID, stackID)
+	}
+
+	return result.Stack.Run, nil
 }
 
 // rawMutate executes a raw GraphQL mutation string.
@@ -454,7 +655,7 @@ func (c *Client) rawMutate(ctx context.Context, mutation string, variables map[s
 			baseURL:   c.config.URL,
 			keyID:     c.config.KeyID,
 			secretKey: c.config.SecretKey,
-			base:      http.DefaultTransport,
+			base:      Transport,
 		},
 		Timeout: 30 * time.Second,
 	}
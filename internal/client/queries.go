@@ -36,6 +36,7 @@ type StacksQuery struct {
 		ProtectFromDeletion    graphql.Boolean  `graphql:"protectFromDeletion"`
 		IsDisabled             graphql.Boolean  `graphql:"isDisabled"`
 		ManagesStateFile       graphql.Boolean  `graphql:"managesStateFile"`
+		StateSetAt             *graphql.Int     `graphql:"stateSetAt"`
 		Labels                 []graphql.String `graphql:"labels"`
 		AdditionalProjectGlobs []graphql.String `graphql:"additionalProjectGlobs"`
 		VendorConfig struct {
@@ -214,3 +215,42 @@ type AzureIntegrationAttachmentsQuery struct {
 	} `graphql:"azureIntegration(id: $id)"`
 }
 
+
+// SearchInput is the input of the search queries, which page through
+// results with a cursor.
+type SearchInput struct {
+	First *graphql.Int    `json:"first,omitempty"`
+	After *graphql.String `json:"after,omitempty"`
+}
+
+// BlueprintsQuery fetches a page of blueprints.
+type BlueprintsQuery struct {
+	SearchBlueprints struct {
+		Edges []struct {
+			Node struct {
+				ID    graphql.ID     `graphql:"id"`
+				Name  graphql.String `graphql:"name"`
+				State graphql.String `graphql:"state"`
+				Space struct {
+					ID graphql.ID `graphql:"id"`
+				} `graphql:"space"`
+			} `graphql:"node"`
+		} `graphql:"edges"`
+		PageInfo struct {
+			EndCursor   graphql.String  `graphql:"endCursor"`
+			HasNextPage graphql.Boolean `graphql:"hasNextPage"`
+		} `graphql:"pageInfo"`
+	} `graphql:"searchBlueprints(input: $input)"`
+}
+
+// RolesQuery is the GraphQL query for fetching custom roles and the stacks
+// they are bound to.
+type RolesQuery struct {
+	Roles []struct {
+		ID                graphql.ID     `graphql:"id"`
+		Name              graphql.String `graphql:"name"`
+		StackRoleBindings []struct {
+			StackID graphql.ID `graphql:"stackID"`
+		} `graphql:"stackRoleBindings"`
+	} `graphql:"roles"`
+}
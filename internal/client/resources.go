@@ -0,0 +1,595 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// CreateContext creates a context without config elements and returns its ID.
+func (c *Client) CreateContext(ctx context.Context, sc models.Context) (string, error) {
+	mutation := `mutation CreateContext($input: ContextInput!) {
+		contextCreateV2(input: $input) {
+			id
+		}
+	}`
+
+	var result struct {
+		ContextCreateV2 struct {
+			ID string `json:"id"`
+		} `json:"contextCreateV2"`
+	}
+
+	variables := map[string]interface{}{
+		"input": contextInput(sc),
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, &result); err != nil {
+		return "", fmt.Errorf("failed to create context %s: %w", sc.Name, err)
+	}
+
+	return result.ContextCreateV2.ID, nil
+}
+
+// UpdateContext updates a context's name, description, labels, space and
+// hooks. Config elements are left untouched.
+func (c *Client) UpdateContext(ctx context.Context, id string, sc models.Context) error {
+	mutation := `mutation UpdateContext($id: ID!, $input: ContextInput!) {
+		contextUpdateV2(id: $id, input: $input, replaceConfigElements: false) {
+			id
+		}
+	}`
+
+	variables := map[string]interface{}{
+		"id":    id,
+		"input": contextInput(sc),
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to update context %s: %w", sc.Name, err)
+	}
+
+	return nil
+}
+
+// contextInput builds a ContextInput from a context. The context's Space
+// must already be a destination space ID.
+func contextInput(sc models.Context) map[string]interface{} {
+	input := map[string]interface{}{
+		"name":   sc.Name,
+		"space":  sc.Space,
+		"labels": nonNil(sc.Labels),
+		"hooks": map[string]interface{}{
+			"afterApply":    nonNil(sc.Hooks.AfterApply),
+			"beforeApply":   nonNil(sc.Hooks.BeforeApply),
+			"afterInit":     nonNil(sc.Hooks.AfterInit),
+			"beforeInit":    nonNil(sc.Hooks.BeforeInit),
+			"afterPlan":     nonNil(sc.Hooks.AfterPlan),
+			"beforePlan":    nonNil(sc.Hooks.BeforePlan),
+			"afterPerform":  nonNil(sc.Hooks.AfterPerform),
+			"beforePerform": nonNil(sc.Hooks.BeforePerform),
+			"afterDestroy":  nonNil(sc.Hooks.AfterDestroy),
+			"beforeDestroy": nonNil(sc.Hooks.BeforeDestroy),
+			"afterRun":      nonNil(sc.Hooks.AfterRun),
+		},
+	}
+	if sc.Description != nil {
+		input["description"] = *sc.Description
+	}
+	return input
+}
+
+// SetContextConfig adds or replaces a config element on a context.
+func (c *Client) SetContextConfig(ctx context.Context, contextID string, element models.ConfigElement) error {
+	mutation := `mutation SetContextConfig($context: ID!, $config: ConfigInput!) {
+		contextConfigAdd(context: $context, config: $config) {
+			id
+		}
+	}`
+
+	// The API takes mounted file content base64 encoded
+	value := element.Value
+	if element.Type == "FILE_MOUNT" {
+		value = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+
+	variables := map[string]interface{}{
+		"context": contextID,
+		"config": map[string]interface{}{
+			"id":          element.ID,
+			"type":        element.Type,
+			"value":       value,
+			"writeOnly":   element.WriteOnly,
+			"description": element.Description,
+		},
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to set %s on context %s: %w", element.ID, contextID, err)
+	}
+
+	return nil
+}
+
+// DeleteContextConfig removes a config element from a context.
+func (c *Client) DeleteContextConfig(ctx context.Context, contextID, elementID string) error {
+	mutation := `mutation DeleteContextConfig($context: ID!, $id: ID!) {
+		contextConfigDelete(context: $context, id: $id) {
+			id
+		}
+	}`
+
+	variables := map[string]interface{}{
+		"context": contextID,
+		"id":      elementID,
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to delete %s from context %s: %w", elementID, contextID, err)
+	}
+
+	return nil
+}
+
+// CreatePolicy creates a policy and returns its ID. The policy's Space must
+// already be a destination space ID.
+func (c *Client) CreatePolicy(ctx context.Context, policy models.Policy) (string, error) {
+	mutation := `mutation CreatePolicy(
+		$name: String!,
+		$body: String!,
+		$type: PolicyType!,
+		$labels: [String!],
+		$space: ID,
+		$description: String
+	) {
+		policyCreate(name: $name, body: $body, type: $type, labels: $labels, space: $space, description: $description) {
+			id
+		}
+	}`
+
+	var result struct {
+		PolicyCreate struct {
+			ID string `json:"id"`
+		} `json:"policyCreate"`
+	}
+
+	variables := policyVariables(policy)
+	variables["type"] = policy.Type
+
+	if err := c.rawMutate(ctx, mutation, variables, &result); err != nil {
+		return "", fmt.Errorf("failed to create policy %s: %w", policy.Name, err)
+	}
+
+	return result.PolicyCreate.ID, nil
+}
+
+// UpdatePolicy updates a policy's name, body, labels, space and description.
+func (c *Client) UpdatePolicy(ctx context.Context, id string, policy models.Policy) error {
+	mutation := `mutation UpdatePolicy(
+		$id: ID!,
+		$name: String!,
+		$body: String!,
+		$labels: [String!],
+		$space: ID,
+		$description: String
+	) {
+		policyUpdate(id: $id, name: $name, body: $body, labels: $labels, space: $space, description: $description) {
+			id
+		}
+	}`
+
+	variables := policyVariables(policy)
+	variables["id"] = id
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to update policy %s: %w", policy.Name, err)
+	}
+
+	return nil
+}
+
+// policyVariables returns the variables shared by policy mutations.
+func policyVariables(policy models.Policy) map[string]interface{} {
+	variables := map[string]interface{}{
+		"name":   policy.Name,
+		"body":   policy.Body,
+		"labels": nonNil(policy.Labels),
+		"space":  policy.Space,
+	}
+	if policy.Description != nil {
+		variables["description"] = *policy.Description
+	}
+	return variables
+}
+
+// AttachContext attaches a context to a stack and returns the attachment ID.
+func (c *Client) AttachContext(ctx context.Context, contextID, stackID string, priority int) (string, error) {
+	mutation := `mutation AttachContext($id: ID!, $stack: ID!, $priority: Int!) {
+		contextAttach(id: $id, stack: $stack, priority: $priority) {
+			id
+		}
+	}`
+
+	var result struct {
+		ContextAttach struct {
+			ID string `json:"id"`
+		} `json:"contextAttach"`
+	}
+
+	variables := map[string]interface{}{
+		"id":       contextID,
+		"stack":    stackID,
+		"priority": priority,
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, &result); err != nil {
+		return "", fmt.Errorf("failed to attach context %s to stack %s: %w", contextID, stackID, err)
+	}
+
+	return result.ContextAttach.ID, nil
+}
+
+// DetachContext removes a context attachment by its attachment ID.
+func (c *Client) DetachContext(ctx context.Context, attachmentID string) error {
+	mutation := `mutation DetachContext($id: ID!) {
+		contextDetach(id: $id) {
+			id
+		}
+	}`
+
+	variables := map[string]interface{}{
+		"id": attachmentID,
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to detach context: %w", err)
+	}
+
+	return nil
+}
+
+// AttachPolicy attaches a policy to a stack and returns the attachment ID.
+func (c *Client) AttachPolicy(ctx context.Context, policyID, stackID string) (string, error) {
+	mutation := `mutation AttachPolicy($id: ID!, $stack: ID!) {
+		policyAttach(id: $id, stack: $stack) {
+			id
+		}
+	}`
+
+	var result struct {
+		PolicyAttach struct {
+			ID string `json:"id"`
+		} `json:"policyAttach"`
+	}
+
+	variables := map[string]interface{}{
+		"id":    policyID,
+		"stack": stackID,
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, &result); err != nil {
+		return "", fmt.Errorf("failed to attach policy %s to stack %s: %w", policyID, stackID, err)
+	}
+
+	return result.PolicyAttach.ID, nil
+}
+
+// DetachPolicy removes a policy attachment by its attachment ID.
+func (c *Client) DetachPolicy(ctx context.Context, attachmentID string) error {
+	mutation := `mutation DetachPolicy($id: ID!) {
+		policyDetach(id: $id) {
+			id
+		}
+	}`
+
+	variables := map[string]interface{}{
+		"id": attachmentID,
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to detach policy: %w", err)
+	}
+
+	return nil
+}
+
+// CreateSpace creates a space and returns its ID. The space's ParentSpace
+// must already be a destination space ID.
+func (c *Client) CreateSpace(ctx context.Context, space models.Space) (string, error) {
+	mutation := `mutation CreateSpace($input: SpaceInput!) {
+		spaceCreate(input: $input) {
+			id
+		}
+	}`
+
+	var result struct {
+		SpaceCreate struct {
+			ID string `json:"id"`
+		} `json:"spaceCreate"`
+	}
+
+	parent := "root"
+	if space.ParentSpace != nil && *space.ParentSpace != "" {
+		parent = *space.ParentSpace
+	}
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"name":            space.Name,
+			"description":     space.Description,
+			"parentSpace":     parent,
+			"inheritEntities": space.InheritEntities,
+			"labels":          nonNil(space.Labels),
+		},
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, &result); err != nil {
+		return "", fmt.Errorf("failed to create space %s: %w", space.Name, err)
+	}
+
+	return result.SpaceCreate.ID, nil
+}
+
+// StackVCS holds the destination VCS settings of a stack being created.
+type StackVCS struct {
+	Provider      string // GITHUB_ENTERPRISE, GITLAB, ...; empty for the default provider
+	IntegrationID string // VCS integration ID; empty for the default integration
+	Namespace     string
+	Repository    string
+}
+
+// CreateStack creates a Terraform, OpenTofu or Terragrunt stack and returns
+// its ID. The stack's Space must already be a destination space ID. The
+// deprecated administrative flag is not sent; bind a role to the stack with
+// AttachRoleToStack instead.
+func (c *Client) CreateStack(ctx context.Context, stack models.Stack, vcs StackVCS) (string, error) {
+	mutation := `mutation CreateStack($input: StackInput!, $manageState: Boolean!) {
+		stackCreate(input: $input, manageState: $manageState) {
+			id
+		}
+	}`
+
+	var result struct {
+		StackCreate struct {
+			ID string `json:"id"`
+		} `json:"stackCreate"`
+	}
+
+	input := stackInput(stack)
+	input["namespace"] = vcs.Namespace
+	input["repository"] = vcs.Repository
+	if vcs.Provider != "" {
+		input["provider"] = vcs.Provider
+	}
+	if vcs.IntegrationID != "" {
+		input["vcsIntegrationId"] = vcs.IntegrationID
+	}
+
+	if stack.IsTerragrunt() {
+		terragrunt := map[string]interface{}{}
+		if stack.TerraformVersion != nil {
+			terragrunt["terraformVersion"] = *stack.TerraformVersion
+		}
+		if stack.TerragruntVersion != nil {
+			terragrunt["terragruntVersion"] = *stack.TerragruntVersion
+		}
+		input["vendorConfig"] = map[string]interface{}{"terragrunt": terragrunt}
+	} else {
+		terraform := map[string]interface{}{}
+		if stack.TerraformVersion != nil {
+			terraform["version"] = *stack.TerraformVersion
+		}
+		if stack.WorkflowTool != nil {
+			terraform["workflowTool"] = *stack.WorkflowTool
+		}
+		input["vendorConfig"] = map[string]interface{}{"terraform": terraform}
+	}
+
+	variables := map[string]interface{}{
+		"input":       input,
+		"manageState": stack.ManagesStateFile,
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, &result); err != nil {
+		return "", fmt.Errorf("failed to create stack %s: %w", stack.Name, err)
+	}
+
+	return result.StackCreate.ID, nil
+}
+
+// stackInput returns the StackInput fields shared by stackCreate and
+// stackUpdate. It leaves out the VCS provider and vendor config, which the
+// callers set.
+func stackInput(stack models.Stack) map[string]interface{} {
+	input := map[string]interface{}{
+		"name":                   stack.Name,
+		"space":                  stack.Space,
+		"branch":                 stack.Branch,
+		"namespace":              stack.Namespace,
+		"repository":             stack.Repository,
+		"labels":                 nonNil(stack.Labels),
+		"autodeploy":             stack.Autodeploy,
+		"autoretry":              stack.Autoretry,
+		"localPreviewEnabled":    stack.LocalPreviewEnabled,
+		"protectFromDeletion":    stack.ProtectFromDeletion,
+		"additionalProjectGlobs": nonNil(stack.AdditionalProjectGlobs),
+		"beforeInit":             nonNil(stack.Hooks.BeforeInit),
+		"afterInit":              nonNil(stack.Hooks.AfterInit),
+		"beforePlan":             nonNil(stack.Hooks.BeforePlan),
+		"afterPlan":              nonNil(stack.Hooks.AfterPlan),
+		"beforeApply":            nonNil(stack.Hooks.BeforeApply),
+		"afterApply":             nonNil(stack.Hooks.AfterApply),
+		"beforeDestroy":          nonNil(stack.Hooks.BeforeDestroy),
+		"afterDestroy":           nonNil(stack.Hooks.AfterDestroy),
+		"beforePerform":          nonNil(stack.Hooks.BeforePerform),
+		"afterPerform":           nonNil(stack.Hooks.AfterPerform),
+		"afterRun":               nonNil(stack.Hooks.AfterRun),
+	}
+	if stack.Description != nil {
+		input["description"] = *stack.Description
+	}
+	if stack.ProjectRoot != nil {
+		input["projectRoot"] = *stack.ProjectRoot
+	}
+	if stack.RunnerImage != nil {
+		input["runnerImage"] = *stack.RunnerImage
+	}
+	return input
+}
+
+// AddStackDependency makes a stack depend on another stack.
+func (c *Client) AddStackDependency(ctx context.Context, stackID, dependsOnStackID string) error {
+	mutation := `mutation AddStackDependency($input: StackDependencyInput!) {
+		stackDependenciesCreate(input: $input) {
+			id
+		}
+	}`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"stackId":          stackID,
+			"dependsOnStackId": dependsOnStackID,
+		},
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to add dependency %s -> %s: %w", stackID, dependsOnStackID, err)
+	}
+
+	return nil
+}
+
+// AttachAWSIntegration attaches an AWS integration to a stack.
+func (c *Client) AttachAWSIntegration(ctx context.Context, integrationID, stackID string, read, write bool) error {
+	mutation := `mutation AttachAWSIntegration($id: ID!, $stack: ID!, $read: Boolean!, $write: Boolean!) {
+		awsIntegrationAttach(id: $id, stack: $stack, read: $read, write: $write) {
+			id
+		}
+	}`
+
+	variables := map[string]interface{}{
+		"id":    integrationID,
+		"stack": stackID,
+		"read":  read,
+		"write": write,
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to attach AWS integration %s to stack %s: %w", integrationID, stackID, err)
+	}
+
+	return nil
+}
+
+// AttachAzureIntegration attaches an Azure integration to a stack.
+func (c *Client) AttachAzureIntegration(ctx context.Context, integrationID, stackID string, attachment models.AzureIntegrationAttachment) error {
+	mutation := `mutation AttachAzureIntegration($id: ID!, $stack: ID!, $read: Boolean!, $write: Boolean!, $subscriptionId: String) {
+		azureIntegrationAttach(id: $id, stack: $stack, read: $read, write: $write, subscriptionId: $subscriptionId) {
+			id
+		}
+	}`
+
+	variables := map[string]interface{}{
+		"id":    integrationID,
+		"stack": stackID,
+		"read":  attachment.Read,
+		"write": attachment.Write,
+	}
+	if attachment.SubscriptionID != nil {
+		variables["subscriptionId"] = *attachment.SubscriptionID
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to attach Azure integration %s to stack %s: %w", integrationID, stackID, err)
+	}
+
+	return nil
+}
+
+// Role is a custom role and the stacks bound to it.
+type Role struct {
+	ID     string
+	Name   string
+	Stacks []string // IDs of the stacks the role is bound to
+}
+
+// ListRoles returns the custom roles of the account.
+func (c *Client) ListRoles(ctx context.Context) ([]Role, error) {
+	var query RolesQuery
+	if err := c.Query(ctx, &query, nil); err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	roles := make([]Role, 0, len(query.Roles))
+	for _, r := range query.Roles {
+		role := Role{ID: fmt.Sprint(r.ID), Name: string(r.Name)}
+		for _, b := range r.StackRoleBindings {
+			role.Stacks = append(role.Stacks, fmt.Sprint(b.StackID))
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// CreateRole creates a custom role and returns its ID.
+func (c *Client) CreateRole(ctx context.Context, name string, actions []string) (string, error) {
+	mutation := `mutation CreateRole($input: RoleInput!) {
+		roleCreate(input: $input) {
+			id
+		}
+	}`
+
+	var result struct {
+		RoleCreate struct {
+			ID string `json:"id"`
+		} `json:"roleCreate"`
+	}
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"name":    name,
+			"actions": nonNil(actions),
+		},
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, &result); err != nil {
+		return "", fmt.Errorf("failed to create role %s: %w", name, err)
+	}
+
+	return result.RoleCreate.ID, nil
+}
+
+// AttachRoleToStack binds a role to a stack in a space, the API equivalent
+// of a spacelift_role_attachment with a stack_id.
+func (c *Client) AttachRoleToStack(ctx context.Context, roleID, stackID, spaceID string) error {
+	mutation := `mutation AttachRoleToStack($input: StackRoleBindingInput!) {
+		stackRoleBindingCreate(input: $input) {
+			id
+		}
+	}`
+
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"roleID":  roleID,
+			"stackID": stackID,
+			"spaceID": spaceID,
+		},
+	}
+
+	if err := c.rawMutate(ctx, mutation, variables, nil); err != nil {
+		return fmt.Errorf("failed to attach role %s to stack %s: %w", roleID, stackID, err)
+	}
+
+	return nil
+}
+
+// nonNil returns an empty list instead of nil, which GraphQL would reject
+// for non-null list inputs.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
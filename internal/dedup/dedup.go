@@ -0,0 +1,326 @@
+// Package dedup finds policies and contexts that are copies of each other and
+// merges each set of copies into one resource in a space that every stack
+// using them can see.
+package dedup
+
+import (
+	"fmt"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// Kinds of merged resources.
+const (
+	KindPolicy  = "policy"
+	KindContext = "context"
+)
+
+// Merge is a set of identical resources and how they are merged.
+type Merge struct {
+	Kind        string   `json:"kind"`
+	Keep        string   `json:"keep"`       // ID of the resource that is kept
+	Duplicates  []string `json:"duplicates"` // IDs of the resources merged into Keep
+	FromSpace   string   `json:"fromSpace"`  // Space of Keep before the merge
+	Space       string   `json:"space"`      // Space of Keep after the merge
+	Attachments int      `json:"attachments"`
+	Skipped     string   `json:"skipped,omitempty"` // Why the resources cannot be merged
+}
+
+// Report lists the merges found in a manifest.
+type Report struct {
+	Merges []Merge `json:"merges"`
+}
+
+// Merged returns the merges that can be applied.
+func (r *Report) Merged() []Merge {
+	var merged []Merge
+	for _, merge := range r.Merges {
+		if merge.Skipped == "" {
+			merged = append(merged, merge)
+		}
+	}
+	return merged
+}
+
+// Find groups the policies with the same body, type and engine, and the
+// contexts with the same config and hooks. Each group is merged into the
+// deepest space that holds all of its resources, as long as every stack
+// attached to one of them inherits from that space.
+//
+// Resources with autoattach labels are left alone, since moving them would
+// attach them to more stacks. Contexts with secrets are grouped by secret key
+// but skipped, since secret values cannot be read and compared.
+func Find(m *discovery.Manifest) *Report {
+	spaces := newSpaceTree(m)
+	report := &Report{Merges: []Merge{}}
+
+	attached := make(map[string][]models.Stack)
+	for _, stack := range m.Stacks {
+		for _, a := range stack.AttachedPolicies {
+			attached[KindPolicy+" "+a.PolicyID] = append(attached[KindPolicy+" "+a.PolicyID], stack)
+		}
+		for _, a := range stack.AttachedContexts {
+			attached[KindContext+" "+a.ContextID] = append(attached[KindContext+" "+a.ContextID], stack)
+		}
+	}
+
+	var policies []resource
+	for _, p := range m.Policies {
+		if !discovery.Autoattached(p.Labels) {
+			key := fmt.Sprintf("%q %q %q", p.Type, p.EngineType, p.Body)
+			policies = append(policies, resource{id: p.ID, space: p.Space, key: key})
+		}
+	}
+	var contexts []resource
+	for _, c := range m.Contexts {
+		if !discovery.Autoattached(c.Labels) && len(c.Config) > 0 {
+			contexts = append(contexts, resource{id: c.ID, space: c.Space, key: discovery.ContextConfigKey(c), secrets: c.HasSecrets()})
+		}
+	}
+
+	for _, group := range groups(policies) {
+		report.Merges = append(report.Merges, spaces.merge(KindPolicy, group, attached))
+	}
+	for _, group := range groups(contexts) {
+		merge := spaces.merge(KindContext, group, attached)
+		if group[0].secrets && merge.Skipped == "" {
+			merge.Skipped = "has secrets, whose values cannot be compared; merge by hand once they are known to match"
+		}
+		report.Merges = append(report.Merges, merge)
+	}
+	return report
+}
+
+// Apply returns a copy of m with the mergeable groups of the report merged:
+// the kept resource moves to its new space and takes the labels of its
+// duplicates, the duplicates are removed and their attachments are moved to
+// the kept resource.
+func (r *Report) Apply(m *discovery.Manifest) (*discovery.Manifest, error) {
+	out, err := m.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, merge := range r.Merged() {
+		merged := make(map[string]bool)
+		for _, id := range merge.Duplicates {
+			merged[id] = true
+		}
+
+		switch merge.Kind {
+		case KindPolicy:
+			var labels []string
+			var policies []models.Policy
+			for _, p := range out.Policies {
+				if merged[p.ID] {
+					labels = append(labels, p.Labels...)
+					continue
+				}
+				policies = append(policies, p)
+			}
+			for i := range policies {
+				if policies[i].ID == merge.Keep {
+					policies[i].Space = merge.Space
+					policies[i].Labels = union(policies[i].Labels, labels)
+				}
+			}
+			out.Policies = policies
+
+			for i := range out.Stacks {
+				stack := &out.Stacks[i]
+				var attachments []models.PolicyAttachment
+				seen := false
+				for _, a := range stack.AttachedPolicies {
+					if merged[a.PolicyID] || a.PolicyID == merge.Keep {
+						if seen {
+							continue
+						}
+						seen = true
+						a.PolicyID = merge.Keep
+					}
+					attachments = append(attachments, a)
+				}
+				stack.AttachedPolicies = attachments
+			}
+
+		case KindContext:
+			var labels []string
+			var contexts []models.Context
+			for _, c := range out.Contexts {
+				if merged[c.ID] {
+					labels = append(labels, c.Labels...)
+					continue
+				}
+				contexts = append(contexts, c)
+			}
+			for i := range contexts {
+				if contexts[i].ID == merge.Keep {
+					contexts[i].Space = merge.Space
+					contexts[i].Labels = union(contexts[i].Labels, labels)
+				}
+			}
+			out.Contexts = contexts
+
+			// A stack with several of the copies keeps the attachment with
+			// the highest precedence, which is the lowest priority
+			for i := range out.Stacks {
+				stack := &out.Stacks[i]
+				var attachments []models.ContextAttachment
+				first := -1
+				for _, a := range stack.AttachedContexts {
+					if !merged[a.ContextID] && a.ContextID != merge.Keep {
+						attachments = append(attachments, a)
+						continue
+					}
+					a.ContextID = merge.Keep
+					if first < 0 {
+						first = len(attachments)
+						attachments = append(attachments, a)
+					} else if a.Priority < attachments[first].Priority {
+						attachments[first].Priority = a.Priority
+					}
+				}
+				stack.AttachedContexts = attachments
+			}
+		}
+	}
+	return out, nil
+}
+
+// resource is a policy or context being grouped.
+type resource struct {
+	id      string
+	space   string
+	key     string // Equal for identical resources
+	secrets bool
+}
+
+// groups returns the sets of more than one resource with the same key, in
+// manifest order.
+func groups(resources []resource) [][]resource {
+	var order []string
+	byKey := make(map[string][]resource)
+	for _, r := range resources {
+		if _, seen := byKey[r.key]; !seen {
+			order = append(order, r.key)
+		}
+		byKey[r.key] = append(byKey[r.key], r)
+	}
+
+	var out [][]resource
+	for _, key := range order {
+		if group := byKey[key]; len(group) > 1 {
+			out = append(out, group)
+		}
+	}
+	return out
+}
+
+// spaceTree answers which spaces can see resources of which other spaces.
+type spaceTree struct {
+	manifest *discovery.Manifest
+	parents  map[string]string
+	inherits map[string]bool
+}
+
+func newSpaceTree(m *discovery.Manifest) *spaceTree {
+	t := &spaceTree{manifest: m, parents: make(map[string]string), inherits: make(map[string]bool)}
+	for _, space := range m.Spaces {
+		if space.ParentSpace != nil {
+			t.parents[space.ID] = *space.ParentSpace
+		}
+		t.inherits[space.ID] = space.InheritEntities
+	}
+	return t
+}
+
+// visible reports whether stacks in space from can be attached to resources
+// in space to: to is from itself, or an ancestor that every space on the way
+// up inherits entities from.
+func (t *spaceTree) visible(from, to string) bool {
+	for space := from; space != to; space = t.parents[space] {
+		if space == "" || !t.inherits[space] {
+			return false
+		}
+	}
+	return true
+}
+
+// commonAncestor returns the deepest space that is, or is an ancestor of,
+// every given space.
+func (t *spaceTree) commonAncestor(spaces []string) (string, bool) {
+	for _, candidate := range t.manifest.SpaceAncestry(spaces[0]) {
+		common := true
+		for _, space := range spaces[1:] {
+			if !contains(t.manifest.SpaceAncestry(space), candidate) {
+				common = false
+				break
+			}
+		}
+		if common {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// merge plans the merge of a group of identical resources.
+func (t *spaceTree) merge(kind string, group []resource, attached map[string][]models.Stack) Merge {
+	var spaces []string
+	for _, r := range group {
+		spaces = append(spaces, r.space)
+	}
+	target, ok := t.commonAncestor(spaces)
+
+	// Keep the copy already in the target space, or else the most attached
+	keep := group[0]
+	for _, r := range group[1:] {
+		switch {
+		case keep.space == target:
+		case r.space == target, len(attached[kind+" "+r.id]) > len(attached[kind+" "+keep.id]):
+			keep = r
+		}
+	}
+
+	merge := Merge{Kind: kind, Keep: keep.id, FromSpace: keep.space, Space: target}
+	for _, r := range group {
+		if r.id != keep.id {
+			merge.Duplicates = append(merge.Duplicates, r.id)
+			merge.Attachments += len(attached[kind+" "+r.id])
+		}
+	}
+	if !ok {
+		merge.Skipped = "the copies share no ancestor space"
+		return merge
+	}
+	for _, r := range group {
+		for _, stack := range attached[kind+" "+r.id] {
+			if !t.visible(stack.Space, target) {
+				merge.Skipped = fmt.Sprintf("stack %s in space %s does not inherit from space %s", stack.Name, stack.Space, target)
+				return merge
+			}
+		}
+	}
+	return merge
+}
+
+// union returns a followed by the elements of b that are not in it.
+func union(a, b []string) []string {
+	out := append([]string(nil), a...)
+	for _, s := range b {
+		if !contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
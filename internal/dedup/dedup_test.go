@@ -0,0 +1,237 @@
+package dedup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// spaces is a tree of root, two inheriting team spaces and a space that
+// does not inherit from root.
+func spaces() []models.Space {
+	root := "root"
+	return []models.Space{
+		{ID: "root", Name: "root"},
+		{ID: "team-a", Name: "team-a", ParentSpace: &root, InheritEntities: true},
+		{ID: "team-b", Name: "team-b", ParentSpace: &root, InheritEntities: true},
+		{ID: "isolated", Name: "isolated", ParentSpace: &root},
+	}
+}
+
+// policy returns a plan policy whose ID is its name.
+func policy(id, space, body string, labels ...string) models.Policy {
+	return models.Policy{ID: id, Name: id, Space: space, Type: "PLAN", EngineType: "REGO", Body: body, Labels: labels}
+}
+
+// context returns a context whose ID is its name, with one config element.
+func context(id, space, value string, secret bool) models.Context {
+	return models.Context{ID: id, Name: id, Space: space, Config: []models.ConfigElement{
+		{ID: "REGION", Type: "ENVIRONMENT_VARIABLE", Value: value, WriteOnly: secret},
+	}}
+}
+
+// stack returns a stack attached to the given policies.
+func stack(id, space string, policies ...string) models.Stack {
+	s := models.Stack{ID: id, Name: id, Space: space}
+	for _, p := range policies {
+		s.AttachedPolicies = append(s.AttachedPolicies, models.PolicyAttachment{ID: id + "-" + p, PolicyID: p})
+	}
+	return s
+}
+
+// withContexts attaches contexts to a stack, as "id:priority".
+func withContexts(s models.Stack, contexts ...string) models.Stack {
+	for _, c := range contexts {
+		var id string
+		var priority int
+		fmt.Sscanf(strings.Replace(c, ":", " ", 1), "%s %d", &id, &priority)
+		s.AttachedContexts = append(s.AttachedContexts, models.ContextAttachment{ID: s.ID + "-" + id, ContextID: id, Priority: priority})
+	}
+	return s
+}
+
+// format renders merges as "kind keep<-dup,dup fromSpace->space (skipped)".
+func format(merges []Merge) string {
+	var out []string
+	for _, m := range merges {
+		line := fmt.Sprintf("%s %s<-%s %s->%s", m.Kind, m.Keep, strings.Join(m.Duplicates, ","), m.FromSpace, m.Space)
+		if m.Skipped != "" {
+			line += " (" + m.Skipped + ")"
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, " | ")
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest discovery.Manifest
+		want     string
+	}{
+		{
+			name: "policies in sibling spaces move to the parent",
+			manifest: discovery.Manifest{
+				Policies: []models.Policy{policy("a", "team-a", "deny"), policy("b", "team-b", "deny")},
+				Stacks:   []models.Stack{stack("s1", "team-a", "a"), stack("s2", "team-b", "b"), stack("s3", "team-b", "b")},
+			},
+			want: "policy b<-a team-b->root",
+		},
+		{
+			name: "the copy already in the target space is kept",
+			manifest: discovery.Manifest{
+				Policies: []models.Policy{policy("a", "team-a", "deny"), policy("b", "root", "deny")},
+				Stacks:   []models.Stack{stack("s1", "team-a", "a"), stack("s2", "team-a", "a")},
+			},
+			want: "policy b<-a root->root",
+		},
+		{
+			name: "different bodies are not merged",
+			manifest: discovery.Manifest{
+				Policies: []models.Policy{policy("a", "team-a", "deny"), policy("b", "team-b", "allow")},
+			},
+			want: "",
+		},
+		{
+			name: "autoattached policies are left alone",
+			manifest: discovery.Manifest{
+				Policies: []models.Policy{policy("a", "team-a", "deny", "autoattach:*"), policy("b", "team-b", "deny")},
+			},
+			want: "",
+		},
+		{
+			name: "a stack that does not inherit from the target space",
+			manifest: discovery.Manifest{
+				Policies: []models.Policy{policy("a", "team-a", "deny"), policy("b", "isolated", "deny")},
+				Stacks:   []models.Stack{stack("s1", "team-a", "a"), stack("s2", "isolated", "b")},
+			},
+			want: "policy a<-b team-a->root (stack s2 in space isolated does not inherit from space root)",
+		},
+		{
+			name: "identical contexts",
+			manifest: discovery.Manifest{
+				Contexts: []models.Context{context("a", "team-a", "eu-west-1", false), context("b", "team-b", "eu-west-1", false)},
+			},
+			want: "context a<-b team-a->root",
+		},
+		{
+			name: "contexts with secrets are skipped",
+			manifest: discovery.Manifest{
+				Contexts: []models.Context{context("a", "team-a", "", true), context("b", "team-b", "", true)},
+			},
+			want: "context a<-b team-a->root (has secrets, whose values cannot be compared; merge by hand once they are known to match)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.manifest
+			m.Spaces = spaces()
+			if got := format(Find(&m).Merges); got != tt.want {
+				t.Errorf("Find = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// contextAttachments renders a stack's context attachments as "id:priority".
+func contextAttachments(s models.Stack) string {
+	var out []string
+	for _, a := range s.AttachedContexts {
+		out = append(out, fmt.Sprintf("%s:%d", a.ContextID, a.Priority))
+	}
+	return strings.Join(out, " ")
+}
+
+// policyAttachments renders a stack's policy attachments as policy IDs.
+func policyAttachments(s models.Stack) string {
+	var out []string
+	for _, a := range s.AttachedPolicies {
+		out = append(out, a.PolicyID)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest discovery.Manifest
+		want     map[string]string // Stack ID -> attachments after the merge
+		render   func(models.Stack) string
+	}{
+		{
+			name: "a stack with several policy copies keeps one attachment",
+			manifest: discovery.Manifest{
+				Policies: []models.Policy{policy("a", "team-a", "deny"), policy("b", "team-b", "deny"), policy("other", "root", "allow")},
+				Stacks:   []models.Stack{stack("s1", "team-a", "a", "other", "b"), stack("s2", "team-b", "b"), stack("s3", "team-b", "b")},
+			},
+			want:   map[string]string{"s1": "b other", "s2": "b", "s3": "b"},
+			render: policyAttachments,
+		},
+		{
+			name: "context attachments keep the lowest priority",
+			manifest: discovery.Manifest{
+				Contexts: []models.Context{context("a", "team-a", "eu-west-1", false), context("b", "team-b", "eu-west-1", false), context("other", "root", "us-east-1", false)},
+				Stacks: []models.Stack{
+					withContexts(stack("s1", "team-a"), "a:5", "other:2", "b:1"),
+					withContexts(stack("s2", "team-b"), "b:3", "a:4"),
+					withContexts(stack("s3", "team-b"), "b:0"),
+				},
+			},
+			want:   map[string]string{"s1": "b:1 other:2", "s2": "b:3", "s3": "b:0"},
+			render: contextAttachments,
+		},
+		{
+			name: "skipped merges are not applied",
+			manifest: discovery.Manifest{
+				Policies: []models.Policy{policy("a", "team-a", "deny"), policy("b", "isolated", "deny")},
+				Stacks:   []models.Stack{stack("s1", "team-a", "a"), stack("s2", "isolated", "b")},
+			},
+			want:   map[string]string{"s1": "a", "s2": "b"},
+			render: policyAttachments,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tt.manifest
+			m.Spaces = spaces()
+			out, err := Find(&m).Apply(&m)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			for _, s := range out.Stacks {
+				if got := tt.render(s); got != tt.want[s.ID] {
+					t.Errorf("stack %s attachments = %q, want %q", s.ID, got, tt.want[s.ID])
+				}
+			}
+		})
+	}
+}
+
+func TestApplyMovesKeptResource(t *testing.T) {
+	m := discovery.Manifest{
+		Spaces:   spaces(),
+		Policies: []models.Policy{policy("a", "team-a", "deny", "team:a"), policy("b", "team-b", "deny", "team:b", "team:a")},
+		Stacks:   []models.Stack{stack("s1", "team-a", "a")},
+	}
+	report := Find(&m)
+	out, err := report.Apply(&m)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if len(out.Policies) != 1 {
+		t.Fatalf("Apply left %d policies, want 1", len(out.Policies))
+	}
+	kept := out.Policies[0]
+	if kept.ID != "a" || kept.Space != "root" || strings.Join(kept.Labels, ",") != "team:a,team:b" {
+		t.Errorf("kept policy = %s in %s with labels %v, want a in root with [team:a team:b]", kept.ID, kept.Space, kept.Labels)
+	}
+	if len(m.Policies) != 2 || m.Policies[0].Space != "team-a" {
+		t.Error("Apply changed the input manifest")
+	}
+}
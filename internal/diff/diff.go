@@ -0,0 +1,446 @@
+// Package diff compares two manifests resource by resource.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// Resource kinds, matching the manifest JSON keys.
+const (
+	KindSpaces            = "spaces"
+	KindStacks            = "stacks"
+	KindContexts          = "contexts"
+	KindPolicies          = "policies"
+	KindAWSIntegrations   = "awsIntegrations"
+	KindAzureIntegrations = "azureIntegrations"
+)
+
+// Kinds lists resource kinds in report order.
+var Kinds = []string{KindSpaces, KindContexts, KindPolicies, KindAWSIntegrations, KindAzureIntegrations, KindStacks}
+
+// Resource statuses.
+const (
+	StatusAdded     = "added"     // Only in the new manifest
+	StatusRemoved   = "removed"   // Only in the old manifest
+	StatusChanged   = "changed"   // In both, with field differences
+	StatusUnchanged = "unchanged" // In both, identical
+)
+
+// How two resources were matched.
+const (
+	MatchedByMapping = "mapping"
+	MatchedByID      = "id"
+	MatchedByName    = "name"
+)
+
+// Mapping maps old resource IDs to new resource IDs per kind, e.g. the ID
+// mapping written when resources are created in the destination.
+type Mapping map[string]map[string]string
+
+// LoadMapping reads a mapping file of the form {"stacks": {"old-id": "new-id"}}.
+func LoadMapping(path string) (Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping file: %w", err)
+	}
+	var m Mapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping file: %w", err)
+	}
+	return m, nil
+}
+
+// Set records that an old resource ID maps to a new one.
+func (m Mapping) Set(kind, oldID, newID string) {
+	if m[kind] == nil {
+		m[kind] = make(map[string]string)
+	}
+	m[kind][oldID] = newID
+}
+
+// Save writes the mapping file. It is written to a temporary file and
+// renamed, so an interrupted write never leaves a truncated mapping.
+func (m Mapping) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal mapping: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write mapping file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write mapping file: %w", err)
+	}
+	return nil
+}
+
+// FieldChange is a single differing field.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ResourceDiff describes one resource present in either manifest.
+type ResourceDiff struct {
+	Kind      string        `json:"kind"`
+	Status    string        `json:"status"`
+	Name      string        `json:"name"`
+	OldID     string        `json:"oldId,omitempty"`
+	NewID     string        `json:"newId,omitempty"`
+	MatchedBy string        `json:"matchedBy,omitempty"`
+	Changes   []FieldChange `json:"changes,omitempty"`
+}
+
+// Counts summarises the diff of one resource kind.
+type Counts struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Result is the outcome of comparing two manifests.
+type Result struct {
+	Old       string            `json:"old"`
+	New       string            `json:"new"`
+	Resources []ResourceDiff    `json:"resources"` // Unchanged resources are omitted
+	Summary   map[string]Counts `json:"summary"`
+}
+
+// HasChanges returns true if any resource was added, removed or changed.
+func (r *Result) HasChanges() bool {
+	return len(r.Resources) > 0
+}
+
+// item is a resource flattened for comparison.
+type item struct {
+	id     string
+	name   string
+	fields map[string]string
+}
+
+// Compare diffs two manifests. Resources are matched by the mapping first,
+// then by ID, then by name. Secret values are never compared or reported.
+func Compare(oldM, newM *discovery.Manifest, mapping Mapping) *Result {
+	result := &Result{Summary: make(map[string]Counts)}
+
+	oldItems := flatten(oldM)
+	newItems := flatten(newM)
+
+	for _, kind := range Kinds {
+		counts := Counts{}
+		olds, news := oldItems[kind], newItems[kind]
+
+		newByID := make(map[string]int)
+		newByName := make(map[string][]int)
+		for i, it := range news {
+			newByID[it.id] = i
+			newByName[it.name] = append(newByName[it.name], i)
+		}
+		used := make(map[int]bool)
+
+		for _, o := range olds {
+			idx, matchedBy := -1, ""
+			if target, ok := mapping[kind][o.id]; ok {
+				if i, ok := newByID[target]; ok && !used[i] {
+					idx, matchedBy = i, MatchedByMapping
+				}
+			}
+			if idx < 0 {
+				if i, ok := newByID[o.id]; ok && !used[i] {
+					idx, matchedBy = i, MatchedByID
+				}
+			}
+			if idx < 0 {
+				// Only match by name when the name is unambiguous
+				if candidates := newByName[o.name]; len(candidates) == 1 && !used[candidates[0]] {
+					idx, matchedBy = candidates[0], MatchedByName
+				}
+			}
+
+			if idx < 0 {
+				counts.Removed++
+				result.Resources = append(result.Resources, ResourceDiff{
+					Kind: kind, Status: StatusRemoved, Name: o.name, OldID: o.id,
+				})
+				continue
+			}
+
+			used[idx] = true
+			n := news[idx]
+			changes := compareFields(o.fields, n.fields)
+			if len(changes) == 0 {
+				counts.Unchanged++
+				continue
+			}
+			counts.Changed++
+			result.Resources = append(result.Resources, ResourceDiff{
+				Kind: kind, Status: StatusChanged, Name: o.name,
+				OldID: o.id, NewID: n.id, MatchedBy: matchedBy, Changes: changes,
+			})
+		}
+
+		for i, n := range news {
+			if used[i] {
+				continue
+			}
+			counts.Added++
+			result.Resources = append(result.Resources, ResourceDiff{
+				Kind: kind, Status: StatusAdded, Name: n.name, NewID: n.id,
+			})
+		}
+
+		result.Summary[kind] = counts
+	}
+
+	return result
+}
+
+// compareFields returns the differing fields, sorted by name.
+func compareFields(oldFields, newFields map[string]string) []FieldChange {
+	names := make(map[string]bool)
+	for name := range oldFields {
+		names[name] = true
+	}
+	for name := range newFields {
+		names[name] = true
+	}
+
+	var changes []FieldChange
+	for name := range names {
+		if oldFields[name] != newFields[name] {
+			changes = append(changes, FieldChange{Field: name, Old: oldFields[name], New: newFields[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// flatten converts every resource into comparable fields. References to
+// other resources are written by name, since IDs differ between accounts.
+func flatten(m *discovery.Manifest) map[string][]item {
+	spaceNames := make(map[string]string)
+	for _, s := range m.Spaces {
+		spaceNames[s.ID] = s.Name
+	}
+	spaceName := func(id string) string {
+		if name, ok := spaceNames[id]; ok {
+			return name
+		}
+		return id
+	}
+	names := func(list []string) string {
+		sorted := append([]string{}, list...)
+		sort.Strings(sorted)
+		return strings.Join(sorted, ", ")
+	}
+
+	contextNames := make(map[string]string)
+	for _, c := range m.Contexts {
+		contextNames[c.ID] = c.Name
+	}
+	policyNames := make(map[string]string)
+	for _, p := range m.Policies {
+		policyNames[p.ID] = p.Name
+	}
+	stackNames := make(map[string]string)
+	for _, s := range m.Stacks {
+		stackNames[s.ID] = s.Name
+	}
+	awsNames := make(map[string]string)
+	for _, i := range m.AWSIntegrations {
+		awsNames[i.ID] = i.Name
+	}
+	azureNames := make(map[string]string)
+	for _, i := range m.AzureIntegrations {
+		azureNames[i.ID] = i.Name
+	}
+	nameOf := func(lookup map[string]string, id string) string {
+		if name, ok := lookup[id]; ok {
+			return name
+		}
+		return id
+	}
+
+	items := make(map[string][]item)
+
+	for _, s := range m.Spaces {
+		f := map[string]string{
+			"name":            s.Name,
+			"description":     s.Description,
+			"inheritEntities": fmt.Sprint(s.InheritEntities),
+			"labels":          names(s.Labels),
+		}
+		if s.ParentSpace != nil {
+			f["parentSpace"] = spaceName(*s.ParentSpace)
+		}
+		items[KindSpaces] = append(items[KindSpaces], item{s.ID, s.Name, f})
+	}
+
+	for _, c := range m.Contexts {
+		f := map[string]string{
+			"name":        c.Name,
+			"description": deref(c.Description),
+			"space":       spaceName(c.Space),
+			"labels":      names(c.Labels),
+		}
+		addHooks(f, c.Hooks)
+		for _, cfg := range c.Config {
+			value := cfg.Value
+			if cfg.WriteOnly {
+				value = "(secret)"
+			}
+			if cfg.Type == "FILE_MOUNT" {
+				value = "[file] " + value
+			}
+			f["config."+cfg.ID] = value
+		}
+		items[KindContexts] = append(items[KindContexts], item{c.ID, c.Name, f})
+	}
+
+	for _, p := range m.Policies {
+		f := map[string]string{
+			"name":        p.Name,
+			"description": deref(p.Description),
+			"space":       spaceName(p.Space),
+			"type":        p.Type,
+			"engineType":  p.EngineType,
+			"body":        p.Body,
+			"labels":      names(p.Labels),
+		}
+		items[KindPolicies] = append(items[KindPolicies], item{p.ID, p.Name, f})
+	}
+
+	for _, i := range m.AWSIntegrations {
+		f := map[string]string{
+			"name":                        i.Name,
+			"roleArn":                     i.RoleARN,
+			"durationSeconds":             fmt.Sprint(i.DurationSeconds),
+			"generateCredentialsInWorker": fmt.Sprint(i.GenerateCredentialsInWorker),
+			"externalId":                  deref(i.ExternalID),
+			"space":                       spaceName(i.Space),
+			"labels":                      names(i.Labels),
+		}
+		items[KindAWSIntegrations] = append(items[KindAWSIntegrations], item{i.ID, i.Name, f})
+	}
+
+	for _, i := range m.AzureIntegrations {
+		f := map[string]string{
+			"name":                  i.Name,
+			"tenantId":              i.TenantID,
+			"defaultSubscriptionId": deref(i.DefaultSubscriptionID),
+			"applicationId":         i.ApplicationID,
+			"space":                 spaceName(i.Space),
+			"labels":                names(i.Labels),
+		}
+		items[KindAzureIntegrations] = append(items[KindAzureIntegrations], item{i.ID, i.Name, f})
+	}
+
+	for _, s := range m.Stacks {
+		f := map[string]string{
+			"name":                       s.Name,
+			"description":                deref(s.Description),
+			"space":                      spaceName(s.Space),
+			"branch":                     s.Branch,
+			"repository":                 s.Repository,
+			"namespace":                  s.Namespace,
+			"projectRoot":                deref(s.ProjectRoot),
+			"provider":                   s.Provider,
+			"vendorType":                 s.VendorType,
+			"runnerImage":                deref(s.RunnerImage),
+			"terraformVersion":           deref(s.TerraformVersion),
+			"terragruntVersion":          deref(s.TerragruntVersion),
+			"workflowTool":               deref(s.WorkflowTool),
+			"administrative":             fmt.Sprint(s.Administrative),
+			"autodeploy":                 fmt.Sprint(s.Autodeploy),
+			"autoretry":                  fmt.Sprint(s.Autoretry),
+			"localPreviewEnabled":        fmt.Sprint(s.LocalPreviewEnabled),
+			"protectFromDeletion":        fmt.Sprint(s.ProtectFromDeletion),
+			"isDisabled":                 fmt.Sprint(s.IsDisabled),
+			"managesStateFile":           fmt.Sprint(s.ManagesStateFile),
+			"externalStateAccessEnabled": fmt.Sprint(s.ExternalStateAccessEnabled),
+			"labels":                     names(s.Labels),
+			"additionalProjectGlobs":     names(s.AdditionalProjectGlobs),
+		}
+		addHooks(f, s.Hooks)
+
+		var attached []string
+		for _, a := range s.AttachedContexts {
+			attached = append(attached, fmt.Sprintf("%s (priority %d)", nameOf(contextNames, a.ContextID), a.Priority))
+		}
+		f["attachedContexts"] = names(attached)
+
+		attached = nil
+		for _, a := range s.AttachedPolicies {
+			attached = append(attached, nameOf(policyNames, a.PolicyID))
+		}
+		f["attachedPolicies"] = names(attached)
+
+		attached = nil
+		for _, d := range s.DependsOn {
+			attached = append(attached, nameOf(stackNames, d.DependsOnStackID))
+		}
+		f["dependsOn"] = names(attached)
+
+		attached = nil
+		for _, a := range s.AttachedAWSIntegrations {
+			attached = append(attached, fmt.Sprintf("%s (%s)", nameOf(awsNames, a.IntegrationID), accessMode(a.Read, a.Write)))
+		}
+		f["attachedAWSIntegrations"] = names(attached)
+
+		attached = nil
+		for _, a := range s.AttachedAzureIntegrations {
+			attached = append(attached, fmt.Sprintf("%s (%s)", nameOf(azureNames, a.IntegrationID), accessMode(a.Read, a.Write)))
+		}
+		f["attachedAzureIntegrations"] = names(attached)
+
+		items[KindStacks] = append(items[KindStacks], item{s.ID, s.Name, f})
+	}
+
+	return items
+}
+
+// addHooks adds non-empty hook lists as hooks.<phase> fields.
+func addHooks(f map[string]string, h models.Hooks) {
+	for phase, commands := range map[string][]string{
+		"afterApply": h.AfterApply, "beforeApply": h.BeforeApply,
+		"afterInit": h.AfterInit, "beforeInit": h.BeforeInit,
+		"afterPlan": h.AfterPlan, "beforePlan": h.BeforePlan,
+		"afterPerform": h.AfterPerform, "beforePerform": h.BeforePerform,
+		"afterDestroy": h.AfterDestroy, "beforeDestroy": h.BeforeDestroy,
+		"afterRun": h.AfterRun,
+	} {
+		if len(commands) > 0 {
+			f["hooks."+phase] = strings.Join(commands, " && ")
+		}
+	}
+}
+
+// accessMode describes integration attachment permissions.
+func accessMode(read, write bool) string {
+	switch {
+	case read && write:
+		return "read/write"
+	case write:
+		return "write"
+	default:
+		return "read"
+	}
+}
+
+// deref returns the value of an optional string, or "".
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
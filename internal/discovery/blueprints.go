@@ -0,0 +1,40 @@
+package discovery
+
+import (
+	"context"
+
+	graphql "github.com/hasura/go-graphql-client"
+	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// blueprintsPageSize is the number of blueprints fetched per request.
+const blueprintsPageSize = 50
+
+// DiscoverBlueprints fetches all blueprints from the Spacelift account.
+// Blueprints are not part of the manifest, since they cannot be migrated.
+func (s *Service) DiscoverBlueprints(ctx context.Context) ([]models.Blueprint, error) {
+	var blueprints []models.Blueprint
+	input := client.SearchInput{First: graphql.NewInt(blueprintsPageSize)}
+	for {
+		var query client.BlueprintsQuery
+		if err := s.client.Query(ctx, &query, map[string]interface{}{"input": input}); err != nil {
+			return nil, err
+		}
+
+		for _, edge := range query.SearchBlueprints.Edges {
+			blueprints = append(blueprints, models.Blueprint{
+				ID:    string(edge.Node.ID),
+				Name:  string(edge.Node.Name),
+				State: string(edge.Node.State),
+				Space: string(edge.Node.Space.ID),
+			})
+		}
+
+		page := query.SearchBlueprints.PageInfo
+		if !page.HasNextPage {
+			return blueprints, nil
+		}
+		input.After = graphql.NewString(page.EndCursor)
+	}
+}
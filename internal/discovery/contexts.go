@@ -2,6 +2,9 @@ package discovery
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/jnesspace/spacebridge/internal/client"
 	"github.com/jnesspace/spacebridge/internal/models"
@@ -80,3 +83,62 @@ func GetContextsWithSecrets(contexts []models.Context) []models.Context {
 	}
 	return result
 }
+
+// AutoattachPrefix starts the context label that attaches a context to every
+// stack with the rest of the label, or to all stacks with "*".
+const AutoattachPrefix = "autoattach:"
+
+// ContextAttached reports whether a context is attached to a stack, either
+// directly or through an autoattach label.
+func ContextAttached(stack models.Stack, c models.Context) bool {
+	for _, a := range stack.AttachedContexts {
+		if a.ContextID == c.ID {
+			return true
+		}
+	}
+	for _, label := range c.Labels {
+		target, ok := strings.CutPrefix(label, AutoattachPrefix)
+		if !ok {
+			continue
+		}
+		if target == "*" {
+			return true
+		}
+		for _, stackLabel := range stack.Labels {
+			if stackLabel == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Autoattached reports whether labels attach their context or policy to
+// stacks by label, so that it is used by any stack created later.
+func Autoattached(labels []string) bool {
+	for _, label := range labels {
+		if strings.HasPrefix(label, AutoattachPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextConfigKey returns a key that is equal for contexts with the same
+// config elements and hooks. Secret values are write-only, so secrets are
+// compared by key only.
+func ContextConfigKey(c models.Context) string {
+	config := append([]models.ConfigElement(nil), c.Config...)
+	sort.Slice(config, func(i, j int) bool { return config[i].ID < config[j].ID })
+
+	var key strings.Builder
+	for _, e := range config {
+		value := e.Value
+		if e.WriteOnly {
+			value = ""
+		}
+		fmt.Fprintf(&key, "%q %q %t %q\n", e.ID, e.Type, e.WriteOnly, value)
+	}
+	fmt.Fprintf(&key, "%q", c.Hooks)
+	return key.String()
+}
@@ -3,6 +3,7 @@ package discovery
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/jnesspace/spacebridge/internal/client"
@@ -22,7 +23,8 @@ func New(c *client.Client) *Service {
 // DiscoverAll fetches all resources from the Spacelift account.
 func (s *Service) DiscoverAll(ctx context.Context) (*Manifest, error) {
 	manifest := &Manifest{
-		SourceURL: s.client.URL(),
+		SchemaVersion: SchemaVersion,
+		SourceURL:     s.client.URL(),
 	}
 
 	// Discover spaces first (foundation)
@@ -111,6 +113,7 @@ func (s *Service) DiscoverAll(ctx context.Context) (*Manifest, error) {
 
 // Manifest represents a complete export of all resources.
 type Manifest struct {
+	SchemaVersion     int                       `json:"schemaVersion"`
 	SourceURL         string                    `json:"sourceUrl"`
 	Spaces            []models.Space            `json:"spaces"`
 	Stacks            []models.Stack            `json:"stacks"`
@@ -144,3 +147,96 @@ func (m *Manifest) SecretsCount() int {
 	}
 	return count
 }
+
+// SpaceAncestry returns the given space ID followed by the IDs of all its
+// ancestors, ending with the root-most space known to the manifest.
+func (m *Manifest) SpaceAncestry(spaceID string) []string {
+	parents := make(map[string]string)
+	for _, space := range m.Spaces {
+		if space.ParentSpace != nil {
+			parents[space.ID] = *space.ParentSpace
+		}
+	}
+
+	var chain []string
+	seen := make(map[string]bool)
+	for current := spaceID; current != "" && !seen[current]; current = parents[current] {
+		seen[current] = true
+		chain = append(chain, current)
+	}
+	return chain
+}
+
+// Subset returns the part of the manifest made of the given stacks, contexts
+// and policies, the integrations attached to those stacks, and the spaces
+// holding any of them along with their ancestors, so that the hierarchy can
+// be created.
+func (m *Manifest) Subset(stackIDs, contextIDs, policyIDs map[string]bool) *Manifest {
+	spaces := make(map[string]bool)
+	includeSpace := func(id string) {
+		for _, ancestor := range m.SpaceAncestry(id) {
+			spaces[ancestor] = true
+		}
+	}
+
+	out := &Manifest{SchemaVersion: m.SchemaVersion, SourceURL: m.SourceURL}
+	awsIntegrations := make(map[string]bool)
+	azureIntegrations := make(map[string]bool)
+	for _, stack := range m.Stacks {
+		if !stackIDs[stack.ID] {
+			continue
+		}
+		out.Stacks = append(out.Stacks, stack)
+		includeSpace(stack.Space)
+		for _, a := range stack.AttachedAWSIntegrations {
+			awsIntegrations[a.IntegrationID] = true
+		}
+		for _, a := range stack.AttachedAzureIntegrations {
+			azureIntegrations[a.IntegrationID] = true
+		}
+	}
+	for _, c := range m.Contexts {
+		if contextIDs[c.ID] {
+			out.Contexts = append(out.Contexts, c)
+			includeSpace(c.Space)
+		}
+	}
+	for _, p := range m.Policies {
+		if policyIDs[p.ID] {
+			out.Policies = append(out.Policies, p)
+			includeSpace(p.Space)
+		}
+	}
+	for _, i := range m.AWSIntegrations {
+		if awsIntegrations[i.ID] {
+			out.AWSIntegrations = append(out.AWSIntegrations, i)
+			includeSpace(i.Space)
+		}
+	}
+	for _, i := range m.AzureIntegrations {
+		if azureIntegrations[i.ID] {
+			out.AzureIntegrations = append(out.AzureIntegrations, i)
+			includeSpace(i.Space)
+		}
+	}
+
+	for _, space := range m.Spaces {
+		if spaces[space.ID] {
+			out.Spaces = append(out.Spaces, space)
+		}
+	}
+	return out
+}
+
+// Clone returns a deep copy of the manifest.
+func (m *Manifest) Clone() (*Manifest, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy manifest: %w", err)
+	}
+	clone := &Manifest{}
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, fmt.Errorf("failed to copy manifest: %w", err)
+	}
+	return clone, nil
+}
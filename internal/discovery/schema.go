@@ -0,0 +1,293 @@
+package discovery
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the manifest schema version written by this build.
+// Manifests exported before versioning was introduced have no schemaVersion
+// field and are treated as version 0.
+const SchemaVersion = 1
+
+// JSONSchema is the published JSON Schema for the current manifest version.
+//
+//go:embed manifest.schema.json
+var JSONSchema []byte
+
+// upgrade migrates a raw manifest from one schema version to the next.
+type upgrade struct {
+	description string
+	apply       func(raw map[string]interface{})
+}
+
+// upgrades holds the migration from version N to N+1 at index N.
+var upgrades = []upgrade{
+	{
+		// Version 0 manifests predate schemaVersion. Older builds also
+		// omitted integration lists and per-stack Azure attachments.
+		description: "add schemaVersion and default missing integration lists",
+		apply: func(raw map[string]interface{}) {
+			for _, key := range []string{"spaces", "stacks", "contexts", "policies", "awsIntegrations", "azureIntegrations"} {
+				if raw[key] == nil {
+					raw[key] = []interface{}{}
+				}
+			}
+		},
+	},
+}
+
+// ManifestVersion returns the schema version of raw manifest JSON.
+func ManifestVersion(data []byte) (int, error) {
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return header.SchemaVersion, nil
+}
+
+// UpgradeManifest migrates raw manifest JSON to the current schema version.
+// It returns the upgraded JSON and a description of each step applied.
+func UpgradeManifest(data []byte) ([]byte, []string, error) {
+	version, err := ManifestVersion(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if version > SchemaVersion {
+		return nil, nil, fmt.Errorf("manifest schema version %d is newer than this build supports (%d); upgrade spacebridge", version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return data, nil, nil
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	var steps []string
+	for v := version; v < SchemaVersion; v++ {
+		upgrades[v].apply(raw)
+		raw["schemaVersion"] = v + 1
+		steps = append(steps, fmt.Sprintf("v%d → v%d: %s", v, v+1, upgrades[v].description))
+	}
+
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal upgraded manifest: %w", err)
+	}
+	return upgraded, steps, nil
+}
+
+// ParseManifest decodes manifest JSON, upgrading older schema versions in
+// memory. It returns the manifest and the schema version it was stored as.
+func ParseManifest(data []byte) (*Manifest, int, error) {
+	version, err := ManifestVersion(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	upgraded, _, err := UpgradeManifest(data)
+	if err != nil {
+		return nil, version, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(upgraded, manifest); err != nil {
+		return nil, version, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return manifest, version, nil
+}
+
+// Problem severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is a single issue found by ValidateManifest.
+type Problem struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"` // e.g. stacks[network].attachedContexts[0]
+	Message  string `json:"message"`
+}
+
+// ValidateManifest checks raw manifest JSON against the schema version and
+// the manifest structure, then checks referential integrity: every
+// attachment must point at an existing context, policy, integration or
+// stack, and every space parent must exist.
+func ValidateManifest(data []byte) ([]Problem, error) {
+	var problems []Problem
+
+	version, err := ManifestVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case version > SchemaVersion:
+		return append(problems, Problem{SeverityError, "schemaVersion",
+			fmt.Sprintf("version %d is newer than this build supports (%d)", version, SchemaVersion)}), nil
+	case version < SchemaVersion:
+		problems = append(problems, Problem{SeverityWarning, "schemaVersion",
+			fmt.Sprintf("version %d is older than %d; run 'spacebridge manifest upgrade'", version, SchemaVersion)})
+	}
+
+	upgraded, _, err := UpgradeManifest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Strict decoding catches fields this build does not know about
+	dec := json.NewDecoder(bytes.NewReader(upgraded))
+	dec.DisallowUnknownFields()
+	manifest := &Manifest{}
+	if err := dec.Decode(manifest); err != nil {
+		problems = append(problems, Problem{SeverityWarning, "", fmt.Sprintf("does not match the schema: %v", err)})
+		manifest = &Manifest{}
+		if err := json.Unmarshal(upgraded, manifest); err != nil {
+			return append(problems, Problem{SeverityError, "", fmt.Sprintf("cannot be parsed: %v", err)}), nil
+		}
+	}
+
+	return append(problems, manifest.CheckReferences()...), nil
+}
+
+// CheckReferences verifies referential integrity within the manifest.
+func (m *Manifest) CheckReferences() []Problem {
+	var problems []Problem
+	errorf := func(path, format string, args ...interface{}) {
+		problems = append(problems, Problem{SeverityError, path, fmt.Sprintf(format, args...)})
+	}
+
+	ids := func(kind string, list []string) map[string]bool {
+		set := make(map[string]bool)
+		for i, id := range list {
+			if id == "" {
+				errorf(fmt.Sprintf("%s[%d]", kind, i), "id is empty")
+				continue
+			}
+			if set[id] {
+				errorf(fmt.Sprintf("%s[%s]", kind, id), "duplicate id")
+			}
+			set[id] = true
+		}
+		return set
+	}
+
+	var spaceIDs, stackIDs, contextIDs, policyIDs, awsIDs, azureIDs []string
+	for _, s := range m.Spaces {
+		spaceIDs = append(spaceIDs, s.ID)
+	}
+	for _, s := range m.Stacks {
+		stackIDs = append(stackIDs, s.ID)
+	}
+	for _, c := range m.Contexts {
+		contextIDs = append(contextIDs, c.ID)
+	}
+	for _, p := range m.Policies {
+		policyIDs = append(policyIDs, p.ID)
+	}
+	for _, i := range m.AWSIntegrations {
+		awsIDs = append(awsIDs, i.ID)
+	}
+	for _, i := range m.AzureIntegrations {
+		azureIDs = append(azureIDs, i.ID)
+	}
+
+	spaces := ids("spaces", spaceIDs)
+	stacks := ids("stacks", stackIDs)
+	contexts := ids("contexts", contextIDs)
+	policies := ids("policies", policyIDs)
+	aws := ids("awsIntegrations", awsIDs)
+	azure := ids("azureIntegrations", azureIDs)
+
+	// Root is implicit in filtered manifests, so it always counts as present
+	spaceExists := func(id string) bool { return id == "root" || spaces[id] }
+
+	for _, s := range m.Spaces {
+		if s.ParentSpace != nil && *s.ParentSpace != "" && !spaceExists(*s.ParentSpace) {
+			errorf(fmt.Sprintf("spaces[%s].parentSpace", s.ID), "parent space %q does not exist", *s.ParentSpace)
+		}
+	}
+	if cycle := m.spaceCycle(); cycle != "" {
+		errorf(fmt.Sprintf("spaces[%s].parentSpace", cycle), "space hierarchy contains a cycle")
+	}
+
+	checkSpace := func(path, space string) {
+		if space != "" && !spaceExists(space) {
+			errorf(path+".space", "space %q does not exist", space)
+		}
+	}
+	for _, c := range m.Contexts {
+		checkSpace(fmt.Sprintf("contexts[%s]", c.ID), c.Space)
+	}
+	for _, p := range m.Policies {
+		checkSpace(fmt.Sprintf("policies[%s]", p.ID), p.Space)
+	}
+	for _, i := range m.AWSIntegrations {
+		checkSpace(fmt.Sprintf("awsIntegrations[%s]", i.ID), i.Space)
+	}
+	for _, i := range m.AzureIntegrations {
+		checkSpace(fmt.Sprintf("azureIntegrations[%s]", i.ID), i.Space)
+	}
+
+	for _, s := range m.Stacks {
+		path := fmt.Sprintf("stacks[%s]", s.ID)
+		checkSpace(path, s.Space)
+		for i, a := range s.AttachedContexts {
+			if !contexts[a.ContextID] {
+				errorf(fmt.Sprintf("%s.attachedContexts[%d]", path, i), "context %q does not exist", a.ContextID)
+			}
+		}
+		for i, a := range s.AttachedPolicies {
+			if !policies[a.PolicyID] {
+				errorf(fmt.Sprintf("%s.attachedPolicies[%d]", path, i), "policy %q does not exist", a.PolicyID)
+			}
+		}
+		for i, a := range s.AttachedAWSIntegrations {
+			if !aws[a.IntegrationID] {
+				errorf(fmt.Sprintf("%s.attachedAWSIntegrations[%d]", path, i), "AWS integration %q does not exist", a.IntegrationID)
+			}
+		}
+		for i, a := range s.AttachedAzureIntegrations {
+			if !azure[a.IntegrationID] {
+				errorf(fmt.Sprintf("%s.attachedAzureIntegrations[%d]", path, i), "Azure integration %q does not exist", a.IntegrationID)
+			}
+		}
+		for i, d := range s.DependsOn {
+			switch {
+			case d.DependsOnStackID == s.ID:
+				errorf(fmt.Sprintf("%s.dependsOn[%d]", path, i), "stack depends on itself")
+			case !stacks[d.DependsOnStackID]:
+				errorf(fmt.Sprintf("%s.dependsOn[%d]", path, i), "stack %q does not exist", d.DependsOnStackID)
+			}
+		}
+	}
+
+	return problems
+}
+
+// spaceCycle returns the ID of a space whose ancestry loops back on itself,
+// or "" if the hierarchy is a tree.
+func (m *Manifest) spaceCycle() string {
+	parents := make(map[string]string)
+	for _, s := range m.Spaces {
+		if s.ParentSpace != nil {
+			parents[s.ID] = *s.ParentSpace
+		}
+	}
+	for _, s := range m.Spaces {
+		seen := map[string]bool{}
+		for current := s.ID; current != ""; current = parents[current] {
+			if seen[current] {
+				return s.ID
+			}
+			seen[current] = true
+		}
+	}
+	return ""
+}
@@ -77,6 +77,10 @@ func (s *Service) DiscoverStacks(ctx context.Context) ([]models.Stack, error) {
 			img := string(*st.RunnerImage)
 			stack.RunnerImage = &img
 		}
+		if st.StateSetAt != nil {
+			setAt := int64(*st.StateSetAt)
+			stack.StateSetAt = &setAt
+		}
 
 		// Version and workflow tool fields based on vendor type
 		switch vendorType {
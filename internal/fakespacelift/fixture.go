@@ -0,0 +1,127 @@
+package fakespacelift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// Fixture holds the content of a source and a destination account.
+type Fixture struct {
+	Source      Account `json:"source"`
+	Destination Account `json:"destination"`
+}
+
+// Account is the content of one fake account. Resources use the manifest
+// format, so an exported manifest is a valid account.
+type Account struct {
+	discovery.Manifest
+	States     map[string]json.RawMessage `json:"states,omitempty"` // Stack ID -> managed state file
+	Blueprints []models.Blueprint         `json:"blueprints,omitempty"`
+	Roles      []Role                     `json:"roles,omitempty"`
+}
+
+// Role is a custom role and the stacks bound to it.
+type Role struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Actions []string `json:"actions"`
+	Stacks  []string `json:"stacks,omitempty"` // IDs of the stacks the role is bound to
+}
+
+// LoadFixture reads a fixture file.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture: %w", err)
+	}
+	return &fixture, nil
+}
+
+// toObject converts a model to a GraphQL object of the given type. The
+// model JSON field names match the Spacelift schema.
+func toObject(typename string, v interface{}) map[string]interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("fakespacelift: cannot marshal %T: %v", v, err))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	object := make(map[string]interface{})
+	if err := decoder.Decode(&object); err != nil {
+		panic(fmt.Sprintf("fakespacelift: cannot unmarshal %T: %v", v, err))
+	}
+	object["__typename"] = typename
+	return object
+}
+
+// stackObject converts a stack to the Stack type of the schema.
+func stackObject(s models.Stack) map[string]interface{} {
+	object := toObject("Stack", s)
+
+	vendorType := s.VendorType
+	if vendorType == "" {
+		vendorType = "StackConfigVendorTerraform"
+	}
+	object["vendorConfig"] = map[string]interface{}{
+		"__typename":                 vendorType,
+		"version":                    s.TerraformVersion,
+		"workflowTool":               s.WorkflowTool,
+		"externalStateAccessEnabled": s.ExternalStateAccessEnabled,
+		"terraformVersion":           s.TerraformVersion,
+		"terragruntVersion":          s.TerragruntVersion,
+		"tool":                       s.WorkflowTool,
+	}
+	object["hooks"] = toObject("Hooks", s.Hooks)
+
+	attachedContexts := []map[string]interface{}{}
+	for _, a := range s.AttachedContexts {
+		attachedContexts = append(attachedContexts, toObject("StackContextAttachment", a))
+	}
+	object["attachedContexts"] = attachedContexts
+
+	attachedPolicies := []map[string]interface{}{}
+	for _, a := range s.AttachedPolicies {
+		attachedPolicies = append(attachedPolicies, toObject("PolicyStackAttachment", a))
+	}
+	object["attachedPolicies"] = attachedPolicies
+
+	dependsOn := []map[string]interface{}{}
+	for _, d := range s.DependsOn {
+		dependsOn = append(dependsOn, map[string]interface{}{
+			"__typename":     "StackDependency",
+			"id":             d.ID,
+			"dependsOnStack": map[string]interface{}{"__typename": "Stack", "id": d.DependsOnStackID},
+		})
+	}
+	object["dependsOn"] = dependsOn
+
+	return object
+}
+
+// contextObject converts a context to the Context type of the schema.
+// Secret values are write-only, so they are never returned.
+func contextObject(c models.Context) map[string]interface{} {
+	object := toObject("Context", c)
+	object["hooks"] = toObject("Hooks", c.Hooks)
+
+	config := []map[string]interface{}{}
+	for _, element := range c.Config {
+		e := toObject("ConfigElement", element)
+		if element.WriteOnly {
+			e["value"] = ""
+		}
+		config = append(config, e)
+	}
+	object["config"] = config
+
+	return object
+}
@@ -0,0 +1,350 @@
+package fakespacelift
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// field is one field of a GraphQL selection set. An inline fragment
+// ("... on Type { ... }") is a field without a name whose selections apply
+// only to objects of that type.
+type field struct {
+	name       string
+	alias      string
+	on         string                 // Inline fragments: the type condition
+	args       map[string]interface{} // Variables already substituted
+	selections []field
+}
+
+// key returns the response key of a field.
+func (f field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// operation is a parsed GraphQL request.
+type operation struct {
+	kind   string // "query" or "mutation"
+	fields []field
+}
+
+// parser is a recursive-descent parser for the subset of GraphQL that
+// SpaceBridge sends: one operation, variables, literals and inline
+// fragments. Variable definitions and directives are skipped.
+type parser struct {
+	tokens    []string
+	pos       int
+	variables map[string]interface{}
+}
+
+// parseOperation parses a GraphQL document, substituting variables.
+func parseOperation(query string, variables map[string]interface{}) (*operation, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, variables: variables}
+
+	op := &operation{kind: "query"}
+	if t := p.peek(); t == "query" || t == "mutation" {
+		op.kind = p.next()
+		if t := p.peek(); t != "{" && t != "(" {
+			p.next() // Operation name
+		}
+		if p.peek() == "(" {
+			if err := p.skipBalanced("(", ")"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if op.fields, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q after operation", p.peek())
+	}
+	return op, nil
+}
+
+// peek returns the next token without consuming it.
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+// next consumes the next token.
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// expect consumes a token that must be want.
+func (p *parser) expect(want string) error {
+	if t := p.next(); t != want {
+		return fmt.Errorf("expected %q, got %q", want, t)
+	}
+	return nil
+}
+
+// skipBalanced skips from an opening token to its matching closing token.
+func (p *parser) skipBalanced(open, close string) error {
+	depth := 0
+	for {
+		switch p.next() {
+		case open:
+			depth++
+		case close:
+			if depth--; depth == 0 {
+				return nil
+			}
+		case "":
+			return fmt.Errorf("unterminated %q", open)
+		}
+	}
+}
+
+// selectionSet parses "{ field ... }".
+func (p *parser) selectionSet() ([]field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []field
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			return nil, fmt.Errorf("unterminated selection set")
+		}
+		f, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	p.next()
+	return fields, nil
+}
+
+// field parses a field or an inline fragment.
+func (p *parser) field() (field, error) {
+	var f field
+	if p.peek() == "..." {
+		p.next()
+		if err := p.expect("on"); err != nil {
+			return f, err
+		}
+		f.on = p.next()
+		var err error
+		f.selections, err = p.selectionSet()
+		return f, err
+	}
+
+	f.name = p.next()
+	if !isName(f.name) {
+		return f, fmt.Errorf("expected field name, got %q", f.name)
+	}
+	if p.peek() == ":" {
+		p.next()
+		f.alias, f.name = f.name, p.next()
+	}
+	if p.peek() == "(" {
+		p.next()
+		f.args = make(map[string]interface{})
+		for p.peek() != ")" {
+			name := p.next()
+			if err := p.expect(":"); err != nil {
+				return f, err
+			}
+			value, err := p.value()
+			if err != nil {
+				return f, err
+			}
+			f.args[name] = value
+		}
+		p.next()
+	}
+	for p.peek() == "@" {
+		p.next()
+		p.next()
+		if p.peek() == "(" {
+			if err := p.skipBalanced("(", ")"); err != nil {
+				return f, err
+			}
+		}
+	}
+	if p.peek() == "{" {
+		var err error
+		if f.selections, err = p.selectionSet(); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// value parses an argument value.
+func (p *parser) value() (interface{}, error) {
+	t := p.next()
+	switch {
+	case t == "$":
+		return p.variables[p.next()], nil
+	case t == "[":
+		list := []interface{}{}
+		for p.peek() != "]" {
+			if p.peek() == "" {
+				return nil, fmt.Errorf("unterminated list")
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		p.next()
+		return list, nil
+	case t == "{":
+		object := make(map[string]interface{})
+		for p.peek() != "}" {
+			name := p.next()
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			object[name] = v
+		}
+		p.next()
+		return object, nil
+	case strings.HasPrefix(t, `"`):
+		var s string
+		if err := json.Unmarshal([]byte(t), &s); err != nil {
+			return nil, fmt.Errorf("invalid string %s: %w", t, err)
+		}
+		return s, nil
+	case t == "true" || t == "false":
+		return t == "true", nil
+	case t == "null":
+		return nil, nil
+	case t != "" && (t[0] == '-' || unicode.IsDigit(rune(t[0]))):
+		return json.Number(t), nil
+	case isName(t):
+		return t, nil // Enum value
+	}
+	return nil, fmt.Errorf("unexpected %q in value", t)
+}
+
+// tokenize splits a GraphQL document into tokens. Commas are insignificant
+// and comments are dropped. String tokens keep their quotes.
+func tokenize(src string) ([]string, error) {
+	var tokens []string
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r) || r == ',':
+			i++
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '.':
+			if i+2 >= len(runes) || runes[i+1] != '.' || runes[i+2] != '.' {
+				return nil, fmt.Errorf("unexpected '.' at offset %d", i)
+			}
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.ContainsRune("{}()[]:$!=@", r):
+			tokens = append(tokens, string(r))
+			i++
+		case r == '"':
+			start := i
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at offset %d", start)
+			}
+			i++
+			tokens = append(tokens, string(runes[start:i]))
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i++; i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])); i++ {
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		case r == '-' || unicode.IsDigit(r):
+			start := i
+			for i++; i < len(runes); i++ {
+				c := runes[i]
+				if !(c == '.' || c == '-' || c == '+' || c == 'e' || c == 'E' || unicode.IsDigit(c)) {
+					break
+				}
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", r, i)
+		}
+	}
+	return tokens, nil
+}
+
+// isName reports whether a token is a GraphQL name.
+func isName(t string) bool {
+	if t == "" || !(t[0] == '_' || unicode.IsLetter(rune(t[0]))) {
+		return false
+	}
+	for _, r := range t {
+		if !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// project keeps the selected fields of a resolved value. Objects are maps
+// with a "__typename" key, which decides the inline fragments that apply.
+func project(value interface{}, selections []field) interface{} {
+	if len(selections) == 0 {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(selections))
+		projectInto(out, v, selections)
+		return out
+	case []map[string]interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, project(item, selections))
+		}
+		return list
+	case []interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, project(item, selections))
+		}
+		return list
+	}
+	return value
+}
+
+// projectInto copies the selected fields of object into out.
+func projectInto(out, object map[string]interface{}, selections []field) {
+	for _, f := range selections {
+		if f.name == "" {
+			if object["__typename"] == f.on {
+				projectInto(out, object, f.selections)
+			}
+			continue
+		}
+		out[f.key()] = project(object[f.name], f.selections)
+	}
+}
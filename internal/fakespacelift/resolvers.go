@@ -0,0 +1,356 @@
+package fakespacelift
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// resolve returns the value of a root field. It is called with s.mu held.
+func (s *Server) resolve(kind string, f field) (interface{}, error) {
+	if kind == "mutation" {
+		switch f.name {
+		case "apiKeyUser":
+			return map[string]interface{}{"__typename": "User", "id": stringArg(f, "id"), "jwt": jwt}, nil
+		case "stackUpdate":
+			return s.stackUpdate(stringArg(f, "id"), objectArg(f, "input"))
+		case "stackLock":
+			return s.setLocked(stringArg(f, "id"), true)
+		case "stackUnlock":
+			return s.setLocked(stringArg(f, "id"), false)
+		case "stateDownloadUrl":
+			return s.stateDownloadURL(stringValue(objectArg(f, "input")["stackId"]))
+		case "stateUploadUrl":
+			return s.stateUploadURL(), nil
+		case "stackManagedStateImport":
+			return s.importState(stringArg(f, "stackId"), stringArg(f, "state"))
+		case "spaceCreate":
+			return s.spaceCreate(objectArg(f, "input"))
+		case "contextCreateV2":
+			return s.contextCreate(objectArg(f, "input"))
+		case "contextConfigAdd":
+			return s.contextConfigAdd(stringArg(f, "context"), objectArg(f, "config"))
+		case "contextAttach":
+			return s.contextAttach(stringArg(f, "id"), stringArg(f, "stack"), intValue(f.args["priority"]))
+		case "policyCreate":
+			return s.policyCreate(f)
+		case "policyAttach":
+			return s.policyAttach(stringArg(f, "id"), stringArg(f, "stack"))
+		case "stackCreate":
+			return s.stackCreate(objectArg(f, "input"), boolValue(f.args["manageState"]))
+		case "stackDependenciesCreate":
+			return s.stackDependencyCreate(objectArg(f, "input"))
+		case "awsIntegrationAttach":
+			return s.awsIntegrationAttach(f)
+		case "azureIntegrationAttach":
+			return s.azureIntegrationAttach(f)
+		case "roleCreate":
+			return s.roleCreate(objectArg(f, "input"))
+		case "stackRoleBindingCreate":
+			return s.stackRoleBindingCreate(objectArg(f, "input"))
+		}
+		return nil, fmt.Errorf("fakespacelift: mutation %q is not supported", f.name)
+	}
+
+	switch f.name {
+	case "spaces":
+		spaces := []map[string]interface{}{}
+		for _, space := range s.account.Spaces {
+			spaces = append(spaces, toObject("Space", space))
+		}
+		return spaces, nil
+	case "stacks":
+		stacks := []map[string]interface{}{}
+		for _, stack := range s.account.Stacks {
+			stacks = append(stacks, stackObject(stack))
+		}
+		return stacks, nil
+	case "stack":
+		stack, err := s.stack(stringArg(f, "id"))
+		if err != nil {
+			return nil, nil // Unknown stacks are null, as in Spacelift
+		}
+		return stackObject(*stack), nil
+	case "contexts":
+		contexts := []map[string]interface{}{}
+		for _, c := range s.account.Contexts {
+			contexts = append(contexts, contextObject(c))
+		}
+		return contexts, nil
+	case "policies":
+		policies := []map[string]interface{}{}
+		for _, policy := range s.account.Policies {
+			policies = append(policies, toObject("Policy", policy))
+		}
+		return policies, nil
+	case "searchBlueprints":
+		edges := []map[string]interface{}{}
+		for _, b := range s.account.Blueprints {
+			node := toObject("Blueprint", b)
+			node["space"] = map[string]interface{}{"__typename": "Space", "id": b.Space}
+			edges = append(edges, map[string]interface{}{"__typename": "BlueprintEdge", "node": node})
+		}
+		return map[string]interface{}{
+			"__typename": "BlueprintConnection",
+			"edges":      edges,
+			"pageInfo":   map[string]interface{}{"__typename": "PageInfo", "endCursor": "", "hasNextPage": false},
+		}, nil
+	case "workerPools":
+		return []map[string]interface{}{}, nil
+	case "roles":
+		roles := []map[string]interface{}{}
+		for _, r := range s.account.Roles {
+			roles = append(roles, roleObject(r))
+		}
+		return roles, nil
+	case "awsIntegrations":
+		integrations := []map[string]interface{}{}
+		for _, i := range s.account.AWSIntegrations {
+			integrations = append(integrations, toObject("AwsIntegration", i))
+		}
+		return integrations, nil
+	case "azureIntegrations":
+		integrations := []map[string]interface{}{}
+		for _, i := range s.account.AzureIntegrations {
+			integrations = append(integrations, toObject("AzureIntegration", i))
+		}
+		return integrations, nil
+	case "awsIntegration":
+		return s.awsIntegration(stringArg(f, "id")), nil
+	case "azureIntegration":
+		return s.azureIntegration(stringArg(f, "id")), nil
+	}
+	return nil, fmt.Errorf("fakespacelift: query %q is not supported", f.name)
+}
+
+// stack returns a stack of the account by ID.
+func (s *Server) stack(id string) (*models.Stack, error) {
+	for i := range s.account.Stacks {
+		if s.account.Stacks[i].ID == id {
+			return &s.account.Stacks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("stack %q not found", id)
+}
+
+// stackUpdate replaces the fields of a stack with those of a StackInput. As
+// in Spacelift, a field left out of the input is reset, not kept.
+func (s *Server) stackUpdate(id string, input map[string]interface{}) (interface{}, error) {
+	stack, err := s.stack(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stack.Name = stringValue(input["name"])
+	stack.Description = stringPointer(input["description"])
+	stack.Space = stringValue(input["space"])
+	if stack.Space == "" {
+		stack.Space = "root"
+	}
+	stack.Branch = stringValue(input["branch"])
+	stack.Repository = stringValue(input["repository"])
+	stack.Namespace = stringValue(input["namespace"])
+	stack.ProjectRoot = stringPointer(input["projectRoot"])
+	stack.RunnerImage = stringPointer(input["runnerImage"])
+	stack.Administrative = boolValue(input["administrative"])
+	stack.Autodeploy = boolValue(input["autodeploy"])
+	stack.Autoretry = boolValue(input["autoretry"])
+	stack.LocalPreviewEnabled = boolValue(input["localPreviewEnabled"])
+	stack.ProtectFromDeletion = boolValue(input["protectFromDeletion"])
+	stack.IsDisabled = boolValue(input["isDisabled"])
+	stack.Labels = stringList(input["labels"])
+	stack.AdditionalProjectGlobs = stringList(input["additionalProjectGlobs"])
+	stack.Hooks = models.Hooks{
+		AfterApply:    stringList(input["afterApply"]),
+		BeforeApply:   stringList(input["beforeApply"]),
+		AfterInit:     stringList(input["afterInit"]),
+		BeforeInit:    stringList(input["beforeInit"]),
+		AfterPlan:     stringList(input["afterPlan"]),
+		BeforePlan:    stringList(input["beforePlan"]),
+		AfterPerform:  stringList(input["afterPerform"]),
+		BeforePerform: stringList(input["beforePerform"]),
+		AfterDestroy:  stringList(input["afterDestroy"]),
+		BeforeDestroy: stringList(input["beforeDestroy"]),
+		AfterRun:      stringList(input["afterRun"]),
+	}
+
+	vendor, _ := input["vendorConfig"].(map[string]interface{})
+	terraform, _ := vendor["terraform"].(map[string]interface{})
+	stack.ExternalStateAccessEnabled = boolValue(terraform["externalStateAccessEnabled"])
+	if terraform != nil {
+		stack.TerraformVersion = stringPointer(terraform["version"])
+		stack.WorkflowTool = stringPointer(terraform["workflowTool"])
+	}
+
+	return stackObject(*stack), nil
+}
+
+// setLocked locks or unlocks a stack.
+func (s *Server) setLocked(id string, lock bool) (interface{}, error) {
+	stack, err := s.stack(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.locked[id] == lock {
+		if lock {
+			return nil, fmt.Errorf("stack %q is already locked", id)
+		}
+		return nil, fmt.Errorf("stack %q is not locked", id)
+	}
+	s.locked[id] = lock
+	return stackObject(*stack), nil
+}
+
+// stateDownloadURL presigns a download of a stack's managed state.
+func (s *Server) stateDownloadURL(stackID string) (interface{}, error) {
+	stack, err := s.stack(stackID)
+	if err != nil {
+		return nil, err
+	}
+	if !stack.ManagesStateFile {
+		return nil, fmt.Errorf("stack %q does not use Spacelift-managed state", stackID)
+	}
+	if !stack.ExternalStateAccessEnabled {
+		return nil, fmt.Errorf("external state access is not enabled on stack %q", stackID)
+	}
+	if _, ok := s.account.States[stackID]; !ok {
+		return nil, fmt.Errorf("stack %q has no state", stackID)
+	}
+	url := s.presign(grant{method: "GET", stackID: stackID})
+	return map[string]interface{}{"__typename": "StateDownloadUrl", "url": url}, nil
+}
+
+// stateUploadURL presigns an upload of a new state object.
+func (s *Server) stateUploadURL() interface{} {
+	s.uploaded++
+	objectID := fmt.Sprintf("state-upload-%d", s.uploaded)
+	url := s.presign(grant{method: "PUT", objectID: objectID})
+	return map[string]interface{}{"__typename": "StateUploadUrl", "url": url, "objectId": objectID}
+}
+
+// importState makes an uploaded object the managed state of a stack.
+func (s *Server) importState(stackID, objectID string) (interface{}, error) {
+	stack, err := s.stack(stackID)
+	if err != nil {
+		return nil, err
+	}
+	if !stack.ManagesStateFile {
+		return nil, fmt.Errorf("stack %q does not use Spacelift-managed state", stackID)
+	}
+	data, ok := s.uploads[objectID]
+	if !ok {
+		return nil, fmt.Errorf("state object %q was not uploaded", objectID)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("state object %q is not valid JSON", objectID)
+	}
+	s.account.States[stackID] = json.RawMessage(data)
+	setAt := time.Now().Unix()
+	stack.StateSetAt = &setAt
+	delete(s.uploads, objectID)
+	return true, nil
+}
+
+// awsIntegration returns an AWS integration with its stack attachments.
+func (s *Server) awsIntegration(id string) interface{} {
+	for _, i := range s.account.AWSIntegrations {
+		if i.ID != id {
+			continue
+		}
+		object := toObject("AwsIntegration", i)
+		attached := []map[string]interface{}{}
+		for _, stack := range s.account.Stacks {
+			for _, a := range stack.AttachedAWSIntegrations {
+				if a.IntegrationID == id {
+					attached = append(attached, map[string]interface{}{
+						"__typename": "AwsIntegrationStackAttachment",
+						"stackId":    stack.ID,
+						"isModule":   false,
+						"read":       a.Read,
+						"write":      a.Write,
+					})
+				}
+			}
+		}
+		object["attachedStacks"] = attached
+		return object
+	}
+	return nil
+}
+
+// azureIntegration returns an Azure integration with its stack attachments.
+func (s *Server) azureIntegration(id string) interface{} {
+	for _, i := range s.account.AzureIntegrations {
+		if i.ID != id {
+			continue
+		}
+		object := toObject("AzureIntegration", i)
+		attached := []map[string]interface{}{}
+		for _, stack := range s.account.Stacks {
+			for _, a := range stack.AttachedAzureIntegrations {
+				if a.IntegrationID == id {
+					attached = append(attached, map[string]interface{}{
+						"__typename":     "AzureIntegrationStackAttachment",
+						"stackId":        stack.ID,
+						"isModule":       false,
+						"read":           a.Read,
+						"write":          a.Write,
+						"subscriptionId": a.SubscriptionID,
+					})
+				}
+			}
+		}
+		object["attachedStacks"] = attached
+		return object
+	}
+	return nil
+}
+
+// stringArg returns a string argument of a field.
+func stringArg(f field, name string) string {
+	return stringValue(f.args[name])
+}
+
+// objectArg returns an input object argument of a field.
+func objectArg(f field, name string) map[string]interface{} {
+	object, _ := f.args[name].(map[string]interface{})
+	return object
+}
+
+// stringValue converts an argument value to a string.
+func stringValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+// stringPointer converts an argument value to an optional string.
+func stringPointer(v interface{}) *string {
+	if v == nil {
+		return nil
+	}
+	s := stringValue(v)
+	return &s
+}
+
+// boolValue converts an argument value to a bool.
+func boolValue(v interface{}) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+// stringList converts an argument value to a list of strings.
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		list = append(list, stringValue(item))
+	}
+	return list
+}
@@ -0,0 +1,359 @@
+package fakespacelift
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// nonSlug matches the runs of characters that a slug replaces with "-".
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug returns the ID Spacelift derives from a resource name.
+func slug(name string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// space returns a space of the account by ID.
+func (s *Server) space(id string) (*models.Space, error) {
+	for i := range s.account.Spaces {
+		if s.account.Spaces[i].ID == id {
+			return &s.account.Spaces[i], nil
+		}
+	}
+	return nil, fmt.Errorf("space %q not found", id)
+}
+
+// context returns a context of the account by ID.
+func (s *Server) context(id string) (*models.Context, error) {
+	for i := range s.account.Contexts {
+		if s.account.Contexts[i].ID == id {
+			return &s.account.Contexts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("context %q not found", id)
+}
+
+// policy returns a policy of the account by ID.
+func (s *Server) policy(id string) (*models.Policy, error) {
+	for i := range s.account.Policies {
+		if s.account.Policies[i].ID == id {
+			return &s.account.Policies[i], nil
+		}
+	}
+	return nil, fmt.Errorf("policy %q not found", id)
+}
+
+// role returns a role of the account by ID.
+func (s *Server) role(id string) (*Role, error) {
+	for i := range s.account.Roles {
+		if s.account.Roles[i].ID == id {
+			return &s.account.Roles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("role %q not found", id)
+}
+
+// spaceOrRoot returns a space ID, or root when it is empty, after checking
+// that the space exists.
+func (s *Server) spaceOrRoot(id string) (string, error) {
+	if id == "" {
+		id = "root"
+	}
+	if _, err := s.space(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// spaceCreate creates a space. Spacelift suffixes space IDs to keep them
+// unique; the fake numbers them.
+func (s *Server) spaceCreate(input map[string]interface{}) (interface{}, error) {
+	parent, err := s.spaceOrRoot(stringValue(input["parentSpace"]))
+	if err != nil {
+		return nil, err
+	}
+	s.created++
+	space := models.Space{
+		ID:              fmt.Sprintf("%s-%02d", slug(stringValue(input["name"])), s.created),
+		Name:            stringValue(input["name"]),
+		Description:     stringValue(input["description"]),
+		ParentSpace:     &parent,
+		InheritEntities: boolValue(input["inheritEntities"]),
+		Labels:          stringList(input["labels"]),
+	}
+	s.account.Spaces = append(s.account.Spaces, space)
+	return toObject("Space", space), nil
+}
+
+// contextCreate creates a context without config elements.
+func (s *Server) contextCreate(input map[string]interface{}) (interface{}, error) {
+	name := stringValue(input["name"])
+	if _, err := s.context(slug(name)); err == nil {
+		return nil, fmt.Errorf("context %q already exists", name)
+	}
+	space, err := s.spaceOrRoot(stringValue(input["space"]))
+	if err != nil {
+		return nil, err
+	}
+	hooks, _ := input["hooks"].(map[string]interface{})
+	now := time.Now().Unix()
+	c := models.Context{
+		ID:          slug(name),
+		Name:        name,
+		Description: stringPointer(input["description"]),
+		Space:       space,
+		Labels:      stringList(input["labels"]),
+		Hooks: models.Hooks{
+			AfterApply:    stringList(hooks["afterApply"]),
+			BeforeApply:   stringList(hooks["beforeApply"]),
+			AfterInit:     stringList(hooks["afterInit"]),
+			BeforeInit:    stringList(hooks["beforeInit"]),
+			AfterPlan:     stringList(hooks["afterPlan"]),
+			BeforePlan:    stringList(hooks["beforePlan"]),
+			AfterPerform:  stringList(hooks["afterPerform"]),
+			BeforePerform: stringList(hooks["beforePerform"]),
+			AfterDestroy:  stringList(hooks["afterDestroy"]),
+			BeforeDestroy: stringList(hooks["beforeDestroy"]),
+			AfterRun:      stringList(hooks["afterRun"]),
+		},
+		Config:    []models.ConfigElement{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.account.Contexts = append(s.account.Contexts, c)
+	return contextObject(c), nil
+}
+
+// contextConfigAdd adds or replaces a config element of a context. Mounted
+// file content arrives base64 encoded.
+func (s *Server) contextConfigAdd(contextID string, input map[string]interface{}) (interface{}, error) {
+	c, err := s.context(contextID)
+	if err != nil {
+		return nil, err
+	}
+	element := models.ConfigElement{
+		ID:          stringValue(input["id"]),
+		Type:        stringValue(input["type"]),
+		Value:       stringValue(input["value"]),
+		WriteOnly:   boolValue(input["writeOnly"]),
+		Description: stringValue(input["description"]),
+	}
+	if element.Type == "FILE_MOUNT" {
+		content, err := base64.StdEncoding.DecodeString(element.Value)
+		if err != nil {
+			return nil, fmt.Errorf("file %q is not base64 encoded", element.ID)
+		}
+		element.Value = string(content)
+	}
+
+	replaced := false
+	for i := range c.Config {
+		if c.Config[i].ID == element.ID {
+			c.Config[i], replaced = element, true
+		}
+	}
+	if !replaced {
+		c.Config = append(c.Config, element)
+	}
+	return toObject("ConfigElement", element), nil
+}
+
+// policyCreate creates a policy.
+func (s *Server) policyCreate(f field) (interface{}, error) {
+	name := stringArg(f, "name")
+	if _, err := s.policy(slug(name)); err == nil {
+		return nil, fmt.Errorf("policy %q already exists", name)
+	}
+	space, err := s.spaceOrRoot(stringArg(f, "space"))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	policy := models.Policy{
+		ID:          slug(name),
+		Name:        name,
+		Description: stringPointer(f.args["description"]),
+		Space:       space,
+		Type:        stringArg(f, "type"),
+		EngineType:  "REGO",
+		Body:        stringArg(f, "body"),
+		Labels:      stringList(f.args["labels"]),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.account.Policies = append(s.account.Policies, policy)
+	return toObject("Policy", policy), nil
+}
+
+// stackCreate creates a Terraform stack from a StackInput.
+func (s *Server) stackCreate(input map[string]interface{}, manageState bool) (interface{}, error) {
+	name := stringValue(input["name"])
+	if _, err := s.stack(slug(name)); err == nil {
+		return nil, fmt.Errorf("stack %q already exists", name)
+	}
+	if _, err := s.spaceOrRoot(stringValue(input["space"])); err != nil {
+		return nil, err
+	}
+	provider := stringValue(input["provider"])
+	if provider == "" {
+		provider = "GITHUB"
+	}
+	s.account.Stacks = append(s.account.Stacks, models.Stack{
+		ID:               slug(name),
+		Provider:         provider,
+		VendorType:       "StackConfigVendorTerraform",
+		ManagesStateFile: manageState,
+	})
+	return s.stackUpdate(slug(name), input)
+}
+
+// contextAttach attaches a context to a stack.
+func (s *Server) contextAttach(contextID, stackID string, priority int) (interface{}, error) {
+	if _, err := s.context(contextID); err != nil {
+		return nil, err
+	}
+	stack, err := s.stack(stackID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range stack.AttachedContexts {
+		if a.ContextID == contextID {
+			return nil, fmt.Errorf("context %q is already attached to stack %q", contextID, stackID)
+		}
+	}
+	attachment := models.ContextAttachment{ID: stackID + "-" + contextID, ContextID: contextID, Priority: priority}
+	stack.AttachedContexts = append(stack.AttachedContexts, attachment)
+	return toObject("StackContextAttachment", attachment), nil
+}
+
+// policyAttach attaches a policy to a stack.
+func (s *Server) policyAttach(policyID, stackID string) (interface{}, error) {
+	if _, err := s.policy(policyID); err != nil {
+		return nil, err
+	}
+	stack, err := s.stack(stackID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range stack.AttachedPolicies {
+		if a.PolicyID == policyID {
+			return nil, fmt.Errorf("policy %q is already attached to stack %q", policyID, stackID)
+		}
+	}
+	attachment := models.PolicyAttachment{ID: stackID + "-" + policyID, PolicyID: policyID}
+	stack.AttachedPolicies = append(stack.AttachedPolicies, attachment)
+	return toObject("PolicyStackAttachment", attachment), nil
+}
+
+// awsIntegrationAttach attaches an AWS integration to a stack.
+func (s *Server) awsIntegrationAttach(f field) (interface{}, error) {
+	id, stackID := stringArg(f, "id"), stringArg(f, "stack")
+	if s.awsIntegration(id) == nil {
+		return nil, fmt.Errorf("AWS integration %q not found", id)
+	}
+	stack, err := s.stack(stackID)
+	if err != nil {
+		return nil, err
+	}
+	stack.AttachedAWSIntegrations = append(stack.AttachedAWSIntegrations, models.AWSIntegrationAttachment{
+		IntegrationID: id,
+		Read:          boolValue(f.args["read"]),
+		Write:         boolValue(f.args["write"]),
+	})
+	return map[string]interface{}{"__typename": "AwsIntegrationStackAttachment", "id": stackID + "-" + id}, nil
+}
+
+// azureIntegrationAttach attaches an Azure integration to a stack.
+func (s *Server) azureIntegrationAttach(f field) (interface{}, error) {
+	id, stackID := stringArg(f, "id"), stringArg(f, "stack")
+	if s.azureIntegration(id) == nil {
+		return nil, fmt.Errorf("Azure integration %q not found", id)
+	}
+	stack, err := s.stack(stackID)
+	if err != nil {
+		return nil, err
+	}
+	stack.AttachedAzureIntegrations = append(stack.AttachedAzureIntegrations, models.AzureIntegrationAttachment{
+		IntegrationID:  id,
+		Read:           boolValue(f.args["read"]),
+		Write:          boolValue(f.args["write"]),
+		SubscriptionID: stringPointer(f.args["subscriptionId"]),
+	})
+	return map[string]interface{}{"__typename": "AzureIntegrationStackAttachment", "id": stackID + "-" + id}, nil
+}
+
+// stackDependencyCreate makes a stack depend on another stack.
+func (s *Server) stackDependencyCreate(input map[string]interface{}) (interface{}, error) {
+	stackID, dependsOnID := stringValue(input["stackId"]), stringValue(input["dependsOnStackId"])
+	stack, err := s.stack(stackID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.stack(dependsOnID); err != nil {
+		return nil, err
+	}
+	dependency := models.StackDependency{ID: stackID + "-" + dependsOnID, DependsOnStackID: dependsOnID}
+	stack.DependsOn = append(stack.DependsOn, dependency)
+	return map[string]interface{}{"__typename": "StackDependency", "id": dependency.ID}, nil
+}
+
+// roleCreate creates a custom role.
+func (s *Server) roleCreate(input map[string]interface{}) (interface{}, error) {
+	name := stringValue(input["name"])
+	if _, err := s.role(slug(name)); err == nil {
+		return nil, fmt.Errorf("role %q already exists", name)
+	}
+	role := Role{ID: slug(name), Name: name, Actions: stringList(input["actions"])}
+	s.account.Roles = append(s.account.Roles, role)
+	return roleObject(role), nil
+}
+
+// stackRoleBindingCreate binds a role to a stack.
+func (s *Server) stackRoleBindingCreate(input map[string]interface{}) (interface{}, error) {
+	role, err := s.role(stringValue(input["roleID"]))
+	if err != nil {
+		return nil, err
+	}
+	stackID := stringValue(input["stackID"])
+	if _, err := s.stack(stackID); err != nil {
+		return nil, err
+	}
+	if _, err := s.space(stringValue(input["spaceID"])); err != nil {
+		return nil, err
+	}
+	for _, id := range role.Stacks {
+		if id == stackID {
+			return nil, fmt.Errorf("role %q is already bound to stack %q", role.ID, stackID)
+		}
+	}
+	role.Stacks = append(role.Stacks, stackID)
+	return map[string]interface{}{"__typename": "StackRoleBinding", "id": role.ID + "-" + stackID, "stackID": stackID}, nil
+}
+
+// roleObject converts a role to the Role type of the schema.
+func roleObject(r Role) map[string]interface{} {
+	bindings := []map[string]interface{}{}
+	for _, stackID := range r.Stacks {
+		bindings = append(bindings, map[string]interface{}{"__typename": "StackRoleBinding", "id": r.ID + "-" + stackID, "stackID": stackID})
+	}
+	return map[string]interface{}{
+		"__typename":        "Role",
+		"id":                r.ID,
+		"name":              r.Name,
+		"actions":           r.Actions,
+		"stackRoleBindings": bindings,
+	}
+}
+
+// intValue converts an argument value to an int.
+func intValue(v interface{}) int {
+	n, _ := v.(json.Number)
+	i, _ := n.Int64()
+	return int(i)
+}
@@ -0,0 +1,211 @@
+// Package fakespacelift is an in-process fake of the Spacelift GraphQL API,
+// backed by a JSON fixture. It serves the queries and mutations SpaceBridge
+// uses for discovery, state migration and apply, plus a blob store behind
+// presigned state URLs, so commands can run without a Spacelift account.
+//
+// Changes made through the API live in memory until the server is closed;
+// the fixture file is never written.
+package fakespacelift
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// jwt is the token the fake issues to every API key.
+const jwt = "fakespacelift-token"
+
+// grant is what a presigned URL allows.
+type grant struct {
+	method   string // GET downloads a stack's state, PUT uploads an object
+	stackID  string
+	objectID string
+}
+
+// Server is a fake Spacelift account served over HTTP.
+type Server struct {
+	// URL is the account URL, e.g. http://127.0.0.1:1234.
+	URL string
+
+	mu       sync.Mutex
+	account  Account
+	locked   map[string]bool
+	uploads  map[string][]byte // Object ID -> uploaded state
+	grants   map[string]grant  // Presigned URL token -> grant
+	uploaded int
+	created  int // Spaces created, to number their IDs
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// Start serves an account on a free local port.
+func Start(account Account) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	if account.States == nil {
+		account.States = make(map[string]json.RawMessage)
+	}
+	s := &Server{
+		URL:      "http://" + listener.Addr().String(),
+		account:  account,
+		locked:   make(map[string]bool),
+		uploads:  make(map[string][]byte),
+		grants:   make(map[string]grant),
+		listener: listener,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", s.handleGraphQL)
+	mux.HandleFunc("/blobs/", s.handleBlob)
+	s.server = &http.Server{Handler: mux}
+	go s.server.Serve(listener)
+
+	return s, nil
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// Account returns a copy of the account as changed through the API.
+func (s *Server) Account() Account {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account := s.account
+	account.Spaces = append([]models.Space(nil), s.account.Spaces...)
+	account.Contexts = append([]models.Context(nil), s.account.Contexts...)
+	account.Policies = append([]models.Policy(nil), s.account.Policies...)
+	account.Stacks = append([]models.Stack(nil), s.account.Stacks...)
+	account.Roles = append([]Role(nil), s.account.Roles...)
+	account.States = make(map[string]json.RawMessage, len(s.account.States))
+	for id, state := range s.account.States {
+		account.States[id] = state
+	}
+	return account
+}
+
+// graphqlRequest is the body of a GraphQL request.
+type graphqlRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// graphqlError is one entry of a GraphQL error response.
+type graphqlError struct {
+	Message string   `json:"message"`
+	Path    []string `json:"path,omitempty"`
+}
+
+// handleGraphQL executes a GraphQL request.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req graphqlRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	op, err := parseOperation(req.Query, req.Variables)
+	if err != nil {
+		writeJSON(w, map[string]interface{}{"errors": []graphqlError{{Message: "parse error: " + err.Error()}}})
+		return
+	}
+
+	authenticated := r.Header.Get("Authorization") == "Bearer "+jwt
+	data := make(map[string]interface{})
+	var errs []graphqlError
+
+	s.mu.Lock()
+	for _, f := range op.fields {
+		if !authenticated && f.name != "apiKeyUser" {
+			errs = append(errs, graphqlError{Message: "unauthorized", Path: []string{f.key()}})
+			continue
+		}
+		value, err := s.resolve(op.kind, f)
+		if err != nil {
+			errs = append(errs, graphqlError{Message: err.Error(), Path: []string{f.key()}})
+			data[f.key()] = nil
+			continue
+		}
+		data[f.key()] = project(value, f.selections)
+	}
+	s.mu.Unlock()
+
+	response := map[string]interface{}{"data": data}
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+	writeJSON(w, response)
+}
+
+// handleBlob serves presigned state downloads and uploads.
+func (s *Server) handleBlob(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, "/blobs/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grants[token]
+	if !ok || g.method != r.Method {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		state, ok := s.account.States[g.stackID]
+		if !ok {
+			http.Error(w, "no such state", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(state)
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		s.uploads[g.objectID] = data
+		delete(s.grants, token)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// presign returns a URL that allows one kind of blob access.
+func (s *Server) presign(g grant) string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("fakespacelift: cannot generate token: %v", err))
+	}
+	token := hex.EncodeToString(b)
+	s.grants[token] = g
+	return s.URL + "/blobs/" + token
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
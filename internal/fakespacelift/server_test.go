@@ -0,0 +1,257 @@
+package fakespacelift
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// graphqlResponse is the body of a GraphQL response.
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []graphqlError             `json:"errors"`
+}
+
+// startAccount serves an account with two managed-state stacks, only one of
+// which allows external state access.
+func startAccount(t *testing.T) *Server {
+	t.Helper()
+	appRoot := "app"
+	s, err := Start(Account{
+		Manifest: discovery.Manifest{
+			Spaces: []models.Space{{ID: "root", Name: "root"}},
+			Stacks: []models.Stack{
+				{ID: "network", Name: "network", Space: "root", ManagesStateFile: true, ExternalStateAccessEnabled: true},
+				{ID: "app", Name: "app", Space: "root", ManagesStateFile: true, Labels: []string{"team:app"}, ProjectRoot: &appRoot},
+			},
+		},
+		States: map[string]json.RawMessage{"network": json.RawMessage(`{"version":4,"serial":7}`)},
+	})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// post sends a GraphQL request, with the token the fake issues unless
+// anonymous is set.
+func post(t *testing.T, s *Server, query string, variables map[string]interface{}, anonymous bool) graphqlResponse {
+	t.Helper()
+	body, err := json.Marshal(graphqlRequest{Query: query, Variables: variables})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	if !anonymous {
+		req.Header.Set("Authorization", "Bearer "+jwt)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /graphql: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var out graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return out
+}
+
+// blob sends a request to a presigned URL and returns the status and body.
+func blob(t *testing.T, method, url, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data)
+}
+
+func TestResolvers(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		anonymous bool
+		wantData  string // Compact JSON of the data, "" to skip
+		wantError string // Substring of the first error, "" for none
+	}{
+		{
+			name:     "list with projection",
+			query:    `query { stacks { id name } }`,
+			wantData: `{"stacks":[{"id":"network","name":"network"},{"id":"app","name":"app"}]}`,
+		},
+		{
+			name:      "field with variable argument",
+			query:     `query Stack($id: ID!) { stack(id: $id) { id externalStateAccessEnabled } }`,
+			variables: map[string]interface{}{"id": "app"},
+			wantData:  `{"stack":{"id":"app","externalStateAccessEnabled":false}}`,
+		},
+		{
+			name:     "alias and unknown stack",
+			query:    `query { missing: stack(id: "nope") { id } }`,
+			wantData: `{"missing":null}`,
+		},
+		{
+			name:      "login without a token",
+			query:     `mutation { apiKeyUser(id: "key", secret: "secret") { jwt } }`,
+			anonymous: true,
+			wantData:  `{"apiKeyUser":{"jwt":"` + jwt + `"}}`,
+		},
+		{
+			name:      "query without a token",
+			query:     `query { stacks { id } }`,
+			anonymous: true,
+			wantError: "unauthorized",
+		},
+		{
+			name:      "unsupported mutation",
+			query:     `mutation { stackDelete(id: "app") { id } }`,
+			wantError: "not supported",
+		},
+		{
+			name:      "download without external access",
+			query:     `mutation { stateDownloadUrl(input: {stackId: "app"}) { url } }`,
+			wantError: "external state access is not enabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := startAccount(t)
+			resp := post(t, s, tt.query, tt.variables, tt.anonymous)
+
+			if tt.wantError != "" {
+				if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, tt.wantError) {
+					t.Fatalf("errors = %+v, want one containing %q", resp.Errors, tt.wantError)
+				}
+				return
+			}
+			if len(resp.Errors) > 0 {
+				t.Fatalf("unexpected errors: %+v", resp.Errors)
+			}
+			data, err := json.Marshal(resp.Data)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if tt.wantData != "" && !jsonEqual(t, string(data), tt.wantData) {
+				t.Errorf("data = %s, want %s", data, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestStackUpdate(t *testing.T) {
+	s := startAccount(t)
+	resp := post(t, s, `mutation Update($input: StackInput!) { stackUpdate(id: "app", input: $input) { id } }`, map[string]interface{}{
+		"input": map[string]interface{}{
+			"name":         "app",
+			"isDisabled":   true,
+			"labels":       []string{"migrated"},
+			"vendorConfig": map[string]interface{}{"terraform": map[string]interface{}{"externalStateAccessEnabled": true}},
+		},
+	}, false)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("stackUpdate: %+v", resp.Errors)
+	}
+
+	stack := s.Account().Stacks[1]
+	if !stack.IsDisabled || !stack.ExternalStateAccessEnabled || len(stack.Labels) != 1 || stack.Labels[0] != "migrated" {
+		t.Errorf("stack after update = %+v", stack)
+	}
+	// The input replaces the stack, as in Spacelift
+	if stack.ProjectRoot != nil {
+		t.Errorf("project root left out of the input = %q, want it reset", *stack.ProjectRoot)
+	}
+}
+
+func TestBlobStore(t *testing.T) {
+	s := startAccount(t)
+
+	// Download
+	resp := post(t, s, `mutation { stateDownloadUrl(input: {stackId: "network"}) { url } }`, nil, false)
+	var download struct{ URL string }
+	decodeField(t, resp, "stateDownloadUrl", &download)
+	if status, _ := blob(t, http.MethodPut, download.URL, "{}"); status != http.StatusForbidden {
+		t.Errorf("PUT to a download URL = %d, want %d", status, http.StatusForbidden)
+	}
+	status, body := blob(t, http.MethodGet, download.URL, "")
+	if status != http.StatusOK || body != `{"version":4,"serial":7}` {
+		t.Errorf("GET download URL = %d %s", status, body)
+	}
+
+	// Upload and import
+	resp = post(t, s, `mutation { stateUploadUrl { url objectId } }`, nil, false)
+	var upload struct {
+		URL      string
+		ObjectID string
+	}
+	decodeField(t, resp, "stateUploadUrl", &upload)
+	if status, _ := blob(t, http.MethodPut, upload.URL, `{"version":4,"serial":8}`); status != http.StatusOK {
+		t.Fatalf("PUT upload URL = %d", status)
+	}
+	if status, _ := blob(t, http.MethodPut, upload.URL, `{}`); status != http.StatusForbidden {
+		t.Errorf("second PUT to an upload URL = %d, want %d", status, http.StatusForbidden)
+	}
+
+	resp = post(t, s, `mutation Import($state: String!) { stackManagedStateImport(stackId: "app", state: $state) }`, map[string]interface{}{"state": upload.ObjectID}, false)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("stackManagedStateImport: %+v", resp.Errors)
+	}
+	if got := string(s.Account().States["app"]); got != `{"version":4,"serial":8}` {
+		t.Errorf("imported state = %s", got)
+	}
+
+	// An object can be imported once
+	resp = post(t, s, `mutation Import($state: String!) { stackManagedStateImport(stackId: "app", state: $state) }`, map[string]interface{}{"state": upload.ObjectID}, false)
+	if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, "was not uploaded") {
+		t.Errorf("second import: errors = %+v", resp.Errors)
+	}
+
+	if status, _ := blob(t, http.MethodGet, s.URL+"/blobs/forged", ""); status != http.StatusForbidden {
+		t.Errorf("GET forged URL = %d, want %d", status, http.StatusForbidden)
+	}
+}
+
+// decodeField decodes one field of a response without errors.
+func decodeField(t *testing.T, resp graphqlResponse, key string, v interface{}) {
+	t.Helper()
+	if len(resp.Errors) > 0 {
+		t.Fatalf("%s: %+v", key, resp.Errors)
+	}
+	if err := json.Unmarshal(resp.Data[key], v); err != nil {
+		t.Fatalf("Unmarshal %s: %v", key, err)
+	}
+}
+
+// jsonEqual reports whether two JSON documents hold the same value.
+func jsonEqual(t *testing.T, a, b string) bool {
+	t.Helper()
+	var va, vb interface{}
+	if err := json.Unmarshal([]byte(a), &va); err != nil {
+		t.Fatalf("Unmarshal %s: %v", a, err)
+	}
+	if err := json.Unmarshal([]byte(b), &vb); err != nil {
+		t.Fatalf("Unmarshal %s: %v", b, err)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
@@ -4,6 +4,7 @@ package generator
 import (
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -14,14 +15,30 @@ import (
 	"github.com/jnesspace/spacebridge/pkg/config"
 )
 
+// ProviderVarsFile holds inline provider credentials when they are allowed.
+const ProviderVarsFile = "provider.auto.tfvars"
+
+// Output formats supported by the generator.
+const (
+	FormatHCL  = "hcl"  // Native Tofu syntax (.tf)
+	FormatJSON = "json" // Tofu JSON syntax (.tf.json)
+)
+
 // Generator creates Tofu code from a manifest.
 type Generator struct {
 	manifest        *discovery.Manifest
 	outputDir       string
-	safeMode        bool                     // Force autodeploy=false for safe state migration
-	autodeployList  []string                 // Stacks that originally had autodeploy=true
-	destConfig      *config.AccountConfig    // Destination account config for provider
-	migrationConfig *config.MigrationConfig  // Migration config for VCS overrides
+	format          string                  // Output format (FormatHCL or FormatJSON)
+	safeMode        bool                    // Force autodeploy=false for safe state migration
+	autodeployList  []string                // Stacks that originally had autodeploy=true
+	destConfig      *config.AccountConfig   // Destination account config for provider
+	migrationConfig *config.MigrationConfig // Migration config for VCS overrides
+
+	inlineCredentials bool     // Write destination credentials to provider.auto.tfvars
+	sensitiveValues   []string // Values that must never appear in generated Tofu code
+
+	files []string  // Paths written by Generate
+	out   io.Writer // Where the created files are reported
 }
 
 // New creates a new generator.
@@ -29,9 +46,17 @@ func New(manifest *discovery.Manifest, outputDir string) *Generator {
 	return &Generator{
 		manifest:  manifest,
 		outputDir: outputDir,
+		format:    FormatHCL,
+		out:       os.Stdout,
 	}
 }
 
+// WithOutput sets where the generator reports the files it creates.
+func (g *Generator) WithOutput(w io.Writer) *Generator {
+	g.out = w
+	return g
+}
+
 // WithSafeMode sets the generator to force autodeploy=false for safe migration.
 func (g *Generator) WithSafeMode(safe bool) *Generator {
 	g.safeMode = safe
@@ -44,208 +69,394 @@ func (g *Generator) WithDestinationConfig(cfg *config.AccountConfig) *Generator
 	return g
 }
 
+// WithInlineCredentials writes the destination API key to the git-ignored
+// provider.auto.tfvars and disables the preflight credentials check.
+func (g *Generator) WithInlineCredentials(allow bool) *Generator {
+	g.inlineCredentials = allow
+	return g
+}
+
+// WithSensitiveValues adds values (such as API secrets) that the preflight
+// check refuses to write into Tofu code.
+func (g *Generator) WithSensitiveValues(values ...string) *Generator {
+	g.sensitiveValues = append(g.sensitiveValues, values...)
+	return g
+}
+
 // WithMigrationConfig sets the migration config for VCS overrides.
 func (g *Generator) WithMigrationConfig(cfg *config.MigrationConfig) *Generator {
 	g.migrationConfig = cfg
 	return g
 }
 
+// WithFormat sets the output format (FormatHCL or FormatJSON).
+func (g *Generator) WithFormat(format string) *Generator {
+	g.format = format
+	return g
+}
+
+// Files returns the paths of the files written by Generate.
+func (g *Generator) Files() []string {
+	return g.files
+}
+
+// ValidateFormat checks that a format name is supported.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatHCL, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q (expected %q or %q)", format, FormatHCL, FormatJSON)
+	}
+}
+
 // Generate creates all Tofu files.
 func (g *Generator) Generate() error {
-	// Create output directory
-	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	if err := ValidateFormat(g.format); err != nil {
+		return err
 	}
 
+	// Render everything first so the preflight check sees every file
+	// before anything is written
+	var outputs []outputFile
+
 	// Generate main.tf with all resources
-	mainTF, err := g.generateMain()
+	main, err := g.renderTofuFile("main", g.generateMain())
 	if err != nil {
-		return fmt.Errorf("failed to generate main.tf: %w", err)
-	}
-	if err := g.writeFile("main.tf", mainTF); err != nil {
 		return err
 	}
+	outputs = append(outputs, main)
 
 	// Generate variables.tf for secrets
-	variablesTF := g.generateVariables()
-	if err := g.writeFile("variables.tf", variablesTF); err != nil {
+	variables, err := g.renderTofuFile("variables", g.generateVariables())
+	if err != nil {
 		return err
 	}
+	outputs = append(outputs, variables)
 
 	// Generate secrets.auto.tfvars.template
-	secretsTemplate := g.generateSecretsTemplate()
-	if err := g.writeFile("secrets.auto.tfvars.template", secretsTemplate); err != nil {
-		return err
-	}
+	outputs = append(outputs, outputFile{name: "secrets.auto.tfvars.template", content: g.generateSecretsTemplate()})
 
 	// Generate provider.tf
-	providerTF := g.generateProvider()
-	if err := g.writeFile("provider.tf", providerTF); err != nil {
+	provider, err := g.renderTofuFile("provider", g.generateProvider())
+	if err != nil {
 		return err
 	}
+	outputs = append(outputs, provider)
+
+	// Inline credentials only ever go to the git-ignored provider.auto.tfvars
+	if g.inlineCredentials && g.destConfig != nil && g.destConfig.SecretKey != "" {
+		outputs = append(outputs, outputFile{name: ProviderVarsFile, content: g.generateProviderVars(), secret: true})
+	}
 
 	// Generate autodeploy re-enable list if in safe mode
 	if g.safeMode && len(g.autodeployList) > 0 {
-		autodeployTF := g.generateAutodeployReEnable()
-		if err := g.writeFile("autodeploy_re_enable.tf.disabled", autodeployTF); err != nil {
+		outputs = append(outputs, outputFile{name: "autodeploy_re_enable.tf.disabled", content: g.generateAutodeployReEnable()})
+	}
+
+	if err := g.preflight(outputs); err != nil {
+		return err
+	}
+
+	// Create output directory
+	if err := os.MkdirAll(g.outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, out := range outputs {
+		if err := g.writeOutput(out); err != nil {
 			return err
 		}
 	}
 
+	return g.writeGitignore()
+}
+
+// outputFile is a rendered file waiting to be written.
+type outputFile struct {
+	name    string
+	content string
+	secret  bool // Written with 0600 permissions
+}
+
+// renderTofuFile renders a file in the configured format as <name>.tf or
+// <name>.tf.json.
+func (g *Generator) renderTofuFile(name string, f *file) (outputFile, error) {
+	if g.format == FormatJSON {
+		data, err := renderJSON(f)
+		if err != nil {
+			return outputFile{}, fmt.Errorf("failed to generate %s.tf.json: %w", name, err)
+		}
+		return outputFile{name: name + ".tf.json", content: string(data)}, nil
+	}
+	return outputFile{name: name + ".tf", content: renderHCL(f)}, nil
+}
+
+// preflight refuses to write known credentials into Tofu code, which is
+// meant to be committed for review. Only git-ignored secret files may hold
+// them, unless inline credentials are explicitly allowed.
+func (g *Generator) preflight(outputs []outputFile) error {
+	if g.inlineCredentials {
+		return nil
+	}
+
+	sensitive := append([]string{}, g.sensitiveValues...)
+	if g.destConfig != nil {
+		sensitive = append(sensitive, g.destConfig.SecretKey)
+	}
+
+	for _, out := range outputs {
+		if out.secret || !isTofuFile(out.name) {
+			continue
+		}
+		for _, value := range sensitive {
+			// Very short values would match by accident
+			if len(value) < 8 {
+				continue
+			}
+			if strings.Contains(out.content, value) || strings.Contains(out.content, escapeTemplate(value)) {
+				return fmt.Errorf("refusing to write %s: it contains a Spacelift API secret; remove it from the source or use --allow-inline-credentials", out.name)
+			}
+		}
+	}
+	return nil
+}
+
+// isTofuFile returns true for files that Tofu loads as configuration.
+func isTofuFile(name string) bool {
+	return strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json")
+}
+
+// writeOutput writes a rendered file, restricting permissions for secrets.
+func (g *Generator) writeOutput(out outputFile) error {
+	if !out.secret {
+		return g.writeFile(out.name, out.content)
+	}
+
+	path := filepath.Join(g.outputDir, out.name)
+	if err := os.WriteFile(path, []byte(out.content), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out.name, err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", out.name, err)
+	}
+	fmt.Fprintf(g.out, "  Created: %s (mode 0600)\n", path)
+	g.files = append(g.files, path)
 	return nil
 }
 
+// gitignoreEntries keep credentials, secrets and local state out of git.
+var gitignoreEntries = []string{
+	"secrets.auto.tfvars",
+	ProviderVarsFile,
+	".terraform/",
+	"*.tfstate",
+	"*.tfstate.*",
+}
+
+// writeGitignore creates .gitignore in the output directory, or adds any
+// missing entries to an existing one.
+func (g *Generator) writeGitignore() error {
+	path := filepath.Join(g.outputDir, ".gitignore")
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .gitignore: %w", err)
+	}
+
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, entry := range gitignoreEntries {
+		if !present[entry] {
+			missing = append(missing, entry)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	content := string(existing)
+	if content == "" {
+		content = "# Generated by SpaceBridge: keep credentials, secrets and state out of version control\n"
+	} else if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += strings.Join(missing, "\n") + "\n"
+
+	return g.writeFile(".gitignore", content)
+}
+
 // writeFile writes content to a file in the output directory.
 func (g *Generator) writeFile(filename, content string) error {
 	path := filepath.Join(g.outputDir, filename)
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write %s: %w", filename, err)
 	}
-	fmt.Printf("  Created: %s\n", path)
+	fmt.Fprintf(g.out, "  Created: %s\n", path)
+	g.files = append(g.files, path)
 	return nil
 }
 
-// generateProvider creates the provider configuration.
-func (g *Generator) generateProvider() string {
-	var sb strings.Builder
+// generateProvider creates the provider configuration. Credentials are never
+// written here: they come from variables (set in provider.auto.tfvars or
+// TF_VAR_*) or, when those are unset, the SPACELIFT_API_KEY_* environment
+// variables read by the provider itself.
+func (g *Generator) generateProvider() *file {
+	f := &file{
+		header: []string{
+			"Provider configuration for Spacelift",
+			"Documentation: https://registry.opentofu.org/providers/spacelift-io/spacelift/latest/docs",
+			"",
+			"Credentials are read from variables or the SPACELIFT_API_KEY_ENDPOINT,",
+			"SPACELIFT_API_KEY_ID and SPACELIFT_API_KEY_SECRET environment variables.",
+			"Never put them in this file.",
+		},
+	}
+
+	terraform := newBlock("terraform").add(
+		newBlock("required_providers").set("spacelift", object{
+			{name: "source", value: "spacelift-io/spacelift"},
+			{name: "version", value: "~> 1.0"},
+		}),
+	)
+	f.blocks = append(f.blocks, terraform)
+
+	// The endpoint is not secret, so the destination URL is a safe default
+	endpoint := newBlock("variable", "spacelift_api_key_endpoint")
+	endpoint.set("description", "Spacelift API endpoint (SPACELIFT_API_KEY_ENDPOINT is used when null)")
+	endpoint.set("type", typeExpr("string"))
+	if g.destConfig != nil && g.destConfig.URL != "" {
+		endpoint.set("default", g.destConfig.URL)
+	} else {
+		endpoint.set("default", nil)
+	}
 
-	sb.WriteString(`# Provider configuration for Spacelift
-# Documentation: https://registry.opentofu.org/providers/spacelift-io/spacelift/latest/docs
+	keyID := newBlock("variable", "spacelift_api_key_id")
+	keyID.set("description", "Spacelift API key ID (SPACELIFT_API_KEY_ID is used when null)")
+	keyID.set("type", typeExpr("string"))
+	keyID.set("default", nil)
 
-terraform {
-  required_providers {
-    spacelift = {
-      source  = "spacelift-io/spacelift"
-      version = "~> 1.0"
-    }
-  }
-}
+	keySecret := newBlock("variable", "spacelift_api_key_secret")
+	keySecret.set("description", "Spacelift API key secret (SPACELIFT_API_KEY_SECRET is used when null)")
+	keySecret.set("type", typeExpr("string"))
+	keySecret.set("default", nil)
+	keySecret.set("sensitive", true)
 
-`)
+	f.blocks = append(f.blocks, endpoint, keyID, keySecret)
 
+	provider := newBlock("provider", "spacelift")
 	if g.destConfig != nil && g.destConfig.URL != "" {
-		// Use destination config from environment
-		sb.WriteString("# Configured for destination account\n")
-		sb.WriteString("provider \"spacelift\" {\n")
-		sb.WriteString(fmt.Sprintf("  api_key_endpoint = %q\n", g.destConfig.URL))
-		sb.WriteString(fmt.Sprintf("  api_key_id       = %q\n", g.destConfig.KeyID))
-		sb.WriteString(fmt.Sprintf("  api_key_secret   = %q\n", g.destConfig.SecretKey))
-		sb.WriteString("}\n")
-	} else {
-		// Fallback to placeholder
-		sb.WriteString(`# Configure the Spacelift provider
-# Set SPACELIFT_API_KEY_ENDPOINT, SPACELIFT_API_KEY_ID, and SPACELIFT_API_KEY_SECRET
-# environment variables, or configure below:
-provider "spacelift" {
-  # api_key_endpoint = "https://your-account.app.spacelift.io"
-  # api_key_id       = "your-api-key-id"
-  # api_key_secret   = "your-api-key-secret"
-}
-`)
+		provider.comment = "Configured for destination account"
 	}
+	provider.set("api_key_endpoint", expr("var.spacelift_api_key_endpoint"))
+	provider.set("api_key_id", expr("var.spacelift_api_key_id"))
+	provider.set("api_key_secret", expr("var.spacelift_api_key_secret"))
+	f.blocks = append(f.blocks, provider)
 
-	return sb.String()
+	return f
 }
 
-// generateMain creates the main.tf with all resources.
-func (g *Generator) generateMain() (string, error) {
-	var sb strings.Builder
-
-	sb.WriteString(fmt.Sprintf(`# Generated by SpaceBridge
-# Source: %s
-#
-# This file contains all Spacelift resources exported from the source account.
-# Review and modify as needed before applying.
+// generateProviderVars creates provider.auto.tfvars with inline destination
+// credentials.
+func (g *Generator) generateProviderVars() string {
+	return RenderTFVars([]string{
+		"Destination Spacelift API credentials",
+		"WARNING: Do not commit this file to version control!",
+	}, []TFVar{
+		{Name: "spacelift_api_key_id", Value: g.destConfig.KeyID},
+		{Name: "spacelift_api_key_secret", Value: g.destConfig.SecretKey},
+	})
+}
 
-`, g.manifest.SourceURL))
+// generateMain creates the main.tf with all resources.
+func (g *Generator) generateMain() *file {
+	f := &file{
+		header: []string{
+			"Generated by SpaceBridge",
+			"Source: " + g.manifest.SourceURL,
+			"",
+			"This file contains all Spacelift resources exported from the source account.",
+			"Review and modify as needed before applying.",
+		},
+	}
 
 	// Generate spaces (in dependency order - parents before children)
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# SPACES\n")
-	sb.WriteString("# =============================================================================\n\n")
-	sortedSpaces := g.sortSpacesByDependency()
-	for _, space := range sortedSpaces {
-		if space.ID == "root" {
-			continue // Skip root space - it always exists
+	spaces := &section{title: "SPACES"}
+	for _, space := range g.sortSpacesByDependency() {
+		if !g.IsSpaceGenerated(space.ID) {
+			continue // Root always exists; mapped spaces already exist in the destination
 		}
-		sb.WriteString(g.generateSpace(space))
-		sb.WriteString("\n")
+		spaces.blocks = append(spaces.blocks, g.generateSpace(space))
 	}
+	f.sections = append(f.sections, spaces)
 
 	// Generate contexts
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# CONTEXTS\n")
-	sb.WriteString("# =============================================================================\n\n")
+	contexts := &section{title: "CONTEXTS"}
 	for _, ctx := range g.manifest.Contexts {
-		sb.WriteString(g.generateContext(ctx))
-		sb.WriteString("\n")
+		contexts.blocks = append(contexts.blocks, g.generateContext(ctx))
 	}
+	f.sections = append(f.sections, contexts)
 
 	// Generate environment variables and mounted files for contexts
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# CONTEXT CONFIGURATION (Environment Variables & Mounted Files)\n")
-	sb.WriteString("# =============================================================================\n\n")
+	configs := &section{title: "CONTEXT CONFIGURATION (Environment Variables & Mounted Files)"}
 	for _, ctx := range g.manifest.Contexts {
 		for _, cfg := range ctx.Config {
-			sb.WriteString(g.generateConfigElement(ctx.ID, cfg))
-			sb.WriteString("\n")
+			if b := g.generateConfigElement(ctx.ID, cfg); b != nil {
+				configs.blocks = append(configs.blocks, b)
+			}
 		}
 	}
+	f.sections = append(f.sections, configs)
 
 	// Generate policies
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# POLICIES\n")
-	sb.WriteString("# =============================================================================\n\n")
+	policies := &section{title: "POLICIES"}
 	for _, policy := range g.manifest.Policies {
-		sb.WriteString(g.generatePolicy(policy))
-		sb.WriteString("\n")
+		policies.blocks = append(policies.blocks, g.generatePolicy(policy))
 	}
+	f.sections = append(f.sections, policies)
 
 	// Generate stacks
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# STACKS\n")
-	sb.WriteString("# =============================================================================\n\n")
+	stacks := &section{title: "STACKS"}
+	g.autodeployList = nil
 	for _, stack := range g.manifest.Stacks {
 		// Track stacks that originally had autodeploy enabled
 		if g.safeMode && stack.Autodeploy {
 			g.autodeployList = append(g.autodeployList, stack.Name)
 		}
-		sb.WriteString(g.generateStack(stack))
-		sb.WriteString("\n")
+		stacks.blocks = append(stacks.blocks, g.generateStack(stack))
 	}
+	f.sections = append(f.sections, stacks)
 
 	// Generate context attachments
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# CONTEXT ATTACHMENTS\n")
-	sb.WriteString("# =============================================================================\n\n")
+	contextAttachments := &section{title: "CONTEXT ATTACHMENTS"}
 	for _, stack := range g.manifest.Stacks {
 		for _, attachment := range stack.AttachedContexts {
-			sb.WriteString(g.generateContextAttachment(stack.ID, attachment))
-			sb.WriteString("\n")
+			contextAttachments.blocks = append(contextAttachments.blocks, g.generateContextAttachment(stack.ID, attachment))
 		}
 	}
+	f.sections = append(f.sections, contextAttachments)
 
 	// Generate policy attachments
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# POLICY ATTACHMENTS\n")
-	sb.WriteString("# =============================================================================\n\n")
+	policyAttachments := &section{title: "POLICY ATTACHMENTS"}
 	for _, stack := range g.manifest.Stacks {
 		for _, attachment := range stack.AttachedPolicies {
-			sb.WriteString(g.generatePolicyAttachment(stack.ID, attachment))
-			sb.WriteString("\n")
+			policyAttachments.blocks = append(policyAttachments.blocks, g.generatePolicyAttachment(stack.ID, attachment))
 		}
 	}
+	f.sections = append(f.sections, policyAttachments)
 
 	// Generate stack dependencies
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# STACK DEPENDENCIES\n")
-	sb.WriteString("# =============================================================================\n\n")
+	dependencies := &section{title: "STACK DEPENDENCIES"}
 	for _, stack := range g.manifest.Stacks {
 		for _, dep := range stack.DependsOn {
-			sb.WriteString(g.generateStackDependency(stack.ID, dep))
-			sb.WriteString("\n")
+			dependencies.blocks = append(dependencies.blocks, g.generateStackDependency(stack.ID, dep))
 		}
 	}
+	f.sections = append(f.sections, dependencies)
 
 	// Generate admin role attachments (replaces deprecated administrative = true)
 	hasAdminStacks := false
@@ -256,568 +467,537 @@ func (g *Generator) generateMain() (string, error) {
 		}
 	}
 	if hasAdminStacks {
-		sb.WriteString("# =============================================================================\n")
-		sb.WriteString("# ADMIN ROLE & ATTACHMENTS\n")
-		sb.WriteString("# Replaces deprecated 'administrative = true' flag on stacks\n")
-		sb.WriteString("# =============================================================================\n\n")
-		sb.WriteString(g.generateAdminRole())
-		sb.WriteString("\n")
+		admin := &section{
+			title:    "ADMIN ROLE & ATTACHMENTS",
+			subtitle: "Replaces deprecated 'administrative = true' flag on stacks",
+		}
+		admin.blocks = append(admin.blocks, g.generateAdminRole())
 		for _, stack := range g.manifest.Stacks {
 			if stack.Administrative {
-				sb.WriteString(g.generateAdminRoleAttachment(stack))
-				sb.WriteString("\n")
+				admin.blocks = append(admin.blocks, g.generateAdminRoleAttachment(stack))
 			}
 		}
+		f.sections = append(f.sections, admin)
 	}
 
 	// Generate AWS integrations
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# AWS INTEGRATIONS\n")
-	sb.WriteString("# =============================================================================\n\n")
+	aws := &section{title: "AWS INTEGRATIONS"}
 	if len(g.manifest.AWSIntegrations) > 0 {
-		sb.WriteString("# NOTE: AWS integrations require IAM trust policy updates in your AWS account.\n")
-		sb.WriteString("# The destination Spacelift account's OIDC provider must be trusted by the IAM role.\n\n")
+		aws.notes = []string{
+			"NOTE: AWS integrations require IAM trust policy updates in your AWS account.",
+			"The destination Spacelift account's OIDC provider must be trusted by the IAM role.",
+		}
 	}
 	for _, integration := range g.manifest.AWSIntegrations {
-		sb.WriteString(g.generateAWSIntegration(integration))
-		sb.WriteString("\n")
+		aws.blocks = append(aws.blocks, g.generateAWSIntegration(integration))
 	}
+	f.sections = append(f.sections, aws)
 
 	// Generate Azure integrations
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# AZURE INTEGRATIONS\n")
-	sb.WriteString("# =============================================================================\n\n")
+	azure := &section{title: "AZURE INTEGRATIONS"}
 	if len(g.manifest.AzureIntegrations) > 0 {
-		sb.WriteString("# NOTE: Azure integrations require app registration updates in Azure AD.\n")
-		sb.WriteString("# The destination Spacelift account must be configured as a trusted identity provider.\n\n")
+		azure.notes = []string{
+			"NOTE: Azure integrations require app registration updates in Azure AD.",
+			"The destination Spacelift account must be configured as a trusted identity provider.",
+		}
 	}
 	for _, integration := range g.manifest.AzureIntegrations {
-		sb.WriteString(g.generateAzureIntegration(integration))
-		sb.WriteString("\n")
+		azure.blocks = append(azure.blocks, g.generateAzureIntegration(integration))
 	}
+	f.sections = append(f.sections, azure)
 
 	// Generate AWS integration attachments
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# AWS INTEGRATION ATTACHMENTS\n")
-	sb.WriteString("# =============================================================================\n\n")
+	awsAttachments := &section{title: "AWS INTEGRATION ATTACHMENTS"}
 	for _, stack := range g.manifest.Stacks {
 		for _, attachment := range stack.AttachedAWSIntegrations {
-			sb.WriteString(g.generateAWSIntegrationAttachment(stack.ID, attachment))
-			sb.WriteString("\n")
+			awsAttachments.blocks = append(awsAttachments.blocks, g.generateAWSIntegrationAttachment(stack.ID, attachment))
 		}
 	}
+	f.sections = append(f.sections, awsAttachments)
 
 	// Generate Azure integration attachments
-	sb.WriteString("# =============================================================================\n")
-	sb.WriteString("# AZURE INTEGRATION ATTACHMENTS\n")
-	sb.WriteString("# =============================================================================\n\n")
+	azureAttachments := &section{title: "AZURE INTEGRATION ATTACHMENTS"}
 	for _, stack := range g.manifest.Stacks {
 		for _, attachment := range stack.AttachedAzureIntegrations {
-			sb.WriteString(g.generateAzureIntegrationAttachment(stack.ID, attachment))
-			sb.WriteString("\n")
+			azureAttachments.blocks = append(azureAttachments.blocks, g.generateAzureIntegrationAttachment(stack.ID, attachment))
 		}
 	}
+	f.sections = append(f.sections, azureAttachments)
+
+	return f
+}
+
+// spaceRef returns the value for a space_id attribute: a literal ID for
+// root and for spaces mapped onto existing destination spaces, otherwise a
+// reference to the generated space resource.
+func (g *Generator) spaceRef(spaceID string) interface{} {
+	if destID, ok := g.mappedSpace(spaceID); ok {
+		return destID
+	}
+	if spaceID == "root" {
+		return "root"
+	}
+	return expr(fmt.Sprintf("spacelift_space.%s.id", sanitizeResourceName(spaceID)))
+}
 
-	return sb.String(), nil
+// mappedSpace returns the existing destination space a source space is
+// mapped onto by the migration config, if any.
+func (g *Generator) mappedSpace(spaceID string) (string, bool) {
+	if g.migrationConfig == nil {
+		return "", false
+	}
+	return g.migrationConfig.Spaces.MappedSpace(spaceID)
+}
+
+// IsSpaceGenerated reports whether a space becomes a spacelift_space
+// resource. Root and spaces mapped onto existing destination spaces are not
+// generated.
+func (g *Generator) IsSpaceGenerated(spaceID string) bool {
+	if spaceID == "root" {
+		return false
+	}
+	_, mapped := g.mappedSpace(spaceID)
+	return !mapped
+}
+
+// resourceRef returns a reference to the id of a generated resource.
+func resourceRef(resourceType, id string) expr {
+	return expr(fmt.Sprintf("%s.%s.id", resourceType, sanitizeResourceName(id)))
 }
 
 // generateSpace creates Tofu for a space.
-func (g *Generator) generateSpace(space models.Space) string {
-	resourceName := sanitizeResourceName(space.ID)
+func (g *Generator) generateSpace(space models.Space) *block {
+	name := space.Name
+	if g.migrationConfig != nil {
+		name = g.migrationConfig.Spaces.NamePrefix + name
+	}
 
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_space\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  name = %q\n", space.Name))
+	b := newResource("spacelift_space", sanitizeResourceName(space.ID))
+	b.source = "space " + space.ID
+	b.set("name", name)
 
-	// Parent space reference
-	if space.ParentSpace != nil && *space.ParentSpace != "" && *space.ParentSpace != "root" {
-		parentResource := sanitizeResourceName(*space.ParentSpace)
-		sb.WriteString(fmt.Sprintf("  parent_space_id = spacelift_space.%s.id\n", parentResource))
-	} else if space.ParentSpace != nil && *space.ParentSpace == "root" {
-		sb.WriteString("  parent_space_id = \"root\"\n")
+	// Parent space reference, re-parented under an existing destination space if configured
+	parent := ""
+	if space.ParentSpace != nil {
+		parent = *space.ParentSpace
+	}
+	if g.migrationConfig != nil {
+		if destID, ok := g.migrationConfig.Spaces.ParentOverride(space.ID, parent); ok {
+			parent = ""
+			b.set("parent_space_id", destID)
+		}
+	}
+	if parent != "" {
+		b.set("parent_space_id", g.spaceRef(parent))
 	}
 
 	if space.Description != "" {
-		sb.WriteString(fmt.Sprintf("  description = %q\n", space.Description))
+		b.set("description", space.Description)
 	}
 
-	sb.WriteString(fmt.Sprintf("  inherit_entities = %t\n", space.InheritEntities))
+	b.set("inherit_entities", space.InheritEntities)
 
 	if len(space.Labels) > 0 {
-		sb.WriteString(fmt.Sprintf("  labels = %s\n", formatStringList(space.Labels)))
+		b.set("labels", space.Labels)
 	}
 
-	sb.WriteString("}\n")
-	return sb.String()
+	return b
 }
 
 // generateContext creates Tofu for a context.
-func (g *Generator) generateContext(ctx models.Context) string {
-	resourceName := sanitizeResourceName(ctx.ID)
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_context\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  name = %q\n", ctx.Name))
-
-	// Space reference
-	if ctx.Space != "root" {
-		spaceResource := sanitizeResourceName(ctx.Space)
-		sb.WriteString(fmt.Sprintf("  space_id = spacelift_space.%s.id\n", spaceResource))
-	} else {
-		sb.WriteString("  space_id = \"root\"\n")
-	}
+func (g *Generator) generateContext(ctx models.Context) *block {
+	b := newResource("spacelift_context", sanitizeResourceName(ctx.ID))
+	b.source = "context " + ctx.ID
+	b.set("name", ctx.Name)
+	b.set("space_id", g.spaceRef(ctx.Space))
 
 	if ctx.Description != nil && *ctx.Description != "" {
-		sb.WriteString(fmt.Sprintf("  description = %q\n", *ctx.Description))
+		b.set("description", *ctx.Description)
 	}
 
 	if len(ctx.Labels) > 0 {
-		sb.WriteString(fmt.Sprintf("  labels = %s\n", formatStringList(ctx.Labels)))
+		b.set("labels", ctx.Labels)
 	}
 
 	// Hooks
-	g.writeHooks(&sb, ctx.Hooks)
+	g.writeHooks(b, ctx.Hooks)
 
-	sb.WriteString("}\n")
-	return sb.String()
+	return b
 }
 
 // generateConfigElement creates Tofu for an env var or mounted file.
-func (g *Generator) generateConfigElement(contextID string, cfg models.ConfigElement) string {
-	contextResource := sanitizeResourceName(contextID)
-	varName := sanitizeResourceName(contextID + "_" + cfg.ID)
-
-	var sb strings.Builder
-
-	if cfg.Type == "ENVIRONMENT_VARIABLE" {
-		sb.WriteString(fmt.Sprintf("resource \"spacelift_environment_variable\" %q {\n", varName))
-		sb.WriteString(fmt.Sprintf("  context_id = spacelift_context.%s.id\n", contextResource))
-		sb.WriteString(fmt.Sprintf("  name       = %q\n", cfg.ID))
-
+// Returns nil for unknown config types.
+func (g *Generator) generateConfigElement(contextID string, cfg models.ConfigElement) *block {
+	contextRef := resourceRef("spacelift_context", contextID)
+	resourceName := sanitizeResourceName(contextID + "_" + cfg.ID)
+	secretVar := "var." + SecretVariableName(contextID, cfg.ID)
+	source := "context " + contextID + " " + cfg.ID
+
+	switch cfg.Type {
+	case "ENVIRONMENT_VARIABLE":
+		b := newResource("spacelift_environment_variable", resourceName)
+		b.source = source
+		b.set("context_id", contextRef)
+		b.set("name", cfg.ID)
 		if cfg.WriteOnly {
 			// Secret - reference variable
-			secretVarName := sanitizeVariableName(contextID + "_" + cfg.ID)
-			sb.WriteString(fmt.Sprintf("  value      = var.secret_%s\n", secretVarName))
+			b.set("value", expr(secretVar))
 		} else {
 			// Non-secret - use actual value
-			sb.WriteString(fmt.Sprintf("  value      = %q\n", cfg.Value))
+			b.set("value", cfg.Value)
 		}
-
-		sb.WriteString(fmt.Sprintf("  write_only = %t\n", cfg.WriteOnly))
-		sb.WriteString("}\n")
-	} else if cfg.Type == "FILE_MOUNT" {
-		sb.WriteString(fmt.Sprintf("resource \"spacelift_mounted_file\" %q {\n", varName))
-		sb.WriteString(fmt.Sprintf("  context_id    = spacelift_context.%s.id\n", contextResource))
-		sb.WriteString(fmt.Sprintf("  relative_path = %q\n", cfg.ID))
-
+		b.set("write_only", cfg.WriteOnly)
+		return b
+	case "FILE_MOUNT":
+		b := newResource("spacelift_mounted_file", resourceName)
+		b.source = source
+		b.set("context_id", contextRef)
+		b.set("relative_path", cfg.ID)
 		if cfg.WriteOnly {
 			// Secret file - reference variable
-			secretVarName := sanitizeVariableName(contextID + "_" + cfg.ID)
-			sb.WriteString(fmt.Sprintf("  content      = base64encode(var.secret_%s)\n", secretVarName))
+			b.set("content", expr(fmt.Sprintf("base64encode(%s)", secretVar)))
 		} else {
 			// Non-secret - use actual value (base64 encoded)
-			encoded := base64.StdEncoding.EncodeToString([]byte(cfg.Value))
-			sb.WriteString(fmt.Sprintf("  content      = %q\n", encoded))
+			b.set("content", base64.StdEncoding.EncodeToString([]byte(cfg.Value)))
 		}
-
-		sb.WriteString(fmt.Sprintf("  write_only   = %t\n", cfg.WriteOnly))
-		sb.WriteString("}\n")
+		b.set("write_only", cfg.WriteOnly)
+		return b
+	default:
+		return nil
 	}
-
-	return sb.String()
 }
 
 // generatePolicy creates Tofu for a policy.
-func (g *Generator) generatePolicy(policy models.Policy) string {
-	resourceName := sanitizeResourceName(policy.ID)
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_policy\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  name = %q\n", policy.Name))
-	sb.WriteString(fmt.Sprintf("  type = %q\n", policy.Type))
-
-	// Space reference
-	if policy.Space != "root" {
-		spaceResource := sanitizeResourceName(policy.Space)
-		sb.WriteString(fmt.Sprintf("  space_id = spacelift_space.%s.id\n", spaceResource))
-	} else {
-		sb.WriteString("  space_id = \"root\"\n")
-	}
+func (g *Generator) generatePolicy(policy models.Policy) *block {
+	b := newResource("spacelift_policy", sanitizeResourceName(policy.ID))
+	b.source = "policy " + policy.ID
+	b.set("name", policy.Name)
+	b.set("type", policy.Type)
+	b.set("space_id", g.spaceRef(policy.Space))
 
 	if policy.Description != nil && *policy.Description != "" {
-		sb.WriteString(fmt.Sprintf("  description = %q\n", *policy.Description))
+		b.set("description", *policy.Description)
 	}
 
 	if len(policy.Labels) > 0 {
-		sb.WriteString(fmt.Sprintf("  labels = %s\n", formatStringList(policy.Labels)))
+		b.set("labels", policy.Labels)
 	}
 
 	// Policy body using heredoc for readability
-	sb.WriteString("  body = <<-EOT\n")
-	sb.WriteString(policy.Body)
-	if !strings.HasSuffix(policy.Body, "\n") {
-		sb.WriteString("\n")
-	}
-	sb.WriteString("EOT\n")
+	b.set("body", heredoc(policy.Body))
 
-	sb.WriteString("}\n")
-	return sb.String()
+	return b
 }
 
 // generateStack creates Tofu for a stack.
-func (g *Generator) generateStack(stack models.Stack) string {
-	resourceName := sanitizeResourceName(stack.ID)
+func (g *Generator) generateStack(stack models.Stack) *block {
+	// VCS integration override and rewrites from migration config
+	vcs := g.ResolveVCS(stack)
 
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_stack\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  name       = %q\n", stack.Name))
-	sb.WriteString(fmt.Sprintf("  repository = %q\n", stack.Repository))
-	sb.WriteString(fmt.Sprintf("  branch     = %q\n", stack.Branch))
-
-	// Space reference
-	if stack.Space != "root" {
-		spaceResource := sanitizeResourceName(stack.Space)
-		sb.WriteString(fmt.Sprintf("  space_id   = spacelift_space.%s.id\n", spaceResource))
-	} else {
-		sb.WriteString("  space_id   = \"root\"\n")
-	}
+	b := newResource("spacelift_stack", sanitizeResourceName(stack.ID))
+	b.source = "stack " + stack.ID
+	b.set("name", stack.Name)
+	b.set("repository", vcs.Repository)
+	b.set("branch", stack.Branch)
+	b.set("space_id", g.spaceRef(stack.Space))
 
-	// VCS integration override from migration config
-	g.writeVCSConfig(&sb, stack)
+	if vb := vcsBlock(vcs); vb != nil {
+		b.add(vb)
+	}
 
 	if stack.Description != nil && *stack.Description != "" {
-		sb.WriteString(fmt.Sprintf("  description = %q\n", *stack.Description))
+		b.set("description", *stack.Description)
 	}
 
 	if stack.ProjectRoot != nil && *stack.ProjectRoot != "" {
-		sb.WriteString(fmt.Sprintf("  project_root = %q\n", *stack.ProjectRoot))
+		b.set("project_root", *stack.ProjectRoot)
 	}
 
 	// Workflow tool and version based on vendor type
 	if stack.WorkflowTool != nil && *stack.WorkflowTool != "" && *stack.WorkflowTool != "TERRAFORM" {
-		sb.WriteString(fmt.Sprintf("  terraform_workflow_tool = %q\n", *stack.WorkflowTool))
+		b.set("terraform_workflow_tool", *stack.WorkflowTool)
 	}
 
 	// Version fields
 	if stack.TerraformVersion != nil && *stack.TerraformVersion != "" {
-		sb.WriteString(fmt.Sprintf("  terraform_version = %q\n", *stack.TerraformVersion))
+		b.set("terraform_version", *stack.TerraformVersion)
 	}
 	if stack.TerragruntVersion != nil && *stack.TerragruntVersion != "" {
-		sb.WriteString(fmt.Sprintf("  terragrunt_version = %q\n", *stack.TerragruntVersion))
+		b.set("terragrunt_version", *stack.TerragruntVersion)
 	}
 
 	if stack.RunnerImage != nil && *stack.RunnerImage != "" {
-		sb.WriteString(fmt.Sprintf("  runner_image = %q\n", *stack.RunnerImage))
+		b.set("runner_image", *stack.RunnerImage)
 	}
 
 	// Note: administrative flag is deprecated - use spacelift_role_attachment instead
 	// Role attachment is generated separately for administrative stacks
 
 	// In safe mode, force autodeploy=false to prevent runs during migration
-	autodeploy := stack.Autodeploy
 	if g.safeMode && stack.Autodeploy {
-		autodeploy = false
-		sb.WriteString("  autodeploy             = false  # Originally true - re-enable after migration\n")
+		b.setWithComment("autodeploy", false, "Originally true - re-enable after migration")
 	} else {
-		sb.WriteString(fmt.Sprintf("  autodeploy             = %t\n", autodeploy))
+		b.set("autodeploy", stack.Autodeploy)
 	}
 
-	sb.WriteString(fmt.Sprintf("  autoretry              = %t\n", stack.Autoretry))
-	sb.WriteString(fmt.Sprintf("  enable_local_preview   = %t\n", stack.LocalPreviewEnabled))
-	sb.WriteString(fmt.Sprintf("  protect_from_deletion  = %t\n", stack.ProtectFromDeletion))
-	sb.WriteString(fmt.Sprintf("  manage_state           = %t\n", stack.ManagesStateFile))
+	b.set("autoretry", stack.Autoretry)
+	b.set("enable_local_preview", stack.LocalPreviewEnabled)
+	b.set("protect_from_deletion", stack.ProtectFromDeletion)
+	b.set("manage_state", stack.ManagesStateFile)
 
 	if len(stack.Labels) > 0 {
-		sb.WriteString(fmt.Sprintf("  labels = %s\n", formatStringList(stack.Labels)))
+		b.set("labels", stack.Labels)
 	}
 
 	if len(stack.AdditionalProjectGlobs) > 0 {
-		sb.WriteString(fmt.Sprintf("  additional_project_globs = %s\n", formatStringList(stack.AdditionalProjectGlobs)))
+		b.set("additional_project_globs", stack.AdditionalProjectGlobs)
 	}
 
 	// Hooks
-	g.writeHooks(&sb, stack.Hooks)
+	g.writeHooks(b, stack.Hooks)
 
-	sb.WriteString("}\n")
-	return sb.String()
+	return b
 }
 
 // generateContextAttachment creates Tofu for a context attachment.
-func (g *Generator) generateContextAttachment(stackID string, attachment models.ContextAttachment) string {
-	stackResource := sanitizeResourceName(stackID)
-	contextResource := sanitizeResourceName(attachment.ContextID)
-	resourceName := sanitizeResourceName(stackID + "_" + attachment.ContextID)
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_context_attachment\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  stack_id   = spacelift_stack.%s.id\n", stackResource))
-	sb.WriteString(fmt.Sprintf("  context_id = spacelift_context.%s.id\n", contextResource))
-	sb.WriteString(fmt.Sprintf("  priority   = %d\n", attachment.Priority))
-	sb.WriteString("}\n")
-	return sb.String()
+func (g *Generator) generateContextAttachment(stackID string, attachment models.ContextAttachment) *block {
+	b := newResource("spacelift_context_attachment", sanitizeResourceName(stackID+"_"+attachment.ContextID))
+	b.source = "attachment " + stackID + " → " + attachment.ContextID
+	b.set("stack_id", resourceRef("spacelift_stack", stackID))
+	b.set("context_id", resourceRef("spacelift_context", attachment.ContextID))
+	b.set("priority", attachment.Priority)
+	return b
 }
 
 // generatePolicyAttachment creates Tofu for a policy attachment.
-func (g *Generator) generatePolicyAttachment(stackID string, attachment models.PolicyAttachment) string {
-	stackResource := sanitizeResourceName(stackID)
-	policyResource := sanitizeResourceName(attachment.PolicyID)
-	resourceName := sanitizeResourceName(stackID + "_" + attachment.PolicyID)
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_policy_attachment\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  stack_id  = spacelift_stack.%s.id\n", stackResource))
-	sb.WriteString(fmt.Sprintf("  policy_id = spacelift_policy.%s.id\n", policyResource))
-	sb.WriteString("}\n")
-	return sb.String()
+func (g *Generator) generatePolicyAttachment(stackID string, attachment models.PolicyAttachment) *block {
+	b := newResource("spacelift_policy_attachment", sanitizeResourceName(stackID+"_"+attachment.PolicyID))
+	b.source = "attachment " + stackID + " → " + attachment.PolicyID
+	b.set("stack_id", resourceRef("spacelift_stack", stackID))
+	b.set("policy_id", resourceRef("spacelift_policy", attachment.PolicyID))
+	return b
 }
 
 // generateStackDependency creates Tofu for a stack dependency.
-func (g *Generator) generateStackDependency(stackID string, dep models.StackDependency) string {
-	stackResource := sanitizeResourceName(stackID)
-	dependsOnResource := sanitizeResourceName(dep.DependsOnStackID)
-	resourceName := sanitizeResourceName(stackID + "_depends_on_" + dep.DependsOnStackID)
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_stack_dependency\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  stack_id            = spacelift_stack.%s.id\n", stackResource))
-	sb.WriteString(fmt.Sprintf("  depends_on_stack_id = spacelift_stack.%s.id\n", dependsOnResource))
-	sb.WriteString("}\n")
-	return sb.String()
+func (g *Generator) generateStackDependency(stackID string, dep models.StackDependency) *block {
+	b := newResource("spacelift_stack_dependency", sanitizeResourceName(stackID+"_depends_on_"+dep.DependsOnStackID))
+	b.source = "dependency " + stackID + " → " + dep.DependsOnStackID
+	b.set("stack_id", resourceRef("spacelift_stack", stackID))
+	b.set("depends_on_stack_id", resourceRef("spacelift_stack", dep.DependsOnStackID))
+	return b
 }
 
 // generateAdminRoleAttachment creates Tofu for an admin role attachment.
 // This replaces the deprecated administrative = true flag on stacks.
-func (g *Generator) generateAdminRoleAttachment(stack models.Stack) string {
-	stackResource := sanitizeResourceName(stack.ID)
-	resourceName := sanitizeResourceName(stack.ID + "_admin_role")
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_role_attachment\" %q {\n", resourceName))
-	sb.WriteString("  role_id  = spacelift_role.space_admin.id\n")
-	sb.WriteString(fmt.Sprintf("  stack_id = spacelift_stack.%s.id\n", stackResource))
+func (g *Generator) generateAdminRoleAttachment(stack models.Stack) *block {
+	b := newResource("spacelift_role_attachment", sanitizeResourceName(stack.ID+"_admin_role"))
+	b.source = "stack " + stack.ID
+	b.set("role_id", expr("spacelift_role.space_admin.id"))
+	b.set("stack_id", resourceRef("spacelift_stack", stack.ID))
 	// Use the stack's space for the role binding
-	if stack.Space == "root" {
-		sb.WriteString("  space_id = \"root\"\n")
-	} else {
-		spaceResource := sanitizeResourceName(stack.Space)
-		sb.WriteString(fmt.Sprintf("  space_id = spacelift_space.%s.id\n", spaceResource))
-	}
-	sb.WriteString("}\n")
-	return sb.String()
+	b.set("space_id", g.spaceRef(stack.Space))
+	return b
 }
 
+// AdminRoleName and AdminRoleActions describe the role bound to
+// administrative stacks in place of the deprecated administrative flag.
+const AdminRoleName = "Space Admin (Migration)"
+
+var AdminRoleActions = []string{"SPACE_ADMIN"}
+
 // generateAdminRole creates the shared SPACE_ADMIN role for administrative stacks.
-func (g *Generator) generateAdminRole() string {
-	var sb strings.Builder
-	sb.WriteString("# Shared role for administrative stacks (replaces deprecated administrative = true)\n")
-	sb.WriteString("resource \"spacelift_role\" \"space_admin\" {\n")
-	sb.WriteString("  name    = \"Space Admin (Migration)\"\n")
-	sb.WriteString("  actions = [\"SPACE_ADMIN\"]\n")
-	sb.WriteString("}\n")
-	return sb.String()
+func (g *Generator) generateAdminRole() *block {
+	b := newResource("spacelift_role", "space_admin")
+	b.source = "admin role"
+	b.comment = "Shared role for administrative stacks (replaces deprecated administrative = true)"
+	b.set("name", AdminRoleName)
+	b.set("actions", AdminRoleActions)
+	return b
 }
 
 // generateAWSIntegration creates Tofu for an AWS integration.
-func (g *Generator) generateAWSIntegration(integration models.AWSIntegration) string {
-	resourceName := sanitizeResourceName(integration.ID)
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_aws_integration\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  name     = %q\n", integration.Name))
-	sb.WriteString(fmt.Sprintf("  role_arn = %q\n", integration.RoleARN))
-
-	// Space reference
-	if integration.Space != "root" {
-		spaceResource := sanitizeResourceName(integration.Space)
-		sb.WriteString(fmt.Sprintf("  space_id = spacelift_space.%s.id\n", spaceResource))
-	} else {
-		sb.WriteString("  space_id = \"root\"\n")
-	}
+func (g *Generator) generateAWSIntegration(integration models.AWSIntegration) *block {
+	b := newResource("spacelift_aws_integration", sanitizeResourceName(integration.ID))
+	b.source = "AWS integration " + integration.ID
+	b.set("name", integration.Name)
+	b.set("role_arn", integration.RoleARN)
+	b.set("space_id", g.spaceRef(integration.Space))
 
 	if integration.DurationSeconds > 0 {
-		sb.WriteString(fmt.Sprintf("  duration_seconds = %d\n", integration.DurationSeconds))
+		b.set("duration_seconds", integration.DurationSeconds)
 	}
 
-	sb.WriteString(fmt.Sprintf("  generate_credentials_in_worker = %t\n", integration.GenerateCredentialsInWorker))
+	b.set("generate_credentials_in_worker", integration.GenerateCredentialsInWorker)
 
 	if integration.ExternalID != nil && *integration.ExternalID != "" {
-		sb.WriteString(fmt.Sprintf("  external_id = %q\n", *integration.ExternalID))
+		b.set("external_id", *integration.ExternalID)
 	}
 
 	if len(integration.Labels) > 0 {
-		sb.WriteString(fmt.Sprintf("  labels = %s\n", formatStringList(integration.Labels)))
+		b.set("labels", integration.Labels)
 	}
 
-	sb.WriteString("}\n")
-	return sb.String()
+	return b
 }
 
 // generateAzureIntegration creates Tofu for an Azure integration.
-func (g *Generator) generateAzureIntegration(integration models.AzureIntegration) string {
-	resourceName := sanitizeResourceName(integration.ID)
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_azure_integration\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  name       = %q\n", integration.Name))
-	sb.WriteString(fmt.Sprintf("  tenant_id  = %q\n", integration.TenantID))
-	sb.WriteString(fmt.Sprintf("  application_id = %q\n", integration.ApplicationID))
-
-	// Space reference
-	if integration.Space != "root" {
-		spaceResource := sanitizeResourceName(integration.Space)
-		sb.WriteString(fmt.Sprintf("  space_id = spacelift_space.%s.id\n", spaceResource))
-	} else {
-		sb.WriteString("  space_id = \"root\"\n")
-	}
+func (g *Generator) generateAzureIntegration(integration models.AzureIntegration) *block {
+	b := newResource("spacelift_azure_integration", sanitizeResourceName(integration.ID))
+	b.source = "Azure integration " + integration.ID
+	b.set("name", integration.Name)
+	b.set("tenant_id", integration.TenantID)
+	b.set("application_id", integration.ApplicationID)
+	b.set("space_id", g.spaceRef(integration.Space))
 
 	if integration.DefaultSubscriptionID != nil && *integration.DefaultSubscriptionID != "" {
-		sb.WriteString(fmt.Sprintf("  default_subscription_id = %q\n", *integration.DefaultSubscriptionID))
+		b.set("default_subscription_id", *integration.DefaultSubscriptionID)
 	}
 
 	if len(integration.Labels) > 0 {
-		sb.WriteString(fmt.Sprintf("  labels = %s\n", formatStringList(integration.Labels)))
+		b.set("labels", integration.Labels)
 	}
 
-	sb.WriteString("}\n")
-	return sb.String()
+	return b
 }
 
 // generateAWSIntegrationAttachment creates Tofu for an AWS integration attachment to a stack.
-func (g *Generator) generateAWSIntegrationAttachment(stackID string, attachment models.AWSIntegrationAttachment) string {
-	stackResource := sanitizeResourceName(stackID)
-	integrationResource := sanitizeResourceName(attachment.IntegrationID)
-	resourceName := sanitizeResourceName(stackID + "_aws_" + attachment.IntegrationID)
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_aws_integration_attachment\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  integration_id = spacelift_aws_integration.%s.id\n", integrationResource))
-	sb.WriteString(fmt.Sprintf("  stack_id       = spacelift_stack.%s.id\n", stackResource))
-	sb.WriteString(fmt.Sprintf("  read           = %t\n", attachment.Read))
-	sb.WriteString(fmt.Sprintf("  write          = %t\n", attachment.Write))
-	sb.WriteString("}\n")
-	return sb.String()
+func (g *Generator) generateAWSIntegrationAttachment(stackID string, attachment models.AWSIntegrationAttachment) *block {
+	b := newResource("spacelift_aws_integration_attachment", sanitizeResourceName(stackID+"_aws_"+attachment.IntegrationID))
+	b.source = "attachment " + stackID + " → " + attachment.IntegrationID
+	b.set("integration_id", resourceRef("spacelift_aws_integration", attachment.IntegrationID))
+	b.set("stack_id", resourceRef("spacelift_stack", stackID))
+	b.set("read", attachment.Read)
+	b.set("write", attachment.Write)
+	return b
 }
 
 // generateAzureIntegrationAttachment creates Tofu for an Azure integration attachment to a stack.
-func (g *Generator) generateAzureIntegrationAttachment(stackID string, attachment models.AzureIntegrationAttachment) string {
-	stackResource := sanitizeResourceName(stackID)
-	integrationResource := sanitizeResourceName(attachment.IntegrationID)
-	resourceName := sanitizeResourceName(stackID + "_azure_" + attachment.IntegrationID)
-
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("resource \"spacelift_azure_integration_attachment\" %q {\n", resourceName))
-	sb.WriteString(fmt.Sprintf("  integration_id = spacelift_azure_integration.%s.id\n", integrationResource))
-	sb.WriteString(fmt.Sprintf("  stack_id       = spacelift_stack.%s.id\n", stackResource))
-	sb.WriteString(fmt.Sprintf("  read           = %t\n", attachment.Read))
-	sb.WriteString(fmt.Sprintf("  write          = %t\n", attachment.Write))
+func (g *Generator) generateAzureIntegrationAttachment(stackID string, attachment models.AzureIntegrationAttachment) *block {
+	b := newResource("spacelift_azure_integration_attachment", sanitizeResourceName(stackID+"_azure_"+attachment.IntegrationID))
+	b.source = "attachment " + stackID + " → " + attachment.IntegrationID
+	b.set("integration_id", resourceRef("spacelift_azure_integration", attachment.IntegrationID))
+	b.set("stack_id", resourceRef("spacelift_stack", stackID))
+	b.set("read", attachment.Read)
+	b.set("write", attachment.Write)
 	if attachment.SubscriptionID != nil && *attachment.SubscriptionID != "" {
-		sb.WriteString(fmt.Sprintf("  subscription_id = %q\n", *attachment.SubscriptionID))
+		b.set("subscription_id", *attachment.SubscriptionID)
 	}
-	sb.WriteString("}\n")
-	return sb.String()
+	return b
 }
 
-// writeVCSConfig writes VCS integration configuration based on migration config.
-func (g *Generator) writeVCSConfig(sb *strings.Builder, stack models.Stack) {
+// ResolveVCS applies the migration config's VCS rules to a stack. Without
+// a migration config the stack's repository is kept and no VCS block is set.
+func (g *Generator) ResolveVCS(stack models.Stack) config.VCSResolution {
 	if g.migrationConfig == nil {
-		return
+		return config.VCSResolution{
+			Namespace:  stack.Namespace,
+			Repository: stack.Repository,
+		}
 	}
 
-	vcs := &g.migrationConfig.Destination.VCS
-	if !vcs.HasVCSOverride() {
-		return
+	return g.migrationConfig.ResolveVCS(config.VCSSubject{
+		Provider:   stack.Provider,
+		Namespace:  stack.Namespace,
+		Repository: stack.Repository,
+		Spaces:     g.manifest.SpaceAncestry(stack.Space),
+		Labels:     stack.Labels,
+	})
+}
+
+// vcsBlock returns the VCS integration block for a resolved stack, or nil
+// to keep the destination's default VCS provider.
+func vcsBlock(res config.VCSResolution) *block {
+	vcs := res.VCS
+	if vcs == nil {
+		return nil
 	}
 
-	// Write the appropriate VCS block based on config
+	// Build the appropriate VCS block based on config
 	if vcs.GithubEnterprise != nil {
-		sb.WriteString("\n  github_enterprise {\n")
-		sb.WriteString(fmt.Sprintf("    id        = %q\n", vcs.GithubEnterprise.ID))
-		sb.WriteString(fmt.Sprintf("    namespace = %q\n", vcs.GithubEnterprise.Namespace))
-		sb.WriteString("  }\n")
+		return newBlock("github_enterprise").
+			set("id", vcs.GithubEnterprise.ID).
+			set("namespace", vcs.GithubEnterprise.Namespace)
 	} else if vcs.Gitlab != nil {
-		sb.WriteString("\n  gitlab {\n")
-		sb.WriteString(fmt.Sprintf("    id        = %q\n", vcs.Gitlab.ID))
-		sb.WriteString(fmt.Sprintf("    namespace = %q\n", vcs.Gitlab.Namespace))
-		sb.WriteString("  }\n")
+		return newBlock("gitlab").
+			set("id", vcs.Gitlab.ID).
+			set("namespace", vcs.Gitlab.Namespace)
 	} else if vcs.BitbucketDatacenter != nil {
-		sb.WriteString("\n  bitbucket_datacenter {\n")
-		sb.WriteString(fmt.Sprintf("    id        = %q\n", vcs.BitbucketDatacenter.ID))
-		sb.WriteString(fmt.Sprintf("    namespace = %q\n", vcs.BitbucketDatacenter.Namespace))
-		sb.WriteString("  }\n")
+		return newBlock("bitbucket_datacenter").
+			set("id", vcs.BitbucketDatacenter.ID).
+			set("namespace", vcs.BitbucketDatacenter.Namespace)
 	} else if vcs.BitbucketCloud != nil {
-		sb.WriteString("\n  bitbucket_cloud {\n")
-		sb.WriteString(fmt.Sprintf("    id        = %q\n", vcs.BitbucketCloud.ID))
-		sb.WriteString(fmt.Sprintf("    namespace = %q\n", vcs.BitbucketCloud.Namespace))
-		sb.WriteString("  }\n")
+		return newBlock("bitbucket_cloud").
+			set("id", vcs.BitbucketCloud.ID).
+			set("namespace", vcs.BitbucketCloud.Namespace)
 	} else if vcs.AzureDevops != nil {
-		sb.WriteString("\n  azure_devops {\n")
-		sb.WriteString(fmt.Sprintf("    id      = %q\n", vcs.AzureDevops.ID))
-		sb.WriteString(fmt.Sprintf("    project = %q\n", vcs.AzureDevops.Project))
-		sb.WriteString("  }\n")
+		return newBlock("azure_devops").
+			set("id", vcs.AzureDevops.ID).
+			set("project", vcs.AzureDevops.Project)
 	}
+	return nil
 }
 
-// writeHooks writes hook configuration to the string builder.
-func (g *Generator) writeHooks(sb *strings.Builder, hooks models.Hooks) {
+// writeHooks adds hook configuration attributes to a block.
+func (g *Generator) writeHooks(b *block, hooks models.Hooks) {
 	if len(hooks.BeforeInit) > 0 {
-		sb.WriteString(fmt.Sprintf("  before_init = %s\n", formatStringList(hooks.BeforeInit)))
+		b.set("before_init", hooks.BeforeInit)
 	}
 	if len(hooks.AfterInit) > 0 {
-		sb.WriteString(fmt.Sprintf("  after_init = %s\n", formatStringList(hooks.AfterInit)))
+		b.set("after_init", hooks.AfterInit)
 	}
 	if len(hooks.BeforePlan) > 0 {
-		sb.WriteString(fmt.Sprintf("  before_plan = %s\n", formatStringList(hooks.BeforePlan)))
+		b.set("before_plan", hooks.BeforePlan)
 	}
 	if len(hooks.AfterPlan) > 0 {
-		sb.WriteString(fmt.Sprintf("  after_plan = %s\n", formatStringList(hooks.AfterPlan)))
+		b.set("after_plan", hooks.AfterPlan)
 	}
 	if len(hooks.BeforeApply) > 0 {
-		sb.WriteString(fmt.Sprintf("  before_apply = %s\n", formatStringList(hooks.BeforeApply)))
+		b.set("before_apply", hooks.BeforeApply)
 	}
 	if len(hooks.AfterApply) > 0 {
-		sb.WriteString(fmt.Sprintf("  after_apply = %s\n", formatStringList(hooks.AfterApply)))
+		b.set("after_apply", hooks.AfterApply)
 	}
 	if len(hooks.BeforeDestroy) > 0 {
-		sb.WriteString(fmt.Sprintf("  before_destroy = %s\n", formatStringList(hooks.BeforeDestroy)))
+		b.set("before_destroy", hooks.BeforeDestroy)
 	}
 	if len(hooks.AfterDestroy) > 0 {
-		sb.WriteString(fmt.Sprintf("  after_destroy = %s\n", formatStringList(hooks.AfterDestroy)))
+		b.set("after_destroy", hooks.AfterDestroy)
 	}
 	if len(hooks.BeforePerform) > 0 {
-		sb.WriteString(fmt.Sprintf("  before_perform = %s\n", formatStringList(hooks.BeforePerform)))
+		b.set("before_perform", hooks.BeforePerform)
 	}
 	if len(hooks.AfterPerform) > 0 {
-		sb.WriteString(fmt.Sprintf("  after_perform = %s\n", formatStringList(hooks.AfterPerform)))
+		b.set("after_perform", hooks.AfterPerform)
 	}
 	if len(hooks.AfterRun) > 0 {
-		sb.WriteString(fmt.Sprintf("  after_run = %s\n", formatStringList(hooks.AfterRun)))
+		b.set("after_run", hooks.AfterRun)
 	}
 }
 
 // generateVariables creates variables.tf for all secrets.
-func (g *Generator) generateVariables() string {
-	var sb strings.Builder
-
-	sb.WriteString(`# Variables for secrets
-# These values could not be exported from the source account.
-# Fill in the values in secrets.auto.tfvars
-
-`)
+func (g *Generator) generateVariables() *file {
+	f := &file{
+		header: []string{
+			"Variables for secrets",
+			"These values could not be exported from the source account.",
+			"Fill in the values in secrets.auto.tfvars",
+		},
+	}
 
 	for _, ctx := range g.manifest.Contexts {
 		for _, cfg := range ctx.Config {
 			if cfg.WriteOnly {
-				varName := sanitizeVariableName(ctx.ID + "_" + cfg.ID)
-				sb.WriteString(fmt.Sprintf("variable \"secret_%s\" {\n", varName))
-				sb.WriteString(fmt.Sprintf("  description = \"Secret for context '%s', config '%s' (%s)\"\n", ctx.Name, cfg.ID, cfg.Type))
-				sb.WriteString("  type        = string\n")
-				sb.WriteString("  sensitive   = true\n")
-				sb.WriteString("}\n\n")
+				v := newBlock("variable", SecretVariableName(ctx.ID, cfg.ID))
+				v.source = "context " + ctx.ID + " " + cfg.ID
+				v.set("description", fmt.Sprintf("Secret for context '%s', config '%s' (%s)", ctx.Name, cfg.ID, cfg.Type))
+				v.set("type", typeExpr("string"))
+				v.set("sensitive", true)
+				f.blocks = append(f.blocks, v)
 			}
 		}
 	}
 
-	return sb.String()
+	return f
 }
 
 // generateSecretsTemplate creates the template for secret values.
@@ -846,11 +1026,11 @@ func (g *Generator) generateSecretsTemplate() string {
 			sb.WriteString(fmt.Sprintf("# Context: %s (%s)\n", ctx.Name, ctx.ID))
 			for _, cfg := range ctx.Config {
 				if cfg.WriteOnly {
-					varName := sanitizeVariableName(ctx.ID + "_" + cfg.ID)
+					varName := SecretVariableName(ctx.ID, cfg.ID)
 					if cfg.Type == "FILE_MOUNT" {
-						sb.WriteString(fmt.Sprintf("secret_%s = \"\" # FILE_MOUNT: %s\n", varName, cfg.ID))
+						sb.WriteString(fmt.Sprintf("%s = \"\" # FILE_MOUNT: %s\n", varName, cfg.ID))
 					} else {
-						sb.WriteString(fmt.Sprintf("secret_%s = \"\" # %s\n", varName, cfg.ID))
+						sb.WriteString(fmt.Sprintf("%s = \"\" # %s\n", varName, cfg.ID))
 					}
 				}
 			}
@@ -896,9 +1076,14 @@ resource "spacelift_stack" "%s" {
 
 // sortSpacesByDependency sorts spaces so parents come before children.
 func (g *Generator) sortSpacesByDependency() []models.Space {
+	return SortSpacesByDependency(g.manifest.Spaces)
+}
+
+// SortSpacesByDependency sorts spaces so parents come before children.
+func SortSpacesByDependency(spaces []models.Space) []models.Space {
 	// Build dependency map
 	spaceMap := make(map[string]models.Space)
-	for _, s := range g.manifest.Spaces {
+	for _, s := range spaces {
 		spaceMap[s.ID] = s
 	}
 
@@ -926,7 +1111,7 @@ func (g *Generator) sortSpacesByDependency() []models.Space {
 		result = append(result, space)
 	}
 
-	for _, space := range g.manifest.Spaces {
+	for _, space := range spaces {
 		visit(space.ID)
 	}
 
@@ -947,6 +1132,12 @@ func sanitizeResourceName(id string) string {
 	return name
 }
 
+// SecretVariableName returns the name of the variable that holds a
+// write-only context value.
+func SecretVariableName(contextID, configID string) string {
+	return "secret_" + sanitizeVariableName(contextID+"_"+configID)
+}
+
 // sanitizeVariableName is similar but for variable names.
 func sanitizeVariableName(id string) string {
 	re := regexp.MustCompile(`[^a-zA-Z0-9_]`)
@@ -966,17 +1157,3 @@ func sanitizeVariableName(id string) string {
 
 	return name
 }
-
-// formatStringList formats a Go string slice as a Tofu list.
-func formatStringList(items []string) string {
-	if len(items) == 0 {
-		return "[]"
-	}
-
-	quoted := make([]string, len(items))
-	for i, item := range items {
-		quoted[i] = fmt.Sprintf("%q", item)
-	}
-
-	return "[" + strings.Join(quoted, ", ") + "]"
-}
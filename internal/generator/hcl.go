@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sectionRule is the horizontal rule used around section titles in HCL output.
+const sectionRule = "# =============================================================================\n"
+
+// renderHCL renders a file as HCL text.
+func renderHCL(f *file) string {
+	var sb strings.Builder
+
+	for _, line := range f.header {
+		writeComment(&sb, "", line)
+	}
+	if len(f.header) > 0 {
+		sb.WriteString("\n")
+	}
+
+	for i, b := range f.blocks {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		writeBlockHCL(&sb, b, "")
+	}
+
+	for _, s := range f.sections {
+		sb.WriteString(sectionRule)
+		sb.WriteString(fmt.Sprintf("# %s\n", s.title))
+		if s.subtitle != "" {
+			sb.WriteString(fmt.Sprintf("# %s\n", s.subtitle))
+		}
+		sb.WriteString(sectionRule)
+		sb.WriteString("\n")
+		for _, note := range s.notes {
+			writeComment(&sb, "", note)
+		}
+		if len(s.notes) > 0 {
+			sb.WriteString("\n")
+		}
+		for _, b := range s.blocks {
+			writeBlockHCL(&sb, b, "")
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// writeComment writes a comment line, or a blank line for an empty string.
+func writeComment(sb *strings.Builder, indent, line string) {
+	if line == "" {
+		sb.WriteString("#\n")
+		return
+	}
+	sb.WriteString(indent + "# " + line + "\n")
+}
+
+// writeBlockHCL writes a block and its children at the given indentation.
+func writeBlockHCL(sb *strings.Builder, b *block, indent string) {
+	if b.comment != "" {
+		for _, line := range strings.Split(b.comment, "\n") {
+			writeComment(sb, indent, line)
+		}
+	}
+
+	sb.WriteString(indent + b.kind)
+	for _, label := range b.labels {
+		sb.WriteString(" " + quoteHCL(label))
+	}
+
+	if len(b.attrs) == 0 && len(b.blocks) == 0 && len(b.bodyComments) == 0 {
+		sb.WriteString(" {}\n")
+		return
+	}
+	sb.WriteString(" {\n")
+
+	inner := indent + "  "
+	for _, line := range b.bodyComments {
+		writeComment(sb, inner, line)
+	}
+	writeAttributesHCL(sb, b.attrs, inner)
+
+	for _, child := range b.blocks {
+		if len(b.attrs) > 0 || len(b.bodyComments) > 0 {
+			sb.WriteString("\n")
+		}
+		writeBlockHCL(sb, child, inner)
+	}
+
+	sb.WriteString(indent + "}\n")
+}
+
+// writeAttributesHCL writes attributes, aligning the equals signs of
+// consecutive single-line attributes the way tofu fmt does.
+func writeAttributesHCL(sb *strings.Builder, attrs []attribute, indent string) {
+	for start := 0; start < len(attrs); {
+		// Find the run of single-line attributes starting here
+		end := start
+		width := 0
+		for end < len(attrs) && !isMultiline(attrs[end].value) {
+			if len(attrs[end].name) > width {
+				width = len(attrs[end].name)
+			}
+			end++
+		}
+
+		if end == start {
+			// Multi-line attribute stands alone
+			a := attrs[start]
+			sb.WriteString(fmt.Sprintf("%s%s = %s\n", indent, a.name, formatValueHCL(a.value, indent)))
+			start++
+			continue
+		}
+
+		for _, a := range attrs[start:end] {
+			line := fmt.Sprintf("%s%-*s = %s", indent, width, a.name, formatValueHCL(a.value, indent))
+			if a.comment != "" {
+				line += "  # " + a.comment
+			}
+			sb.WriteString(line + "\n")
+		}
+		start = end
+	}
+}
+
+// isMultiline reports whether a value renders across several lines.
+func isMultiline(value interface{}) bool {
+	switch value.(type) {
+	case heredoc, object:
+		return true
+	default:
+		return false
+	}
+}
+
+// formatValueHCL formats an attribute value as HCL.
+func formatValueHCL(value interface{}, indent string) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quoteHCL(v)
+	case bool:
+		return fmt.Sprintf("%t", v)
+	case int:
+		return fmt.Sprintf("%d", v)
+	case []string:
+		return formatStringList(v)
+	case expr:
+		return string(v)
+	case typeExpr:
+		return string(v)
+	case heredoc:
+		body := escapeTemplate(string(v))
+		if !strings.HasSuffix(body, "\n") {
+			body += "\n"
+		}
+		return "<<-EOT\n" + body + "EOT"
+	case object:
+		var sb strings.Builder
+		sb.WriteString("{\n")
+		writeAttributesHCL(&sb, v, indent+"  ")
+		sb.WriteString(indent + "}")
+		return sb.String()
+	default:
+		return quoteHCL(fmt.Sprint(v))
+	}
+}
+
+// quoteHCL quotes a literal string for HCL, escaping template sequences so
+// values such as "echo ${HOME}" are not treated as interpolations.
+func quoteHCL(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range escapeTemplate(s) {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				sb.WriteString(fmt.Sprintf(`\u%04x`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// escapeTemplate escapes Tofu template sequences in a literal string.
+func escapeTemplate(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
+
+// formatStringList formats a Go string slice as a Tofu list.
+func formatStringList(items []string) string {
+	if len(items) == 0 {
+		return "[]"
+	}
+
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = quoteHCL(item)
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
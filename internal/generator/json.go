@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// renderJSON renders a file using the Tofu JSON configuration syntax (.tf.json).
+func renderJSON(f *file) ([]byte, error) {
+	root := make(map[string]interface{})
+	if len(f.header) > 0 {
+		root["//"] = strings.TrimSpace(strings.Join(f.header, "\n"))
+	}
+
+	for _, b := range f.allBlocks() {
+		if err := mergeBlockJSON(root, b); err != nil {
+			return nil, err
+		}
+	}
+
+	// Encode without HTML escaping so constraints like "~> 1.0" stay readable
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// mergeBlockJSON adds a top-level block to the root object, nesting it under
+// its type and labels, e.g. resource.spacelift_stack.name.
+func mergeBlockJSON(root map[string]interface{}, b *block) error {
+	parent := root
+	keys := append([]string{b.kind}, b.labels...)
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := parent[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			parent[key] = next
+		}
+		parent = next
+	}
+
+	last := keys[len(keys)-1]
+	if _, exists := parent[last]; exists {
+		return fmt.Errorf("duplicate block %s", strings.Join(keys, "."))
+	}
+	parent[last] = blockBodyJSON(b)
+	return nil
+}
+
+// blockBodyJSON converts a block body to a JSON object. Nested blocks of the
+// same type are grouped into arrays.
+func blockBodyJSON(b *block) map[string]interface{} {
+	body := make(map[string]interface{})
+	for _, a := range b.attrs {
+		body[a.name] = formatValueJSON(a.value)
+	}
+
+	nested := make(map[string][]interface{})
+	var order []string
+	for _, child := range b.blocks {
+		if _, ok := nested[child.kind]; !ok {
+			order = append(order, child.kind)
+		}
+		nested[child.kind] = append(nested[child.kind], blockBodyJSON(child))
+	}
+	for _, kind := range order {
+		if len(nested[kind]) == 1 {
+			body[kind] = nested[kind][0]
+		} else {
+			body[kind] = nested[kind]
+		}
+	}
+
+	return body
+}
+
+// formatValueJSON converts an attribute value to its JSON representation.
+func formatValueJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return escapeTemplate(v)
+	case []string:
+		escaped := make([]string, len(v))
+		for i, item := range v {
+			escaped[i] = escapeTemplate(item)
+		}
+		return escaped
+	case expr:
+		return "${" + string(v) + "}"
+	case typeExpr:
+		return string(v)
+	case heredoc:
+		return escapeTemplate(string(v))
+	case object:
+		obj := make(map[string]interface{})
+		for _, a := range v {
+			obj[a.name] = formatValueJSON(a.value)
+		}
+		return obj
+	default:
+		return v
+	}
+}
@@ -0,0 +1,100 @@
+package generator
+
+import "strings"
+
+// This file defines the format-neutral resource model shared by the HCL and
+// JSON renderers. The generate* functions build blocks; the renderers only
+// decide how to spell them.
+
+// expr is a raw Tofu expression such as a resource reference. It is written
+// unquoted in HCL and wrapped in "${...}" in JSON.
+type expr string
+
+// typeExpr is a type constraint (e.g. string). It is written unquoted in HCL
+// and as a plain string in JSON, which is how Tofu expects variable types.
+type typeExpr string
+
+// heredoc is a multi-line literal string, written as a heredoc in HCL.
+type heredoc string
+
+// object is an ordered map value such as a required_providers entry.
+type object []attribute
+
+// attribute is a single name = value pair inside a block.
+type attribute struct {
+	name    string
+	value   interface{} // nil (null), string, bool, int, []string, expr, typeExpr, heredoc or object
+	comment string      // Trailing comment (HCL only)
+}
+
+// block is a Tofu block such as a resource, variable or nested VCS block.
+type block struct {
+	kind         string   // resource, variable, provider, terraform, or a nested block name
+	labels       []string // e.g. ["spacelift_stack", "my_stack"]
+	comment      string   // Leading comment (HCL only)
+	bodyComments []string // Comment lines at the top of the body (HCL only)
+	attrs        []attribute
+	blocks       []*block
+	source       string // Manifest resource the block is generated from, e.g. stack prod-app
+}
+
+// newBlock creates a block with the given type and labels.
+func newBlock(kind string, labels ...string) *block {
+	return &block{kind: kind, labels: labels}
+}
+
+// newResource creates a resource block.
+func newResource(resourceType, name string) *block {
+	return newBlock("resource", resourceType, name)
+}
+
+// address returns the Tofu address of a resource or variable block, e.g.
+// spacelift_stack.prod_app or var.secret_name.
+func (b *block) address() string {
+	if b.kind == "variable" {
+		return "var." + b.labels[0]
+	}
+	return strings.Join(b.labels, ".")
+}
+
+// set appends an attribute to the block.
+func (b *block) set(name string, value interface{}) *block {
+	b.attrs = append(b.attrs, attribute{name: name, value: value})
+	return b
+}
+
+// setWithComment appends an attribute with a trailing comment.
+func (b *block) setWithComment(name string, value interface{}, comment string) *block {
+	b.attrs = append(b.attrs, attribute{name: name, value: value, comment: comment})
+	return b
+}
+
+// add appends a nested block.
+func (b *block) add(child *block) *block {
+	b.blocks = append(b.blocks, child)
+	return b
+}
+
+// section groups related blocks under a heading in the generated file.
+type section struct {
+	title    string
+	subtitle string   // Optional second heading line (HCL only)
+	notes    []string // Extra comment lines printed below the heading (HCL only)
+	blocks   []*block
+}
+
+// file is a complete generated Tofu file.
+type file struct {
+	header   []string // Comment lines at the top of the file
+	sections []*section
+	blocks   []*block // Blocks not grouped into sections
+}
+
+// allBlocks returns every top-level block in the file in order.
+func (f *file) allBlocks() []*block {
+	blocks := append([]*block{}, f.blocks...)
+	for _, s := range f.sections {
+		blocks = append(blocks, s.blocks...)
+	}
+	return blocks
+}
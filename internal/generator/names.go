@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"sort"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+)
+
+// NameCollision is a Tofu address that more than one resource of a manifest
+// would be generated under, because their IDs sanitize to the same name.
+type NameCollision struct {
+	Address   string   `json:"address"`   // e.g. spacelift_stack.prod_app
+	Resources []string `json:"resources"` // e.g. stack prod-app, stack prod.app
+}
+
+// NameCollisions returns the addresses that more than one resource would be
+// generated under. Tofu rejects the code generated for such a manifest.
+//
+// The addresses are taken from the blocks that Generate writes to main and
+// variables, so they cannot drift from the generated code.
+func NameCollisions(m *discovery.Manifest) []NameCollision {
+	g := New(m, "")
+	blocks := append(g.generateMain().allBlocks(), g.generateVariables().allBlocks()...)
+
+	var order []string
+	owners := make(map[string][]string)
+	for _, b := range blocks {
+		address := b.address()
+		if _, seen := owners[address]; !seen {
+			order = append(order, address)
+		}
+		owners[address] = append(owners[address], b.source)
+	}
+
+	var collisions []NameCollision
+	for _, address := range order {
+		if resources := owners[address]; len(resources) > 1 {
+			sort.Strings(resources)
+			collisions = append(collisions, NameCollision{Address: address, Resources: resources})
+		}
+	}
+	return collisions
+}
@@ -0,0 +1,143 @@
+package generator
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+// TFVar is a single variable assignment in a .tfvars file.
+type TFVar struct {
+	Name    string
+	Value   string
+	Comment string // Optional trailing comment
+}
+
+// RenderTFVars renders variable assignments as a .tfvars file. Values are
+// written as escaped string literals, so secrets containing quotes, newlines
+// or template sequences round-trip unchanged.
+func RenderTFVars(header []string, vars []TFVar) string {
+	var sb strings.Builder
+	for _, line := range header {
+		if line == "" {
+			sb.WriteString("#\n")
+			continue
+		}
+		sb.WriteString("# " + line + "\n")
+	}
+	if len(header) > 0 {
+		sb.WriteString("\n")
+	}
+
+	for _, v := range vars {
+		sb.WriteString(v.Name + " = " + quoteHCL(v.Value))
+		if v.Comment != "" {
+			sb.WriteString(" # " + v.Comment)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ParseTFVars reads the string assignments of a .tfvars file written by
+// RenderTFVars or filled in by hand from the secrets template. Quoted strings
+// and heredocs are supported; anything else is skipped.
+func ParseTFVars(content string) map[string]string {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		name, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		rest = strings.TrimSpace(rest)
+
+		switch {
+		case strings.HasPrefix(rest, `"`):
+			if value, ok := unquoteHCL(rest); ok {
+				values[name] = value
+			}
+		case strings.HasPrefix(rest, "<<"):
+			marker := strings.TrimPrefix(strings.TrimPrefix(rest, "<<"), "-")
+			var body []string
+			for scanner.Scan() {
+				if strings.TrimSpace(scanner.Text()) == marker {
+					break
+				}
+				body = append(body, scanner.Text())
+			}
+			if strings.HasPrefix(rest, "<<-") {
+				body = trimHeredocIndent(body)
+			}
+			values[name] = unescapeTemplate(strings.Join(body, "\n") + "\n")
+		}
+	}
+
+	return values
+}
+
+// unquoteHCL decodes the quoted string at the start of s, ignoring anything
+// after the closing quote (such as a trailing comment).
+func unquoteHCL(s string) (string, bool) {
+	end := -1
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == '"' {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return "", false
+	}
+
+	value, err := strconv.Unquote(s[:end+1])
+	if err != nil {
+		return "", false
+	}
+	return unescapeTemplate(value), true
+}
+
+// unescapeTemplate reverses escapeTemplate.
+func unescapeTemplate(s string) string {
+	s = strings.ReplaceAll(s, "$${", "${")
+	return strings.ReplaceAll(s, "%%{", "%{")
+}
+
+// trimHeredocIndent removes the common leading whitespace of an indented heredoc.
+func trimHeredocIndent(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent <= 0 {
+		return lines
+	}
+
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) >= indent {
+			trimmed[i] = line[indent:]
+		} else {
+			trimmed[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+	return trimmed
+}
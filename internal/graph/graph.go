@@ -0,0 +1,364 @@
+// Package graph builds the graph of how the resources of a manifest relate
+// to each other: space parents, context and policy attachments, stack
+// dependencies and integration attachments. It writes the graph as
+// Graphviz DOT, Mermaid or JSON.
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+)
+
+// Output formats.
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+	FormatJSON    = "json"
+)
+
+// Node kinds.
+const (
+	KindSpace            = "space"
+	KindStack            = "stack"
+	KindContext          = "context"
+	KindPolicy           = "policy"
+	KindAWSIntegration   = "aws_integration"
+	KindAzureIntegration = "azure_integration"
+)
+
+// Edge kinds. Edges point from the dependent resource to the resource it
+// needs: a space to its parent, a stack to what is attached to it or what
+// it depends on.
+const (
+	EdgeParent      = "parent"
+	EdgeContext     = "context"
+	EdgeAutoattach  = "autoattach"
+	EdgePolicy      = "policy"
+	EdgeDependsOn   = "depends_on"
+	EdgeIntegration = "integration"
+)
+
+// Node is a resource in the graph.
+type Node struct {
+	ID    string `json:"id"` // Kind and resource ID, e.g. stack:network
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Space string `json:"space,omitempty"` // Space ID, empty for spaces
+
+	// Outside is set on nodes that are not selected but are connected to
+	// the selection.
+	Outside bool `json:"outside,omitempty"`
+
+	spaceName string
+}
+
+// Edge is a relation between two nodes.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+
+	// Crossing is set on edges between a selected and an outside node.
+	Crossing bool `json:"crossing,omitempty"`
+}
+
+// Graph is the resource graph of a manifest.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// NodeID returns the ID of the node for a resource.
+func NodeID(kind, id string) string {
+	return kind + ":" + id
+}
+
+// Build returns the graph of every resource in a manifest. Edges to
+// resources the manifest does not hold are left out.
+func Build(m *discovery.Manifest) *Graph {
+	g := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	known := make(map[string]bool)
+	spaceNames := make(map[string]string)
+	for _, space := range m.Spaces {
+		spaceNames[space.ID] = space.Name
+	}
+	addNode := func(kind, id, name, space string) {
+		node := Node{ID: NodeID(kind, id), Kind: kind, Name: name, Space: space, spaceName: spaceNames[space]}
+		g.Nodes = append(g.Nodes, node)
+		known[node.ID] = true
+	}
+	for _, space := range m.Spaces {
+		addNode(KindSpace, space.ID, space.Name, "")
+	}
+	for _, stack := range m.Stacks {
+		addNode(KindStack, stack.ID, stack.Name, stack.Space)
+	}
+	for _, c := range m.Contexts {
+		addNode(KindContext, c.ID, c.Name, c.Space)
+	}
+	for _, p := range m.Policies {
+		addNode(KindPolicy, p.ID, p.Name, p.Space)
+	}
+	for _, i := range m.AWSIntegrations {
+		addNode(KindAWSIntegration, i.ID, i.Name, i.Space)
+	}
+	for _, i := range m.AzureIntegrations {
+		addNode(KindAzureIntegration, i.ID, i.Name, i.Space)
+	}
+
+	addEdge := func(from, to, kind string) {
+		if known[from] && known[to] {
+			g.Edges = append(g.Edges, Edge{From: from, To: to, Kind: kind})
+		}
+	}
+	for _, space := range m.Spaces {
+		if space.ParentSpace != nil {
+			addEdge(NodeID(KindSpace, space.ID), NodeID(KindSpace, *space.ParentSpace), EdgeParent)
+		}
+	}
+	for _, stack := range m.Stacks {
+		from := NodeID(KindStack, stack.ID)
+		attached := make(map[string]bool)
+		for _, a := range stack.AttachedContexts {
+			attached[a.ContextID] = true
+			addEdge(from, NodeID(KindContext, a.ContextID), EdgeContext)
+		}
+		for _, c := range m.Contexts {
+			if !attached[c.ID] && discovery.ContextAttached(stack, c) {
+				addEdge(from, NodeID(KindContext, c.ID), EdgeAutoattach)
+			}
+		}
+		for _, a := range stack.AttachedPolicies {
+			addEdge(from, NodeID(KindPolicy, a.PolicyID), EdgePolicy)
+		}
+		for _, dep := range stack.DependsOn {
+			addEdge(from, NodeID(KindStack, dep.DependsOnStackID), EdgeDependsOn)
+		}
+		for _, a := range stack.AttachedAWSIntegrations {
+			addEdge(from, NodeID(KindAWSIntegration, a.IntegrationID), EdgeIntegration)
+		}
+		for _, a := range stack.AttachedAzureIntegrations {
+			addEdge(from, NodeID(KindAzureIntegration, a.IntegrationID), EdgeIntegration)
+		}
+	}
+	return g
+}
+
+// Focus returns the part of the graph around the selected nodes: the
+// selected nodes, and the nodes outside the selection that an attachment or
+// dependency connects them to, marked Outside. Edges between selected and
+// outside nodes are marked Crossing. Space parent edges do not pull in
+// outside nodes, since every selected resource brings its ancestors along.
+func (g *Graph) Focus(selected map[string]bool) *Graph {
+	outside := make(map[string]bool)
+	for _, e := range g.Edges {
+		if e.Kind == EdgeParent || selected[e.From] == selected[e.To] {
+			continue
+		}
+		if selected[e.From] {
+			outside[e.To] = true
+		} else {
+			outside[e.From] = true
+		}
+	}
+
+	out := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	for _, node := range g.Nodes {
+		if selected[node.ID] || outside[node.ID] {
+			node.Outside = outside[node.ID]
+			out.Nodes = append(out.Nodes, node)
+		}
+	}
+	for _, e := range g.Edges {
+		if !selected[e.From] && !selected[e.To] {
+			continue
+		}
+		if !(selected[e.From] || outside[e.From]) || !(selected[e.To] || outside[e.To]) {
+			continue
+		}
+		e.Crossing = selected[e.From] != selected[e.To]
+		out.Edges = append(out.Edges, e)
+	}
+	return out
+}
+
+// Outside returns the nodes outside the selection.
+func (g *Graph) Outside() []Node {
+	var nodes []Node
+	for _, node := range g.Nodes {
+		if node.Outside {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// ValidateFormat checks that a format name is supported.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatDOT, FormatMermaid, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q (expected %q, %q or %q)", format, FormatDOT, FormatMermaid, FormatJSON)
+	}
+}
+
+// Write writes the graph in a format.
+func (g *Graph) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatDOT:
+		return g.writeDOT(w)
+	case FormatMermaid:
+		return g.writeMermaid(w)
+	case FormatJSON:
+		data, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal graph: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+	return ValidateFormat(format)
+}
+
+// edgeLabel returns the label drawn on an edge.
+func edgeLabel(kind string) string {
+	switch kind {
+	case EdgeDependsOn:
+		return "depends on"
+	case EdgeIntegration:
+		return ""
+	}
+	return kind
+}
+
+// label returns the label drawn on a node: its name and, for resources in
+// a space, the space name.
+func (n Node) label() string {
+	if n.Space == "" {
+		return n.Name
+	}
+	space := n.spaceName
+	if space == "" {
+		space = n.Space
+	}
+	return n.Name + "\n(" + space + ")"
+}
+
+// dotShapes are the Graphviz shapes of node kinds.
+var dotShapes = map[string]string{
+	KindSpace:            "folder",
+	KindStack:            "box",
+	KindContext:          "note",
+	KindPolicy:           "hexagon",
+	KindAWSIntegration:   "component",
+	KindAzureIntegration: "component",
+}
+
+// writeDOT writes the graph as Graphviz DOT.
+func (g *Graph) writeDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph spacebridge {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n\n")
+	for _, node := range g.Nodes {
+		attrs := fmt.Sprintf("label=%s, shape=%s", dotQuote(node.label()), dotShapes[node.Kind])
+		if node.Outside {
+			attrs += `, style="dashed,filled", fillcolor="#fde2e2", color="#c62828"`
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(node.ID), attrs)
+	}
+	if len(g.Edges) > 0 {
+		b.WriteString("\n")
+	}
+	for _, e := range g.Edges {
+		var attrs []string
+		if label := edgeLabel(e.Kind); label != "" {
+			attrs = append(attrs, "label="+dotQuote(label))
+		}
+		if e.Kind == EdgeParent || e.Kind == EdgeAutoattach {
+			attrs = append(attrs, "style=dashed")
+		}
+		if e.Crossing {
+			attrs = append(attrs, `color="#c62828"`, "penwidth=2")
+		}
+		line := fmt.Sprintf("  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if len(attrs) > 0 {
+			line += " [" + strings.Join(attrs, ", ") + "]"
+		}
+		b.WriteString(line + ";\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes a DOT ID or label.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// mermaidShapes are the opening and closing brackets of node kinds in
+// Mermaid flowcharts.
+var mermaidShapes = map[string][2]string{
+	KindSpace:            {"[[", "]]"},
+	KindStack:            {"[", "]"},
+	KindContext:          {"([", "])"},
+	KindPolicy:           {"{{", "}}"},
+	KindAWSIntegration:   {"[/", "/]"},
+	KindAzureIntegration: {"[/", "/]"},
+}
+
+// writeMermaid writes the graph as a Mermaid flowchart. Nodes get short
+// IDs, since Mermaid IDs cannot hold every character resource IDs can.
+func (g *Graph) writeMermaid(w io.Writer) error {
+	var b strings.Builder
+	ids := make(map[string]string)
+	b.WriteString("flowchart LR\n")
+	var outside []string
+	for i, node := range g.Nodes {
+		id := fmt.Sprintf("n%d", i+1)
+		ids[node.ID] = id
+		shape := mermaidShapes[node.Kind]
+		fmt.Fprintf(&b, "  %s%s%s%s\n", id, shape[0], mermaidQuote(node.label()), shape[1])
+		if node.Outside {
+			outside = append(outside, id)
+		}
+	}
+	var crossing []string
+	for i, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == EdgeParent || e.Kind == EdgeAutoattach {
+			arrow = "-.->"
+		}
+		if label := edgeLabel(e.Kind); label != "" {
+			arrow += "|" + label + "|"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+		if e.Crossing {
+			crossing = append(crossing, fmt.Sprint(i))
+		}
+	}
+	if len(outside) > 0 {
+		b.WriteString("  classDef outside fill:#fde2e2,stroke:#c62828,stroke-dasharray:5 5\n")
+		fmt.Fprintf(&b, "  class %s outside\n", strings.Join(outside, ","))
+	}
+	if len(crossing) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#c62828,stroke-width:2px\n", strings.Join(crossing, ","))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidQuote quotes a Mermaid label.
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	return `"` + strings.ReplaceAll(s, "\n", "<br/>") + `"`
+}
@@ -0,0 +1,187 @@
+// Package journal records the changes SpaceBridge makes to stacks in either
+// account, so that a migration can be rolled back.
+//
+// The journal is an append-only JSON Lines file. Undoing a change appends
+// the same entry again with Reverted set, so the file is a complete log of
+// everything that happened.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultPath is the journal file used when none is given.
+const DefaultPath = "spacebridge-journal.jsonl"
+
+// Journaled actions.
+const (
+	ExternalAccessEnabled = "external-access-enabled" // External state access turned on for a source stack
+	SourceLocked          = "source-locked"           // Source stack locked by --freeze-source
+	SourceDisabled        = "source-disabled"         // Source stack disabled by --freeze-source
+	SourceLabeled         = "source-labeled"          // migrated-to label added to a source stack
+	StateImported         = "state-imported"          // State imported into a destination stack
+	DestEnabled           = "destination-enabled"     // Destination stack enabled
+)
+
+// Entry is one journaled change.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	SourceID   string    `json:"sourceId,omitempty"`
+	SourceName string    `json:"sourceName,omitempty"`
+	DestID     string    `json:"destId,omitempty"`
+	DestName   string    `json:"destName,omitempty"`
+	Label      string    `json:"label,omitempty"`      // SourceLabeled: the label added
+	Backup     string    `json:"backup,omitempty"`     // StateImported: destination state saved before import
+	Reverted   bool      `json:"reverted,omitempty"`   // The change was undone
+	SourceHost string    `json:"sourceHost,omitempty"` // Source account host (see AccountHost)
+	DestHost   string    `json:"destHost,omitempty"`   // Destination account host
+}
+
+// AccountHost returns the host of a Spacelift account URL, which entries
+// record to tell accounts apart.
+func AccountHost(accountURL string) string {
+	u, err := url.Parse(accountURL)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(strings.TrimSuffix(accountURL, "/"))
+	}
+	return strings.ToLower(u.Host)
+}
+
+// Destination reports whether the action changed a destination stack.
+func (e Entry) Destination() bool {
+	return e.Action == StateImported || e.Action == DestEnabled
+}
+
+// StackID returns the ID of the stack the action changed.
+func (e Entry) StackID() string {
+	if e.Destination() {
+		return e.DestID
+	}
+	return e.SourceID
+}
+
+// StackName returns the name of the stack the action changed.
+func (e Entry) StackName() string {
+	if e.Destination() {
+		return e.DestName
+	}
+	return e.SourceName
+}
+
+// Host returns the host of the account of the stack the action changed.
+func (e Entry) Host() string {
+	if e.Destination() {
+		return e.DestHost
+	}
+	return e.SourceHost
+}
+
+// InAccount reports whether the action changed a stack of the account at
+// host.
+func (e Entry) InAccount(host string) bool {
+	return e.Host() == host
+}
+
+// key identifies the change an entry makes, so that a revert matches it.
+// Stack IDs are only unique within an account, so the key includes the
+// account host.
+func (e Entry) key() string {
+	return e.Action + "\x00" + e.Host() + "\x00" + e.StackID()
+}
+
+// Journal appends entries to a journal file.
+type Journal struct {
+	path string
+}
+
+// Open returns a journal that appends to path. An empty path disables
+// journaling. The file is created on the first Record.
+func Open(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Path returns the journal file path.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Record appends an entry, stamping it with the current time.
+func (j *Journal) Record(e Entry) error {
+	if j.path == "" {
+		return nil
+	}
+	e.Time = time.Now().UTC()
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Load reads every entry of a journal file, oldest first. A missing file
+// has no entries.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("failed to parse journal line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// Pending returns the changes that are still in effect, newest first: for
+// each action on each stack of each account, the latest entry, unless it
+// was reverted.
+func Pending(entries []Entry) []Entry {
+	latest := make(map[string]int)
+	for i, e := range entries {
+		latest[e.key()] = i
+	}
+
+	var pending []Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if latest[e.key()] == i && !e.Reverted {
+			pending = append(pending, e)
+		}
+	}
+	return pending
+}
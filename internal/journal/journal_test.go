@@ -0,0 +1,106 @@
+package journal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPending(t *testing.T) {
+	const source, other, dest = "source.app.spacelift.io", "other.app.spacelift.io", "destination.app.spacelift.io"
+	entries := []Entry{
+		{Action: ExternalAccessEnabled, SourceID: "app", SourceHost: source},
+		{Action: SourceLocked, SourceID: "app", SourceHost: source},
+		{Action: SourceLocked, SourceID: "app", SourceHost: source, Reverted: true},
+		{Action: SourceLabeled, SourceID: "app", SourceHost: source, Label: "migrated-to:old"},
+		{Action: SourceLabeled, SourceID: "app", SourceHost: source, Label: "migrated-to:new"},
+		{Action: ExternalAccessEnabled, SourceID: "app", SourceHost: other},
+		{Action: ExternalAccessEnabled, SourceID: "app", SourceHost: other, Reverted: true},
+		{Action: DestEnabled, DestID: "app", SourceHost: source, DestHost: dest},
+		{Action: DestEnabled, DestID: "app", SourceHost: other, DestHost: dest, Reverted: true},
+		{Action: StateImported, DestID: "app", SourceHost: source, DestHost: dest, Reverted: true},
+		{Action: StateImported, DestID: "app", SourceHost: source, DestHost: dest},
+	}
+
+	tests := []struct {
+		name  string
+		entry int
+		want  bool
+	}{
+		{"in effect", 0, true},
+		{"reverted", 1, false},
+		{"revert entry", 2, false},
+		{"superseded", 3, false},
+		{"latest", 4, true},
+		{"reverted in another account", 5, false},
+		{"revert in another account", 6, false},
+		{"reverted from another source account", 7, false},
+		{"reverted, then done again", 9, false},
+		{"done again", 10, true},
+	}
+
+	pending := make(map[int]bool)
+	var order []int
+	for _, e := range Pending(entries) {
+		for i := range entries {
+			if entries[i] == e {
+				pending[i] = true
+				order = append(order, i)
+			}
+		}
+	}
+	for _, tt := range tests {
+		if pending[tt.entry] != tt.want {
+			t.Errorf("%s: entry %d pending = %t, want %t", tt.name, tt.entry, pending[tt.entry], tt.want)
+		}
+	}
+	for i := 1; i < len(order); i++ {
+		if order[i] > order[i-1] {
+			t.Errorf("pending entries are not newest first: %v", order)
+		}
+	}
+}
+
+func TestAccountHost(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"https://acme.app.spacelift.io", "acme.app.spacelift.io"},
+		{"https://ACME.app.spacelift.io/", "acme.app.spacelift.io"},
+		{"http://127.0.0.1:8080", "127.0.0.1:8080"},
+		{"acme.app.spacelift.io/", "acme.app.spacelift.io"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := AccountHost(tt.url); got != tt.want {
+			t.Errorf("AccountHost(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRecordAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultPath)
+	j := Open(path)
+	for _, e := range []Entry{
+		{Action: SourceLocked, SourceID: "app", SourceHost: "source"},
+		{Action: SourceLocked, SourceID: "app", SourceHost: "source", Reverted: true},
+	} {
+		if err := j.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	entries, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(entries) != 2 || !entries[1].Reverted || entries[0].SourceHost != "source" {
+		t.Fatalf("Load = %+v", entries)
+	}
+	if entries[0].Time.IsZero() || time.Since(entries[0].Time) > time.Minute {
+		t.Errorf("entry time = %s, want now", entries[0].Time)
+	}
+	if pending := Pending(entries); len(pending) != 0 {
+		t.Errorf("Pending = %+v, want none", pending)
+	}
+}
@@ -0,0 +1,237 @@
+// Package lint checks a manifest for everything that will need attention,
+// or go wrong, when it is migrated. Every finding has a severity and a hint
+// on how to fix it.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/generator"
+	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/internal/selector"
+	"github.com/jnesspace/spacebridge/internal/transform"
+	"github.com/jnesspace/spacebridge/internal/waves"
+)
+
+// Severities of findings.
+const (
+	SeverityError   = "error"   // The migration will fail or lose something
+	SeverityWarning = "warning" // The migration needs a manual step
+	SeverityInfo    = "info"    // Worth knowing, nothing to do
+)
+
+// Checks.
+const (
+	CheckAdministrative      = "administrative"
+	CheckManualSecrets       = "manual-secrets"
+	CheckNameCollision       = "name-collision"
+	CheckOutsideSelection    = "outside-selection"
+	CheckDependencyCycle     = "dependency-cycle"
+	CheckUnsupportedVendor   = "unsupported-vendor"
+	CheckExternalStateAccess = "external-state-access"
+	CheckAWSTrustPolicy      = "aws-trust-policy"
+	CheckBlueprint           = "blueprint"
+)
+
+// Finding is a single problem found by Run.
+type Finding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Resource string `json:"resource,omitempty"` // e.g. stack network
+	Message  string `json:"message"`
+	Fix      string `json:"fix"`
+}
+
+// Input is what Run checks.
+type Input struct {
+	// Manifest holds the resources to migrate.
+	Manifest *discovery.Manifest
+
+	// Account holds every resource of the source account, so that
+	// references from Manifest to resources left out of it can be told from
+	// references to resources that do not exist. It is Manifest itself when
+	// nothing was filtered out.
+	Account *discovery.Manifest
+
+	// Blueprints are the blueprints of the source account. They are only
+	// checked when BlueprintsChecked is set, since manifests do not
+	// record them.
+	Blueprints        []models.Blueprint
+	BlueprintsChecked bool
+
+	// DestinationURL is the URL of the destination account, if known.
+	DestinationURL string
+}
+
+// Run checks a migration and returns the findings in check order.
+func Run(in Input) []Finding {
+	m := in.Manifest
+	var findings []Finding
+	add := func(check, severity, resource, message, fix string) {
+		findings = append(findings, Finding{check, severity, resource, message, fix})
+	}
+
+	for _, stack := range m.Stacks {
+		if stack.Administrative {
+			add(CheckAdministrative, SeverityWarning, "stack "+stack.Name,
+				"uses the deprecated administrative flag",
+				"generate and apply attach the space_admin role instead (spacelift_role_attachment); check that its scope is what the stack needs")
+		}
+	}
+
+	for _, c := range m.Contexts {
+		var keys []string
+		for _, cfg := range c.Config {
+			if cfg.WriteOnly {
+				keys = append(keys, cfg.ID)
+			}
+		}
+		if len(keys) > 0 {
+			add(CheckManualSecrets, SeverityWarning, "context "+c.Name,
+				fmt.Sprintf("%d secret values cannot be read and need manual entry: %s", len(keys), strings.Join(keys, ", ")),
+				"fill them in secrets.auto.tfvars, or pull them from a secret store with generate --secrets-from")
+		}
+	}
+
+	for _, collision := range generator.NameCollisions(m) {
+		add(CheckNameCollision, SeverityError, collision.Address,
+			fmt.Sprintf("is generated for %s", strings.Join(collision.Resources, ", ")),
+			"change the ID of all but one of them in the manifest, or migrate them in separate runs with stack selectors")
+	}
+
+	findings = append(findings, outsideSelection(m, in.Account)...)
+
+	var cycle *waves.CycleError
+	if _, err := waves.NewGraph(m.Stacks).Waves(0); errors.As(err, &cycle) {
+		add(CheckDependencyCycle, SeverityError, "stack "+cycle.Cycle[0],
+			"is in a dependency cycle: "+strings.Join(cycle.Cycle, " → "),
+			"remove one of these stack dependencies; Tofu cannot create cyclic dependencies and plan waves cannot order them")
+	}
+
+	for _, stack := range m.Stacks {
+		if !stack.IsTerraform() {
+			vendor := selector.VendorName(stack.VendorType)
+			add(CheckUnsupportedVendor, SeverityError, "stack "+stack.Name,
+				fmt.Sprintf("is a %s stack; generate only supports Terraform, OpenTofu and Terragrunt settings", vendor),
+				fmt.Sprintf("add the %s vendor block to the generated spacelift_stack by hand, or create the stack in the destination yourself", strings.ToLower(vendor)))
+		}
+	}
+
+	for _, stack := range m.Stacks {
+		if stack.ManagesStateFile && stack.IsTerraform() && !stack.ExternalStateAccessEnabled {
+			add(CheckExternalStateAccess, SeverityWarning, "stack "+stack.Name,
+				"has Spacelift-managed state without external state access, so its state cannot be downloaded",
+				"run: spacebridge state enable-access")
+		}
+	}
+
+	source := accountName(m.SourceURL, "<source>")
+	destination := accountName(in.DestinationURL, "<destination>")
+	for _, i := range m.AWSIntegrations {
+		if i.GenerateCredentialsInWorker {
+			add(CheckAWSTrustPolicy, SeverityInfo, "AWS integration "+i.Name,
+				fmt.Sprintf("generates credentials on the worker, so %s trusts the worker's own identity", i.RoleARN),
+				"make sure the workers used in the destination can assume the role")
+			continue
+		}
+		add(CheckAWSTrustPolicy, SeverityWarning, "AWS integration "+i.Name,
+			fmt.Sprintf("the trust policy of %s only allows %s; the destination assumes it with external IDs starting %s@", i.RoleARN, source, destination),
+			fmt.Sprintf("add %s@* to the sts:ExternalId condition of the role's trust policy before enabling destination stacks", destination))
+	}
+
+	if !in.BlueprintsChecked {
+		add(CheckBlueprint, SeverityInfo, "",
+			"blueprints were not checked, since manifests do not record them",
+			"run lint against the source account (without -m) to check blueprints")
+	}
+	for _, b := range in.Blueprints {
+		add(CheckBlueprint, SeverityWarning, "blueprint "+b.Name,
+			"blueprints are not migrated",
+			"recreate the blueprint in the destination from its template")
+	}
+
+	return findings
+}
+
+// outsideSelection finds references from the stacks of m to resources that
+// are in the account but not in m, or that do not exist at all.
+func outsideSelection(m, account *discovery.Manifest) []Finding {
+	selected := make(map[string]bool)
+	existing := make(map[string]bool)
+	for _, stack := range m.Stacks {
+		selected["stack "+stack.ID] = true
+	}
+	for _, c := range m.Contexts {
+		selected["context "+c.ID] = true
+	}
+	for _, p := range m.Policies {
+		selected["policy "+p.ID] = true
+	}
+	for _, i := range m.AWSIntegrations {
+		selected["AWS integration "+i.ID] = true
+	}
+	for _, i := range m.AzureIntegrations {
+		selected["Azure integration "+i.ID] = true
+	}
+	for _, stack := range account.Stacks {
+		existing["stack "+stack.ID] = true
+	}
+	for _, c := range account.Contexts {
+		existing["context "+c.ID] = true
+	}
+	for _, p := range account.Policies {
+		existing["policy "+p.ID] = true
+	}
+	for _, i := range account.AWSIntegrations {
+		existing["AWS integration "+i.ID] = true
+	}
+	for _, i := range account.AzureIntegrations {
+		existing["Azure integration "+i.ID] = true
+	}
+
+	var findings []Finding
+	check := func(stack models.Stack, how, ref string) {
+		if selected[ref] {
+			return
+		}
+		f := Finding{Check: CheckOutsideSelection, Severity: SeverityError, Resource: "stack " + stack.Name}
+		if existing[ref] {
+			f.Message = fmt.Sprintf("%s %s, which is not selected", how, ref)
+			f.Fix = "select it too (spacebridge select pulls in what a stack needs), or remove the reference before migrating"
+		} else {
+			f.Message = fmt.Sprintf("%s %s, which does not exist", how, ref)
+			f.Fix = "remove the reference, or re-export the manifest (run: spacebridge manifest validate)"
+		}
+		findings = append(findings, f)
+	}
+	for _, stack := range m.Stacks {
+		for _, a := range stack.AttachedContexts {
+			check(stack, "has attached", "context "+a.ContextID)
+		}
+		for _, a := range stack.AttachedPolicies {
+			check(stack, "has attached", "policy "+a.PolicyID)
+		}
+		for _, dep := range stack.DependsOn {
+			check(stack, "depends on", "stack "+dep.DependsOnStackID)
+		}
+		for _, a := range stack.AttachedAWSIntegrations {
+			check(stack, "has attached", "AWS integration "+a.IntegrationID)
+		}
+		for _, a := range stack.AttachedAzureIntegrations {
+			check(stack, "has attached", "Azure integration "+a.IntegrationID)
+		}
+	}
+	return findings
+}
+
+// accountName returns the account name of an account URL, or fallback when
+// the URL has none.
+func accountName(accountURL, fallback string) string {
+	if name := transform.AccountName(accountURL); name != "" {
+		return name
+	}
+	return fallback
+}
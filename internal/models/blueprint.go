@@ -0,0 +1,10 @@
+package models
+
+// Blueprint represents a Spacelift blueprint, a template that stacks are
+// created from.
+type Blueprint struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"` // DRAFT or PUBLISHED
+	Space string `json:"space"`
+}
@@ -25,6 +25,7 @@ type Stack struct {
 	IsDisabled                 bool                `json:"isDisabled"`
 	ManagesStateFile           bool                `json:"managesStateFile"`
 	ExternalStateAccessEnabled bool                `json:"externalStateAccessEnabled"`
+	StateSetAt                 *int64              `json:"stateSetAt,omitempty"` // Unix time of the last state change
 	Labels                     []string            `json:"labels"`
 	AdditionalProjectGlobs     []string            `json:"additionalProjectGlobs"`
 	Hooks                      Hooks               `json:"hooks"`
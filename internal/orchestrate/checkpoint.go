@@ -0,0 +1,147 @@
+package orchestrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Unit statuses recorded in the checkpoint.
+const (
+	StatusPending = "pending" // No phase has run yet
+	StatusRunning = "running" // A phase started and has not finished (or the process died)
+	StatusPaused  = "paused"  // Confirmation was declined before a destructive phase
+	StatusFailed  = "failed"  // The current phase returned an error
+	StatusDone    = "done"    // Every phase completed
+)
+
+// Progress is the checkpointed progress of one unit.
+type Progress struct {
+	Completed []string  `json:"completed"`
+	Current   string    `json:"current,omitempty"` // Phase running, paused at or failed in
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Checkpoint records which phases each unit has completed. It is saved
+// after every phase transition.
+type Checkpoint struct {
+	Plan  string               `json:"plan"`
+	Units map[string]*Progress `json:"units"`
+
+	path string
+}
+
+// LoadCheckpoint reads a checkpoint file. A missing file yields an empty
+// checkpoint that is created on the first save.
+func LoadCheckpoint(path, planPath string) (*Checkpoint, error) {
+	c := &Checkpoint{Plan: planPath, Units: make(map[string]*Progress), path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint file: %w", err)
+	}
+	if c.Units == nil {
+		c.Units = make(map[string]*Progress)
+	}
+	return c, nil
+}
+
+// Path returns the checkpoint file path.
+func (c *Checkpoint) Path() string {
+	return c.path
+}
+
+// Progress returns a unit's progress, creating it if needed.
+func (c *Checkpoint) Progress(unit string) *Progress {
+	p, ok := c.Units[unit]
+	if !ok {
+		p = &Progress{Status: StatusPending}
+		c.Units[unit] = p
+	}
+	return p
+}
+
+// Start marks a phase as running and saves the checkpoint.
+func (c *Checkpoint) Start(unit, phase string) error {
+	p := c.Progress(unit)
+	p.Current, p.Status, p.Error = phase, StatusRunning, ""
+	return c.save(p)
+}
+
+// Complete marks a phase as completed and saves the checkpoint. The unit is
+// done once every phase is completed or skipped by the plan.
+func (c *Checkpoint) Complete(unit, phase string, plan *Plan) error {
+	p := c.Progress(unit)
+	if !p.Done(phase) {
+		p.Completed = append(p.Completed, phase)
+	}
+	p.Current, p.Status, p.Error = "", StatusRunning, ""
+	if p.Next(plan) == "" {
+		p.Status = StatusDone
+	}
+	return c.save(p)
+}
+
+// Pause marks a unit as waiting for confirmation before a phase.
+func (c *Checkpoint) Pause(unit, phase string) error {
+	p := c.Progress(unit)
+	p.Current, p.Status, p.Error = phase, StatusPaused, ""
+	return c.save(p)
+}
+
+// Fail records a phase error and saves the checkpoint.
+func (c *Checkpoint) Fail(unit, phase string, phaseErr error) error {
+	p := c.Progress(unit)
+	p.Current, p.Status, p.Error = phase, StatusFailed, phaseErr.Error()
+	return c.save(p)
+}
+
+// Done reports whether a phase has completed.
+func (p *Progress) Done(phase string) bool {
+	for _, done := range p.Completed {
+		if done == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the first phase still to run, or "" when the unit is done.
+func (p *Progress) Next(plan *Plan) string {
+	for _, phase := range Phases {
+		if !p.Done(phase) && !plan.Skipped(phase) {
+			return phase
+		}
+	}
+	return ""
+}
+
+// save stamps the progress and writes the checkpoint to a temporary file
+// that is renamed into place, so an interrupted write never loses progress.
+func (c *Checkpoint) save(p *Progress) error {
+	p.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Package orchestrate runs a full migration as a sequence of phases, with a
+// checkpoint after each phase so an interrupted migration can be resumed.
+package orchestrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Migration phases, in execution order.
+const (
+	PhaseGenerate     = "generate"      // Generate Tofu code
+	PhaseEnableAccess = "enable-access" // Enable external state access on source stacks
+	PhaseStatePlan    = "state-plan"    // Check state migration readiness
+	PhaseTofuApply    = "tofu-apply"    // Create destination resources with tofu
+	PhaseStateMigrate = "state-migrate" // Copy state into destination stacks
+	PhaseStacksEnable = "stacks-enable" // Enable destination stacks
+)
+
+// Phases lists every phase in execution order.
+var Phases = []string{
+	PhaseGenerate,
+	PhaseEnableAccess,
+	PhaseStatePlan,
+	PhaseTofuApply,
+	PhaseStateMigrate,
+	PhaseStacksEnable,
+}
+
+// Destructive reports whether a phase changes the destination account and
+// needs confirmation before it runs.
+func Destructive(phase string) bool {
+	switch phase {
+	case PhaseTofuApply, PhaseStateMigrate, PhaseStacksEnable:
+		return true
+	}
+	return false
+}
+
+// AllSpaces is the unit name used when a plan lists no spaces.
+const AllSpaces = "(all)"
+
+// DefaultTofuBinary is the tofu executable used when the plan names none.
+const DefaultTofuBinary = "tofu"
+
+// Plan describes a migration: which spaces to migrate and how to run each
+// phase. Paths are relative to the working directory, like command flags.
+type Plan struct {
+	// Manifest is the source manifest file (default: discover fresh)
+	Manifest string `yaml:"manifest,omitempty"`
+
+	// Config is the migration config file (VCS overrides, space remapping, transforms)
+	Config string `yaml:"config,omitempty"`
+
+	// Output is the Tofu output directory. With several spaces, each space
+	// is generated into its own subdirectory.
+	Output string `yaml:"output,omitempty"`
+
+	// Format is the Tofu output format: hcl or json
+	Format string `yaml:"format,omitempty"`
+
+	// Disabled generates stacks with autodeploy disabled
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// SecretsFrom lists secret sources (type[:path]) used to fill secrets.auto.tfvars
+	SecretsFrom []string `yaml:"secrets_from,omitempty"`
+
+	// Spaces are migrated one after another, each through every phase.
+	// Entries take the same forms as --space. Empty means all resources.
+	Spaces []string `yaml:"spaces,omitempty"`
+
+	// Skip lists phases that are not run, e.g. enable-access when it was
+	// done by hand
+	Skip []string `yaml:"skip,omitempty"`
+
+	// Tofu controls the tofu-apply phase
+	Tofu TofuConfig `yaml:"tofu,omitempty"`
+
+	// Checkpoint is the progress file (default: <plan>.checkpoint.json)
+	Checkpoint string `yaml:"checkpoint,omitempty"`
+}
+
+// TofuConfig controls whether SpaceBridge runs tofu itself.
+type TofuConfig struct {
+	// Run makes the tofu-apply phase run tofu init, plan and apply. When
+	// false the phase waits for confirmation that the code was applied.
+	Run bool `yaml:"run,omitempty"`
+
+	// Binary is the tofu executable (default tofu)
+	Binary string `yaml:"binary,omitempty"`
+}
+
+// Unit is one space migrated through the phases.
+type Unit struct {
+	Space     string // Space filter, empty for all resources
+	Name      string // Checkpoint key
+	OutputDir string
+}
+
+// LoadPlan reads a migration plan and fills in defaults.
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var plan Plan
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+
+	if plan.Output == "" {
+		plan.Output = "./generated"
+	}
+	if plan.Tofu.Binary == "" {
+		plan.Tofu.Binary = DefaultTofuBinary
+	}
+	if plan.Checkpoint == "" {
+		plan.Checkpoint = strings.TrimSuffix(path, filepath.Ext(path)) + ".checkpoint.json"
+	}
+
+	return &plan, nil
+}
+
+// Validate checks the plan.
+func (p *Plan) Validate() error {
+	for _, phase := range p.Skip {
+		if !validPhase(phase) {
+			return fmt.Errorf("skip: unknown phase %q (expected one of %s)", phase, strings.Join(Phases, ", "))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, space := range p.Spaces {
+		if space == "" {
+			return fmt.Errorf("spaces[%d]: space is required", i)
+		}
+		if seen[space] {
+			return fmt.Errorf("spaces[%d]: %s is listed twice", i, space)
+		}
+		seen[space] = true
+	}
+
+	return nil
+}
+
+// Units returns the spaces to migrate, in plan order.
+func (p *Plan) Units() []Unit {
+	if len(p.Spaces) == 0 {
+		return []Unit{{Name: AllSpaces, OutputDir: p.Output}}
+	}
+
+	units := make([]Unit, 0, len(p.Spaces))
+	for _, space := range p.Spaces {
+		dir := p.Output
+		if len(p.Spaces) > 1 {
+			dir = filepath.Join(p.Output, space)
+		}
+		units = append(units, Unit{Space: space, Name: space, OutputDir: dir})
+	}
+	return units
+}
+
+// Skipped reports whether the plan skips a phase.
+func (p *Plan) Skipped(phase string) bool {
+	for _, s := range p.Skip {
+		if s == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// validPhase reports whether name is a known phase.
+func validPhase(name string) bool {
+	for _, phase := range Phases {
+		if phase == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,451 @@
+// Package reconcile turns a source → destination diff into the API calls
+// that bring the destination in line with the source.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/diff"
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// Action is a single change to the destination account.
+type Action struct {
+	Kind        string // Resource kind (diff.Kind*)
+	Name        string // Resource name
+	Description string // What the action does, e.g. "set config LOG_LEVEL"
+	Skipped     string // Why the action cannot be applied through the API, if it can't
+
+	run func(ctx context.Context, c *client.Client) error
+}
+
+// Apply performs the action against the destination.
+func (a Action) Apply(ctx context.Context, c *client.Client) error {
+	if a.Skipped != "" || a.run == nil {
+		return nil
+	}
+	return a.run(ctx, c)
+}
+
+// Filter selects resources by kind and name. Patterns are either a kind
+// ("contexts") or kind/name-glob ("policies/team-*").
+type Filter struct {
+	Include []string
+	Exclude []string
+}
+
+// Match returns true if the resource passes the filter.
+func (f Filter) Match(kind, name string) bool {
+	matches := func(patterns []string) bool {
+		for _, p := range patterns {
+			k, glob, hasName := strings.Cut(p, "/")
+			if k != kind {
+				continue
+			}
+			if !hasName {
+				return true
+			}
+			if ok, _ := path.Match(glob, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(f.Include) > 0 && !matches(f.Include) {
+		return false
+	}
+	return !matches(f.Exclude)
+}
+
+// Validate checks that every pattern names a known kind and a valid glob.
+func (f Filter) Validate() error {
+	for _, p := range append(append([]string{}, f.Include...), f.Exclude...) {
+		kind, glob, hasName := strings.Cut(p, "/")
+		known := false
+		for _, k := range diff.Kinds {
+			known = known || k == kind
+		}
+		if !known {
+			return fmt.Errorf("invalid filter %q: unknown kind %q (expected one of %s)", p, kind, strings.Join(diff.Kinds, ", "))
+		}
+		if hasName {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("invalid filter %q: %w", p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Plan returns the actions that apply the differences to the destination.
+// The diff must compare the destination (old) with the source (new).
+// Resources that only exist in the destination are never deleted. Spaces
+// mapped by the migration config, which may be nil, resolve to their mapped
+// destination space.
+func Plan(source, dest *discovery.Manifest, migCfg *config.MigrationConfig, resources []diff.ResourceDiff) []Action {
+	p := newPlanner(source, dest)
+	if migCfg != nil {
+		p.spaces = &migCfg.Spaces
+	}
+
+	// Contexts and policies first, so attachments can use new IDs
+	for _, kind := range []string{diff.KindContexts, diff.KindPolicies, diff.KindStacks} {
+		for _, r := range resources {
+			if r.Kind != kind {
+				continue
+			}
+			switch kind {
+			case diff.KindContexts:
+				p.planContext(r)
+			case diff.KindPolicies:
+				p.planPolicy(r)
+			case diff.KindStacks:
+				p.planStack(r)
+			}
+		}
+	}
+
+	for _, r := range resources {
+		switch r.Kind {
+		case diff.KindContexts, diff.KindPolicies, diff.KindStacks:
+			continue
+		}
+		if r.Status != diff.StatusRemoved {
+			p.skip(r.Kind, r.Name, r.Status, "not supported by the API sync; use --mode tofu")
+		}
+	}
+
+	return p.actions
+}
+
+// planner accumulates actions and resolves destination IDs.
+type planner struct {
+	source, dest *discovery.Manifest
+	spaces       *config.SpacesConfig // Space mapping, if any
+	actions      []Action
+
+	// Destination IDs by resource name, including resources created earlier
+	// in the same run
+	contextIDs map[string]string
+	policyIDs  map[string]string
+}
+
+func newPlanner(source, dest *discovery.Manifest) *planner {
+	p := &planner{
+		source:     source,
+		dest:       dest,
+		contextIDs: make(map[string]string),
+		policyIDs:  make(map[string]string),
+	}
+	for _, c := range dest.Contexts {
+		p.contextIDs[c.Name] = c.ID
+	}
+	for _, pol := range dest.Policies {
+		p.policyIDs[pol.Name] = pol.ID
+	}
+	return p
+}
+
+func (p *planner) add(kind, name, description string, run func(ctx context.Context, c *client.Client) error) {
+	p.actions = append(p.actions, Action{Kind: kind, Name: name, Description: description, run: run})
+}
+
+func (p *planner) skip(kind, name, description, reason string) {
+	p.actions = append(p.actions, Action{Kind: kind, Name: name, Description: description, Skipped: reason})
+}
+
+// destSpace resolves a source space ID to a destination space ID: through
+// the migration config's space mapping first, then by name.
+func (p *planner) destSpace(sourceSpaceID string) (string, bool) {
+	if sourceSpaceID == "root" || sourceSpaceID == "" {
+		return "root", true
+	}
+	if p.spaces != nil {
+		if destID, ok := p.spaces.MappedSpace(sourceSpaceID); ok {
+			return destID, true
+		}
+	}
+	name := ""
+	for _, s := range p.source.Spaces {
+		if s.ID == sourceSpaceID {
+			name = s.Name
+		}
+	}
+	for _, s := range p.dest.Spaces {
+		if s.ID == sourceSpaceID || (name != "" && s.Name == name) {
+			return s.ID, true
+		}
+	}
+	return "", false
+}
+
+func (p *planner) planContext(r diff.ResourceDiff) {
+	if r.Status == diff.StatusRemoved {
+		return
+	}
+
+	var src models.Context
+	for _, c := range p.source.Contexts {
+		if c.ID == r.NewID {
+			src = c
+		}
+	}
+	space, ok := p.destSpace(src.Space)
+	if !ok {
+		p.skip(diff.KindContexts, src.Name, r.Status, fmt.Sprintf("space %q does not exist in the destination", src.Space))
+		return
+	}
+	desired := src
+	desired.Space = space
+
+	if r.Status == diff.StatusAdded {
+		p.add(diff.KindContexts, src.Name, "create context", func(ctx context.Context, c *client.Client) error {
+			id, err := c.CreateContext(ctx, desired)
+			if err != nil {
+				return err
+			}
+			p.contextIDs[src.Name] = id
+			return nil
+		})
+		for _, element := range src.Config {
+			p.planConfig(src.Name, element)
+		}
+		return
+	}
+
+	destID := r.OldID
+	p.contextIDs[src.Name] = destID // The context may be renamed
+	updated := false
+	for _, change := range r.Changes {
+		if strings.HasPrefix(change.Field, "config.") {
+			key := strings.TrimPrefix(change.Field, "config.")
+			element, inSource := findConfig(src, key)
+			if !inSource {
+				p.add(diff.KindContexts, src.Name, "delete config "+key, func(ctx context.Context, c *client.Client) error {
+					return c.DeleteContextConfig(ctx, destID, key)
+				})
+				continue
+			}
+			p.planConfig(src.Name, element)
+			continue
+		}
+		if !updated {
+			updated = true
+			p.add(diff.KindContexts, src.Name, "update context", func(ctx context.Context, c *client.Client) error {
+				return c.UpdateContext(ctx, destID, desired)
+			})
+		}
+	}
+}
+
+// planConfig sets a non-secret config element. Secret values are never
+// read from the source, so they are reported instead.
+func (p *planner) planConfig(contextName string, element models.ConfigElement) {
+	if element.WriteOnly {
+		p.skip(diff.KindContexts, contextName, "set secret "+element.ID, "secret values are not synced; set it in the destination")
+		return
+	}
+	p.add(diff.KindContexts, contextName, "set config "+element.ID, func(ctx context.Context, c *client.Client) error {
+		return c.SetContextConfig(ctx, p.contextIDs[contextName], element)
+	})
+}
+
+func findConfig(sc models.Context, key string) (models.ConfigElement, bool) {
+	for _, e := range sc.Config {
+		if e.ID == key {
+			return e, true
+		}
+	}
+	return models.ConfigElement{}, false
+}
+
+func (p *planner) planPolicy(r diff.ResourceDiff) {
+	if r.Status == diff.StatusRemoved {
+		return
+	}
+
+	var src models.Policy
+	for _, pol := range p.source.Policies {
+		if pol.ID == r.NewID {
+			src = pol
+		}
+	}
+	space, ok := p.destSpace(src.Space)
+	if !ok {
+		p.skip(diff.KindPolicies, src.Name, r.Status, fmt.Sprintf("space %q does not exist in the destination", src.Space))
+		return
+	}
+	desired := src
+	desired.Space = space
+
+	if r.Status == diff.StatusAdded {
+		p.add(diff.KindPolicies, src.Name, "create policy", func(ctx context.Context, c *client.Client) error {
+			id, err := c.CreatePolicy(ctx, desired)
+			if err != nil {
+				return err
+			}
+			p.policyIDs[src.Name] = id
+			return nil
+		})
+		return
+	}
+
+	for _, change := range r.Changes {
+		if change.Field == "type" || change.Field == "engineType" {
+			p.skip(diff.KindPolicies, src.Name, "change "+change.Field, "policy type and engine cannot be changed; recreate the policy")
+			return
+		}
+	}
+	destID := r.OldID
+	p.policyIDs[src.Name] = destID // The policy may be renamed
+	p.add(diff.KindPolicies, src.Name, "update policy", func(ctx context.Context, c *client.Client) error {
+		return c.UpdatePolicy(ctx, destID, desired)
+	})
+}
+
+func (p *planner) planStack(r diff.ResourceDiff) {
+	switch r.Status {
+	case diff.StatusRemoved:
+		return
+	case diff.StatusAdded:
+		p.skip(diff.KindStacks, r.Name, "create stack", "stacks are not created by the API sync; use --mode tofu")
+		return
+	}
+
+	var src, dst models.Stack
+	for _, s := range p.source.Stacks {
+		if s.ID == r.NewID {
+			src = s
+		}
+	}
+	for _, s := range p.dest.Stacks {
+		if s.ID == r.OldID {
+			dst = s
+		}
+	}
+
+	for _, change := range r.Changes {
+		switch change.Field {
+		case "attachedContexts":
+			p.planContextAttachments(src, dst)
+		case "attachedPolicies":
+			p.planPolicyAttachments(src, dst)
+		default:
+			p.skip(diff.KindStacks, src.Name, "change "+change.Field, "stack settings are not synced by the API sync; use --mode tofu")
+		}
+	}
+}
+
+// planContextAttachments attaches and detaches contexts by name. A changed
+// priority is applied by detaching and re-attaching.
+func (p *planner) planContextAttachments(src, dst models.Stack) {
+	sourceContexts := nameIndex(p.source.Contexts)
+	destContexts := nameIndex(p.dest.Contexts)
+
+	want := make(map[string]int)
+	for _, a := range src.AttachedContexts {
+		want[sourceContexts[a.ContextID]] = a.Priority
+	}
+	have := make(map[string]models.ContextAttachment)
+	for _, a := range dst.AttachedContexts {
+		have[destContexts[a.ContextID]] = a
+	}
+
+	for _, name := range sortedKeys(have) {
+		a := have[name]
+		if priority, ok := want[name]; ok && priority == a.Priority {
+			continue
+		}
+		attachmentID := a.ID
+		p.add(diff.KindStacks, src.Name, "detach context "+name, func(ctx context.Context, c *client.Client) error {
+			return c.DetachContext(ctx, attachmentID)
+		})
+	}
+	for _, name := range sortedKeys(want) {
+		priority := want[name]
+		if a, ok := have[name]; ok && a.Priority == priority {
+			continue
+		}
+		name, stackID := name, dst.ID
+		p.add(diff.KindStacks, src.Name, fmt.Sprintf("attach context %s (priority %d)", name, priority), func(ctx context.Context, c *client.Client) error {
+			contextID, ok := p.contextIDs[name]
+			if !ok {
+				return fmt.Errorf("context %s does not exist in the destination", name)
+			}
+			_, err := c.AttachContext(ctx, contextID, stackID, priority)
+			return err
+		})
+	}
+}
+
+// planPolicyAttachments attaches and detaches policies by name.
+func (p *planner) planPolicyAttachments(src, dst models.Stack) {
+	sourcePolicies := make(map[string]string)
+	for _, pol := range p.source.Policies {
+		sourcePolicies[pol.ID] = pol.Name
+	}
+	destPolicies := make(map[string]string)
+	for _, pol := range p.dest.Policies {
+		destPolicies[pol.ID] = pol.Name
+	}
+
+	want := make(map[string]bool)
+	for _, a := range src.AttachedPolicies {
+		want[sourcePolicies[a.PolicyID]] = true
+	}
+	have := make(map[string]string)
+	for _, a := range dst.AttachedPolicies {
+		have[destPolicies[a.PolicyID]] = a.ID
+	}
+
+	for _, name := range sortedKeys(have) {
+		if want[name] {
+			continue
+		}
+		attachmentID := have[name]
+		p.add(diff.KindStacks, src.Name, "detach policy "+name, func(ctx context.Context, c *client.Client) error {
+			return c.DetachPolicy(ctx, attachmentID)
+		})
+	}
+	for _, name := range sortedKeys(want) {
+		if _, ok := have[name]; ok {
+			continue
+		}
+		name, stackID := name, dst.ID
+		p.add(diff.KindStacks, src.Name, "attach policy "+name, func(ctx context.Context, c *client.Client) error {
+			policyID, ok := p.policyIDs[name]
+			if !ok {
+				return fmt.Errorf("policy %s does not exist in the destination", name)
+			}
+			_, err := c.AttachPolicy(ctx, policyID, stackID)
+			return err
+		})
+	}
+}
+
+// nameIndex maps context IDs to names.
+func nameIndex(contexts []models.Context) map[string]string {
+	names := make(map[string]string)
+	for _, c := range contexts {
+		names[c.ID] = c.Name
+	}
+	return names
+}
+
+// sortedKeys returns a map's keys in order, so plans are deterministic.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
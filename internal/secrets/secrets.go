@@ -0,0 +1,173 @@
+// Package secrets resolves write-only context values from external secret
+// stores and writes them to secrets.auto.tfvars.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/generator"
+)
+
+// FileName is the tfvars file that resolved secrets are written to.
+const FileName = "secrets.auto.tfvars"
+
+// Secret is a write-only context value that must be supplied at apply time.
+type Secret struct {
+	ContextID   string
+	ContextName string
+	Key         string // Environment variable name or mounted file path
+	Type        string // ENVIRONMENT_VARIABLE or FILE_MOUNT
+	Variable    string // Tofu variable that holds the value
+}
+
+// Resolved is a secret with its value and where the value came from.
+type Resolved struct {
+	Secret
+	Value  string
+	Source string
+}
+
+// Result is the outcome of resolving secrets against the configured sources.
+type Result struct {
+	Resolved []Resolved
+	Missing  []Secret
+}
+
+// Collect returns every write-only value in the manifest.
+func Collect(manifest *discovery.Manifest) []Secret {
+	var secrets []Secret
+	for _, ctx := range manifest.Contexts {
+		for _, cfg := range ctx.Config {
+			if !cfg.WriteOnly {
+				continue
+			}
+			secrets = append(secrets, Secret{
+				ContextID:   ctx.ID,
+				ContextName: ctx.Name,
+				Key:         cfg.ID,
+				Type:        cfg.Type,
+				Variable:    generator.SecretVariableName(ctx.ID, cfg.ID),
+			})
+		}
+	}
+	return secrets
+}
+
+// Resolve looks up every secret in the sources, in order. Values in existing
+// (typically read from a previous secrets.auto.tfvars) are used for secrets
+// that no source has, so hand-entered values are not lost.
+func Resolve(ctx context.Context, secrets []Secret, sources []Source, existing map[string]string) (*Result, error) {
+	result := &Result{}
+	for _, secret := range secrets {
+		found := false
+		for _, src := range sources {
+			value, ok, err := src.Lookup(ctx, secret)
+			if err != nil {
+				return nil, fmt.Errorf("%s: failed to look up %s/%s: %w", src.Name(), secret.ContextName, secret.Key, err)
+			}
+			if ok {
+				result.Resolved = append(result.Resolved, Resolved{Secret: secret, Value: value, Source: src.Name()})
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		if value := existing[secret.Variable]; value != "" {
+			result.Resolved = append(result.Resolved, Resolved{Secret: secret, Value: value, Source: "existing " + FileName})
+			continue
+		}
+		result.Missing = append(result.Missing, secret)
+	}
+	return result, nil
+}
+
+// ReadExisting reads the values of a previously written secrets file. A
+// missing file yields no values.
+func ReadExisting(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return generator.ParseTFVars(string(data)), nil
+}
+
+// Write writes resolved secrets to <dir>/secrets.auto.tfvars with 0600
+// permissions. Missing secrets are written as empty strings so the file can
+// be completed by hand. An existing file is replaced atomically.
+func Write(dir string, result *Result) (string, error) {
+	var vars []generator.TFVar
+	for _, r := range result.Resolved {
+		vars = append(vars, generator.TFVar{
+			Name:    r.Variable,
+			Value:   r.Value,
+			Comment: fmt.Sprintf("%s/%s (%s)", r.ContextName, r.Key, r.Source),
+		})
+	}
+	for _, s := range result.Missing {
+		vars = append(vars, generator.TFVar{
+			Name:    s.Variable,
+			Comment: fmt.Sprintf("%s/%s MISSING", s.ContextName, s.Key),
+		})
+	}
+
+	content := generator.RenderTFVars([]string{
+		"Secret values for write-only context config",
+		"Written by spacebridge secrets fill",
+		"WARNING: Do not commit this file to version control!",
+	}, vars)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// CreateTemp creates the file with 0600, so secrets are never world-readable
+	tmp, err := os.CreateTemp(dir, "."+FileName+".*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", FileName, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to set permissions on %s: %w", FileName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+
+	path := filepath.Join(dir, FileName)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	return path, nil
+}
+
+// EnvName returns the environment variable name a secret is read from by
+// env and dotenv sources: <prefix><CONTEXT>__<KEY>.
+func EnvName(prefix string, s Secret) string {
+	return prefix + envSegment(s.ContextName) + "__" + envSegment(s.Key)
+}
+
+var nonEnvChars = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// envSegment upper-cases a name and replaces anything that is not valid in
+// an environment variable name with underscores.
+func envSegment(name string) string {
+	return strings.Trim(nonEnvChars.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+}
@@ -0,0 +1,206 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// Source looks up secret values in an external store.
+type Source interface {
+	// Name describes the source in reports
+	Name() string
+
+	// Lookup returns the value of a secret and whether the source has it
+	Lookup(ctx context.Context, s Secret) (string, bool, error)
+}
+
+// New creates a source from its configuration. File-based sources are read
+// (and decrypted) immediately so configuration errors surface before any
+// lookups.
+func New(ctx context.Context, src config.SecretSource) (Source, error) {
+	switch src.Type {
+	case config.SecretSourceEnv:
+		return &envSource{name: src.String(), prefix: src.EnvPrefix(), lookup: os.LookupEnv}, nil
+	case config.SecretSourceDotenv:
+		return newDotenvSource(src)
+	case config.SecretSourceSOPS:
+		return newSOPSSource(ctx, src)
+	case config.SecretSourcePass:
+		return newPassSource(src)
+	case config.SecretSourceVault:
+		return newVaultSource(src)
+	default:
+		return nil, fmt.Errorf("unknown secret source type %q", src.Type)
+	}
+}
+
+// NewAll creates every configured source, in order.
+func NewAll(ctx context.Context, sources []config.SecretSource) ([]Source, error) {
+	var result []Source
+	for _, src := range sources {
+		s, err := New(ctx, src)
+		if err != nil {
+			return nil, fmt.Errorf("secret source %s: %w", src.String(), err)
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// contextKeys returns the names a secret's context is looked up by: its
+// name, then its ID.
+func contextKeys(s Secret) []string {
+	if s.ContextName == "" || s.ContextName == s.ContextID {
+		return []string{s.ContextID}
+	}
+	return []string{s.ContextName, s.ContextID}
+}
+
+// envSource reads secrets from environment variables (or a dotenv file).
+type envSource struct {
+	name   string
+	prefix string
+	lookup func(string) (string, bool)
+}
+
+func (e *envSource) Name() string { return e.name }
+
+func (e *envSource) Lookup(_ context.Context, s Secret) (string, bool, error) {
+	for _, key := range contextKeys(s) {
+		subject := s
+		subject.ContextName = key
+		if value, ok := e.lookup(EnvName(e.prefix, subject)); ok {
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// newDotenvSource reads a dotenv file and serves it like the environment.
+func newDotenvSource(src config.SecretSource) (Source, error) {
+	values, err := godotenv.Read(src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dotenv file: %w", err)
+	}
+	return &envSource{
+		name:   src.String(),
+		prefix: src.EnvPrefix(),
+		lookup: func(key string) (string, bool) {
+			value, ok := values[key]
+			return value, ok
+		},
+	}, nil
+}
+
+// mapSource serves secrets from a decoded context -> key -> value document.
+type mapSource struct {
+	name   string
+	values map[string]map[string]interface{}
+}
+
+func (m *mapSource) Name() string { return m.name }
+
+func (m *mapSource) Lookup(_ context.Context, s Secret) (string, bool, error) {
+	for _, key := range contextKeys(s) {
+		if value, ok := m.values[key][s.Key]; ok && value != nil {
+			if str, ok := value.(string); ok {
+				return str, true, nil
+			}
+			return fmt.Sprint(value), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// newSOPSSource decrypts a SOPS file with the sops CLI. The decrypted
+// document maps context names (or IDs) to key/value pairs.
+func newSOPSSource(ctx context.Context, src config.SecretSource) (Source, error) {
+	command := src.Command
+	if command == "" {
+		command = "sops"
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, "--decrypt", src.Path)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", src.Path, commandError(err, stderr))
+	}
+
+	// JSON is valid YAML, so this handles both SOPS formats
+	var values map[string]map[string]interface{}
+	if err := yaml.Unmarshal(stdout.Bytes(), &values); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted %s (expected context -> key -> value): %w", src.Path, err)
+	}
+	delete(values, "sops")
+
+	return &mapSource{name: src.String(), values: values}, nil
+}
+
+// passSource reads secrets from a pass (or passage) store at
+// <path>/<context>/<key>.
+type passSource struct {
+	name    string
+	command string
+	prefix  string
+}
+
+// newPassSource checks that the password store command is available.
+func newPassSource(src config.SecretSource) (Source, error) {
+	command := src.Command
+	if command == "" {
+		command = "pass"
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("%s not found in PATH: %w", command, err)
+	}
+	return &passSource{name: src.String(), command: command, prefix: src.Path}, nil
+}
+
+func (p *passSource) Name() string { return p.name }
+
+func (p *passSource) Lookup(ctx context.Context, s Secret) (string, bool, error) {
+	for _, key := range contextKeys(s) {
+		entry := path.Join(p.prefix, key, s.Key)
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, p.command, "show", entry)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if strings.Contains(stderr.String(), "not in the password store") {
+				continue
+			}
+			return "", false, fmt.Errorf("%s show %s: %w", p.command, entry, commandError(err, stderr))
+		}
+
+		// By pass convention the first line is the secret; file mounts keep
+		// the whole entry
+		value := stdout.String()
+		if s.Type == "FILE_MOUNT" {
+			return value, true, nil
+		}
+		line, _, _ := strings.Cut(value, "\n")
+		return line, true, nil
+	}
+	return "", false, nil
+}
+
+// commandError adds a command's stderr output, if any, to its error.
+func commandError(err error, stderr bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// vaultSource reads secrets from a Vault KV mount. Each context is one
+// secret at <mount>/<path>/<context> whose keys are the config keys; KV v2
+// serves it at <mount>/data/<path>/<context>.
+type vaultSource struct {
+	name      string
+	address   string
+	token     string
+	mount     string
+	prefix    string
+	kvVersion int
+	http      *http.Client
+	cache     map[string]map[string]interface{} // Secret data by context, nil if absent
+}
+
+// newVaultSource reads the Vault address and token from the config or the
+// standard VAULT_* environment variables.
+func newVaultSource(src config.SecretSource) (Source, error) {
+	address := src.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return nil, fmt.Errorf("vault address is required (set address or VAULT_ADDR)")
+	}
+
+	tokenEnv := src.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = "VAULT_TOKEN"
+	}
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("vault token is required (set %s)", tokenEnv)
+	}
+
+	mount := src.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+
+	kvVersion := src.KVVersion
+	if kvVersion == 0 {
+		kvVersion = 2
+	}
+
+	return &vaultSource{
+		name:      src.String(),
+		address:   strings.TrimSuffix(address, "/"),
+		token:     token,
+		mount:     strings.Trim(mount, "/"),
+		prefix:    strings.Trim(src.Path, "/"),
+		kvVersion: kvVersion,
+		http:      &http.Client{Timeout: 30 * time.Second},
+		cache:     make(map[string]map[string]interface{}),
+	}, nil
+}
+
+func (v *vaultSource) Name() string { return v.name }
+
+func (v *vaultSource) Lookup(ctx context.Context, s Secret) (string, bool, error) {
+	for _, key := range contextKeys(s) {
+		data, err := v.read(ctx, key)
+		if err != nil {
+			return "", false, err
+		}
+		if value, ok := data[s.Key]; ok && value != nil {
+			if str, ok := value.(string); ok {
+				return str, true, nil
+			}
+			return fmt.Sprint(value), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// read fetches (and caches) the secret data for a context.
+func (v *vaultSource) read(ctx context.Context, contextKey string) (map[string]interface{}, error) {
+	if data, ok := v.cache[contextKey]; ok {
+		return data, nil
+	}
+
+	secretPath := path.Join(v.prefix, contextKey)
+	endpoint := fmt.Sprintf("%s/v1/%s/data/%s", v.address, v.mount, escapePath(secretPath))
+	if v.kvVersion == 1 {
+		endpoint = fmt.Sprintf("%s/v1/%s/%s", v.address, v.mount, escapePath(secretPath))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", secretPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		v.cache[contextKey] = nil
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read %s: vault returned %s", secretPath, resp.Status)
+	}
+
+	// KV v1 returns the secret as data; KV v2 nests it with its metadata
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", secretPath, err)
+	}
+	var data map[string]interface{}
+	if v.kvVersion == 1 {
+		err = json.Unmarshal(body.Data, &data)
+	} else {
+		var versioned struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		err = json.Unmarshal(body.Data, &versioned)
+		data = versioned.Data
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", secretPath, err)
+	}
+
+	v.cache[contextKey] = data
+	return data, nil
+}
+
+// escapePath escapes each segment of a slash-separated path for a URL.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,124 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// newVaultServer serves the given JSON bodies by request path, and 404 for
+// any other path. Requests without the test token are rejected.
+func newVaultServer(t *testing.T, bodies map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultLookup(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    config.SecretSource
+		bodies    map[string]string
+		secret    Secret
+		wantValue string
+		wantFound bool
+	}{
+		{
+			name:   "kv v2",
+			source: config.SecretSource{Type: config.SecretSourceVault, Path: "migration"},
+			bodies: map[string]string{
+				"/v1/secret/data/migration/aws-defaults": `{"data":{"data":{"DATADOG_API_KEY":"dd-key"},"metadata":{"version":3}}}`,
+			},
+			secret:    Secret{ContextID: "aws-defaults", ContextName: "aws-defaults", Key: "DATADOG_API_KEY"},
+			wantValue: "dd-key",
+			wantFound: true,
+		},
+		{
+			name:   "kv v1",
+			source: config.SecretSource{Type: config.SecretSourceVault, Path: "migration", Mount: "kv", KVVersion: 1},
+			bodies: map[string]string{
+				"/v1/kv/migration/aws-defaults": `{"data":{"DATADOG_API_KEY":"dd-key"}}`,
+			},
+			secret:    Secret{ContextID: "aws-defaults", ContextName: "aws-defaults", Key: "DATADOG_API_KEY"},
+			wantValue: "dd-key",
+			wantFound: true,
+		},
+		{
+			name:   "falls back to the context ID",
+			source: config.SecretSource{Type: config.SecretSourceVault},
+			bodies: map[string]string{
+				"/v1/secret/data/aws-defaults-01": `{"data":{"data":{"TOKEN":"by-id"}}}`,
+			},
+			secret:    Secret{ContextID: "aws-defaults-01", ContextName: "AWS defaults", Key: "TOKEN"},
+			wantValue: "by-id",
+			wantFound: true,
+		},
+		{
+			name:   "missing key",
+			source: config.SecretSource{Type: config.SecretSourceVault, Path: "migration"},
+			bodies: map[string]string{
+				"/v1/secret/data/migration/aws-defaults": `{"data":{"data":{"OTHER":"value"}}}`,
+			},
+			secret:    Secret{ContextID: "aws-defaults", ContextName: "aws-defaults", Key: "DATADOG_API_KEY"},
+			wantFound: false,
+		},
+		{
+			name:      "missing secret",
+			source:    config.SecretSource{Type: config.SecretSourceVault, Path: "migration"},
+			bodies:    map[string]string{},
+			secret:    Secret{ContextID: "aws-defaults", ContextName: "aws-defaults", Key: "DATADOG_API_KEY"},
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newVaultServer(t, tt.bodies)
+			t.Setenv("VAULT_TOKEN", "test-token")
+			t.Setenv("VAULT_NAMESPACE", "")
+			tt.source.Address = server.URL
+
+			source, err := newVaultSource(tt.source)
+			if err != nil {
+				t.Fatalf("newVaultSource: %v", err)
+			}
+			value, found, err := source.Lookup(context.Background(), tt.secret)
+			if err != nil {
+				t.Fatalf("Lookup: %v", err)
+			}
+			if found != tt.wantFound || value != tt.wantValue {
+				t.Errorf("Lookup = %q, %t; want %q, %t", value, found, tt.wantValue, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestVaultLookupError(t *testing.T) {
+	server := newVaultServer(t, nil)
+	t.Setenv("VAULT_TOKEN", "wrong-token")
+
+	source, err := newVaultSource(config.SecretSource{Type: config.SecretSourceVault, Address: server.URL})
+	if err != nil {
+		t.Fatalf("newVaultSource: %v", err)
+	}
+	if _, _, err := source.Lookup(context.Background(), Secret{ContextID: "app", Key: "TOKEN"}); err == nil {
+		t.Error("Lookup with a rejected token: expected an error")
+	}
+}
@@ -0,0 +1,288 @@
+// Package selection holds the stacks, contexts and policies picked for
+// migration with spacebridge select, together with the resources they pull
+// in, and narrows a manifest down to a saved selection.
+package selection
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// DefaultPath is the selection file written by spacebridge select.
+const DefaultPath = "selection.yaml"
+
+// Item is a selected resource.
+type Item struct {
+	ID   string `yaml:"id"`
+	Name string `yaml:"name"`
+
+	// RequiredBy is the name of the stack that pulled this resource in.
+	// It is empty for resources that were picked.
+	RequiredBy string `yaml:"required_by,omitempty"`
+}
+
+// Selection lists the resources to migrate.
+type Selection struct {
+	// Source is the URL of the account the selection was made in
+	Source string `yaml:"source,omitempty"`
+
+	Stacks   []Item `yaml:"stacks,omitempty"`
+	Contexts []Item `yaml:"contexts,omitempty"`
+	Policies []Item `yaml:"policies,omitempty"`
+}
+
+// Picks are the IDs of resources picked by hand.
+type Picks struct {
+	Stacks   map[string]bool
+	Contexts map[string]bool
+	Policies map[string]bool
+}
+
+// NewPicks returns an empty set of picks.
+func NewPicks() Picks {
+	return Picks{
+		Stacks:   make(map[string]bool),
+		Contexts: make(map[string]bool),
+		Policies: make(map[string]bool),
+	}
+}
+
+// Resolve builds the selection for picked resources: the picked stacks,
+// contexts and policies, every stack a selected stack depends on, and the
+// contexts (attached or autoattached) and policies of the selected stacks.
+// Picks that are not in the manifest are left out.
+func Resolve(m *discovery.Manifest, picks Picks) *Selection {
+	stacks := make(map[string]models.Stack)
+	for _, stack := range m.Stacks {
+		stacks[stack.ID] = stack
+	}
+
+	// Follow dependencies breadth first, so each stack is credited to the
+	// closest picked stack
+	requiredBy := make(map[string]string)
+	var queue []string
+	for _, stack := range m.Stacks {
+		if picks.Stacks[stack.ID] {
+			requiredBy[stack.ID] = ""
+			queue = append(queue, stack.ID)
+		}
+	}
+	for len(queue) > 0 {
+		stack := stacks[queue[0]]
+		queue = queue[1:]
+		for _, dep := range stack.DependsOn {
+			if _, seen := requiredBy[dep.DependsOnStackID]; seen {
+				continue
+			}
+			if _, exists := stacks[dep.DependsOnStackID]; !exists {
+				continue
+			}
+			requiredBy[dep.DependsOnStackID] = stack.Name
+			queue = append(queue, dep.DependsOnStackID)
+		}
+	}
+
+	sel := &Selection{Source: m.SourceURL}
+	var selected []models.Stack
+	for _, stack := range m.Stacks {
+		if by, ok := requiredBy[stack.ID]; ok {
+			sel.Stacks = append(sel.Stacks, Item{ID: stack.ID, Name: stack.Name, RequiredBy: by})
+			selected = append(selected, stack)
+		}
+	}
+
+	for _, c := range m.Contexts {
+		if picks.Contexts[c.ID] {
+			sel.Contexts = append(sel.Contexts, Item{ID: c.ID, Name: c.Name})
+			continue
+		}
+		for _, stack := range selected {
+			if discovery.ContextAttached(stack, c) {
+				sel.Contexts = append(sel.Contexts, Item{ID: c.ID, Name: c.Name, RequiredBy: stack.Name})
+				break
+			}
+		}
+	}
+
+	for _, p := range m.Policies {
+		if picks.Policies[p.ID] {
+			sel.Policies = append(sel.Policies, Item{ID: p.ID, Name: p.Name})
+			continue
+		}
+		for _, stack := range selected {
+			if attachesPolicy(stack, p.ID) {
+				sel.Policies = append(sel.Policies, Item{ID: p.ID, Name: p.Name, RequiredBy: stack.Name})
+				break
+			}
+		}
+	}
+
+	return sel
+}
+
+// attachesPolicy reports whether a policy is attached to a stack.
+func attachesPolicy(stack models.Stack, policyID string) bool {
+	for _, a := range stack.AttachedPolicies {
+		if a.PolicyID == policyID {
+			return true
+		}
+	}
+	return false
+}
+
+// Picks returns the resources of a selection that were picked by hand.
+func (s *Selection) Picks() Picks {
+	picks := NewPicks()
+	for _, item := range s.Stacks {
+		if item.RequiredBy == "" {
+			picks.Stacks[item.ID] = true
+		}
+	}
+	for _, item := range s.Contexts {
+		if item.RequiredBy == "" {
+			picks.Contexts[item.ID] = true
+		}
+	}
+	for _, item := range s.Policies {
+		if item.RequiredBy == "" {
+			picks.Policies[item.ID] = true
+		}
+	}
+	return picks
+}
+
+// HasStack reports whether a stack is selected.
+func (s *Selection) HasStack(id string) bool {
+	return hasID(s.Stacks, id)
+}
+
+// HasStackName reports whether a stack with this name is selected.
+// Destination stacks are matched to source stacks by name.
+func (s *Selection) HasStackName(name string) bool {
+	for _, item := range s.Stacks {
+		if item.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasID reports whether items include an ID.
+func hasID(items []Item, id string) bool {
+	for _, item := range items {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing lists the selected resources that a manifest does not contain.
+func (s *Selection) Missing(m *discovery.Manifest) []string {
+	var missing []string
+	for _, item := range s.Stacks {
+		if !containsStack(m.Stacks, item.ID) {
+			missing = append(missing, "stack "+item.ID)
+		}
+	}
+	for _, item := range s.Contexts {
+		if !containsContext(m.Contexts, item.ID) {
+			missing = append(missing, "context "+item.ID)
+		}
+	}
+	for _, item := range s.Policies {
+		if !containsPolicy(m.Policies, item.ID) {
+			missing = append(missing, "policy "+item.ID)
+		}
+	}
+	return missing
+}
+
+func containsStack(stacks []models.Stack, id string) bool {
+	for _, stack := range stacks {
+		if stack.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsContext(contexts []models.Context, id string) bool {
+	for _, c := range contexts {
+		if c.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPolicy(policies []models.Policy, id string) bool {
+	for _, p := range policies {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the part of a manifest a selection covers: the selected
+// stacks, contexts and policies, the integrations attached to those
+// stacks, and the spaces holding any of them along with their ancestors,
+// so that the hierarchy can be created. The selection is resolved again
+// against the manifest, so a hand-edited file still pulls in everything
+// its stacks need.
+func (s *Selection) Apply(m *discovery.Manifest) *discovery.Manifest {
+	all := NewPicks()
+	for _, item := range s.Stacks {
+		all.Stacks[item.ID] = true
+	}
+	for _, item := range s.Contexts {
+		all.Contexts[item.ID] = true
+	}
+	for _, item := range s.Policies {
+		all.Policies[item.ID] = true
+	}
+	resolved := Resolve(m, all)
+	return m.Subset(ids(resolved.Stacks), ids(resolved.Contexts), ids(resolved.Policies))
+}
+
+// ids returns the set of IDs of items.
+func ids(items []Item) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item.ID] = true
+	}
+	return set
+}
+
+// Load reads a selection file.
+func Load(path string) (*Selection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selection file: %w", err)
+	}
+
+	var sel Selection
+	if err := yaml.Unmarshal(data, &sel); err != nil {
+		return nil, fmt.Errorf("failed to parse selection file: %w", err)
+	}
+	return &sel, nil
+}
+
+// Save writes a selection file.
+func (s *Selection) Save(path string) error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal selection: %w", err)
+	}
+	data = append([]byte("# Written by spacebridge select. Resources with required_by were pulled in\n# by a selected stack; the others were picked.\n"), data...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write selection file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Package selector picks stacks by label, name, ID and vendor, and narrows a
+// manifest down to the picked stacks and the resources attached to them.
+package selector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/internal/selection"
+)
+
+// vendorPrefix starts the GraphQL typename of a stack vendor.
+const vendorPrefix = "StackConfigVendor"
+
+// Selector matches stacks. A stack is selected when it passes every include
+// criterion that is set and no exclude criterion. Within one list, such as
+// Labels, a stack needs to match only one entry.
+type Selector struct {
+	Labels    []string
+	NameRegex *regexp.Regexp
+	StackIDs  map[string]bool // nil matches every stack
+	Vendors   []string
+
+	ExcludeLabels    []string
+	ExcludeNameRegex *regexp.Regexp
+	ExcludeStackIDs  map[string]bool
+	ExcludeVendors   []string
+}
+
+// IsZero reports whether the selector has no criteria, and so matches every
+// stack.
+func (s *Selector) IsZero() bool {
+	return len(s.Labels) == 0 && s.NameRegex == nil && s.StackIDs == nil && len(s.Vendors) == 0 &&
+		len(s.ExcludeLabels) == 0 && s.ExcludeNameRegex == nil && len(s.ExcludeStackIDs) == 0 && len(s.ExcludeVendors) == 0
+}
+
+// Match reports whether a stack is selected.
+func (s *Selector) Match(stack models.Stack) bool {
+	if len(s.Labels) > 0 && !hasAnyLabel(stack, s.Labels) {
+		return false
+	}
+	if s.NameRegex != nil && !s.NameRegex.MatchString(stack.Name) {
+		return false
+	}
+	if s.StackIDs != nil && !s.StackIDs[stack.ID] {
+		return false
+	}
+	if len(s.Vendors) > 0 && !isAnyVendor(stack, s.Vendors) {
+		return false
+	}
+
+	if hasAnyLabel(stack, s.ExcludeLabels) {
+		return false
+	}
+	if s.ExcludeNameRegex != nil && s.ExcludeNameRegex.MatchString(stack.Name) {
+		return false
+	}
+	if s.ExcludeStackIDs[stack.ID] {
+		return false
+	}
+	return !isAnyVendor(stack, s.ExcludeVendors)
+}
+
+// hasAnyLabel reports whether a stack has one of the labels.
+func hasAnyLabel(stack models.Stack, labels []string) bool {
+	for _, label := range labels {
+		for _, stackLabel := range stack.Labels {
+			if stackLabel == label {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isAnyVendor reports whether a stack uses one of the vendors.
+func isAnyVendor(stack models.Stack, vendors []string) bool {
+	name := VendorName(stack.VendorType)
+	for _, vendor := range vendors {
+		if strings.EqualFold(name, strings.TrimPrefix(vendor, vendorPrefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// VendorName returns the short vendor name of a stack type, e.g. Terraform.
+func VendorName(vendorType string) string {
+	if vendorType == "" {
+		return "Terraform"
+	}
+	return strings.TrimPrefix(vendorType, vendorPrefix)
+}
+
+// Filter returns the selected stacks.
+func (s *Selector) Filter(stacks []models.Stack) []models.Stack {
+	if s.IsZero() {
+		return stacks
+	}
+	var filtered []models.Stack
+	for _, stack := range stacks {
+		if s.Match(stack) {
+			filtered = append(filtered, stack)
+		}
+	}
+	return filtered
+}
+
+// Resolve returns the selection the selector makes in a manifest: the
+// selected stacks, the stacks they depend on, even if the selector leaves
+// them out, and the contexts (attached or autoattached) and policies
+// attached to any of these.
+func (s *Selector) Resolve(m *discovery.Manifest) *selection.Selection {
+	picks := selection.NewPicks()
+	for _, stack := range m.Stacks {
+		if s.Match(stack) {
+			picks.Stacks[stack.ID] = true
+		}
+	}
+	return selection.Resolve(m, picks)
+}
+
+// Apply returns the part of a manifest the selector covers: the resources of
+// Resolve, their integrations, and the spaces holding any of these along
+// with their ancestors. Contexts and policies that no selected stack uses are
+// left out.
+func (s *Selector) Apply(m *discovery.Manifest) *discovery.Manifest {
+	if s.IsZero() {
+		return m
+	}
+	return s.Resolve(m).Apply(m)
+}
+
+// String describes the selector, e.g. "label=team:payments !vendor=Ansible".
+func (s *Selector) String() string {
+	var parts []string
+	add := func(prefix, key string, values ...string) {
+		for _, value := range values {
+			parts = append(parts, prefix+key+"="+value)
+		}
+	}
+	add("", "label", s.Labels...)
+	if s.NameRegex != nil {
+		add("", "name", s.NameRegex.String())
+	}
+	if s.StackIDs != nil {
+		add("", "ids", fmt.Sprintf("%d stacks", len(s.StackIDs)))
+	}
+	add("", "vendor", s.Vendors...)
+	add("!", "label", s.ExcludeLabels...)
+	if s.ExcludeNameRegex != nil {
+		add("!", "name", s.ExcludeNameRegex.String())
+	}
+	if s.ExcludeStackIDs != nil {
+		add("!", "ids", fmt.Sprintf("%d stacks", len(s.ExcludeStackIDs)))
+	}
+	add("!", "vendor", s.ExcludeVendors...)
+	return strings.Join(parts, " ")
+}
+
+// ReadStackIDs reads a file of stack IDs, one per line. Blank lines and
+// lines starting with # are ignored.
+func ReadStackIDs(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stack IDs file: %w", err)
+	}
+	defer f.Close()
+
+	ids := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids[line] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stack IDs file: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,333 @@
+// Package transform applies declarative bulk edits to a discovery manifest.
+package transform
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+	"github.com/jnesspace/spacebridge/pkg/config"
+)
+
+// Change records a single field modified by a transform rule.
+type Change struct {
+	Rule      string `json:"rule"`
+	RuleIndex int    `json:"ruleIndex"` // Position of the rule in the config
+	Kind      string `json:"kind"`      // stack, context, policy or space
+	ID        string `json:"id"`
+	Name      string `json:"name"` // Resource name before any transforms
+	Field     string `json:"field"`
+	Old       string `json:"old"`
+	New       string `json:"new"`
+
+	// Label changes: the labels before and after the rule
+	OldLabels []string `json:"oldLabels,omitempty"`
+	NewLabels []string `json:"newLabels,omitempty"`
+}
+
+// resource is a uniform view over the manifest resources that transforms edit.
+type resource struct {
+	kind     string
+	id       string
+	origName string
+	name     *string
+	labels   *[]string
+	space    string
+	stack    *models.Stack // Set for stacks only
+}
+
+// Apply runs the rules against a copy of the manifest and returns the
+// transformed manifest along with every change made. The input manifest is
+// not modified.
+func Apply(manifest *discovery.Manifest, rules []config.TransformRule) (*discovery.Manifest, []Change, error) {
+	result, err := manifest.Clone()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resources := collect(result)
+	account := AccountName(manifest.SourceURL)
+	if account == "" {
+		account = manifest.SourceURL
+	}
+
+	var changes []Change
+	for i := range rules {
+		rule := &rules[i]
+		ruleChanges, err := applyRule(result, resources, rule, i, account)
+		if err != nil {
+			return nil, nil, fmt.Errorf("transform %s: %w", rule.DisplayName(i), err)
+		}
+		changes = append(changes, ruleChanges...)
+	}
+
+	return result, changes, nil
+}
+
+// StackNames returns the destination name of every stack after rename
+// transforms, keyed by source stack ID. Commands that match source stacks to
+// destination stacks by name use this to follow renames. Spaces are the
+// source spaces, so that match.space rules also match stacks in child spaces.
+func StackNames(spaces []models.Space, stacks []models.Stack, rules []config.TransformRule) (map[string]string, error) {
+	manifest := &discovery.Manifest{Spaces: spaces, Stacks: stacks}
+	transformed, _, err := Apply(manifest, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(transformed.Stacks))
+	for _, stack := range transformed.Stacks {
+		names[stack.ID] = stack.Name
+	}
+	return names, nil
+}
+
+// collect builds resource views for every editable resource, grouped by target.
+func collect(m *discovery.Manifest) map[string][]*resource {
+	resources := make(map[string][]*resource)
+	for i := range m.Stacks {
+		s := &m.Stacks[i]
+		resources[config.TargetStacks] = append(resources[config.TargetStacks], &resource{
+			kind: "stack", id: s.ID, origName: s.Name, name: &s.Name, labels: &s.Labels, space: s.Space, stack: s,
+		})
+	}
+	for i := range m.Contexts {
+		c := &m.Contexts[i]
+		resources[config.TargetContexts] = append(resources[config.TargetContexts], &resource{
+			kind: "context", id: c.ID, origName: c.Name, name: &c.Name, labels: &c.Labels, space: c.Space,
+		})
+	}
+	for i := range m.Policies {
+		p := &m.Policies[i]
+		resources[config.TargetPolicies] = append(resources[config.TargetPolicies], &resource{
+			kind: "policy", id: p.ID, origName: p.Name, name: &p.Name, labels: &p.Labels, space: p.Space,
+		})
+	}
+	for i := range m.Spaces {
+		s := &m.Spaces[i]
+		if s.ID == "root" {
+			continue // Root is never generated, so there is nothing to transform
+		}
+		resources[config.TargetSpaces] = append(resources[config.TargetSpaces], &resource{
+			kind: "space", id: s.ID, origName: s.Name, name: &s.Name, labels: &s.Labels, space: s.ID,
+		})
+	}
+	return resources
+}
+
+// applyRule applies one rule to every matching resource.
+func applyRule(m *discovery.Manifest, resources map[string][]*resource, rule *config.TransformRule, index int, account string) ([]Change, error) {
+	var pattern, from, nameMatch *regexp.Regexp
+	var err error
+	if pattern, err = compileOptional(rule.Pattern); err != nil {
+		return nil, err
+	}
+	if from, err = compileOptional(rule.From); err != nil {
+		return nil, err
+	}
+	if nameMatch, err = compileOptional(rule.Match.Name); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	record := func(r *resource, field, old, new string) *Change {
+		if old == new {
+			return nil
+		}
+		changes = append(changes, Change{
+			Rule: rule.DisplayName(index), RuleIndex: index, Kind: r.kind, ID: r.id, Name: r.origName,
+			Field: field, Old: old, New: new,
+		})
+		return &changes[len(changes)-1]
+	}
+	recordLabels := func(r *resource, old, new []string) {
+		if c := record(r, "labels", strings.Join(old, ", "), strings.Join(new, ", ")); c != nil {
+			c.OldLabels, c.NewLabels = old, new
+		}
+	}
+
+	for _, target := range rule.TargetList() {
+		for _, r := range resources[target] {
+			if !matches(m, r, rule.Match, nameMatch) {
+				continue
+			}
+
+			switch rule.Type {
+			case config.TransformRename:
+				old := *r.name
+				*r.name = pattern.ReplaceAllString(old, rule.Replacement)
+				record(r, "name", old, *r.name)
+
+			case config.TransformAddLabel:
+				label := strings.ReplaceAll(rule.Label, "{account}", account)
+				if !contains(*r.labels, label) {
+					old := append([]string(nil), *r.labels...)
+					*r.labels = append(*r.labels, label)
+					recordLabels(r, old, *r.labels)
+				}
+
+			case config.TransformRemoveLabel:
+				old := *r.labels
+				var kept []string
+				for _, label := range *r.labels {
+					if label == rule.Label || (pattern != nil && pattern.MatchString(label)) {
+						continue
+					}
+					kept = append(kept, label)
+				}
+				*r.labels = kept
+				recordLabels(r, old, kept)
+
+			case config.TransformRewriteLabel:
+				old := *r.labels
+				var rewritten []string
+				for _, label := range *r.labels {
+					if pattern.MatchString(label) {
+						label = pattern.ReplaceAllString(label, rule.Replacement)
+					}
+					if !contains(rewritten, label) {
+						rewritten = append(rewritten, label)
+					}
+				}
+				*r.labels = rewritten
+				recordLabels(r, old, rewritten)
+
+			case config.TransformSetField:
+				get, set := stackField(r.stack, rule.Field)
+				old := get()
+				new := rule.Value
+				if pattern != nil {
+					if !pattern.MatchString(old) {
+						continue
+					}
+					new = pattern.ReplaceAllString(old, rule.Value)
+				}
+				set(new)
+				record(r, rule.Field, old, new)
+
+			case config.TransformVersionMap:
+				stack := r.stack
+				if stack.TerraformVersion == nil || !from.MatchString(*stack.TerraformVersion) {
+					continue
+				}
+				old := *stack.TerraformVersion
+				to := rule.To
+				stack.TerraformVersion = &to
+				record(r, "terraform_version", old, to)
+
+				if rule.WorkflowTool != "" {
+					oldTool := ""
+					if stack.WorkflowTool != nil {
+						oldTool = *stack.WorkflowTool
+					}
+					tool := rule.WorkflowTool
+					stack.WorkflowTool = &tool
+					record(r, "workflow_tool", oldTool, tool)
+				}
+
+			default:
+				return nil, fmt.Errorf("unknown transform type %q", rule.Type)
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// matches checks a resource against a rule's match conditions.
+func matches(m *discovery.Manifest, r *resource, match config.TransformMatch, nameMatch *regexp.Regexp) bool {
+	if nameMatch != nil && !nameMatch.MatchString(*r.name) {
+		return false
+	}
+	if match.Label != "" && !contains(*r.labels, match.Label) {
+		return false
+	}
+	if match.Space != "" && !contains(m.SpaceAncestry(r.space), match.Space) {
+		return false
+	}
+	return true
+}
+
+// stackField returns a getter and setter for a stack field supported by set_field.
+func stackField(stack *models.Stack, field string) (func() string, func(string)) {
+	plain := func(p *string) (func() string, func(string)) {
+		return func() string { return *p }, func(v string) { *p = v }
+	}
+	optional := func(p **string) (func() string, func(string)) {
+		return func() string {
+				if *p == nil {
+					return ""
+				}
+				return **p
+			}, func(v string) {
+				if v == "" {
+					*p = nil
+					return
+				}
+				*p = &v
+			}
+	}
+
+	switch field {
+	case "branch":
+		return plain(&stack.Branch)
+	case "repository":
+		return plain(&stack.Repository)
+	case "namespace":
+		return plain(&stack.Namespace)
+	case "project_root":
+		return optional(&stack.ProjectRoot)
+	case "description":
+		return optional(&stack.Description)
+	case "runner_image":
+		return optional(&stack.RunnerImage)
+	case "terraform_version":
+		return optional(&stack.TerraformVersion)
+	case "terragrunt_version":
+		return optional(&stack.TerragruntVersion)
+	case "workflow_tool":
+		return optional(&stack.WorkflowTool)
+	default:
+		// Unreachable after config validation; make the rule a no-op
+		var discard string
+		return plain(&discard)
+	}
+}
+
+// AccountName derives a short account name from a Spacelift URL, e.g.
+// "acme" from https://acme.app.spacelift.io. It returns "" when the URL has
+// no account name, as with a local fake listening on an IP address.
+func AccountName(accountURL string) string {
+	u, err := url.Parse(accountURL)
+	if err != nil || u.Hostname() == "" || net.ParseIP(u.Hostname()) != nil {
+		return ""
+	}
+	name, _, _ := strings.Cut(u.Hostname(), ".")
+	return name
+}
+
+// compileOptional compiles a regular expression, returning nil for an empty pattern.
+func compileOptional(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// contains reports whether a slice contains a value.
+func contains(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
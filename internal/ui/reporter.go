@@ -2,52 +2,53 @@ package ui
 
 import (
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/jnesspace/spacebridge/internal/discovery"
 	"github.com/jnesspace/spacebridge/internal/models"
 )
 
-// PrintSummary prints a summary of the discovered resources.
-func PrintSummary(manifest *discovery.Manifest) {
-	fmt.Println("\n" + strings.Repeat("=", 50))
-	fmt.Println("DISCOVERY SUMMARY")
-	fmt.Println(strings.Repeat("=", 50))
-	fmt.Printf("Source: %s\n\n", manifest.SourceURL)
+// PrintSummary writes a summary of the discovered resources.
+func PrintSummary(w io.Writer, manifest *discovery.Manifest) {
+	fmt.Fprintln(w, "\n"+strings.Repeat("=", 50))
+	fmt.Fprintln(w, "DISCOVERY SUMMARY")
+	fmt.Fprintln(w, strings.Repeat("=", 50))
+	fmt.Fprintf(w, "Source: %s\n\n", manifest.SourceURL)
 
 	summary := manifest.Summary()
-	fmt.Printf("  Spaces:   %d\n", summary["spaces"])
-	fmt.Printf("  Stacks:   %d\n", summary["stacks"])
-	fmt.Printf("  Contexts: %d\n", summary["contexts"])
-	fmt.Printf("  Policies: %d\n", summary["policies"])
-	fmt.Println()
+	fmt.Fprintf(w, "  Spaces:   %d\n", summary["spaces"])
+	fmt.Fprintf(w, "  Stacks:   %d\n", summary["stacks"])
+	fmt.Fprintf(w, "  Contexts: %d\n", summary["contexts"])
+	fmt.Fprintf(w, "  Policies: %d\n", summary["policies"])
+	fmt.Fprintln(w)
 
 	secretsCount := manifest.SecretsCount()
 	if secretsCount > 0 {
-		fmt.Printf("  ⚠️  Secrets requiring manual entry: %d\n", secretsCount)
+		fmt.Fprintf(w, "  ⚠️  Secrets requiring manual entry: %d\n", secretsCount)
 	}
 
-	fmt.Println(strings.Repeat("=", 50))
+	fmt.Fprintln(w, strings.Repeat("=", 50))
 }
 
-// PrintSpaces prints spaces in a formatted way.
-func PrintSpaces(spaces []models.Space) {
-	fmt.Println("\n" + strings.Repeat("-", 40))
-	fmt.Println("SPACES")
-	fmt.Println(strings.Repeat("-", 40))
+// PrintSpaces writes spaces in a formatted way.
+func PrintSpaces(w io.Writer, spaces []models.Space) {
+	fmt.Fprintln(w, "\n"+strings.Repeat("-", 40))
+	fmt.Fprintln(w, "SPACES")
+	fmt.Fprintln(w, strings.Repeat("-", 40))
 
 	trees := models.BuildSpaceTree(spaces)
-	fmt.Print(RenderSpaceTree(trees))
+	fmt.Fprint(w, RenderSpaceTree(trees))
 }
 
-// PrintStacks prints stacks in a formatted table.
-func PrintStacks(stacks []models.Stack) {
-	fmt.Println("\n" + strings.Repeat("-", 40))
-	fmt.Printf("STACKS (%d total)\n", len(stacks))
-	fmt.Println(strings.Repeat("-", 40))
+// PrintStacks writes stacks in a formatted table.
+func PrintStacks(w io.Writer, stacks []models.Stack) {
+	fmt.Fprintln(w, "\n"+strings.Repeat("-", 40))
+	fmt.Fprintf(w, "STACKS (%d total)\n", len(stacks))
+	fmt.Fprintln(w, strings.Repeat("-", 40))
 
 	if len(stacks) == 0 {
-		fmt.Println("No stacks found.")
+		fmt.Fprintln(w, "No stacks found.")
 		return
 	}
 
@@ -64,17 +65,17 @@ func PrintStacks(stacks []models.Stack) {
 		})
 	}
 
-	fmt.Print(RenderTable(headers, rows))
+	fmt.Fprint(w, RenderTable(headers, rows))
 }
 
-// PrintContexts prints contexts in a formatted table.
-func PrintContexts(contexts []models.Context) {
-	fmt.Println("\n" + strings.Repeat("-", 40))
-	fmt.Printf("CONTEXTS (%d total)\n", len(contexts))
-	fmt.Println(strings.Repeat("-", 40))
+// PrintContexts writes contexts in a formatted table.
+func PrintContexts(w io.Writer, contexts []models.Context) {
+	fmt.Fprintln(w, "\n"+strings.Repeat("-", 40))
+	fmt.Fprintf(w, "CONTEXTS (%d total)\n", len(contexts))
+	fmt.Fprintln(w, strings.Repeat("-", 40))
 
 	if len(contexts) == 0 {
-		fmt.Println("No contexts found.")
+		fmt.Fprintln(w, "No contexts found.")
 		return
 	}
 
@@ -97,17 +98,17 @@ func PrintContexts(contexts []models.Context) {
 		})
 	}
 
-	fmt.Print(RenderTable(headers, rows))
+	fmt.Fprint(w, RenderTable(headers, rows))
 }
 
-// PrintPolicies prints policies in a formatted table.
-func PrintPolicies(policies []models.Policy) {
-	fmt.Println("\n" + strings.Repeat("-", 40))
-	fmt.Printf("POLICIES (%d total)\n", len(policies))
-	fmt.Println(strings.Repeat("-", 40))
+// PrintPolicies writes policies in a formatted table.
+func PrintPolicies(w io.Writer, policies []models.Policy) {
+	fmt.Fprintln(w, "\n"+strings.Repeat("-", 40))
+	fmt.Fprintf(w, "POLICIES (%d total)\n", len(policies))
+	fmt.Fprintln(w, strings.Repeat("-", 40))
 
 	if len(policies) == 0 {
-		fmt.Println("No policies found.")
+		fmt.Fprintln(w, "No policies found.")
 		return
 	}
 
@@ -123,11 +124,11 @@ func PrintPolicies(policies []models.Policy) {
 		})
 	}
 
-	fmt.Print(RenderTable(headers, rows))
+	fmt.Fprint(w, RenderTable(headers, rows))
 }
 
-// PrintSecretsWarning prints a warning about secrets that need manual entry.
-func PrintSecretsWarning(contexts []models.Context) {
+// PrintSecretsWarning writes a warning about secrets that need manual entry.
+func PrintSecretsWarning(w io.Writer, contexts []models.Context) {
 	secretContexts := make([]models.Context, 0)
 	for _, ctx := range contexts {
 		if ctx.HasSecrets() {
@@ -139,21 +140,21 @@ func PrintSecretsWarning(contexts []models.Context) {
 		return
 	}
 
-	fmt.Println("\n" + strings.Repeat("!", 50))
-	fmt.Println("SECRETS REQUIRING MANUAL ENTRY")
-	fmt.Println(strings.Repeat("!", 50))
-	fmt.Println("The following contexts contain secrets that cannot")
-	fmt.Println("be exported via the API. You will need to manually")
-	fmt.Println("re-enter these values in the destination account.")
-	fmt.Println()
+	fmt.Fprintln(w, "\n"+strings.Repeat("!", 50))
+	fmt.Fprintln(w, "SECRETS REQUIRING MANUAL ENTRY")
+	fmt.Fprintln(w, strings.Repeat("!", 50))
+	fmt.Fprintln(w, "The following contexts contain secrets that cannot")
+	fmt.Fprintln(w, "be exported via the API. You will need to manually")
+	fmt.Fprintln(w, "re-enter these values in the destination account.")
+	fmt.Fprintln(w)
 
 	for _, ctx := range secretContexts {
 		secrets := ctx.GetSecretConfigs()
-		fmt.Printf("  Context: %s\n", ctx.ID)
+		fmt.Fprintf(w, "  Context: %s\n", ctx.ID)
 		for _, secret := range secrets {
-			fmt.Printf("    - %s (%s)\n", secret.ID, secret.Type)
+			fmt.Fprintf(w, "    - %s (%s)\n", secret.ID, secret.Type)
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 }
 
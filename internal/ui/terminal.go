@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Keys returned by Terminal.ReadKey. Printable characters, including the
+// space bar, are returned as themselves.
+const (
+	KeyUp        = "up"
+	KeyDown      = "down"
+	KeyLeft      = "left"
+	KeyRight     = "right"
+	KeyPageUp    = "pgup"
+	KeyPageDown  = "pgdown"
+	KeyHome      = "home"
+	KeyEnd       = "end"
+	KeyEnter     = "enter"
+	KeyEscape    = "esc"
+	KeyBackspace = "backspace"
+	KeyCtrlC     = "ctrl+c"
+)
+
+// ErrNotTerminal is returned by OpenTerminal when stdin or stdout is not a
+// terminal.
+var ErrNotTerminal = errors.New("not running in an interactive terminal")
+
+// Terminal is a full-screen session on the alternate screen of the
+// terminal, with input in raw mode.
+type Terminal struct {
+	in    *bufio.Reader
+	out   *bufio.Writer
+	state *term.State
+}
+
+// IsTerminal reports whether stdin and stdout are a terminal.
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// OpenTerminal switches the terminal to raw mode and the alternate screen.
+// Close restores it.
+func OpenTerminal() (*Terminal, error) {
+	if !IsTerminal() {
+		return nil, ErrNotTerminal
+	}
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+
+	t := &Terminal{in: bufio.NewReader(os.Stdin), out: bufio.NewWriter(os.Stdout), state: state}
+	t.out.WriteString("\x1b[?1049h\x1b[?25l") // Alternate screen, hide cursor
+	t.out.Flush()
+	return t, nil
+}
+
+// Close leaves the alternate screen and restores the terminal.
+func (t *Terminal) Close() error {
+	t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	t.out.Flush()
+	return term.Restore(int(os.Stdin.Fd()), t.state)
+}
+
+// Size returns the width and height of the terminal.
+func (t *Terminal) Size() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// ReadKey waits for a key press. Unknown escape sequences are returned as
+// "".
+func (t *Terminal) ReadKey() (string, error) {
+	r, _, err := t.in.ReadRune()
+	if err != nil {
+		return "", err
+	}
+
+	switch r {
+	case '\r', '\n':
+		return KeyEnter, nil
+	case 0x7f, 0x08:
+		return KeyBackspace, nil
+	case 0x03:
+		return KeyCtrlC, nil
+	case 0x1b:
+		// A lone escape arrives by itself; sequences arrive in one read
+		if t.in.Buffered() == 0 {
+			return KeyEscape, nil
+		}
+		return t.readSequence()
+	}
+	return string(r), nil
+}
+
+// readSequence reads the rest of an escape sequence sent by a special key.
+func (t *Terminal) readSequence() (string, error) {
+	intro, err := t.in.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if intro != '[' && intro != 'O' {
+		return KeyEscape, nil
+	}
+
+	var seq []byte
+	for {
+		b, err := t.in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		seq = append(seq, b)
+		if b >= 0x40 && b <= 0x7e { // Final byte
+			break
+		}
+	}
+
+	switch string(seq) {
+	case "A":
+		return KeyUp, nil
+	case "B":
+		return KeyDown, nil
+	case "C":
+		return KeyRight, nil
+	case "D":
+		return KeyLeft, nil
+	case "H", "1~", "7~":
+		return KeyHome, nil
+	case "F", "4~", "8~":
+		return KeyEnd, nil
+	case "5~":
+		return KeyPageUp, nil
+	case "6~":
+		return KeyPageDown, nil
+	}
+	return "", nil
+}
+
+// Draw redraws the screen with lines, cut to the terminal width. The line
+// at index highlight, if any, is shown in reverse video.
+func (t *Terminal) Draw(lines []string, highlight int) {
+	width, height := t.Size()
+
+	t.out.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i >= height {
+			break
+		}
+		if runes := []rune(line); len(runes) > width {
+			line = string(runes[:width-1]) + "…"
+		}
+		if i == highlight {
+			line = "\x1b[7m" + line + strings.Repeat(" ", width-len([]rune(line))) + "\x1b[0m"
+		}
+		t.out.WriteString(line + "\x1b[K")
+		if i < height-1 {
+			t.out.WriteString("\r\n")
+		}
+	}
+	t.out.WriteString("\x1b[J")
+	t.out.Flush()
+}
@@ -0,0 +1,264 @@
+// Package unused finds the resources of a manifest that nothing uses, so
+// that they can be cleaned up instead of migrated.
+package unused
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jnesspace/spacebridge/internal/discovery"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// Kinds of unused resources.
+const (
+	KindSpace            = "space"
+	KindStack            = "stack"
+	KindContext          = "context"
+	KindPolicy           = "policy"
+	KindAWSIntegration   = "aws_integration"
+	KindAzureIntegration = "azure_integration"
+)
+
+// Resource is a resource that nothing uses.
+type Resource struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Space  string `json:"space"` // Space holding the resource, or the parent of a space
+	Reason string `json:"reason"`
+}
+
+// DuplicateContexts is a group of contexts with the same config and hooks.
+type DuplicateContexts struct {
+	Contexts []string `json:"contexts"` // Context IDs
+	Keys     []string `json:"keys"`
+	Secrets  bool     `json:"secrets"` // Secret values cannot be compared, only their keys
+}
+
+// Report lists the unused resources of a manifest.
+type Report struct {
+	Resources         []Resource          `json:"resources"`
+	DuplicateContexts []DuplicateContexts `json:"duplicateContexts"`
+}
+
+// Find returns the unused resources of a manifest:
+//   - disabled stacks whose state has not changed since staleBefore, unless a
+//     stack that is still used depends on them
+//   - contexts and policies attached to no used stack and without autoattach
+//     labels; login policies are account-wide and always used
+//   - integrations attached to no used stack
+//   - spaces other than root that hold no used resources
+//
+// It also groups the contexts that have the same config and hooks. Those are
+// reported, not unused, since stacks still use each of them.
+func Find(m *discovery.Manifest, staleBefore time.Time) *Report {
+	report := &Report{Resources: []Resource{}, DuplicateContexts: []DuplicateContexts{}}
+	add := func(kind, id, name, space, reason string) {
+		report.Resources = append(report.Resources, Resource{kind, id, name, space, reason})
+	}
+
+	// Stale stacks, less those that a used stack depends on
+	stale := make(map[string]bool)
+	for _, stack := range m.Stacks {
+		if stack.IsDisabled && (stack.StateSetAt == nil || time.Unix(*stack.StateSetAt, 0).Before(staleBefore)) {
+			stale[stack.ID] = true
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, stack := range m.Stacks {
+			if stale[stack.ID] {
+				continue
+			}
+			for _, dep := range stack.DependsOn {
+				if stale[dep.DependsOnStackID] {
+					delete(stale, dep.DependsOnStackID)
+					changed = true
+				}
+			}
+		}
+	}
+
+	var used []models.Stack
+	for _, stack := range m.Stacks {
+		if !stale[stack.ID] {
+			used = append(used, stack)
+			continue
+		}
+		reason := "disabled, no state change recorded"
+		if stack.StateSetAt != nil {
+			setAt := time.Unix(*stack.StateSetAt, 0)
+			reason = fmt.Sprintf("disabled, state last changed %s", setAt.UTC().Format("2006-01-02"))
+		}
+		add(KindStack, stack.ID, stack.Name, stack.Space, reason)
+	}
+
+	// Count the attachments of each resource to used stacks and to any
+	// stack, keyed by kind and ID
+	usedBy := make(map[string]int)
+	attachedTo := make(map[string]int)
+	for _, stack := range m.Stacks {
+		var keys []string
+		for _, a := range stack.AttachedContexts {
+			keys = append(keys, KindContext+" "+a.ContextID)
+		}
+		for _, a := range stack.AttachedPolicies {
+			keys = append(keys, KindPolicy+" "+a.PolicyID)
+		}
+		for _, a := range stack.AttachedAWSIntegrations {
+			keys = append(keys, KindAWSIntegration+" "+a.IntegrationID)
+		}
+		for _, a := range stack.AttachedAzureIntegrations {
+			keys = append(keys, KindAzureIntegration+" "+a.IntegrationID)
+		}
+		for _, key := range keys {
+			attachedTo[key]++
+			if !stale[stack.ID] {
+				usedBy[key]++
+			}
+		}
+	}
+	unattached := func(kind, id string) (string, bool) {
+		key := kind + " " + id
+		switch {
+		case usedBy[key] > 0:
+			return "", false
+		case attachedTo[key] > 0:
+			return "only attached to unused stacks", true
+		default:
+			return "attached to no stack", true
+		}
+	}
+
+	usedSpaces := make(map[string]bool)
+	useSpace := func(id string) {
+		for _, ancestor := range m.SpaceAncestry(id) {
+			usedSpaces[ancestor] = true
+		}
+	}
+	for _, stack := range used {
+		useSpace(stack.Space)
+	}
+
+	for _, c := range m.Contexts {
+		if !discovery.Autoattached(c.Labels) {
+			if reason, ok := unattached(KindContext, c.ID); ok {
+				add(KindContext, c.ID, c.Name, c.Space, reason)
+				continue
+			}
+		}
+		useSpace(c.Space)
+	}
+	for _, p := range m.Policies {
+		if p.Type != models.PolicyTypeLogin && !discovery.Autoattached(p.Labels) {
+			if reason, ok := unattached(KindPolicy, p.ID); ok {
+				add(KindPolicy, p.ID, p.Name, p.Space, reason)
+				continue
+			}
+		}
+		useSpace(p.Space)
+	}
+	for _, i := range m.AWSIntegrations {
+		if reason, ok := unattached(KindAWSIntegration, i.ID); ok {
+			add(KindAWSIntegration, i.ID, i.Name, i.Space, reason)
+			continue
+		}
+		useSpace(i.Space)
+	}
+	for _, i := range m.AzureIntegrations {
+		if reason, ok := unattached(KindAzureIntegration, i.ID); ok {
+			add(KindAzureIntegration, i.ID, i.Name, i.Space, reason)
+			continue
+		}
+		useSpace(i.Space)
+	}
+
+	for _, space := range m.Spaces {
+		if space.ID != "root" && !usedSpaces[space.ID] {
+			parent := ""
+			if space.ParentSpace != nil {
+				parent = *space.ParentSpace
+			}
+			add(KindSpace, space.ID, space.Name, parent, "holds no used resources")
+		}
+	}
+
+	report.DuplicateContexts = duplicateContexts(m.Contexts)
+	return report
+}
+
+// Prune returns a copy of m without the unused resources of the report.
+func (r *Report) Prune(m *discovery.Manifest) *discovery.Manifest {
+	pruned := make(map[string]bool)
+	for _, res := range r.Resources {
+		pruned[res.Kind+" "+res.ID] = true
+	}
+
+	out := &discovery.Manifest{SchemaVersion: m.SchemaVersion, SourceURL: m.SourceURL}
+	for _, space := range m.Spaces {
+		if !pruned[KindSpace+" "+space.ID] {
+			out.Spaces = append(out.Spaces, space)
+		}
+	}
+	for _, stack := range m.Stacks {
+		if !pruned[KindStack+" "+stack.ID] {
+			out.Stacks = append(out.Stacks, stack)
+		}
+	}
+	for _, c := range m.Contexts {
+		if !pruned[KindContext+" "+c.ID] {
+			out.Contexts = append(out.Contexts, c)
+		}
+	}
+	for _, p := range m.Policies {
+		if !pruned[KindPolicy+" "+p.ID] {
+			out.Policies = append(out.Policies, p)
+		}
+	}
+	for _, i := range m.AWSIntegrations {
+		if !pruned[KindAWSIntegration+" "+i.ID] {
+			out.AWSIntegrations = append(out.AWSIntegrations, i)
+		}
+	}
+	for _, i := range m.AzureIntegrations {
+		if !pruned[KindAzureIntegration+" "+i.ID] {
+			out.AzureIntegrations = append(out.AzureIntegrations, i)
+		}
+	}
+	return out
+}
+
+// duplicateContexts groups the contexts with the same config, as
+// discovery.ContextConfigKey compares them.
+func duplicateContexts(contexts []models.Context) []DuplicateContexts {
+	var order []string
+	groups := make(map[string]*DuplicateContexts)
+	for _, c := range contexts {
+		if len(c.Config) == 0 {
+			continue
+		}
+		key := discovery.ContextConfigKey(c)
+		group, ok := groups[key]
+		if !ok {
+			var keys []string
+			for _, e := range c.Config {
+				keys = append(keys, e.ID)
+			}
+			sort.Strings(keys)
+			group = &DuplicateContexts{Keys: keys, Secrets: c.HasSecrets()}
+			groups[key] = group
+			order = append(order, key)
+		}
+		group.Contexts = append(group.Contexts, c.ID)
+	}
+
+	duplicates := []DuplicateContexts{}
+	for _, key := range order {
+		if group := groups[key]; len(group.Contexts) > 1 {
+			duplicates = append(duplicates, *group)
+		}
+	}
+	return duplicates
+}
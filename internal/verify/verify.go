@@ -0,0 +1,212 @@
+// Package verify checks migrated stacks by running a proposed run on each
+// and reading its plan summary. A stack whose state was migrated correctly
+// plans no changes.
+package verify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jnesspace/spacebridge/internal/client"
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// Result statuses.
+const (
+	StatusNoChanges = "no-changes" // The plan is empty
+	StatusDrifted   = "drifted"    // The plan adds, changes or deletes resources
+	StatusFailed    = "failed"     // The run could not be triggered or did not finish
+	StatusTimedOut  = "timed-out"  // The run did not finish before the timeout
+)
+
+// Defaults for Options.
+const (
+	DefaultConcurrency     = 5
+	DefaultPollInterval    = 5 * time.Second
+	DefaultMaxPollInterval = time.Minute
+	DefaultTimeout         = 30 * time.Minute
+)
+
+// maxPollErrors is how many polls in a row may fail before a run is given up.
+const maxPollErrors = 3
+
+// Result is the outcome of verifying one stack.
+type Result struct {
+	StackID   string `json:"stackId"`
+	StackName string `json:"stackName"`
+	RunID     string `json:"runId,omitempty"`
+	RunState  string `json:"runState,omitempty"`
+	Status    string `json:"status"`
+	Added     int    `json:"added"`
+	Changed   int    `json:"changed"`
+	Deleted   int    `json:"deleted"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Summary formats the plan summary, e.g. "+1 ~0 -2".
+func (r Result) Summary() string {
+	return fmt.Sprintf("+%d ~%d -%d", r.Added, r.Changed, r.Deleted)
+}
+
+// Options controls a verification.
+type Options struct {
+	Concurrency     int           // Stacks verified at the same time
+	PollInterval    time.Duration // First wait between polls of a run
+	MaxPollInterval time.Duration // The wait doubles up to this
+	Timeout         time.Duration // Per run, from trigger to finish
+	OnResult        func(Result)  // Called as each stack finishes, never concurrently
+}
+
+// withDefaults fills unset options.
+func (o Options) withDefaults() Options {
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultConcurrency
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = DefaultPollInterval
+	}
+	if o.MaxPollInterval <= 0 {
+		o.MaxPollInterval = DefaultMaxPollInterval
+	}
+	if o.MaxPollInterval < o.PollInterval {
+		o.MaxPollInterval = o.PollInterval
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	return o
+}
+
+// Run triggers a proposed run on every stack, at most opts.Concurrency at
+// a time, and waits for each run to finish. Results are in stack order.
+func Run(ctx context.Context, c *client.Client, stacks []models.Stack, opts Options) []Result {
+	opts = opts.withDefaults()
+
+	results := make([]Result, len(stacks))
+	slots := make(chan struct{}, opts.Concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, stack := range stacks {
+		i, stack := i, stack
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result := verifyStack(ctx, c, stack, opts)
+			results[i] = result
+			if opts.OnResult != nil {
+				mu.Lock()
+				opts.OnResult(result)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// verifyStack triggers a proposed run on one stack and polls it with
+// exponential backoff until it finishes.
+func verifyStack(ctx context.Context, c *client.Client, stack models.Stack, opts Options) Result {
+	result := Result{StackID: stack.ID, StackName: stack.Name}
+
+	runID, err := c.TriggerProposedRun(ctx, stack.ID)
+	if err != nil {
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.RunID = runID
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	interval := opts.PollInterval
+	pollErrors := 0
+	for {
+		select {
+		case <-ctx.Done():
+			result.Status = StatusTimedOut
+			result.Error = fmt.Sprintf("run did not finish within %s", opts.Timeout)
+			return result
+		case <-time.After(interval):
+		}
+
+		if interval *= 2; interval > opts.MaxPollInterval {
+			interval = opts.MaxPollInterval
+		}
+
+		run, err := c.GetRun(ctx, stack.ID, runID)
+		if err != nil {
+			if ctx.Err() != nil {
+				continue // Reported as a timeout
+			}
+			if pollErrors++; pollErrors >= maxPollErrors {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+				return result
+			}
+			continue
+		}
+		pollErrors = 0
+		result.RunState = run.State
+
+		if !run.Finished {
+			continue
+		}
+
+		result.Added = run.Delta.Added
+		result.Changed = run.Delta.Changed
+		result.Deleted = run.Delta.Deleted
+		switch {
+		case run.State != "FINISHED":
+			result.Status = StatusFailed
+			result.Error = "run ended in state " + run.State
+		case result.Added+result.Changed+result.Deleted > 0:
+			result.Status = StatusDrifted
+		default:
+			result.Status = StatusNoChanges
+		}
+		return result
+	}
+}
+
+// Report lists the stacks that did not verify.
+type Report struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	Destination string    `json:"destination"`
+	Total       int       `json:"total"`
+	NoChanges   int       `json:"noChanges"`
+	Drifted     int       `json:"drifted"`
+	Failed      int       `json:"failed"` // Failed and timed-out runs
+	Stacks      []Result  `json:"stacks"` // Drifted, failed and timed-out stacks only
+}
+
+// NewReport summarizes results.
+func NewReport(destination string, results []Result) Report {
+	report := Report{
+		GeneratedAt: time.Now().UTC(),
+		Destination: destination,
+		Total:       len(results),
+		Stacks:      []Result{},
+	}
+	for _, r := range results {
+		switch r.Status {
+		case StatusNoChanges:
+			report.NoChanges++
+			continue
+		case StatusDrifted:
+			report.Drifted++
+		default:
+			report.Failed++
+		}
+		report.Stacks = append(report.Stacks, r)
+	}
+	return report
+}
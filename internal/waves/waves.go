@@ -0,0 +1,192 @@
+// Package waves orders stacks into cutover waves from their dependencies,
+// so that every stack moves after the stacks it depends on.
+package waves
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// Wave is a group of stacks that can be cut over together. None of them
+// depends on another stack in the same or a later wave.
+type Wave struct {
+	Number int // 1-based
+	Stacks []models.Stack
+}
+
+// CycleError reports a dependency cycle, which has no valid order.
+type CycleError struct {
+	Cycle []string // Stack names, the first repeated at the end
+}
+
+func (e *CycleError) Error() string {
+	return "stack dependency cycle: " + strings.Join(e.Cycle, " → ")
+}
+
+// Graph is the dependency DAG of a set of stacks. Dependencies on stacks
+// outside the set are not edges; they are reported by External.
+type Graph struct {
+	stacks    map[string]models.Stack
+	dependsOn map[string][]string // Stack ID -> IDs it depends on, within the set
+	external  map[string][]string // Stack ID -> IDs it depends on, outside the set
+}
+
+// NewGraph builds the dependency graph of stacks.
+func NewGraph(stacks []models.Stack) *Graph {
+	g := &Graph{
+		stacks:    make(map[string]models.Stack, len(stacks)),
+		dependsOn: make(map[string][]string),
+		external:  make(map[string][]string),
+	}
+	for _, s := range stacks {
+		g.stacks[s.ID] = s
+	}
+	for _, s := range stacks {
+		for _, dep := range s.DependsOn {
+			if _, ok := g.stacks[dep.DependsOnStackID]; ok {
+				g.dependsOn[s.ID] = append(g.dependsOn[s.ID], dep.DependsOnStackID)
+			} else {
+				g.external[s.ID] = append(g.external[s.ID], dep.DependsOnStackID)
+			}
+		}
+	}
+	return g
+}
+
+// DependsOn returns the stacks in the set that a stack depends on, by name.
+func (g *Graph) DependsOn(stackID string) []string {
+	return g.names(g.dependsOn[stackID])
+}
+
+// External returns the IDs of stacks outside the set that a stack depends on.
+func (g *Graph) External(stackID string) []string {
+	return g.external[stackID]
+}
+
+// Waves computes the topological waves: wave 1 holds stacks without
+// dependencies, and every later wave holds the stacks whose dependencies
+// are all in earlier waves. A maxSize above zero splits larger waves into
+// consecutive waves of at most maxSize stacks. Stacks are sorted by name
+// within a wave, so the schedule is the same on every run.
+func (g *Graph) Waves(maxSize int) ([]Wave, error) {
+	remaining := make(map[string]int, len(g.stacks)) // Stack ID -> unmet dependencies
+	dependents := make(map[string][]string)
+	for id := range g.stacks {
+		remaining[id] = len(g.dependsOn[id])
+		for _, dep := range g.dependsOn[id] {
+			dependents[dep] = append(dependents[dep], id)
+		}
+	}
+
+	var level []string
+	for id, n := range remaining {
+		if n == 0 {
+			level = append(level, id)
+		}
+	}
+
+	var waves []Wave
+	placed := 0
+	for len(level) > 0 {
+		g.sortByName(level)
+		for start := 0; start < len(level); {
+			end := len(level)
+			if maxSize > 0 && start+maxSize < end {
+				end = start + maxSize
+			}
+			wave := Wave{Number: len(waves) + 1}
+			for _, id := range level[start:end] {
+				wave.Stacks = append(wave.Stacks, g.stacks[id])
+			}
+			waves = append(waves, wave)
+			start = end
+		}
+		placed += len(level)
+
+		var next []string
+		for _, id := range level {
+			for _, dependent := range dependents[id] {
+				remaining[dependent]--
+				if remaining[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		level = next
+	}
+
+	if placed < len(g.stacks) {
+		return nil, &CycleError{Cycle: g.findCycle(remaining)}
+	}
+	return waves, nil
+}
+
+// findCycle returns a cycle among the stacks that could not be placed.
+// Every such stack has an unmet dependency that is itself unplaced, so
+// following those dependencies must eventually revisit a stack.
+func (g *Graph) findCycle(remaining map[string]int) []string {
+	var start []string
+	for id, n := range remaining {
+		if n > 0 {
+			start = append(start, id)
+		}
+	}
+	g.sortByName(start)
+
+	seen := make(map[string]int) // Stack ID -> position in path
+	var path []string
+	id := start[0]
+	for {
+		if i, ok := seen[id]; ok {
+			cycle := append(path[i:], id)
+			return g.names(cycle)
+		}
+		seen[id] = len(path)
+		path = append(path, id)
+
+		deps := append([]string(nil), g.dependsOn[id]...)
+		g.sortByName(deps)
+		for _, dep := range deps {
+			if remaining[dep] > 0 {
+				id = dep
+				break
+			}
+		}
+	}
+}
+
+// sortByName sorts stack IDs by stack name, then ID.
+func (g *Graph) sortByName(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := g.stacks[ids[i]], g.stacks[ids[j]]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+}
+
+// names maps stack IDs to stack names.
+func (g *Graph) names(ids []string) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, g.stacks[id].Name)
+	}
+	return names
+}
+
+// Select returns the stacks in wave n of the schedule for stacks, and the
+// number of waves.
+func Select(stacks []models.Stack, n, maxSize int) ([]models.Stack, int, error) {
+	schedule, err := NewGraph(stacks).Waves(maxSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n < 1 || n > len(schedule) {
+		return nil, len(schedule), fmt.Errorf("wave %d does not exist (%d waves)", n, len(schedule))
+	}
+	return schedule[n-1].Stacks, len(schedule), nil
+}
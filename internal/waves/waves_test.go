@@ -0,0 +1,139 @@
+package waves
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jnesspace/spacebridge/internal/models"
+)
+
+// stack returns a stack whose ID is its name, depending on the given stacks.
+func stack(name string, dependsOn ...string) models.Stack {
+	s := models.Stack{ID: name, Name: name}
+	for _, dep := range dependsOn {
+		s.DependsOn = append(s.DependsOn, models.StackDependency{ID: name + "-" + dep, DependsOnStackID: dep})
+	}
+	return s
+}
+
+// format renders a schedule as "1: a b | 2: c".
+func format(schedule []Wave) string {
+	var waves []string
+	for _, wave := range schedule {
+		var names []string
+		for _, s := range wave.Stacks {
+			names = append(names, s.Name)
+		}
+		waves = append(waves, fmt.Sprintf("%d: %s", wave.Number, strings.Join(names, " ")))
+	}
+	return strings.Join(waves, " | ")
+}
+
+func TestWaves(t *testing.T) {
+	tests := []struct {
+		name    string
+		stacks  []models.Stack
+		maxSize int
+		want    string
+	}{
+		{
+			name:   "no dependencies",
+			stacks: []models.Stack{stack("b"), stack("a"), stack("c")},
+			want:   "1: a b c",
+		},
+		{
+			name:   "levels",
+			stacks: []models.Stack{stack("app", "network", "db"), stack("db", "network"), stack("network"), stack("dns")},
+			want:   "1: dns network | 2: db | 3: app",
+		},
+		{
+			name:   "diamond",
+			stacks: []models.Stack{stack("d", "b", "c"), stack("c", "a"), stack("b", "a"), stack("a")},
+			want:   "1: a | 2: b c | 3: d",
+		},
+		{
+			name:   "dependency outside the set",
+			stacks: []models.Stack{stack("app", "elsewhere"), stack("db")},
+			want:   "1: app db",
+		},
+		{
+			name:    "split by name",
+			stacks:  []models.Stack{stack("e"), stack("d"), stack("c"), stack("b"), stack("a"), stack("f", "a")},
+			maxSize: 2,
+			want:    "1: a b | 2: c d | 3: e | 4: f",
+		},
+		{
+			name:    "split larger than the wave",
+			stacks:  []models.Stack{stack("b"), stack("a", "b")},
+			maxSize: 5,
+			want:    "1: b | 2: a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := NewGraph(tt.stacks).Waves(tt.maxSize)
+			if err != nil {
+				t.Fatalf("Waves: %v", err)
+			}
+			if got := format(schedule); got != tt.want {
+				t.Errorf("Waves = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWavesCycle(t *testing.T) {
+	tests := []struct {
+		name   string
+		stacks []models.Stack
+		want   string
+	}{
+		{
+			name:   "two stacks",
+			stacks: []models.Stack{stack("a", "b"), stack("b", "a")},
+			want:   "a → b → a",
+		},
+		{
+			name:   "cycle behind a placed stack",
+			stacks: []models.Stack{stack("base"), stack("x", "base", "z"), stack("y", "x"), stack("z", "y")},
+			want:   "x → z → y → x",
+		},
+		{
+			name:   "self dependency",
+			stacks: []models.Stack{stack("ok"), stack("self", "self")},
+			want:   "self → self",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGraph(tt.stacks).Waves(0)
+			var cycle *CycleError
+			if !errors.As(err, &cycle) {
+				t.Fatalf("Waves error = %v, want a CycleError", err)
+			}
+			if got := strings.Join(cycle.Cycle, " → "); got != tt.want {
+				t.Errorf("Cycle = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	stacks := []models.Stack{stack("c", "a"), stack("b"), stack("a")}
+
+	selected, total, err := Select(stacks, 1, 1)
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if total != 3 || len(selected) != 1 || selected[0].Name != "a" {
+		t.Errorf("Select(1) = %v of %d waves, want [a] of 3", selected, total)
+	}
+
+	if _, total, err := Select(stacks, 4, 1); err == nil || total != 3 {
+		t.Errorf("Select(4) = %d waves, %v; want an error for a missing wave", total, err)
+	}
+}
@@ -10,11 +10,18 @@ import (
 // MigrationConfig holds the configuration for migration transformations.
 type MigrationConfig struct {
 	Destination DestinationConfig `yaml:"destination"`
+	Spaces      SpacesConfig      `yaml:"spaces,omitempty"`
+	Transforms  []TransformRule   `yaml:"transforms,omitempty"`
+	Secrets     SecretsConfig     `yaml:"secrets,omitempty"`
 }
 
 // DestinationConfig holds destination-specific configuration.
 type DestinationConfig struct {
+	// VCS is applied to every stack that no VCS rule matches
 	VCS VCSConfig `yaml:"vcs"`
+
+	// VCSRules map stacks to different VCS integrations, first match wins
+	VCSRules []VCSRule `yaml:"vcs_rules,omitempty"`
 }
 
 // VCSConfig holds VCS integration configuration for the destination.
@@ -92,56 +99,99 @@ func LoadMigrationConfig(path string) (*MigrationConfig, error) {
 
 // Validate checks if the configuration is valid.
 func (c *MigrationConfig) Validate() error {
-	vcs := &c.Destination.VCS
+	if err := c.Destination.VCS.validate("", true); err != nil {
+		return err
+	}
+
+	for i := range c.Destination.VCSRules {
+		rule := &c.Destination.VCSRules[i]
+		prefix := fmt.Sprintf("vcs_rules[%d] (%s): ", i, rule.DisplayName(i))
+		if err := rule.compile(); err != nil {
+			return fmt.Errorf("%s%w", prefix, err)
+		}
+		if err := rule.VCS.validate(prefix+"vcs.", false); err != nil {
+			return err
+		}
+		if !rule.VCS.HasVCSOverride() && rule.Rewrite.Namespace == "" && rule.Rewrite.Repository == "" {
+			return fmt.Errorf("%sa vcs block or rewrite is required", prefix)
+		}
+		// The namespace lives in the vcs block, so without one there is
+		// nowhere to write a rewritten namespace
+		if rule.Rewrite.Namespace != "" && !rule.VCS.HasVCSOverride() && !c.Destination.VCS.HasVCSOverride() {
+			return fmt.Errorf("%srewrite.namespace needs a vcs block, on the rule or in destination.vcs", prefix)
+		}
+	}
+
+	if err := c.Spaces.validate(); err != nil {
+		return err
+	}
+
+	for i := range c.Transforms {
+		rule := &c.Transforms[i]
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("transforms[%d] (%s): %w", i, rule.DisplayName(i), err)
+		}
+	}
+
+	if err := c.Secrets.validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validate checks a VCS block. Rules may omit the namespace (or project) to
+// keep the stack's own, so requireNamespace is false for them.
+func (v *VCSConfig) validate(prefix string, requireNamespace bool) error {
 	count := 0
-	if vcs.GithubEnterprise != nil {
+	if v.GithubEnterprise != nil {
 		count++
-		if vcs.GithubEnterprise.ID == "" {
-			return fmt.Errorf("github_enterprise.id is required")
+		if v.GithubEnterprise.ID == "" {
+			return fmt.Errorf("%sgithub_enterprise.id is required", prefix)
 		}
-		if vcs.GithubEnterprise.Namespace == "" {
-			return fmt.Errorf("github_enterprise.namespace is required")
+		if requireNamespace && v.GithubEnterprise.Namespace == "" {
+			return fmt.Errorf("%sgithub_enterprise.namespace is required", prefix)
 		}
 	}
-	if vcs.Gitlab != nil {
+	if v.Gitlab != nil {
 		count++
-		if vcs.Gitlab.ID == "" {
-			return fmt.Errorf("gitlab.id is required")
+		if v.Gitlab.ID == "" {
+			return fmt.Errorf("%sgitlab.id is required", prefix)
 		}
-		if vcs.Gitlab.Namespace == "" {
-			return fmt.Errorf("gitlab.namespace is required")
+		if requireNamespace && v.Gitlab.Namespace == "" {
+			return fmt.Errorf("%sgitlab.namespace is required", prefix)
 		}
 	}
-	if vcs.BitbucketDatacenter != nil {
+	if v.BitbucketDatacenter != nil {
 		count++
-		if vcs.BitbucketDatacenter.ID == "" {
-			return fmt.Errorf("bitbucket_datacenter.id is required")
+		if v.BitbucketDatacenter.ID == "" {
+			return fmt.Errorf("%sbitbucket_datacenter.id is required", prefix)
 		}
-		if vcs.BitbucketDatacenter.Namespace == "" {
-			return fmt.Errorf("bitbucket_datacenter.namespace is required")
+		if requireNamespace && v.BitbucketDatacenter.Namespace == "" {
+			return fmt.Errorf("%sbitbucket_datacenter.namespace is required", prefix)
 		}
 	}
-	if vcs.BitbucketCloud != nil {
+	if v.BitbucketCloud != nil {
 		count++
-		if vcs.BitbucketCloud.ID == "" {
-			return fmt.Errorf("bitbucket_cloud.id is required")
+		if v.BitbucketCloud.ID == "" {
+			return fmt.Errorf("%sbitbucket_cloud.id is required", prefix)
 		}
-		if vcs.BitbucketCloud.Namespace == "" {
-			return fmt.Errorf("bitbucket_cloud.namespace is required")
+		if requireNamespace && v.BitbucketCloud.Namespace == "" {
+			return fmt.Errorf("%sbitbucket_cloud.namespace is required", prefix)
 		}
 	}
-	if vcs.AzureDevops != nil {
+	if v.AzureDevops != nil {
 		count++
-		if vcs.AzureDevops.ID == "" {
-			return fmt.Errorf("azure_devops.id is required")
+		if v.AzureDevops.ID == "" {
+			return fmt.Errorf("%sazure_devops.id is required", prefix)
 		}
-		if vcs.AzureDevops.Project == "" {
-			return fmt.Errorf("azure_devops.project is required")
+		if requireNamespace && v.AzureDevops.Project == "" {
+			return fmt.Errorf("%sazure_devops.project is required", prefix)
 		}
 	}
 
 	if count > 1 {
-		return fmt.Errorf("only one VCS integration type can be configured")
+		return fmt.Errorf("%sonly one VCS integration type can be configured", prefix)
 	}
 
 	return nil
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Secret source types.
+const (
+	SecretSourceEnv    = "env"    // Environment variables
+	SecretSourceDotenv = "dotenv" // A dotenv file
+	SecretSourceSOPS   = "sops"   // A SOPS-encrypted YAML or JSON file
+	SecretSourcePass   = "pass"   // A pass (or age-based passage) password store
+	SecretSourceVault  = "vault"  // A HashiCorp Vault KV mount
+)
+
+// DefaultSecretPrefix is the variable prefix used by env and dotenv sources.
+const DefaultSecretPrefix = "SPACEBRIDGE_SECRET_"
+
+// SecretsConfig configures where write-only context values are read from.
+type SecretsConfig struct {
+	// Sources are tried in order; the first source that has a value wins
+	Sources []SecretSource `yaml:"sources,omitempty"`
+}
+
+// SecretSource configures a single secret provider.
+//
+// Secrets are looked up by context name and config key (falling back to the
+// context ID). Environment and dotenv sources use variables named
+// <prefix><CONTEXT>__<KEY>, upper-cased with non-alphanumerics replaced by
+// underscores. SOPS files are maps of context -> key -> value. pass and Vault
+// read <path>/<context>/<key> and <path>/<context> respectively.
+type SecretSource struct {
+	// Type is one of env, dotenv, sops, pass or vault
+	Type string `yaml:"type"`
+
+	// Prefix is the variable prefix for env and dotenv (default SPACEBRIDGE_SECRET_)
+	Prefix *string `yaml:"prefix,omitempty"`
+
+	// Path is the dotenv or SOPS file, the pass store folder, or the Vault
+	// path below the mount
+	Path string `yaml:"path,omitempty"`
+
+	// Command overrides the binary for sops and pass (e.g. passage for age)
+	Command string `yaml:"command,omitempty"`
+
+	// Address is the Vault address (default $VAULT_ADDR)
+	Address string `yaml:"address,omitempty"`
+
+	// Mount is the Vault KV mount (default secret)
+	Mount string `yaml:"mount,omitempty"`
+
+	// KVVersion is the version of the Vault KV engine at Mount, 1 or 2 (default 2)
+	KVVersion int `yaml:"kv_version,omitempty"`
+
+	// TokenEnv names the environment variable holding the Vault token (default VAULT_TOKEN)
+	TokenEnv string `yaml:"token_env,omitempty"`
+}
+
+// ParseSecretSource parses a command-line secret source of the form
+// type[:path], e.g. "env", "dotenv:.env.secrets", "sops:secrets.enc.yaml",
+// "pass:spacelift", "passage:spacelift" or "vault:migration/contexts".
+func ParseSecretSource(spec string) (SecretSource, error) {
+	kind, path, _ := strings.Cut(spec, ":")
+	src := SecretSource{Type: kind, Path: path}
+	if kind == "passage" {
+		src.Type = SecretSourcePass
+		src.Command = "passage"
+	}
+	if err := src.validate(); err != nil {
+		return SecretSource{}, fmt.Errorf("invalid secret source %q: %w", spec, err)
+	}
+	return src, nil
+}
+
+// EnvPrefix returns the variable prefix for env and dotenv sources.
+func (s *SecretSource) EnvPrefix() string {
+	if s.Prefix == nil {
+		return DefaultSecretPrefix
+	}
+	return *s.Prefix
+}
+
+// String describes the source for reports.
+func (s *SecretSource) String() string {
+	name := s.Type
+	if s.Type == SecretSourcePass && s.Command != "" {
+		name = s.Command
+	}
+	if s.Path != "" {
+		return name + ":" + s.Path
+	}
+	return name
+}
+
+// validate checks a secret source.
+func (s *SecretSource) validate() error {
+	switch s.Type {
+	case SecretSourceEnv, SecretSourcePass:
+	case SecretSourceVault:
+		if s.KVVersion != 0 && s.KVVersion != 1 && s.KVVersion != 2 {
+			return fmt.Errorf("vault kv_version must be 1 or 2, got %d", s.KVVersion)
+		}
+	case SecretSourceDotenv, SecretSourceSOPS:
+		if s.Path == "" {
+			return fmt.Errorf("%s requires a file path", s.Type)
+		}
+	default:
+		return fmt.Errorf("unknown secret source type %q (expected env, dotenv, sops, pass or vault)", s.Type)
+	}
+	return nil
+}
+
+// validate checks the secrets configuration.
+func (c *SecretsConfig) validate() error {
+	for i := range c.Sources {
+		if err := c.Sources[i].validate(); err != nil {
+			return fmt.Errorf("secrets.sources[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
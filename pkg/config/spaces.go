@@ -0,0 +1,62 @@
+package config
+
+import "fmt"
+
+// SpacesConfig controls where source spaces land in the destination account.
+type SpacesConfig struct {
+	// RootParent is the destination space ID that top-level source spaces
+	// (children of root) are created under. Defaults to root.
+	RootParent string `yaml:"root_parent,omitempty"`
+
+	// Reparent creates specific source spaces under an existing destination
+	// space: source space ID -> destination parent space ID.
+	Reparent map[string]string `yaml:"reparent,omitempty"`
+
+	// Map points source spaces at existing destination spaces: source space
+	// ID -> destination space ID. Mapped spaces are not generated; resources
+	// in them reference the destination space by ID.
+	Map map[string]string `yaml:"map,omitempty"`
+
+	// NamePrefix is prepended to the name of every generated space
+	NamePrefix string `yaml:"name_prefix,omitempty"`
+}
+
+// MappedSpace returns the destination space ID a source space is mapped
+// onto, if any.
+func (s *SpacesConfig) MappedSpace(sourceID string) (string, bool) {
+	destID, ok := s.Map[sourceID]
+	return destID, ok
+}
+
+// ParentOverride returns the destination parent space ID for a generated
+// source space, if the config re-parents it.
+func (s *SpacesConfig) ParentOverride(sourceID string, sourceParent string) (string, bool) {
+	if destID, ok := s.Reparent[sourceID]; ok {
+		return destID, true
+	}
+	if sourceParent == "root" && s.RootParent != "" {
+		return s.RootParent, true
+	}
+	return "", false
+}
+
+// validate checks the spaces configuration.
+func (s *SpacesConfig) validate() error {
+	for sourceID, destID := range s.Map {
+		if destID == "" {
+			return fmt.Errorf("spaces.map[%s]: destination space ID is required", sourceID)
+		}
+		if _, ok := s.Reparent[sourceID]; ok {
+			return fmt.Errorf("spaces: %s cannot be both mapped and re-parented", sourceID)
+		}
+	}
+	for sourceID, destID := range s.Reparent {
+		if destID == "" {
+			return fmt.Errorf("spaces.reparent[%s]: destination parent space ID is required", sourceID)
+		}
+		if sourceID == "root" {
+			return fmt.Errorf("spaces.reparent: root cannot be re-parented, use spaces.root_parent or spaces.map")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Transform rule types.
+const (
+	TransformRename       = "rename"        // Regex rename of resource names
+	TransformAddLabel     = "add_label"     // Add a label
+	TransformRemoveLabel  = "remove_label"  // Remove labels matching a pattern
+	TransformRewriteLabel = "rewrite_label" // Regex rewrite of labels
+	TransformSetField     = "set_field"     // Set (or regex-rewrite) a stack field
+	TransformVersionMap   = "version_map"   // Map Terraform versions, e.g. 1.5.x -> OpenTofu 1.8
+)
+
+// Transform targets.
+const (
+	TargetStacks   = "stacks"
+	TargetContexts = "contexts"
+	TargetPolicies = "policies"
+	TargetSpaces   = "spaces"
+)
+
+// TransformRule is a single bulk edit applied to the manifest before
+// generation. Rules are applied in order.
+type TransformRule struct {
+	// Name identifies the rule in previews (defaults to "<type> #N")
+	Name string `yaml:"name,omitempty"`
+
+	// Type is one of rename, add_label, remove_label, rewrite_label, set_field or version_map
+	Type string `yaml:"type"`
+
+	// Targets lists the resource kinds the rule applies to (default: stacks).
+	// set_field and version_map only apply to stacks.
+	Targets []string `yaml:"targets,omitempty"`
+
+	// Match restricts the rule to some resources
+	Match TransformMatch `yaml:"match,omitempty"`
+
+	// Pattern is a regular expression used by rename, remove_label,
+	// rewrite_label, and optionally set_field
+	Pattern string `yaml:"pattern,omitempty"`
+
+	// Replacement is used by rename and rewrite_label; capture groups are
+	// available as $1, ${name}, etc.
+	Replacement string `yaml:"replacement,omitempty"`
+
+	// Label is the label added by add_label (or removed by remove_label)
+	Label string `yaml:"label,omitempty"`
+
+	// Field and Value are used by set_field. When Pattern is set, the field
+	// is only changed if it matches, and Value may reference capture groups.
+	Field string `yaml:"field,omitempty"`
+	Value string `yaml:"value,omitempty"`
+
+	// From, To and WorkflowTool are used by version_map. From is a regular
+	// expression matched against terraform_version; WorkflowTool optionally
+	// switches the stack tool (e.g. OPEN_TOFU).
+	From         string `yaml:"from,omitempty"`
+	To           string `yaml:"to,omitempty"`
+	WorkflowTool string `yaml:"workflow_tool,omitempty"`
+}
+
+// TransformMatch restricts a transform rule. All non-empty conditions must match.
+type TransformMatch struct {
+	Name  string `yaml:"name,omitempty"`  // Regular expression on the resource name
+	Label string `yaml:"label,omitempty"` // Label that must be present
+	Space string `yaml:"space,omitempty"` // Space ID; also matches child spaces
+}
+
+// StackFields lists the stack fields that set_field can change.
+var StackFields = []string{
+	"branch",
+	"repository",
+	"namespace",
+	"project_root",
+	"description",
+	"runner_image",
+	"terraform_version",
+	"terragrunt_version",
+	"workflow_tool",
+}
+
+// DisplayName returns the rule name used in previews.
+func (r *TransformRule) DisplayName(index int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s #%d", r.Type, index+1)
+}
+
+// TargetList returns the rule targets, defaulting to stacks.
+func (r *TransformRule) TargetList() []string {
+	if len(r.Targets) == 0 {
+		return []string{TargetStacks}
+	}
+	return r.Targets
+}
+
+// validate checks a transform rule.
+func (r *TransformRule) validate() error {
+	for _, target := range r.TargetList() {
+		switch target {
+		case TargetStacks, TargetContexts, TargetPolicies, TargetSpaces:
+		default:
+			return fmt.Errorf("unknown target %q", target)
+		}
+	}
+
+	for field, pattern := range map[string]string{
+		"pattern":    r.Pattern,
+		"from":       r.From,
+		"match.name": r.Match.Name,
+	} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid %s: %w", field, err)
+		}
+	}
+
+	stacksOnly := func() error {
+		for _, target := range r.TargetList() {
+			if target != TargetStacks {
+				return fmt.Errorf("%s only applies to stacks", r.Type)
+			}
+		}
+		return nil
+	}
+
+	switch r.Type {
+	case TransformRename, TransformRewriteLabel:
+		if r.Pattern == "" {
+			return fmt.Errorf("pattern is required")
+		}
+	case TransformAddLabel:
+		if r.Label == "" {
+			return fmt.Errorf("label is required")
+		}
+	case TransformRemoveLabel:
+		if r.Label == "" && r.Pattern == "" {
+			return fmt.Errorf("label or pattern is required")
+		}
+	case TransformSetField:
+		if err := stacksOnly(); err != nil {
+			return err
+		}
+		if !containsString(StackFields, r.Field) {
+			return fmt.Errorf("unsupported field %q (supported: %v)", r.Field, StackFields)
+		}
+	case TransformVersionMap:
+		if err := stacksOnly(); err != nil {
+			return err
+		}
+		if r.From == "" || r.To == "" {
+			return fmt.Errorf("from and to are required")
+		}
+	default:
+		return fmt.Errorf("unknown transform type %q", r.Type)
+	}
+
+	return nil
+}
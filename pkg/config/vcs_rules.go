@@ -0,0 +1,236 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// VCSRule maps matching source stacks onto a destination VCS integration.
+// Rules are evaluated in order and the first match wins.
+type VCSRule struct {
+	// Name identifies the rule in reports (defaults to "rule N")
+	Name string `yaml:"name,omitempty"`
+
+	// Match selects which source stacks the rule applies to
+	Match VCSMatch `yaml:"match"`
+
+	// VCS is the destination VCS block for matching stacks. The namespace
+	// (or project) may be omitted to keep the stack's own namespace.
+	VCS VCSConfig `yaml:"vcs"`
+
+	// Rewrite optionally changes the namespace or repository of matching stacks
+	Rewrite VCSRewrite `yaml:"rewrite,omitempty"`
+
+	repositoryRe *regexp.Regexp
+}
+
+// VCSMatch holds the conditions of a VCS rule. All non-empty conditions must match.
+type VCSMatch struct {
+	Provider   string `yaml:"provider,omitempty"`   // Source VCS provider (GITHUB, GITLAB, BITBUCKET_DATACENTER, ...)
+	Namespace  string `yaml:"namespace,omitempty"`  // Source namespace (organization, group or project key)
+	Repository string `yaml:"repository,omitempty"` // Regular expression matched against the repository name
+	Space      string `yaml:"space,omitempty"`      // Space ID; also matches stacks in child spaces
+	Label      string `yaml:"label,omitempty"`      // Stack label that must be present
+}
+
+// VCSRewrite changes source VCS settings for the destination.
+type VCSRewrite struct {
+	// Namespace replaces the stack namespace
+	Namespace string `yaml:"namespace,omitempty"`
+
+	// Repository replaces the repository name. When match.repository is set,
+	// capture groups can be referenced as $1, ${name}, etc.
+	Repository string `yaml:"repository,omitempty"`
+}
+
+// VCSSubject describes the source stack fields that VCS rules match on.
+type VCSSubject struct {
+	Provider   string
+	Namespace  string
+	Repository string
+	Spaces     []string // The stack's space followed by its ancestors
+	Labels     []string
+}
+
+// VCSResolution is the outcome of applying VCS rules to a stack.
+type VCSResolution struct {
+	Rule       *VCSRule   // Matched rule, nil if the global override or nothing applied
+	RuleName   string     // Display name of the matched rule, "destination.vcs" or ""
+	VCS        *VCSConfig // Destination VCS block with namespace filled in, nil to keep the default provider
+	Namespace  string     // Effective namespace after rewrites
+	Repository string     // Effective repository after rewrites
+}
+
+// DisplayName returns the rule name used in reports.
+func (r *VCSRule) DisplayName(index int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("rule %d", index+1)
+}
+
+// compile prepares the rule's regular expression.
+func (r *VCSRule) compile() error {
+	if r.Match.Repository == "" {
+		r.repositoryRe = nil
+		return nil
+	}
+	re, err := regexp.Compile(r.Match.Repository)
+	if err != nil {
+		return fmt.Errorf("invalid match.repository: %w", err)
+	}
+	r.repositoryRe = re
+	return nil
+}
+
+// Matches returns true if the rule applies to the given stack.
+func (r *VCSRule) Matches(s VCSSubject) bool {
+	m := r.Match
+	if m.Provider != "" && m.Provider != s.Provider {
+		return false
+	}
+	if m.Namespace != "" && m.Namespace != s.Namespace {
+		return false
+	}
+	if m.Repository != "" {
+		if r.repositoryRe == nil {
+			if err := r.compile(); err != nil {
+				return false
+			}
+		}
+		if !r.repositoryRe.MatchString(s.Repository) {
+			return false
+		}
+	}
+	if m.Space != "" && !containsString(s.Spaces, m.Space) {
+		return false
+	}
+	if m.Label != "" && !containsString(s.Labels, m.Label) {
+		return false
+	}
+	return true
+}
+
+// ResolveVCS determines the destination VCS settings for a stack. Rules are
+// tried in order; if none match, the global destination.vcs override is used.
+// A matching rule without a vcs block only rewrites, and also uses the
+// global override, with the rewritten namespace.
+func (c *MigrationConfig) ResolveVCS(s VCSSubject) VCSResolution {
+	res := VCSResolution{
+		Namespace:  s.Namespace,
+		Repository: s.Repository,
+	}
+
+	for i := range c.Destination.VCSRules {
+		rule := &c.Destination.VCSRules[i]
+		if !rule.Matches(s) {
+			continue
+		}
+
+		res.Rule = rule
+		res.RuleName = rule.DisplayName(i)
+		if rule.Rewrite.Namespace != "" {
+			res.Namespace = rule.Rewrite.Namespace
+		}
+		if rule.Rewrite.Repository != "" {
+			if rule.repositoryRe != nil {
+				res.Repository = rule.repositoryRe.ReplaceAllString(s.Repository, rule.Rewrite.Repository)
+			} else {
+				res.Repository = rule.Rewrite.Repository
+			}
+		}
+		if rule.VCS.HasVCSOverride() {
+			res.VCS = rule.VCS.withDefaultNamespace(res.Namespace)
+		} else if c.Destination.VCS.HasVCSOverride() {
+			vcs := c.Destination.VCS
+			if rule.Rewrite.Namespace != "" {
+				vcs = *vcs.withNamespace(res.Namespace)
+			}
+			res.VCS = &vcs
+		}
+		return res
+	}
+
+	if c.Destination.VCS.HasVCSOverride() {
+		res.RuleName = "destination.vcs"
+		res.VCS = &c.Destination.VCS
+	}
+	return res
+}
+
+// withDefaultNamespace returns a copy of the VCS block where an empty
+// namespace (or Azure DevOps project) is replaced by the given namespace.
+func (v VCSConfig) withDefaultNamespace(namespace string) *VCSConfig {
+	if v.GithubEnterprise != nil && v.GithubEnterprise.Namespace == "" {
+		c := *v.GithubEnterprise
+		c.Namespace = namespace
+		v.GithubEnterprise = &c
+	}
+	if v.Gitlab != nil && v.Gitlab.Namespace == "" {
+		c := *v.Gitlab
+		c.Namespace = namespace
+		v.Gitlab = &c
+	}
+	if v.BitbucketDatacenter != nil && v.BitbucketDatacenter.Namespace == "" {
+		c := *v.BitbucketDatacenter
+		c.Namespace = namespace
+		v.BitbucketDatacenter = &c
+	}
+	if v.BitbucketCloud != nil && v.BitbucketCloud.Namespace == "" {
+		c := *v.BitbucketCloud
+		c.Namespace = namespace
+		v.BitbucketCloud = &c
+	}
+	if v.AzureDevops != nil && v.AzureDevops.Project == "" {
+		c := *v.AzureDevops
+		c.Project = namespace
+		v.AzureDevops = &c
+	}
+	return &v
+}
+
+// withNamespace returns a copy of the VCS block with its namespace (or Azure
+// DevOps project) replaced by the given namespace.
+func (v VCSConfig) withNamespace(namespace string) *VCSConfig {
+	if v.GithubEnterprise != nil {
+		c := *v.GithubEnterprise
+		c.Namespace = ""
+		v.GithubEnterprise = &c
+	}
+	if v.Gitlab != nil {
+		c := *v.Gitlab
+		c.Namespace = ""
+		v.Gitlab = &c
+	}
+	if v.BitbucketDatacenter != nil {
+		c := *v.BitbucketDatacenter
+		c.Namespace = ""
+		v.BitbucketDatacenter = &c
+	}
+	if v.BitbucketCloud != nil {
+		c := *v.BitbucketCloud
+		c.Namespace = ""
+		v.BitbucketCloud = &c
+	}
+	if v.AzureDevops != nil {
+		c := *v.AzureDevops
+		c.Project = ""
+		v.AzureDevops = &c
+	}
+	return v.withDefaultNamespace(namespace)
+}
+
+// HasVCSRules returns true if any VCS mapping is configured.
+func (c *MigrationConfig) HasVCSRules() bool {
+	return len(c.Destination.VCSRules) > 0 || c.Destination.VCS.HasVCSOverride()
+}
+
+// containsString reports whether a slice contains a value.
+func containsString(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}